@@ -0,0 +1,174 @@
+// This file was generated by counterfeiter
+package allocationfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/allocation"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type FakeDatabase struct {
+	GetDailyCostsStub        func(time.Time, time.Time) ([]datamodels.DailyCost, error)
+	getDailyCostsMutex       sync.RWMutex
+	getDailyCostsArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getDailyCostsReturns struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}
+	ClearAllocationsStub        func(string, int, time.Month, int) error
+	clearAllocationsMutex       sync.RWMutex
+	clearAllocationsArgsForCall []struct {
+		arg1 string
+		arg2 int
+		arg3 time.Month
+		arg4 int
+	}
+	clearAllocationsReturns struct {
+		result1 error
+	}
+	SaveAllocationsStub        func([]datamodels.Allocation) error
+	saveAllocationsMutex       sync.RWMutex
+	saveAllocationsArgsForCall []struct {
+		arg1 []datamodels.Allocation
+	}
+	saveAllocationsReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) GetDailyCosts(arg1 time.Time, arg2 time.Time) ([]datamodels.DailyCost, error) {
+	fake.getDailyCostsMutex.Lock()
+	fake.getDailyCostsArgsForCall = append(fake.getDailyCostsArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetDailyCosts", []interface{}{arg1, arg2})
+	fake.getDailyCostsMutex.Unlock()
+	if fake.GetDailyCostsStub != nil {
+		return fake.GetDailyCostsStub(arg1, arg2)
+	} else {
+		return fake.getDailyCostsReturns.result1, fake.getDailyCostsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetDailyCostsCallCount() int {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return len(fake.getDailyCostsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetDailyCostsArgsForCall(i int) (time.Time, time.Time) {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return fake.getDailyCostsArgsForCall[i].arg1, fake.getDailyCostsArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) GetDailyCostsReturns(result1 []datamodels.DailyCost, result2 error) {
+	fake.GetDailyCostsStub = nil
+	fake.getDailyCostsReturns = struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) ClearAllocations(arg1 string, arg2 int, arg3 time.Month, arg4 int) error {
+	fake.clearAllocationsMutex.Lock()
+	fake.clearAllocationsArgsForCall = append(fake.clearAllocationsArgsForCall, struct {
+		arg1 string
+		arg2 int
+		arg3 time.Month
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("ClearAllocations", []interface{}{arg1, arg2, arg3, arg4})
+	fake.clearAllocationsMutex.Unlock()
+	if fake.ClearAllocationsStub != nil {
+		return fake.ClearAllocationsStub(arg1, arg2, arg3, arg4)
+	} else {
+		return fake.clearAllocationsReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) ClearAllocationsCallCount() int {
+	fake.clearAllocationsMutex.RLock()
+	defer fake.clearAllocationsMutex.RUnlock()
+	return len(fake.clearAllocationsArgsForCall)
+}
+
+func (fake *FakeDatabase) ClearAllocationsArgsForCall(i int) (string, int, time.Month, int) {
+	fake.clearAllocationsMutex.RLock()
+	defer fake.clearAllocationsMutex.RUnlock()
+	return fake.clearAllocationsArgsForCall[i].arg1, fake.clearAllocationsArgsForCall[i].arg2, fake.clearAllocationsArgsForCall[i].arg3, fake.clearAllocationsArgsForCall[i].arg4
+}
+
+func (fake *FakeDatabase) ClearAllocationsReturns(result1 error) {
+	fake.ClearAllocationsStub = nil
+	fake.clearAllocationsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) SaveAllocations(arg1 []datamodels.Allocation) error {
+	fake.saveAllocationsMutex.Lock()
+	fake.saveAllocationsArgsForCall = append(fake.saveAllocationsArgsForCall, struct {
+		arg1 []datamodels.Allocation
+	}{arg1})
+	fake.recordInvocation("SaveAllocations", []interface{}{arg1})
+	fake.saveAllocationsMutex.Unlock()
+	if fake.SaveAllocationsStub != nil {
+		return fake.SaveAllocationsStub(arg1)
+	} else {
+		return fake.saveAllocationsReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveAllocationsCallCount() int {
+	fake.saveAllocationsMutex.RLock()
+	defer fake.saveAllocationsMutex.RUnlock()
+	return len(fake.saveAllocationsArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveAllocationsArgsForCall(i int) []datamodels.Allocation {
+	fake.saveAllocationsMutex.RLock()
+	defer fake.saveAllocationsMutex.RUnlock()
+	return fake.saveAllocationsArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveAllocationsReturns(result1 error) {
+	fake.SaveAllocationsStub = nil
+	fake.saveAllocationsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	fake.clearAllocationsMutex.RLock()
+	defer fake.clearAllocationsMutex.RUnlock()
+	fake.saveAllocationsMutex.RLock()
+	defer fake.saveAllocationsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ allocation.Database = new(FakeDatabase)
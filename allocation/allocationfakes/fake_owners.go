@@ -0,0 +1,69 @@
+// This file was generated by counterfeiter
+package allocationfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/allocation"
+	"github.com/challiwill/meteorologica/ownership"
+)
+
+type FakeOwners struct {
+	DirectoryStub        func() (ownership.Directory, error)
+	directoryMutex       sync.RWMutex
+	directoryArgsForCall []struct{}
+	directoryReturns     struct {
+		result1 ownership.Directory
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeOwners) Directory() (ownership.Directory, error) {
+	fake.directoryMutex.Lock()
+	fake.directoryArgsForCall = append(fake.directoryArgsForCall, struct{}{})
+	fake.recordInvocation("Directory", []interface{}{})
+	fake.directoryMutex.Unlock()
+	if fake.DirectoryStub != nil {
+		return fake.DirectoryStub()
+	} else {
+		return fake.directoryReturns.result1, fake.directoryReturns.result2
+	}
+}
+
+func (fake *FakeOwners) DirectoryCallCount() int {
+	fake.directoryMutex.RLock()
+	defer fake.directoryMutex.RUnlock()
+	return len(fake.directoryArgsForCall)
+}
+
+func (fake *FakeOwners) DirectoryReturns(result1 ownership.Directory, result2 error) {
+	fake.DirectoryStub = nil
+	fake.directoryReturns = struct {
+		result1 ownership.Directory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOwners) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.directoryMutex.RLock()
+	defer fake.directoryMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeOwners) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ allocation.Owners = new(FakeOwners)
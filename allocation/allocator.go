@@ -0,0 +1,206 @@
+package allocation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/ownership"
+)
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+	ClearAllocations(resource string, year int, month time.Month, day int) error
+	SaveAllocations([]datamodels.Allocation) error
+}
+
+//go:generate counterfeiter . Owners
+
+// Owners tells which team owns each cost, for the metric of proportional
+// rules.
+type Owners interface {
+	Directory() (ownership.Directory, error)
+}
+
+// Allocator splits the cost of shared reports across the teams that consume
+// them. Each report is split by the first rule that matches it. Cloud Foundry
+// reports are never split: their cost is already in the reports of the IAAS
+// the foundation runs on.
+type Allocator struct {
+	log    *logrus.Logger
+	db     Database
+	owners Owners
+
+	Rules []Rule
+}
+
+func NewAllocator(log *logrus.Logger, db Database, owners Owners, rules []Rule) (*Allocator, error) {
+	for _, r := range rules {
+		err := r.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Allocator{log: log, db: db, owners: owners, Rules: rules}, nil
+}
+
+func (a *Allocator) Name() string {
+	return "allocation"
+}
+
+// Run allocates the reports saved by the run and saves the allocations next to
+// them, replacing the allocations of every IAAS and day the run saved.
+func (a *Allocator) Run(_ datamodels.Run, reports datamodels.Reports) error {
+	a.log.Debug("Entering allocation.Run")
+	defer a.log.Debug("Returning allocation.Run")
+
+	allocations, err := a.Allocate(reports)
+	if err != nil {
+		return err
+	}
+	cleared := make(map[string]bool)
+	for _, r := range reports {
+		day := fmt.Sprintf("%s %d-%d-%d", r.Resource, r.Year, r.Month, r.Day)
+		if cleared[day] {
+			continue
+		}
+		err = a.db.ClearAllocations(r.Resource, r.Year, r.Month, r.Day)
+		if err != nil {
+			return err
+		}
+		cleared[day] = true
+	}
+	if len(allocations) == 0 {
+		return nil
+	}
+	a.log.Infof("Allocated %d shares of shared costs", len(allocations))
+	return a.db.SaveAllocations(allocations)
+}
+
+// Allocate returns the shares of each report matched by a rule. The shares of
+// a report always add up to its cost.
+func (a *Allocator) Allocate(reports datamodels.Reports) ([]datamodels.Allocation, error) {
+	metrics := &metricCache{db: a.db, owners: a.owners, costs: make(map[string][]datamodels.DailyCost)}
+	allocations := []datamodels.Allocation{}
+	for _, report := range reports {
+		if report.Resource == datamodels.CloudFoundry {
+			continue
+		}
+		rule, ok := a.ruleFor(report)
+		if !ok || report.Cost == 0 {
+			continue
+		}
+		shares, err := a.shares(rule, report, metrics)
+		if err != nil {
+			return nil, err
+		}
+
+		allocated := 0.0
+		for i, target := range rule.Targets {
+			cost := report.Cost * shares[i]
+			if i == len(rule.Targets)-1 {
+				cost = report.Cost - allocated
+			}
+			allocated += cost
+			allocations = append(allocations, datamodels.Allocation{
+				SourceReportID: report.ID,
+				Rule:           rule.Name,
+				Team:           target.Team,
+				Share:          shares[i],
+				AccountNumber:  report.AccountNumber,
+				AccountName:    report.AccountName,
+				Day:            report.Day,
+				Month:          report.Month,
+				Year:           report.Year,
+				ServiceType:    report.ServiceType,
+				Region:         report.Region,
+				Resource:       report.Resource,
+				Cost:           cost,
+			})
+		}
+	}
+	return allocations, nil
+}
+
+func (a *Allocator) ruleFor(report datamodels.Report) (Rule, bool) {
+	for _, r := range a.Rules {
+		if r.Matches(report) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// shares returns the fraction of the report's cost each target is charged.
+// Proportional rules split evenly when none of the targets spent anything.
+func (a *Allocator) shares(rule Rule, report datamodels.Report, metrics *metricCache) ([]float64, error) {
+	shares := make([]float64, len(rule.Targets))
+	switch rule.Method {
+	case Percent:
+		for i, t := range rule.Targets {
+			shares[i] = t.Percent / 100
+		}
+		return shares, nil
+	case Proportional:
+		spend, err := metrics.spend(rule.Metric, time.Date(report.Year, report.Month, report.Day, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			return nil, err
+		}
+		total := 0.0
+		for i, t := range rule.Targets {
+			shares[i] = spend[t.Team]
+			total += shares[i]
+		}
+		if total > 0 {
+			for i := range shares {
+				shares[i] /= total
+			}
+			return shares, nil
+		}
+		a.log.Warnf("None of the teams of allocation rule %s spent anything on %d-%02d-%02d, splitting evenly", rule.Name, report.Year, report.Month, report.Day)
+	}
+	for i := range shares {
+		shares[i] = 1 / float64(len(shares))
+	}
+	return shares, nil
+}
+
+// metricCache reads the costs of each day and the ownership directory once.
+type metricCache struct {
+	db        Database
+	owners    Owners
+	directory *ownership.Directory
+	costs     map[string][]datamodels.DailyCost
+}
+
+// spend returns how much each team spent on the day within the metric.
+func (m *metricCache) spend(metric Metric, date time.Time) (map[string]float64, error) {
+	if m.directory == nil {
+		directory, err := m.owners.Directory()
+		if err != nil {
+			return nil, err
+		}
+		m.directory = &directory
+	}
+	key := date.Format("2006-01-02")
+	costs, ok := m.costs[key]
+	if !ok {
+		var err error
+		costs, err = m.db.GetDailyCosts(date, date)
+		if err != nil {
+			return nil, err
+		}
+		m.costs[key] = costs
+	}
+
+	spend := make(map[string]float64)
+	for _, c := range costs {
+		if metric.matches(c) {
+			spend[m.directory.Owner(c)] += c.Cost
+		}
+	}
+	return spend, nil
+}
@@ -0,0 +1,196 @@
+package allocation_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/allocation"
+	"github.com/challiwill/meteorologica/allocation/allocationfakes"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/ownership"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Allocator", func() {
+	var (
+		log       *logrus.Logger
+		db        *allocationfakes.FakeDatabase
+		owners    *allocationfakes.FakeOwners
+		rules     []Rule
+		allocator *Allocator
+		reports   datamodels.Reports
+	)
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		db = new(allocationfakes.FakeDatabase)
+		owners = new(allocationfakes.FakeOwners)
+		reports = datamodels.Reports{
+			{ID: "shared", Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.October, Day: 3, Cost: 100},
+			{ID: "own", Resource: "AWS", AccountNumber: "456", ServiceType: "EC2", Year: 2016, Month: time.October, Day: 3, Cost: 50},
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		allocator, err = NewAllocator(log, db, owners, rules)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Allocate", func() {
+		Context("with a percent rule", func() {
+			BeforeEach(func() {
+				rules = []Rule{{Name: "cf", Account: "123", Method: Percent, Targets: []Target{{Team: "a", Percent: 70}, {Team: "b", Percent: 30}}}}
+			})
+
+			It("splits the matching reports by the percents", func() {
+				allocations, err := allocator.Allocate(reports)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allocations).To(HaveLen(2))
+				Expect(allocations[0]).To(Equal(datamodels.Allocation{
+					SourceReportID: "shared",
+					Rule:           "cf",
+					Team:           "a",
+					Share:          0.7,
+					AccountNumber:  "123",
+					Year:           2016,
+					Month:          time.October,
+					Day:            3,
+					ServiceType:    "EC2",
+					Resource:       "AWS",
+					Cost:           70,
+				}))
+				Expect(allocations[1].Team).To(Equal("b"))
+				Expect(allocations[1].Cost).To(BeNumerically("~", 30, 0.0001))
+			})
+		})
+
+		Context("with Cloud Foundry reports", func() {
+			BeforeEach(func() {
+				rules = []Rule{{Name: "cf", Resource: datamodels.CloudFoundry, Method: Even, Targets: []Target{{Team: "a"}, {Team: "b"}}}}
+			})
+
+			It("does not allocate them", func() {
+				allocations, err := allocator.Allocate(datamodels.Reports{
+					{ID: "app", Resource: datamodels.CloudFoundry, AccountNumber: "org-guid", Year: 2016, Month: time.October, Day: 3, Cost: 10},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allocations).To(BeEmpty())
+			})
+		})
+
+		Context("with an even rule", func() {
+			BeforeEach(func() {
+				rules = []Rule{{Name: "network", Resource: "AWS", Method: Even, Targets: []Target{{Team: "a"}, {Team: "b"}, {Team: "c"}}}}
+			})
+
+			It("splits the cost evenly and charges every cent", func() {
+				allocations, err := allocator.Allocate(reports[:1])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allocations).To(HaveLen(3))
+				Expect(allocations[0].Share).To(Equal(1.0 / 3))
+				Expect(allocations[0].Cost + allocations[1].Cost + allocations[2].Cost).To(Equal(100.0))
+			})
+		})
+
+		Context("with a proportional rule", func() {
+			BeforeEach(func() {
+				rules = []Rule{{
+					Name:    "cf",
+					Account: "123",
+					Method:  Proportional,
+					Metric:  Metric{ServiceType: "EC2"},
+					Targets: []Target{{Team: "a"}, {Team: "b"}},
+				}}
+				owners.DirectoryReturns(ownership.Directory{Mappings: []datamodels.OwnershipMapping{
+					{Team: "a", AccountNumber: "456"},
+					{Team: "b", AccountNumber: "789"},
+				}}, nil)
+				db.GetDailyCostsReturns([]datamodels.DailyCost{
+					{Resource: "AWS", AccountNumber: "456", ServiceType: "EC2", Cost: 30},
+					{Resource: "AWS", AccountNumber: "789", ServiceType: "EC2", Cost: 10},
+					{Resource: "AWS", AccountNumber: "789", ServiceType: "S3", Cost: 1000},
+				}, nil)
+			})
+
+			It("splits the cost by each team's spend within the metric on the same day", func() {
+				allocations, err := allocator.Allocate(reports)
+				Expect(err).NotTo(HaveOccurred())
+				from, to := db.GetDailyCostsArgsForCall(0)
+				Expect(from).To(Equal(time.Date(2016, time.October, 3, 0, 0, 0, 0, time.UTC)))
+				Expect(to).To(Equal(from))
+				Expect(allocations).To(HaveLen(2))
+				Expect(allocations[0].Cost).To(Equal(75.0))
+				Expect(allocations[1].Cost).To(Equal(25.0))
+			})
+
+			Context("when none of the teams spent anything", func() {
+				BeforeEach(func() {
+					db.GetDailyCostsReturns(nil, nil)
+				})
+
+				It("splits the cost evenly", func() {
+					allocations, err := allocator.Allocate(reports)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(allocations[0].Cost).To(Equal(50.0))
+				})
+			})
+
+			Context("when the spend cannot be read", func() {
+				BeforeEach(func() {
+					db.GetDailyCostsReturns(nil, errors.New("some-error"))
+				})
+
+				It("errors", func() {
+					_, err := allocator.Allocate(reports)
+					Expect(err).To(MatchError("some-error"))
+				})
+			})
+		})
+	})
+
+	Describe("Run", func() {
+		BeforeEach(func() {
+			rules = []Rule{{Name: "cf", Account: "123", Method: Even, Targets: []Target{{Team: "a"}, {Team: "b"}}}}
+		})
+
+		It("replaces the allocations of each IAAS and day of the reports", func() {
+			Expect(allocator.Run(datamodels.Run{}, reports)).To(Succeed())
+			Expect(db.ClearAllocationsCallCount()).To(Equal(1))
+			resource, year, month, day := db.ClearAllocationsArgsForCall(0)
+			Expect(resource).To(Equal("AWS"))
+			Expect([]int{year, int(month), day}).To(Equal([]int{2016, 10, 3}))
+			Expect(db.SaveAllocationsCallCount()).To(Equal(1))
+			Expect(db.SaveAllocationsArgsForCall(0)).To(HaveLen(2))
+		})
+
+		It("still clears the days when no report is shared", func() {
+			Expect(allocator.Run(datamodels.Run{}, reports[1:])).To(Succeed())
+			Expect(db.ClearAllocationsCallCount()).To(Equal(1))
+			Expect(db.SaveAllocationsCallCount()).To(Equal(0))
+		})
+
+		Context("when clearing the allocations fails", func() {
+			BeforeEach(func() {
+				db.ClearAllocationsReturns(errors.New("some-error"))
+			})
+
+			It("returns the error without saving", func() {
+				Expect(allocator.Run(datamodels.Run{}, reports)).To(MatchError("some-error"))
+				Expect(db.SaveAllocationsCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("NewAllocator", func() {
+		It("rejects invalid rules", func() {
+			_, err := NewAllocator(log, db, owners, []Rule{{Name: "cf"}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,93 @@
+package allocation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+const (
+	Percent      = "percent"
+	Proportional = "proportional"
+	Even         = "even"
+)
+
+// Target is a team a shared cost is split across. Percent is only used by
+// rules with the percent method.
+type Target struct {
+	Team    string
+	Percent float64
+}
+
+// Metric scopes the spend that proportional rules split shared costs by: each
+// target team's own spend on the same day, optionally limited to a Resource and
+// ServiceType.
+type Metric struct {
+	Resource    string
+	ServiceType string `yaml:"service-type"`
+}
+
+// Rule splits the cost of the reports it matches across its targets. Reports
+// are matched by the optional Resource, Account and ServiceType.
+type Rule struct {
+	Name        string
+	Resource    string
+	Account     string
+	ServiceType string `yaml:"service-type"`
+	Method      string
+	Targets     []Target
+	Metric      Metric
+}
+
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("Allocation rules must have a name")
+	}
+	if r.Resource == "" && r.Account == "" && r.ServiceType == "" {
+		return fmt.Errorf("Allocation rule %s must match a resource, account or service type", r.Name)
+	}
+	if len(r.Targets) == 0 {
+		return fmt.Errorf("Allocation rule %s must have targets", r.Name)
+	}
+	teams := make(map[string]bool)
+	for _, t := range r.Targets {
+		if t.Team == "" {
+			return fmt.Errorf("Allocation rule %s has a target without a team", r.Name)
+		}
+		if teams[t.Team] {
+			return fmt.Errorf("Allocation rule %s has team %s more than once", r.Name, t.Team)
+		}
+		teams[t.Team] = true
+	}
+
+	switch r.Method {
+	case Percent:
+		total := 0.0
+		for _, t := range r.Targets {
+			if t.Percent <= 0 {
+				return fmt.Errorf("Allocation rule %s must give %s a positive percent", r.Name, t.Team)
+			}
+			total += t.Percent
+		}
+		if math.Abs(total-100) > 0.0001 {
+			return fmt.Errorf("Allocation rule %s splits %g%% of the cost, the percents must add up to 100", r.Name, total)
+		}
+	case Proportional, Even:
+	default:
+		return fmt.Errorf("Allocation rule %s must have a %s, %s or %s method", r.Name, Percent, Proportional, Even)
+	}
+	return nil
+}
+
+// Matches returns whether a report's cost is split by the rule.
+func (r Rule) Matches(report datamodels.Report) bool {
+	return (r.Resource == "" || r.Resource == report.Resource) &&
+		(r.Account == "" || r.Account == report.AccountNumber) &&
+		(r.ServiceType == "" || r.ServiceType == report.ServiceType)
+}
+
+func (m Metric) matches(cost datamodels.DailyCost) bool {
+	return (m.Resource == "" || m.Resource == cost.Resource) &&
+		(m.ServiceType == "" || m.ServiceType == cost.ServiceType)
+}
@@ -0,0 +1,46 @@
+package allocation_test
+
+import (
+	. "github.com/challiwill/meteorologica/allocation"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rule", func() {
+	var rule Rule
+
+	BeforeEach(func() {
+		rule = Rule{
+			Name:    "cf",
+			Account: "123",
+			Method:  Percent,
+			Targets: []Target{{Team: "a", Percent: 60}, {Team: "b", Percent: 40}},
+		}
+	})
+
+	It("is valid", func() {
+		Expect(rule.Validate()).To(Succeed())
+	})
+
+	It("requires the percents to add up to 100", func() {
+		rule.Targets[1].Percent = 30
+		Expect(rule.Validate()).To(MatchError(ContainSubstring("must add up to 100")))
+	})
+
+	It("requires a scope", func() {
+		rule.Account = ""
+		Expect(rule.Validate()).To(MatchError(ContainSubstring("must match")))
+	})
+
+	It("requires a known method", func() {
+		rule.Method = "random"
+		Expect(rule.Validate()).To(HaveOccurred())
+	})
+
+	It("rejects teams that are targeted twice", func() {
+		rule.Method = Even
+		rule.Targets[1].Team = "a"
+		Expect(rule.Validate()).To(MatchError(ContainSubstring("more than once")))
+	})
+})
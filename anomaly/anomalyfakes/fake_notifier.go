@@ -0,0 +1,77 @@
+// This file was generated by counterfeiter
+package anomalyfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/anomaly"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type FakeNotifier struct {
+	NotifyAnomalyStub        func(datamodels.Anomaly) error
+	notifyAnomalyMutex       sync.RWMutex
+	notifyAnomalyArgsForCall []struct {
+		arg1 datamodels.Anomaly
+	}
+	notifyAnomalyReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeNotifier) NotifyAnomaly(arg1 datamodels.Anomaly) error {
+	fake.notifyAnomalyMutex.Lock()
+	fake.notifyAnomalyArgsForCall = append(fake.notifyAnomalyArgsForCall, struct {
+		arg1 datamodels.Anomaly
+	}{arg1})
+	fake.recordInvocation("NotifyAnomaly", []interface{}{arg1})
+	fake.notifyAnomalyMutex.Unlock()
+	if fake.NotifyAnomalyStub != nil {
+		return fake.NotifyAnomalyStub(arg1)
+	} else {
+		return fake.notifyAnomalyReturns.result1
+	}
+}
+
+func (fake *FakeNotifier) NotifyAnomalyCallCount() int {
+	fake.notifyAnomalyMutex.RLock()
+	defer fake.notifyAnomalyMutex.RUnlock()
+	return len(fake.notifyAnomalyArgsForCall)
+}
+
+func (fake *FakeNotifier) NotifyAnomalyArgsForCall(i int) datamodels.Anomaly {
+	fake.notifyAnomalyMutex.RLock()
+	defer fake.notifyAnomalyMutex.RUnlock()
+	return fake.notifyAnomalyArgsForCall[i].arg1
+}
+
+func (fake *FakeNotifier) NotifyAnomalyReturns(result1 error) {
+	fake.NotifyAnomalyStub = nil
+	fake.notifyAnomalyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotifier) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.notifyAnomalyMutex.RLock()
+	defer fake.notifyAnomalyMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeNotifier) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ anomaly.Notifier = new(FakeNotifier)
@@ -0,0 +1,338 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type Method string
+
+const (
+	// ZScore measures how many standard deviations a day is from the mean of
+	// its baseline.
+	ZScore Method = "zscore"
+	// MAD measures how many median absolute deviations a day is from the
+	// median of its baseline, so a past spike does not hide the next one.
+	MAD Method = "mad"
+)
+
+// minimumHistory is the number of baseline days an account needs before its
+// days are checked.
+const minimumHistory = 7
+
+// maxContributingServices is the number of services reported with an anomaly.
+const maxContributingServices = 5
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+	SaveAnomalies([]datamodels.Anomaly) error
+}
+
+//go:generate counterfeiter . Notifier
+
+// Notifier is told about every anomaly found by a run.
+type Notifier interface {
+	NotifyAnomaly(datamodels.Anomaly) error
+}
+
+// Detector flags days on which the cost of an account deviates from the days
+// before it. The baseline is adjusted for each weekday's usual share of the
+// cost so that quiet weekends are not flagged.
+type Detector struct {
+	log *logrus.Logger
+	db  Database
+
+	Method       Method
+	BaselineDays int
+	Threshold    float64
+	MinimumCost  float64
+
+	Notifier Notifier
+}
+
+func NewDetector(log *logrus.Logger, db Database, method Method, baselineDays int, threshold, minimumCost float64) (*Detector, error) {
+	if method != ZScore && method != MAD {
+		return nil, fmt.Errorf("Unknown anomaly detection method %q, must be %q or %q", method, ZScore, MAD)
+	}
+	return &Detector{
+		log: log,
+		db:  db,
+
+		Method:       method,
+		BaselineDays: baselineDays,
+		Threshold:    threshold,
+		MinimumCost:  minimumCost,
+	}, nil
+}
+
+func (d *Detector) Name() string {
+	return "anomaly detection"
+}
+
+// Run checks every account on the days the run saved reports for and saves
+// the anomalies found.
+func (d *Detector) Run(run datamodels.Run, reports datamodels.Reports) error {
+	d.log.Debug("Entering anomaly.Run")
+	defer d.log.Debug("Returning anomaly.Run")
+
+	days := make(map[string][]time.Time)
+	seen := make(map[resourceDay]bool)
+	for _, r := range reports {
+		day := resourceDay{r.Resource, time.Date(r.Year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)}
+		if !seen[day] {
+			seen[day] = true
+			days[r.Resource] = append(days[r.Resource], day.date)
+		}
+	}
+	if len(days) == 0 {
+		return nil
+	}
+
+	anomalies, err := d.Detect(days)
+	if err != nil {
+		return err
+	}
+	for i := range anomalies {
+		anomalies[i].RunID = run.ID
+		a := anomalies[i]
+		d.log.Warnf("Cost of %s account %s on %04d-%02d-%02d was %.2f, expected %.2f (%+.2f)", a.Resource, a.AccountNumber, a.Year, a.Month, a.Day, a.Cost, a.Expected, a.Magnitude)
+	}
+	if len(anomalies) == 0 {
+		d.log.Info("No cost anomalies found")
+		return nil
+	}
+	err = d.db.SaveAnomalies(anomalies)
+	if err != nil {
+		return err
+	}
+	if d.Notifier != nil {
+		for _, a := range anomalies {
+			err = d.Notifier.NotifyAnomaly(a)
+			if err != nil {
+				d.log.Errorf("Failed to notify anomaly of %s account %s: %s", a.Resource, a.AccountNumber, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// Detect checks the given days of each resource's accounts.
+func (d *Detector) Detect(days map[string][]time.Time) ([]datamodels.Anomaly, error) {
+	var from, to time.Time
+	for _, dates := range days {
+		for _, date := range dates {
+			if from.IsZero() || date.Before(from) {
+				from = date
+			}
+			if date.After(to) {
+				to = date
+			}
+		}
+	}
+	costs, err := d.db.GetDailyCosts(from.AddDate(0, 0, -d.BaselineDays), to)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[account]*series)
+	for _, c := range costs {
+		key := account{c.Resource, c.AccountNumber}
+		s, ok := accounts[key]
+		if !ok {
+			s = newSeries()
+			accounts[key] = s
+		}
+		s.add(c.ServiceType, c.Date(), c.Cost)
+	}
+
+	keys := accountKeys{}
+	for key := range accounts {
+		keys = append(keys, key)
+	}
+	sort.Sort(keys)
+
+	anomalies := []datamodels.Anomaly{}
+	for _, key := range keys {
+		dates := days[key.resource]
+		sort.Sort(timeSlice(dates))
+		for _, date := range dates {
+			anomaly, found := d.check(key, accounts[key], date)
+			if found {
+				anomalies = append(anomalies, anomaly)
+			}
+		}
+	}
+	return anomalies, nil
+}
+
+func (d *Detector) check(key account, s *series, date time.Time) (datamodels.Anomaly, bool) {
+	baseline := []time.Time{}
+	for day := date.AddDate(0, 0, -d.BaselineDays); day.Before(date); day = day.AddDate(0, 0, 1) {
+		if !day.Before(s.first) {
+			baseline = append(baseline, day)
+		}
+	}
+	if len(baseline) < minimumHistory {
+		return datamodels.Anomaly{}, false
+	}
+
+	factors := weekdayFactors(baseline, s.totals)
+	center, spread := d.describe(deseasonalize(baseline, s.totals, factors))
+	spread = math.Max(spread, math.Max(0.01*math.Abs(center), 0.01))
+
+	cost := s.totals[date]
+	expected := center * factors[date.Weekday()]
+	score := (cost/factors[date.Weekday()] - center) / spread
+	magnitude := cost - expected
+	if math.Abs(score) < d.Threshold || math.Abs(magnitude) < d.MinimumCost {
+		return datamodels.Anomaly{}, false
+	}
+
+	return datamodels.Anomaly{
+		ID:            datamodels.AnomalyID(key.resource, key.accountNumber, date.Year(), date.Month(), date.Day()),
+		Resource:      key.resource,
+		AccountNumber: key.accountNumber,
+		Year:          date.Year(),
+		Month:         date.Month(),
+		Day:           date.Day(),
+		Method:        string(d.Method),
+		Cost:          cost,
+		Expected:      expected,
+		Magnitude:     magnitude,
+		Score:         score,
+
+		ContributingServices: d.contributingServices(s, baseline, factors, date, magnitude),
+	}, true
+}
+
+// contributingServices returns the services that moved the day's cost in the
+// direction of the anomaly, largest first.
+func (d *Detector) contributingServices(s *series, baseline []time.Time, factors [7]float64, date time.Time, magnitude float64) []datamodels.ServiceContribution {
+	contributions := contributionSlice{}
+	for service, costs := range s.services {
+		center, _ := d.describe(deseasonalize(baseline, costs, factors))
+		expected := center * factors[date.Weekday()]
+		moved := costs[date] - expected
+		if moved*magnitude <= 0 {
+			continue
+		}
+		contributions = append(contributions, datamodels.ServiceContribution{
+			ServiceType: service,
+			Cost:        costs[date],
+			Expected:    expected,
+			Magnitude:   moved,
+		})
+	}
+	sort.Sort(contributions)
+	if len(contributions) > maxContributingServices {
+		contributions = contributions[:maxContributingServices]
+	}
+	return contributions
+}
+
+func (d *Detector) describe(values []float64) (float64, float64) {
+	if d.Method == ZScore {
+		return mean(values), stddev(values)
+	}
+	return median(values), medianAbsoluteDeviation(values)
+}
+
+// weekdayFactors returns how much each weekday costs compared to the average
+// day of the baseline.
+func weekdayFactors(baseline []time.Time, costs map[time.Time]float64) [7]float64 {
+	var (
+		factors [7]float64
+		sums    [7]float64
+		counts  [7]float64
+		total   float64
+	)
+	for _, day := range baseline {
+		sums[day.Weekday()] += costs[day]
+		counts[day.Weekday()]++
+		total += costs[day]
+	}
+	average := total / float64(len(baseline))
+	for i := range factors {
+		factors[i] = 1
+		if average > 0 && counts[i] > 0 && sums[i] > 0 {
+			factors[i] = sums[i] / counts[i] / average
+		}
+	}
+	return factors
+}
+
+func deseasonalize(baseline []time.Time, costs map[time.Time]float64, factors [7]float64) []float64 {
+	values := make([]float64, len(baseline))
+	for i, day := range baseline {
+		values[i] = costs[day] / factors[day.Weekday()]
+	}
+	return values
+}
+
+type resourceDay struct {
+	resource string
+	date     time.Time
+}
+
+type account struct {
+	resource      string
+	accountNumber string
+}
+
+type accountKeys []account
+
+func (a accountKeys) Len() int      { return len(a) }
+func (a accountKeys) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a accountKeys) Less(i, j int) bool {
+	if a[i].resource != a[j].resource {
+		return a[i].resource < a[j].resource
+	}
+	return a[i].accountNumber < a[j].accountNumber
+}
+
+// series is the daily cost of an account, in total and by service. Days
+// without cost count as zero from the first day the account had any.
+type series struct {
+	first    time.Time
+	totals   map[time.Time]float64
+	services map[string]map[time.Time]float64
+}
+
+func newSeries() *series {
+	return &series{
+		totals:   make(map[time.Time]float64),
+		services: make(map[string]map[time.Time]float64),
+	}
+}
+
+func (s *series) add(service string, date time.Time, cost float64) {
+	if s.first.IsZero() || date.Before(s.first) {
+		s.first = date
+	}
+	s.totals[date] += cost
+	if s.services[service] == nil {
+		s.services[service] = make(map[time.Time]float64)
+	}
+	s.services[service][date] += cost
+}
+
+type timeSlice []time.Time
+
+func (t timeSlice) Len() int           { return len(t) }
+func (t timeSlice) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t timeSlice) Less(i, j int) bool { return t[i].Before(t[j]) }
+
+type contributionSlice []datamodels.ServiceContribution
+
+func (c contributionSlice) Len() int      { return len(c) }
+func (c contributionSlice) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c contributionSlice) Less(i, j int) bool {
+	return math.Abs(c[i].Magnitude) > math.Abs(c[j].Magnitude)
+}
@@ -0,0 +1,173 @@
+package anomaly_test
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/anomaly"
+	"github.com/challiwill/meteorologica/anomaly/anomalyfakes"
+	"github.com/challiwill/meteorologica/datamodels"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Detector", func() {
+	var (
+		log      *logrus.Logger
+		db       *anomalyfakes.FakeDatabase
+		notifier *anomalyfakes.FakeNotifier
+		detector *Detector
+		method   Method
+		costs    []datamodels.DailyCost
+		run      datamodels.Run
+		reports  datamodels.Reports
+		err      error
+	)
+
+	// dailyCosts returns four weeks of an account that costs 100 on weekdays
+	// and 20 on weekends, ending on 2016-09-28.
+	dailyCosts := func(accountNumber string) []datamodels.DailyCost {
+		costs := []datamodels.DailyCost{}
+		for day := time.Date(2016, time.August, 31, 0, 0, 0, 0, time.UTC); day.Before(time.Date(2016, time.September, 29, 0, 0, 0, 0, time.UTC)); day = day.AddDate(0, 0, 1) {
+			compute, storage := 80.0, 20.0
+			if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+				compute, storage = 10.0, 10.0
+			}
+			costs = append(costs,
+				datamodels.DailyCost{Resource: "AWS", AccountNumber: accountNumber, ServiceType: "compute", Year: day.Year(), Month: day.Month(), Day: day.Day(), Cost: compute},
+				datamodels.DailyCost{Resource: "AWS", AccountNumber: accountNumber, ServiceType: "storage", Year: day.Year(), Month: day.Month(), Day: day.Day(), Cost: storage},
+			)
+		}
+		return costs
+	}
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		db = new(anomalyfakes.FakeDatabase)
+		notifier = new(anomalyfakes.FakeNotifier)
+		method = MAD
+		costs = dailyCosts("123")
+		run = datamodels.NewRun(time.Date(2016, time.October, 2, 0, 0, 0, 0, time.UTC))
+		reports = datamodels.Reports{
+			datamodels.Report{Resource: "AWS", AccountNumber: "123", Year: 2016, Month: time.September, Day: 29},
+		}
+	})
+
+	JustBeforeEach(func() {
+		db.GetDailyCostsReturns(costs, nil)
+		detector, err = NewDetector(log, db, method, 28, 3.5, 10)
+		Expect(err).NotTo(HaveOccurred())
+		detector.Notifier = notifier
+		err = detector.Run(run, reports)
+	})
+
+	It("reads the baseline before the days the run saved", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.GetDailyCostsCallCount()).To(Equal(1))
+		from, to := db.GetDailyCostsArgsForCall(0)
+		Expect(from).To(Equal(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(to).To(Equal(time.Date(2016, time.September, 29, 0, 0, 0, 0, time.UTC)))
+	})
+
+	Context("when a weekday costs as much as usual", func() {
+		BeforeEach(func() {
+			costs = append(costs, datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "compute", Year: 2016, Month: time.September, Day: 29, Cost: 100})
+		})
+
+		It("does not flag it", func() {
+			Expect(db.SaveAnomaliesCallCount()).To(Equal(0))
+			Expect(notifier.NotifyAnomalyCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when a weekday costs far more than usual", func() {
+		BeforeEach(func() {
+			costs = append(costs,
+				datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "compute", Year: 2016, Month: time.September, Day: 29, Cost: 480},
+				datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "storage", Year: 2016, Month: time.September, Day: 29, Cost: 20},
+			)
+		})
+
+		It("saves an anomaly with the services that caused it", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.SaveAnomaliesCallCount()).To(Equal(1))
+			anomalies := db.SaveAnomaliesArgsForCall(0)
+			Expect(anomalies).To(HaveLen(1))
+			Expect(anomalies[0].ID).To(Equal(datamodels.AnomalyID("AWS", "123", 2016, time.September, 29)))
+			Expect(anomalies[0].RunID).To(Equal(run.ID))
+			Expect(anomalies[0].Method).To(Equal("mad"))
+			Expect(anomalies[0].Cost).To(Equal(500.0))
+			Expect(anomalies[0].Expected).To(BeNumerically("~", 100, 0.001))
+			Expect(anomalies[0].Magnitude).To(BeNumerically("~", 400, 0.001))
+			Expect(anomalies[0].ContributingServices).To(HaveLen(1))
+			Expect(anomalies[0].ContributingServices[0].ServiceType).To(Equal("compute"))
+			Expect(anomalies[0].ContributingServices[0].Magnitude).To(BeNumerically("~", 400, 0.001))
+		})
+
+		It("notifies the anomaly", func() {
+			Expect(notifier.NotifyAnomalyCallCount()).To(Equal(1))
+			Expect(notifier.NotifyAnomalyArgsForCall(0)).To(Equal(db.SaveAnomaliesArgsForCall(0)[0]))
+		})
+
+		Context("with the z-score method", func() {
+			BeforeEach(func() {
+				method = ZScore
+			})
+
+			It("flags it too", func() {
+				Expect(db.SaveAnomaliesCallCount()).To(Equal(1))
+				Expect(db.SaveAnomaliesArgsForCall(0)[0].Method).To(Equal("zscore"))
+			})
+		})
+	})
+
+	Context("when a weekend costs less than a weekday", func() {
+		BeforeEach(func() {
+			costs = append(costs, datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "compute", Year: 2016, Month: time.October, Day: 1, Cost: 20})
+			reports = datamodels.Reports{
+				datamodels.Report{Resource: "AWS", AccountNumber: "123", Year: 2016, Month: time.October, Day: 1},
+			}
+		})
+
+		It("does not flag it", func() {
+			Expect(db.SaveAnomaliesCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the change is smaller than the minimum cost", func() {
+		BeforeEach(func() {
+			costs = []datamodels.DailyCost{}
+			for day := 1; day <= 28; day++ {
+				costs = append(costs, datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "compute", Year: 2016, Month: time.September, Day: day, Cost: 1})
+			}
+			costs = append(costs, datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "compute", Year: 2016, Month: time.September, Day: 29, Cost: 8})
+		})
+
+		It("does not flag it", func() {
+			Expect(db.SaveAnomaliesCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when an account does not have enough history", func() {
+		BeforeEach(func() {
+			costs = []datamodels.DailyCost{
+				datamodels.DailyCost{Resource: "AWS", AccountNumber: "new", ServiceType: "compute", Year: 2016, Month: time.September, Day: 28, Cost: 1},
+				datamodels.DailyCost{Resource: "AWS", AccountNumber: "new", ServiceType: "compute", Year: 2016, Month: time.September, Day: 29, Cost: 1000},
+			}
+		})
+
+		It("does not check it", func() {
+			Expect(db.SaveAnomaliesCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("NewDetector", func() {
+		It("errors on an unknown method", func() {
+			_, err := NewDetector(log, db, Method("magic"), 28, 3.5, 10)
+			Expect(err).To(MatchError(ContainSubstring("Unknown anomaly detection method")))
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+//go:generate counterfeiter . AnomaliesDatabase
+
+type AnomaliesDatabase interface {
+	GetAnomalies(from, to time.Time) ([]datamodels.Anomaly, error)
+}
+
+type anomaliesHandler struct {
+	log *logrus.Logger
+	db  AnomaliesDatabase
+}
+
+// NewAnomaliesHandler serves the anomalies found between the from and to
+// query parameters (YYYY-MM-DD, the last 30 days by default) as JSON. They can
+// be narrowed down with the resource and account query parameters.
+func NewAnomaliesHandler(log *logrus.Logger, db AnomaliesDatabase) http.Handler {
+	return &anomaliesHandler{log: log, db: db}
+}
+
+func (h *anomaliesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+	from, to, err := dateRange(r, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	anomalies, err := h.db.GetAnomalies(from, to)
+	if err != nil {
+		h.log.Error("Failed to get anomalies: ", err.Error())
+		http.Error(w, "Failed to get anomalies", http.StatusInternalServerError)
+		return
+	}
+
+	resource := r.URL.Query().Get("resource")
+	accountNumber := r.URL.Query().Get("account")
+	filtered := []datamodels.Anomaly{}
+	for _, a := range anomalies {
+		if resource != "" && a.Resource != resource {
+			continue
+		}
+		if accountNumber != "" && a.AccountNumber != accountNumber {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+
+	writeJSON(h.log, w, filtered)
+}
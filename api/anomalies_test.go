@@ -0,0 +1,93 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/api"
+	"github.com/challiwill/meteorologica/api/apifakes"
+	"github.com/challiwill/meteorologica/datamodels"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Anomalies", func() {
+	var (
+		db       *apifakes.FakeAnomaliesDatabase
+		handler  http.Handler
+		recorder *httptest.ResponseRecorder
+		url      string
+	)
+
+	BeforeEach(func() {
+		log := logrus.New()
+		log.Out = NewBuffer()
+		db = new(apifakes.FakeAnomaliesDatabase)
+		db.GetAnomaliesReturns([]datamodels.Anomaly{
+			datamodels.Anomaly{ID: "a", Resource: "AWS", AccountNumber: "123", Magnitude: 400},
+			datamodels.Anomaly{ID: "b", Resource: "GCP", AccountNumber: "456", Magnitude: -50},
+		}, nil)
+		handler = NewAnomaliesHandler(log, db)
+		recorder = httptest.NewRecorder()
+		url = "/anomalies?from=2016-09-01&to=2016-09-30"
+	})
+
+	JustBeforeEach(func() {
+		request, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(recorder, request)
+	})
+
+	It("returns the anomalies in the date range as JSON", func() {
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		from, to := db.GetAnomaliesArgsForCall(0)
+		Expect(from).To(Equal(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(to).To(Equal(time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC)))
+
+		var anomalies []datamodels.Anomaly
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &anomalies)).To(Succeed())
+		Expect(anomalies).To(HaveLen(2))
+		Expect(anomalies[0].Magnitude).To(Equal(400.0))
+	})
+
+	Context("when filtered by resource", func() {
+		BeforeEach(func() {
+			url = "/anomalies?resource=GCP"
+		})
+
+		It("only returns that resource's anomalies", func() {
+			var anomalies []datamodels.Anomaly
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &anomalies)).To(Succeed())
+			Expect(anomalies).To(HaveLen(1))
+			Expect(anomalies[0].ID).To(Equal("b"))
+		})
+	})
+
+	Context("when a date is invalid", func() {
+		BeforeEach(func() {
+			url = "/anomalies?from=yesterday"
+		})
+
+		It("is a bad request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(db.GetAnomaliesCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the database fails", func() {
+		BeforeEach(func() {
+			db.GetAnomaliesReturns(nil, errors.New("some-error"))
+		})
+
+		It("is an internal server error", func() {
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+// This file was generated by counterfeiter
+package apifakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/api"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type FakeAnomaliesDatabase struct {
+	GetAnomaliesStub        func(time.Time, time.Time) ([]datamodels.Anomaly, error)
+	getAnomaliesMutex       sync.RWMutex
+	getAnomaliesArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getAnomaliesReturns struct {
+		result1 []datamodels.Anomaly
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAnomaliesDatabase) GetAnomalies(arg1 time.Time, arg2 time.Time) ([]datamodels.Anomaly, error) {
+	fake.getAnomaliesMutex.Lock()
+	fake.getAnomaliesArgsForCall = append(fake.getAnomaliesArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetAnomalies", []interface{}{arg1, arg2})
+	fake.getAnomaliesMutex.Unlock()
+	if fake.GetAnomaliesStub != nil {
+		return fake.GetAnomaliesStub(arg1, arg2)
+	} else {
+		return fake.getAnomaliesReturns.result1, fake.getAnomaliesReturns.result2
+	}
+}
+
+func (fake *FakeAnomaliesDatabase) GetAnomaliesCallCount() int {
+	fake.getAnomaliesMutex.RLock()
+	defer fake.getAnomaliesMutex.RUnlock()
+	return len(fake.getAnomaliesArgsForCall)
+}
+
+func (fake *FakeAnomaliesDatabase) GetAnomaliesArgsForCall(i int) (time.Time, time.Time) {
+	fake.getAnomaliesMutex.RLock()
+	defer fake.getAnomaliesMutex.RUnlock()
+	return fake.getAnomaliesArgsForCall[i].arg1, fake.getAnomaliesArgsForCall[i].arg2
+}
+
+func (fake *FakeAnomaliesDatabase) GetAnomaliesReturns(result1 []datamodels.Anomaly, result2 error) {
+	fake.GetAnomaliesStub = nil
+	fake.getAnomaliesReturns = struct {
+		result1 []datamodels.Anomaly
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAnomaliesDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getAnomaliesMutex.RLock()
+	defer fake.getAnomaliesMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeAnomaliesDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ api.AnomaliesDatabase = new(FakeAnomaliesDatabase)
@@ -0,0 +1,84 @@
+// This file was generated by counterfeiter
+package apifakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/api"
+	"github.com/challiwill/meteorologica/compare"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type FakeComparer struct {
+	CompareStub        func(compare.Period, compare.Period, int) (datamodels.Comparison, error)
+	compareMutex       sync.RWMutex
+	compareArgsForCall []struct {
+		arg1 compare.Period
+		arg2 compare.Period
+		arg3 int
+	}
+	compareReturns struct {
+		result1 datamodels.Comparison
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeComparer) Compare(arg1 compare.Period, arg2 compare.Period, arg3 int) (datamodels.Comparison, error) {
+	fake.compareMutex.Lock()
+	fake.compareArgsForCall = append(fake.compareArgsForCall, struct {
+		arg1 compare.Period
+		arg2 compare.Period
+		arg3 int
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("Compare", []interface{}{arg1, arg2, arg3})
+	fake.compareMutex.Unlock()
+	if fake.CompareStub != nil {
+		return fake.CompareStub(arg1, arg2, arg3)
+	} else {
+		return fake.compareReturns.result1, fake.compareReturns.result2
+	}
+}
+
+func (fake *FakeComparer) CompareCallCount() int {
+	fake.compareMutex.RLock()
+	defer fake.compareMutex.RUnlock()
+	return len(fake.compareArgsForCall)
+}
+
+func (fake *FakeComparer) CompareArgsForCall(i int) (compare.Period, compare.Period, int) {
+	fake.compareMutex.RLock()
+	defer fake.compareMutex.RUnlock()
+	return fake.compareArgsForCall[i].arg1, fake.compareArgsForCall[i].arg2, fake.compareArgsForCall[i].arg3
+}
+
+func (fake *FakeComparer) CompareReturns(result1 datamodels.Comparison, result2 error) {
+	fake.CompareStub = nil
+	fake.compareReturns = struct {
+		result1 datamodels.Comparison
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeComparer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.compareMutex.RLock()
+	defer fake.compareMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeComparer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ api.Comparer = new(FakeComparer)
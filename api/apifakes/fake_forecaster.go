@@ -0,0 +1,83 @@
+// This file was generated by counterfeiter
+package apifakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/api"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/forecast"
+)
+
+type FakeForecaster struct {
+	ForecastStub        func(forecast.Period, time.Time) ([]datamodels.Forecast, error)
+	forecastMutex       sync.RWMutex
+	forecastArgsForCall []struct {
+		arg1 forecast.Period
+		arg2 time.Time
+	}
+	forecastReturns struct {
+		result1 []datamodels.Forecast
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeForecaster) Forecast(arg1 forecast.Period, arg2 time.Time) ([]datamodels.Forecast, error) {
+	fake.forecastMutex.Lock()
+	fake.forecastArgsForCall = append(fake.forecastArgsForCall, struct {
+		arg1 forecast.Period
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("Forecast", []interface{}{arg1, arg2})
+	fake.forecastMutex.Unlock()
+	if fake.ForecastStub != nil {
+		return fake.ForecastStub(arg1, arg2)
+	} else {
+		return fake.forecastReturns.result1, fake.forecastReturns.result2
+	}
+}
+
+func (fake *FakeForecaster) ForecastCallCount() int {
+	fake.forecastMutex.RLock()
+	defer fake.forecastMutex.RUnlock()
+	return len(fake.forecastArgsForCall)
+}
+
+func (fake *FakeForecaster) ForecastArgsForCall(i int) (forecast.Period, time.Time) {
+	fake.forecastMutex.RLock()
+	defer fake.forecastMutex.RUnlock()
+	return fake.forecastArgsForCall[i].arg1, fake.forecastArgsForCall[i].arg2
+}
+
+func (fake *FakeForecaster) ForecastReturns(result1 []datamodels.Forecast, result2 error) {
+	fake.ForecastStub = nil
+	fake.forecastReturns = struct {
+		result1 []datamodels.Forecast
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeForecaster) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.forecastMutex.RLock()
+	defer fake.forecastMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeForecaster) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ api.Forecaster = new(FakeForecaster)
@@ -0,0 +1,290 @@
+// This file was generated by counterfeiter
+package apifakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/api"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/ownership"
+)
+
+type FakeOwnership struct {
+	DirectoryStub        func() (ownership.Directory, error)
+	directoryMutex       sync.RWMutex
+	directoryArgsForCall []struct{}
+	directoryReturns     struct {
+		result1 ownership.Directory
+		result2 error
+	}
+	SaveTeamStub        func(datamodels.Team) error
+	saveTeamMutex       sync.RWMutex
+	saveTeamArgsForCall []struct {
+		arg1 datamodels.Team
+	}
+	saveTeamReturns struct {
+		result1 error
+	}
+	DeleteTeamStub        func(string) error
+	deleteTeamMutex       sync.RWMutex
+	deleteTeamArgsForCall []struct {
+		arg1 string
+	}
+	deleteTeamReturns struct {
+		result1 error
+	}
+	SaveMappingStub        func(datamodels.OwnershipMapping) error
+	saveMappingMutex       sync.RWMutex
+	saveMappingArgsForCall []struct {
+		arg1 datamodels.OwnershipMapping
+	}
+	saveMappingReturns struct {
+		result1 error
+	}
+	DeleteMappingStub        func(int64) error
+	deleteMappingMutex       sync.RWMutex
+	deleteMappingArgsForCall []struct {
+		arg1 int64
+	}
+	deleteMappingReturns struct {
+		result1 error
+	}
+	StatementsStub        func(int, time.Month) ([]datamodels.ChargebackStatement, error)
+	statementsMutex       sync.RWMutex
+	statementsArgsForCall []struct {
+		arg1 int
+		arg2 time.Month
+	}
+	statementsReturns struct {
+		result1 []datamodels.ChargebackStatement
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeOwnership) Directory() (ownership.Directory, error) {
+	fake.directoryMutex.Lock()
+	fake.directoryArgsForCall = append(fake.directoryArgsForCall, struct{}{})
+	fake.recordInvocation("Directory", []interface{}{})
+	fake.directoryMutex.Unlock()
+	if fake.DirectoryStub != nil {
+		return fake.DirectoryStub()
+	} else {
+		return fake.directoryReturns.result1, fake.directoryReturns.result2
+	}
+}
+
+func (fake *FakeOwnership) DirectoryCallCount() int {
+	fake.directoryMutex.RLock()
+	defer fake.directoryMutex.RUnlock()
+	return len(fake.directoryArgsForCall)
+}
+
+func (fake *FakeOwnership) DirectoryReturns(result1 ownership.Directory, result2 error) {
+	fake.DirectoryStub = nil
+	fake.directoryReturns = struct {
+		result1 ownership.Directory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOwnership) SaveTeam(arg1 datamodels.Team) error {
+	fake.saveTeamMutex.Lock()
+	fake.saveTeamArgsForCall = append(fake.saveTeamArgsForCall, struct {
+		arg1 datamodels.Team
+	}{arg1})
+	fake.recordInvocation("SaveTeam", []interface{}{arg1})
+	fake.saveTeamMutex.Unlock()
+	if fake.SaveTeamStub != nil {
+		return fake.SaveTeamStub(arg1)
+	} else {
+		return fake.saveTeamReturns.result1
+	}
+}
+
+func (fake *FakeOwnership) SaveTeamCallCount() int {
+	fake.saveTeamMutex.RLock()
+	defer fake.saveTeamMutex.RUnlock()
+	return len(fake.saveTeamArgsForCall)
+}
+
+func (fake *FakeOwnership) SaveTeamArgsForCall(i int) datamodels.Team {
+	fake.saveTeamMutex.RLock()
+	defer fake.saveTeamMutex.RUnlock()
+	return fake.saveTeamArgsForCall[i].arg1
+}
+
+func (fake *FakeOwnership) SaveTeamReturns(result1 error) {
+	fake.SaveTeamStub = nil
+	fake.saveTeamReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOwnership) DeleteTeam(arg1 string) error {
+	fake.deleteTeamMutex.Lock()
+	fake.deleteTeamArgsForCall = append(fake.deleteTeamArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("DeleteTeam", []interface{}{arg1})
+	fake.deleteTeamMutex.Unlock()
+	if fake.DeleteTeamStub != nil {
+		return fake.DeleteTeamStub(arg1)
+	} else {
+		return fake.deleteTeamReturns.result1
+	}
+}
+
+func (fake *FakeOwnership) DeleteTeamCallCount() int {
+	fake.deleteTeamMutex.RLock()
+	defer fake.deleteTeamMutex.RUnlock()
+	return len(fake.deleteTeamArgsForCall)
+}
+
+func (fake *FakeOwnership) DeleteTeamArgsForCall(i int) string {
+	fake.deleteTeamMutex.RLock()
+	defer fake.deleteTeamMutex.RUnlock()
+	return fake.deleteTeamArgsForCall[i].arg1
+}
+
+func (fake *FakeOwnership) DeleteTeamReturns(result1 error) {
+	fake.DeleteTeamStub = nil
+	fake.deleteTeamReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOwnership) SaveMapping(arg1 datamodels.OwnershipMapping) error {
+	fake.saveMappingMutex.Lock()
+	fake.saveMappingArgsForCall = append(fake.saveMappingArgsForCall, struct {
+		arg1 datamodels.OwnershipMapping
+	}{arg1})
+	fake.recordInvocation("SaveMapping", []interface{}{arg1})
+	fake.saveMappingMutex.Unlock()
+	if fake.SaveMappingStub != nil {
+		return fake.SaveMappingStub(arg1)
+	} else {
+		return fake.saveMappingReturns.result1
+	}
+}
+
+func (fake *FakeOwnership) SaveMappingCallCount() int {
+	fake.saveMappingMutex.RLock()
+	defer fake.saveMappingMutex.RUnlock()
+	return len(fake.saveMappingArgsForCall)
+}
+
+func (fake *FakeOwnership) SaveMappingArgsForCall(i int) datamodels.OwnershipMapping {
+	fake.saveMappingMutex.RLock()
+	defer fake.saveMappingMutex.RUnlock()
+	return fake.saveMappingArgsForCall[i].arg1
+}
+
+func (fake *FakeOwnership) SaveMappingReturns(result1 error) {
+	fake.SaveMappingStub = nil
+	fake.saveMappingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOwnership) DeleteMapping(arg1 int64) error {
+	fake.deleteMappingMutex.Lock()
+	fake.deleteMappingArgsForCall = append(fake.deleteMappingArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	fake.recordInvocation("DeleteMapping", []interface{}{arg1})
+	fake.deleteMappingMutex.Unlock()
+	if fake.DeleteMappingStub != nil {
+		return fake.DeleteMappingStub(arg1)
+	} else {
+		return fake.deleteMappingReturns.result1
+	}
+}
+
+func (fake *FakeOwnership) DeleteMappingCallCount() int {
+	fake.deleteMappingMutex.RLock()
+	defer fake.deleteMappingMutex.RUnlock()
+	return len(fake.deleteMappingArgsForCall)
+}
+
+func (fake *FakeOwnership) DeleteMappingArgsForCall(i int) int64 {
+	fake.deleteMappingMutex.RLock()
+	defer fake.deleteMappingMutex.RUnlock()
+	return fake.deleteMappingArgsForCall[i].arg1
+}
+
+func (fake *FakeOwnership) DeleteMappingReturns(result1 error) {
+	fake.DeleteMappingStub = nil
+	fake.deleteMappingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOwnership) Statements(arg1 int, arg2 time.Month) ([]datamodels.ChargebackStatement, error) {
+	fake.statementsMutex.Lock()
+	fake.statementsArgsForCall = append(fake.statementsArgsForCall, struct {
+		arg1 int
+		arg2 time.Month
+	}{arg1, arg2})
+	fake.recordInvocation("Statements", []interface{}{arg1, arg2})
+	fake.statementsMutex.Unlock()
+	if fake.StatementsStub != nil {
+		return fake.StatementsStub(arg1, arg2)
+	} else {
+		return fake.statementsReturns.result1, fake.statementsReturns.result2
+	}
+}
+
+func (fake *FakeOwnership) StatementsCallCount() int {
+	fake.statementsMutex.RLock()
+	defer fake.statementsMutex.RUnlock()
+	return len(fake.statementsArgsForCall)
+}
+
+func (fake *FakeOwnership) StatementsArgsForCall(i int) (int, time.Month) {
+	fake.statementsMutex.RLock()
+	defer fake.statementsMutex.RUnlock()
+	return fake.statementsArgsForCall[i].arg1, fake.statementsArgsForCall[i].arg2
+}
+
+func (fake *FakeOwnership) StatementsReturns(result1 []datamodels.ChargebackStatement, result2 error) {
+	fake.StatementsStub = nil
+	fake.statementsReturns = struct {
+		result1 []datamodels.ChargebackStatement
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOwnership) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.directoryMutex.RLock()
+	defer fake.directoryMutex.RUnlock()
+	fake.saveTeamMutex.RLock()
+	defer fake.saveTeamMutex.RUnlock()
+	fake.deleteTeamMutex.RLock()
+	defer fake.deleteTeamMutex.RUnlock()
+	fake.saveMappingMutex.RLock()
+	defer fake.saveMappingMutex.RUnlock()
+	fake.deleteMappingMutex.RLock()
+	defer fake.deleteMappingMutex.RUnlock()
+	fake.statementsMutex.RLock()
+	defer fake.statementsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeOwnership) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ api.Ownership = new(FakeOwnership)
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+type tokenHandler struct {
+	token   string
+	handler http.Handler
+}
+
+// RequireToken serves GET requests with the handler, and every other request
+// only when it carries the token as a bearer token. Without a token nothing
+// but GET is served, so changes through the API must be opted into.
+func RequireToken(token string, handler http.Handler) http.Handler {
+	return &tokenHandler{token: token, handler: handler}
+}
+
+func (h *tokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	if h.token == "" {
+		http.Error(w, "Changes through the API are disabled, set an API token to enable them", http.StatusForbidden)
+		return
+	}
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(h.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "A valid API token is required", http.StatusUnauthorized)
+		return
+	}
+	h.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,58 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/challiwill/meteorologica/api"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequireToken", func() {
+	var (
+		served   bool
+		handler  http.Handler
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		served = false
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served = true
+		})
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(token, method, authorization string) {
+		request, err := http.NewRequest(method, "/teams", nil)
+		Expect(err).NotTo(HaveOccurred())
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		RequireToken(token, handler).ServeHTTP(recorder, request)
+	}
+
+	It("serves GET without a token", func() {
+		serve("secret", "GET", "")
+		Expect(served).To(BeTrue())
+	})
+
+	It("serves changes with the token", func() {
+		serve("secret", "POST", "Bearer secret")
+		Expect(served).To(BeTrue())
+	})
+
+	It("rejects changes without the token", func() {
+		serve("secret", "DELETE", "Bearer guess")
+		Expect(served).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("rejects every change when no token is configured", func() {
+		serve("", "POST", "Bearer ")
+		Expect(served).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+	})
+})
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/compare"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+//go:generate counterfeiter . Comparer
+
+type Comparer interface {
+	Compare(current, previous compare.Period, top int) (datamodels.Comparison, error)
+}
+
+type compareHandler struct {
+	log      *logrus.Logger
+	location *time.Location
+	comparer Comparer
+}
+
+// NewCompareHandler serves how spend changed between the current and previous
+// query parameters, each a month (YYYY-MM) or a quarter (YYYY-Qn). Current
+// defaults to last month and previous to the period before current. The top
+// query parameter (10 by default) limits the ranked increases and decreases.
+// It is served as JSON, or with format=csv or format=text.
+func NewCompareHandler(log *logrus.Logger, location *time.Location, comparer Comparer) http.Handler {
+	return &compareHandler{log: log, location: location, comparer: comparer}
+}
+
+func (h *compareHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	current := compare.LastMonth(time.Now().In(h.location))
+	if param := query.Get("current"); param != "" {
+		var err error
+		current, err = compare.ParsePeriod(param)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	previous := current.Previous()
+	if param := query.Get("previous"); param != "" {
+		var err error
+		previous, err = compare.ParsePeriod(param)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	top := compare.DefaultTop
+	if param := query.Get("top"); param != "" {
+		var err error
+		top, err = strconv.Atoi(param)
+		if err != nil || top < 0 {
+			http.Error(w, "Invalid top, expected a non-negative number", http.StatusBadRequest)
+			return
+		}
+	}
+	format := compare.JSON
+	if param := query.Get("format"); param != "" {
+		format = param
+	}
+	contentType := map[string]string{compare.JSON: "application/json", compare.CSV: "text/csv", compare.Text: "text/plain"}[format]
+	if contentType == "" {
+		http.Error(w, "Unknown format, must be json, csv or text", http.StatusBadRequest)
+		return
+	}
+
+	comparison, err := h.comparer.Compare(current, previous, top)
+	if err != nil {
+		h.log.Error("Failed to compare periods: ", err.Error())
+		http.Error(w, "Failed to compare periods", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	err = compare.Write(w, comparison, format)
+	if err != nil {
+		h.log.Error("Failed to write response: ", err.Error())
+	}
+}
@@ -0,0 +1,111 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/api"
+	"github.com/challiwill/meteorologica/api/apifakes"
+	"github.com/challiwill/meteorologica/compare"
+	"github.com/challiwill/meteorologica/datamodels"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Compare", func() {
+	var (
+		comparer *apifakes.FakeComparer
+		recorder *httptest.ResponseRecorder
+		url      string
+	)
+
+	BeforeEach(func() {
+		comparer = new(apifakes.FakeComparer)
+		comparer.CompareReturns(datamodels.Comparison{
+			Previous: "2016-08",
+			Current:  "2016-09",
+			Lines: []datamodels.ComparisonLine{
+				{Resource: "AWS", AccountNumber: "1", ServiceType: "EC2", Previous: 200, Current: 150, Change: -50, ChangePercent: -25, Status: datamodels.Changed},
+			},
+		}, nil)
+		recorder = httptest.NewRecorder()
+		url = "/compare?current=2016-09&previous=2016-06&top=3"
+	})
+
+	JustBeforeEach(func() {
+		log := logrus.New()
+		log.Out = NewBuffer()
+		request, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+		NewCompareHandler(log, time.UTC, comparer).ServeHTTP(recorder, request)
+	})
+
+	It("returns the comparison of the periods as JSON", func() {
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		current, previous, top := comparer.CompareArgsForCall(0)
+		Expect(current.Name).To(Equal("2016-09"))
+		Expect(previous.Name).To(Equal("2016-06"))
+		Expect(top).To(Equal(3))
+
+		var comparison datamodels.Comparison
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &comparison)).To(Succeed())
+		Expect(comparison.Lines).To(HaveLen(1))
+		Expect(comparison.Lines[0].Change).To(Equal(-50.0))
+	})
+
+	Context("without periods", func() {
+		BeforeEach(func() {
+			url = "/compare?format=csv"
+		})
+
+		It("compares last month with the month before as CSV", func() {
+			current, previous, top := comparer.CompareArgsForCall(0)
+			lastMonth := compare.LastMonth(time.Now().In(time.UTC))
+			Expect(current).To(Equal(lastMonth))
+			Expect(previous).To(Equal(lastMonth.Previous()))
+			Expect(top).To(Equal(compare.DefaultTop))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/csv"))
+			Expect(recorder.Body.String()).To(ContainSubstring("AWS,1,,EC2,200,150,-50,-25,changed"))
+		})
+	})
+
+	Context("with a quarter and the text format", func() {
+		BeforeEach(func() {
+			url = "/compare?current=2016-Q3&format=text"
+		})
+
+		It("compares it with the quarter before as a table", func() {
+			current, previous, _ := comparer.CompareArgsForCall(0)
+			Expect(current.Name).To(Equal("2016-Q3"))
+			Expect(previous.Name).To(Equal("2016-Q2"))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/plain"))
+			Expect(recorder.Body.String()).To(ContainSubstring("2016-09 compared with 2016-08"))
+		})
+	})
+
+	Context("with an invalid period", func() {
+		BeforeEach(func() {
+			url = "/compare?current=September"
+		})
+
+		It("is a bad request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(comparer.CompareCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("with an unknown format", func() {
+		BeforeEach(func() {
+			url = "/compare?format=xml"
+		})
+
+		It("is a bad request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/forecast"
+	"github.com/gocarina/gocsv"
+)
+
+//go:generate counterfeiter . Forecaster
+
+type Forecaster interface {
+	Forecast(forecast.Period, time.Time) ([]datamodels.Forecast, error)
+}
+
+type forecastHandler struct {
+	log        *logrus.Logger
+	location   *time.Location
+	forecaster Forecaster
+}
+
+// NewForecastHandler serves the forecast of each account for the period query
+// parameter (month, the default, or quarter) as of the date query parameter
+// (YYYY-MM-DD, yesterday by default). It is served as JSON, or as CSV with
+// format=csv.
+func NewForecastHandler(log *logrus.Logger, location *time.Location, forecaster Forecaster) http.Handler {
+	return &forecastHandler{log: log, location: location, forecaster: forecaster}
+}
+
+func (h *forecastHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	period := forecast.Month
+	if param := r.URL.Query().Get("period"); param != "" {
+		var err error
+		period, err = forecast.ParsePeriod(param)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	yesterday := time.Now().In(h.location).AddDate(0, 0, -1)
+	asOf, err := parseDate(r, "date", yesterday)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	forecasts, err := h.forecaster.Forecast(period, asOf)
+	if err != nil {
+		h.log.Error("Failed to forecast: ", err.Error())
+		http.Error(w, "Failed to forecast", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		err = gocsv.Marshal(&forecasts, w)
+		if err != nil {
+			h.log.Error("Failed to write response: ", err.Error())
+		}
+		return
+	}
+	writeJSON(h.log, w, forecasts)
+}
@@ -0,0 +1,79 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/api"
+	"github.com/challiwill/meteorologica/api/apifakes"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/forecast"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Forecast", func() {
+	var (
+		forecaster *apifakes.FakeForecaster
+		recorder   *httptest.ResponseRecorder
+		url        string
+	)
+
+	BeforeEach(func() {
+		forecaster = new(apifakes.FakeForecaster)
+		forecaster.ForecastReturns([]datamodels.Forecast{
+			datamodels.Forecast{Resource: "AWS", AccountNumber: "123", Period: "2016-Q4", Forecast: 9200, Lower: 9000, Upper: 9400},
+		}, nil)
+		recorder = httptest.NewRecorder()
+		url = "/forecast?period=quarter&date=2016-10-10"
+	})
+
+	JustBeforeEach(func() {
+		log := logrus.New()
+		log.Out = NewBuffer()
+		request, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+		NewForecastHandler(log, time.UTC, forecaster).ServeHTTP(recorder, request)
+	})
+
+	It("returns the forecast of the period as of the date as JSON", func() {
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		period, asOf := forecaster.ForecastArgsForCall(0)
+		Expect(period).To(Equal(forecast.Quarter))
+		Expect(asOf).To(Equal(time.Date(2016, time.October, 10, 0, 0, 0, 0, time.UTC)))
+
+		var forecasts []datamodels.Forecast
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &forecasts)).To(Succeed())
+		Expect(forecasts).To(HaveLen(1))
+		Expect(forecasts[0].Upper).To(Equal(9400.0))
+	})
+
+	Context("with the csv format", func() {
+		BeforeEach(func() {
+			url = "/forecast?format=csv"
+		})
+
+		It("returns the month's forecast as CSV", func() {
+			period, _ := forecaster.ForecastArgsForCall(0)
+			Expect(period).To(Equal(forecast.Month))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/csv"))
+			Expect(recorder.Body.String()).To(ContainSubstring("Lower Bound,Upper Bound"))
+			Expect(recorder.Body.String()).To(ContainSubstring("AWS,123,2016-Q4"))
+		})
+	})
+
+	Context("with an unknown period", func() {
+		BeforeEach(func() {
+			url = "/forecast?period=week"
+		})
+
+		It("is a bad request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
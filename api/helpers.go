@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const dateFormat = "2006-01-02"
+
+// parseDate parses a date query parameter, falling back to the given default
+// when it is not set.
+func parseDate(r *http.Request, name string, fallback time.Time) (time.Time, error) {
+	param := r.URL.Query().Get(name)
+	if param == "" {
+		return fallback, nil
+	}
+	date, err := time.Parse(dateFormat, param)
+	if err != nil {
+		return fallback, fmt.Errorf("Invalid %s date %q, expected YYYY-MM-DD", name, param)
+	}
+	return date, nil
+}
+
+// dateRange reads the from and to query parameters, falling back to the given
+// defaults when they are not set.
+func dateRange(r *http.Request, from, to time.Time) (time.Time, time.Time, error) {
+	from, err := parseDate(r, "from", from)
+	if err != nil {
+		return from, to, err
+	}
+	to, err = parseDate(r, "to", to)
+	if err != nil {
+		return from, to, err
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("The from date must not be after the to date")
+	}
+	return from, to, nil
+}
+
+func writeJSON(log *logrus.Logger, w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Error("Failed to write response: ", err.Error())
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/ownership"
+	"github.com/gocarina/gocsv"
+)
+
+//go:generate counterfeiter . Ownership
+
+type Ownership interface {
+	Directory() (ownership.Directory, error)
+	SaveTeam(datamodels.Team) error
+	DeleteTeam(string) error
+	SaveMapping(datamodels.OwnershipMapping) error
+	DeleteMapping(int64) error
+	Statements(int, time.Month) ([]datamodels.ChargebackStatement, error)
+}
+
+type teamsHandler struct {
+	log       *logrus.Logger
+	ownership Ownership
+}
+
+// NewTeamsHandler lists the teams with GET, saves the JSON team in the body
+// with POST, and deletes the team named by the name query parameter with
+// DELETE.
+func NewTeamsHandler(log *logrus.Logger, ownership Ownership) http.Handler {
+	return &teamsHandler{log: log, ownership: ownership}
+}
+
+func (h *teamsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		directory, err := h.ownership.Directory()
+		if err != nil {
+			h.log.Error("Failed to get teams: ", err.Error())
+			http.Error(w, "Failed to get teams", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(h.log, w, directory.Teams)
+	case "POST":
+		var team datamodels.Team
+		err := json.NewDecoder(r.Body).Decode(&team)
+		if err != nil {
+			http.Error(w, "Invalid team: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = h.ownership.SaveTeam(team)
+		if _, ok := err.(ownership.InvalidError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			h.log.Error("Failed to save team: ", err.Error())
+			http.Error(w, "Failed to save team", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(h.log, w, team)
+	case "DELETE":
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			http.Error(w, "The name of the team is required", http.StatusBadRequest)
+			return
+		}
+		err := h.ownership.DeleteTeam(name)
+		if err != nil {
+			h.log.Error("Failed to delete team: ", err.Error())
+			http.Error(w, "Failed to delete team", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Only GET, POST and DELETE are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+type ownershipMappingsHandler struct {
+	log       *logrus.Logger
+	ownership Ownership
+}
+
+// NewOwnershipMappingsHandler lists the ownership mappings with GET, saves the
+// JSON mapping in the body with POST (updating the mapping with its id when it
+// has one), and deletes the mapping with the id query
+// parameter with DELETE.
+func NewOwnershipMappingsHandler(log *logrus.Logger, ownership Ownership) http.Handler {
+	return &ownershipMappingsHandler{log: log, ownership: ownership}
+}
+
+func (h *ownershipMappingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		directory, err := h.ownership.Directory()
+		if err != nil {
+			h.log.Error("Failed to get ownership mappings: ", err.Error())
+			http.Error(w, "Failed to get ownership mappings", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(h.log, w, directory.Mappings)
+	case "POST":
+		var mapping datamodels.OwnershipMapping
+		err := json.NewDecoder(r.Body).Decode(&mapping)
+		if err != nil {
+			http.Error(w, "Invalid ownership mapping: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = h.ownership.SaveMapping(mapping)
+		if _, ok := err.(ownership.InvalidError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == ownership.ErrMappingNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			h.log.Error("Failed to save ownership mapping: ", err.Error())
+			http.Error(w, "Failed to save ownership mapping", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(h.log, w, mapping)
+	case "DELETE":
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "The id of the ownership mapping is required", http.StatusBadRequest)
+			return
+		}
+		err = h.ownership.DeleteMapping(id)
+		if err != nil {
+			h.log.Error("Failed to delete ownership mapping: ", err.Error())
+			http.Error(w, "Failed to delete ownership mapping", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Only GET, POST and DELETE are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+type chargebackHandler struct {
+	log       *logrus.Logger
+	location  *time.Location
+	ownership Ownership
+}
+
+// NewChargebackHandler serves the chargeback statement of each team for the
+// month query parameter (YYYY-MM, last month by default). It is served as
+// JSON, or with format=csv as the CSV lines of every statement.
+func NewChargebackHandler(log *logrus.Logger, location *time.Location, ownership Ownership) http.Handler {
+	return &chargebackHandler{log: log, location: location, ownership: ownership}
+}
+
+func (h *chargebackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now().In(h.location)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	if param := r.URL.Query().Get("month"); param != "" {
+		var err error
+		month, err = time.Parse("2006-01", param)
+		if err != nil {
+			http.Error(w, "Invalid month "+strconv.Quote(param)+", expected YYYY-MM", http.StatusBadRequest)
+			return
+		}
+	}
+
+	statements, err := h.ownership.Statements(month.Year(), month.Month())
+	if err != nil {
+		h.log.Error("Failed to produce chargeback statements: ", err.Error())
+		http.Error(w, "Failed to produce chargeback statements", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		lines := []datamodels.ChargebackLine{}
+		for _, statement := range statements {
+			lines = append(lines, statement.Lines...)
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		err = gocsv.Marshal(&lines, w)
+		if err != nil {
+			h.log.Error("Failed to write response: ", err.Error())
+		}
+		return
+	}
+	writeJSON(h.log, w, statements)
+}
@@ -0,0 +1,170 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/api"
+	"github.com/challiwill/meteorologica/api/apifakes"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/ownership"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Ownership", func() {
+	var (
+		log      *logrus.Logger
+		owners   *apifakes.FakeOwnership
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		owners = new(apifakes.FakeOwnership)
+		owners.DirectoryReturns(ownership.Directory{
+			Teams:    []datamodels.Team{{Name: "platform"}},
+			Mappings: []datamodels.OwnershipMapping{{ID: 3, Team: "platform", Resource: "AWS"}},
+		}, nil)
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(handler http.Handler, method, url, body string) {
+		request, err := http.NewRequest(method, url, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(recorder, request)
+	}
+
+	Describe("Teams", func() {
+		It("lists the teams", func() {
+			serve(NewTeamsHandler(log, owners), "GET", "/teams", "")
+			var teams []datamodels.Team
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &teams)).To(Succeed())
+			Expect(teams).To(Equal([]datamodels.Team{{Name: "platform"}}))
+		})
+
+		It("saves a team", func() {
+			serve(NewTeamsHandler(log, owners), "POST", "/teams", `{"name":"databases","parent":"platform"}`)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(owners.SaveTeamArgsForCall(0)).To(Equal(datamodels.Team{Name: "databases", Parent: "platform"}))
+		})
+
+		It("rejects teams that cannot be saved", func() {
+			owners.SaveTeamReturns(ownership.InvalidError{Reason: "Team a is its own ancestor"})
+			serve(NewTeamsHandler(log, owners), "POST", "/teams", `{"name":"a"}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("its own ancestor"))
+		})
+
+		It("fails when the team cannot be saved", func() {
+			owners.SaveTeamReturns(errors.New("connection refused"))
+			serve(NewTeamsHandler(log, owners), "POST", "/teams", `{"name":"a"}`)
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(log.Out).To(Say("Failed to save team: connection refused"))
+		})
+
+		It("deletes a team", func() {
+			serve(NewTeamsHandler(log, owners), "DELETE", "/teams?name=platform", "")
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(owners.DeleteTeamArgsForCall(0)).To(Equal("platform"))
+		})
+	})
+
+	Describe("OwnershipMappings", func() {
+		It("lists the mappings", func() {
+			serve(NewOwnershipMappingsHandler(log, owners), "GET", "/ownership-mappings", "")
+			Expect(recorder.Body.String()).To(ContainSubstring(`"id":3`))
+		})
+
+		It("saves a mapping", func() {
+			serve(NewOwnershipMappingsHandler(log, owners), "POST", "/ownership-mappings", `{"team":"platform","account_number":"123","effective_from":"2016-10-01"}`)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(owners.SaveMappingArgsForCall(0)).To(Equal(datamodels.OwnershipMapping{Team: "platform", AccountNumber: "123", EffectiveFrom: "2016-10-01"}))
+		})
+
+		It("updates a mapping by its id", func() {
+			serve(NewOwnershipMappingsHandler(log, owners), "POST", "/ownership-mappings", `{"id":3,"team":"databases","resource":"AWS"}`)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(owners.SaveMappingArgsForCall(0)).To(Equal(datamodels.OwnershipMapping{ID: 3, Team: "databases", Resource: "AWS"}))
+		})
+
+		It("rejects invalid mappings", func() {
+			owners.SaveMappingReturns(ownership.InvalidError{Reason: "Ownership mappings must have a team"})
+			serve(NewOwnershipMappingsHandler(log, owners), "POST", "/ownership-mappings", `{"resource":"AWS"}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("must have a team"))
+		})
+
+		It("returns not found when updating a mapping that is not saved", func() {
+			owners.SaveMappingReturns(ownership.ErrMappingNotFound)
+			serve(NewOwnershipMappingsHandler(log, owners), "POST", "/ownership-mappings", `{"id":4,"team":"databases","resource":"AWS"}`)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("fails when the mapping cannot be saved", func() {
+			owners.SaveMappingReturns(errors.New("connection refused"))
+			serve(NewOwnershipMappingsHandler(log, owners), "POST", "/ownership-mappings", `{"team":"platform","resource":"AWS"}`)
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(log.Out).To(Say("Failed to save ownership mapping: connection refused"))
+		})
+
+		It("deletes a mapping", func() {
+			serve(NewOwnershipMappingsHandler(log, owners), "DELETE", "/ownership-mappings?id=3", "")
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(owners.DeleteMappingArgsForCall(0)).To(Equal(int64(3)))
+		})
+
+		It("requires the id to delete", func() {
+			serve(NewOwnershipMappingsHandler(log, owners), "DELETE", "/ownership-mappings", "")
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("Chargeback", func() {
+		BeforeEach(func() {
+			owners.StatementsReturns([]datamodels.ChargebackStatement{
+				{Team: "platform", Path: "platform", Period: "2016-09", Total: 25, RolledUpTotal: 25, Lines: []datamodels.ChargebackLine{
+					{Team: "platform", Period: "2016-09", Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Cost: 25},
+				}},
+			}, nil)
+		})
+
+		It("returns the statements of the month as JSON", func() {
+			serve(NewChargebackHandler(log, time.UTC, owners), "GET", "/chargeback?month=2016-09", "")
+			year, month := owners.StatementsArgsForCall(0)
+			Expect(year).To(Equal(2016))
+			Expect(month).To(Equal(time.September))
+
+			var statements []datamodels.ChargebackStatement
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &statements)).To(Succeed())
+			Expect(statements[0].RolledUpTotal).To(Equal(25.0))
+		})
+
+		It("returns last month by default", func() {
+			serve(NewChargebackHandler(log, time.UTC, owners), "GET", "/chargeback", "")
+			lastMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+			year, month := owners.StatementsArgsForCall(0)
+			Expect(year).To(Equal(lastMonth.Year()))
+			Expect(month).To(Equal(lastMonth.Month()))
+		})
+
+		It("returns the lines of the statements as CSV", func() {
+			serve(NewChargebackHandler(log, time.UTC, owners), "GET", "/chargeback?month=2016-09&format=csv", "")
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/csv"))
+			Expect(recorder.Body.String()).To(ContainSubstring("platform,2016-09,AWS,123,,EC2,25"))
+		})
+
+		It("rejects invalid months", func() {
+			serve(NewChargebackHandler(log, time.UTC, owners), "GET", "/chargeback?month=september", "")
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -18,6 +18,23 @@ type FakeReportsDatabase struct {
 		result1 datamodels.UsageMonthToDate
 		result2 error
 	}
+	GetLatestMonthToDateSnapshotStub        func(datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error)
+	getLatestMonthToDateSnapshotMutex       sync.RWMutex
+	getLatestMonthToDateSnapshotArgsForCall []struct {
+		arg1 datamodels.ReportIdentifier
+	}
+	getLatestMonthToDateSnapshotReturns struct {
+		result1 datamodels.UsageMonthToDate
+		result2 error
+	}
+	SaveMonthToDateSnapshotsStub        func([]datamodels.UsageMonthToDate) error
+	saveMonthToDateSnapshotsMutex       sync.RWMutex
+	saveMonthToDateSnapshotsArgsForCall []struct {
+		arg1 []datamodels.UsageMonthToDate
+	}
+	saveMonthToDateSnapshotsReturns struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -56,11 +73,82 @@ func (fake *FakeReportsDatabase) GetUsageMonthToDateReturns(result1 datamodels.U
 	}{result1, result2}
 }
 
+func (fake *FakeReportsDatabase) GetLatestMonthToDateSnapshot(arg1 datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error) {
+	fake.getLatestMonthToDateSnapshotMutex.Lock()
+	fake.getLatestMonthToDateSnapshotArgsForCall = append(fake.getLatestMonthToDateSnapshotArgsForCall, struct {
+		arg1 datamodels.ReportIdentifier
+	}{arg1})
+	fake.recordInvocation("GetLatestMonthToDateSnapshot", []interface{}{arg1})
+	fake.getLatestMonthToDateSnapshotMutex.Unlock()
+	if fake.GetLatestMonthToDateSnapshotStub != nil {
+		return fake.GetLatestMonthToDateSnapshotStub(arg1)
+	} else {
+		return fake.getLatestMonthToDateSnapshotReturns.result1, fake.getLatestMonthToDateSnapshotReturns.result2
+	}
+}
+
+func (fake *FakeReportsDatabase) GetLatestMonthToDateSnapshotCallCount() int {
+	fake.getLatestMonthToDateSnapshotMutex.RLock()
+	defer fake.getLatestMonthToDateSnapshotMutex.RUnlock()
+	return len(fake.getLatestMonthToDateSnapshotArgsForCall)
+}
+
+func (fake *FakeReportsDatabase) GetLatestMonthToDateSnapshotArgsForCall(i int) datamodels.ReportIdentifier {
+	fake.getLatestMonthToDateSnapshotMutex.RLock()
+	defer fake.getLatestMonthToDateSnapshotMutex.RUnlock()
+	return fake.getLatestMonthToDateSnapshotArgsForCall[i].arg1
+}
+
+func (fake *FakeReportsDatabase) GetLatestMonthToDateSnapshotReturns(result1 datamodels.UsageMonthToDate, result2 error) {
+	fake.GetLatestMonthToDateSnapshotStub = nil
+	fake.getLatestMonthToDateSnapshotReturns = struct {
+		result1 datamodels.UsageMonthToDate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeReportsDatabase) SaveMonthToDateSnapshots(arg1 []datamodels.UsageMonthToDate) error {
+	fake.saveMonthToDateSnapshotsMutex.Lock()
+	fake.saveMonthToDateSnapshotsArgsForCall = append(fake.saveMonthToDateSnapshotsArgsForCall, struct {
+		arg1 []datamodels.UsageMonthToDate
+	}{arg1})
+	fake.recordInvocation("SaveMonthToDateSnapshots", []interface{}{arg1})
+	fake.saveMonthToDateSnapshotsMutex.Unlock()
+	if fake.SaveMonthToDateSnapshotsStub != nil {
+		return fake.SaveMonthToDateSnapshotsStub(arg1)
+	} else {
+		return fake.saveMonthToDateSnapshotsReturns.result1
+	}
+}
+
+func (fake *FakeReportsDatabase) SaveMonthToDateSnapshotsCallCount() int {
+	fake.saveMonthToDateSnapshotsMutex.RLock()
+	defer fake.saveMonthToDateSnapshotsMutex.RUnlock()
+	return len(fake.saveMonthToDateSnapshotsArgsForCall)
+}
+
+func (fake *FakeReportsDatabase) SaveMonthToDateSnapshotsArgsForCall(i int) []datamodels.UsageMonthToDate {
+	fake.saveMonthToDateSnapshotsMutex.RLock()
+	defer fake.saveMonthToDateSnapshotsMutex.RUnlock()
+	return fake.saveMonthToDateSnapshotsArgsForCall[i].arg1
+}
+
+func (fake *FakeReportsDatabase) SaveMonthToDateSnapshotsReturns(result1 error) {
+	fake.SaveMonthToDateSnapshotsStub = nil
+	fake.saveMonthToDateSnapshotsReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeReportsDatabase) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.getUsageMonthToDateMutex.RLock()
 	defer fake.getUsageMonthToDateMutex.RUnlock()
+	fake.getLatestMonthToDateSnapshotMutex.RLock()
+	defer fake.getLatestMonthToDateSnapshotMutex.RUnlock()
+	fake.saveMonthToDateSnapshotsMutex.RLock()
+	defer fake.saveMonthToDateSnapshotsMutex.RUnlock()
 	return fake.invocations
 }
 
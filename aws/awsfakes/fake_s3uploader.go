@@ -0,0 +1,79 @@
+// This file was generated by counterfeiter
+package awsfakes
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/challiwill/meteorologica/aws"
+)
+
+type FakeS3Uploader struct {
+	PutObjectStub        func(*s3.PutObjectInput) (*s3.PutObjectOutput, error)
+	putObjectMutex       sync.RWMutex
+	putObjectArgsForCall []struct {
+		arg1 *s3.PutObjectInput
+	}
+	putObjectReturns struct {
+		result1 *s3.PutObjectOutput
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeS3Uploader) PutObject(arg1 *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	fake.putObjectMutex.Lock()
+	fake.putObjectArgsForCall = append(fake.putObjectArgsForCall, struct {
+		arg1 *s3.PutObjectInput
+	}{arg1})
+	fake.recordInvocation("PutObject", []interface{}{arg1})
+	fake.putObjectMutex.Unlock()
+	if fake.PutObjectStub != nil {
+		return fake.PutObjectStub(arg1)
+	} else {
+		return fake.putObjectReturns.result1, fake.putObjectReturns.result2
+	}
+}
+
+func (fake *FakeS3Uploader) PutObjectCallCount() int {
+	fake.putObjectMutex.RLock()
+	defer fake.putObjectMutex.RUnlock()
+	return len(fake.putObjectArgsForCall)
+}
+
+func (fake *FakeS3Uploader) PutObjectArgsForCall(i int) *s3.PutObjectInput {
+	fake.putObjectMutex.RLock()
+	defer fake.putObjectMutex.RUnlock()
+	return fake.putObjectArgsForCall[i].arg1
+}
+
+func (fake *FakeS3Uploader) PutObjectReturns(result1 *s3.PutObjectOutput, result2 error) {
+	fake.PutObjectStub = nil
+	fake.putObjectReturns = struct {
+		result1 *s3.PutObjectOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeS3Uploader) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.putObjectMutex.RLock()
+	defer fake.putObjectMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeS3Uploader) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ aws.S3Uploader = new(FakeS3Uploader)
@@ -0,0 +1,45 @@
+package aws
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+//go:generate counterfeiter . S3Uploader
+
+type S3Uploader interface {
+	PutObject(*s3.PutObjectInput) (*s3.PutObjectOutput, error)
+}
+
+// Bucket uploads files as objects to an S3 bucket.
+type Bucket struct {
+	S3         S3Uploader
+	BucketName string
+}
+
+// NewBucket returns the bucket of the S3 service of the session or, when an
+// endpoint is given, of the S3-compatible service (such as MinIO) at the
+// endpoint, addressing the bucket in the path.
+func NewBucket(sess client.ConfigProvider, bucketName, endpoint string) *Bucket {
+	config := &aws.Config{}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	return &Bucket{
+		S3:         s3.New(sess, config),
+		BucketName: bucketName,
+	}
+}
+
+func (b *Bucket) Put(key string, file *os.File) error {
+	_, err := b.S3.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(b.BucketName),
+		Key:    aws.String(key),
+		Body:   file,
+	})
+	return err
+}
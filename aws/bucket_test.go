@@ -0,0 +1,98 @@
+package aws_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	. "github.com/challiwill/meteorologica/aws"
+	"github.com/challiwill/meteorologica/aws/awsfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bucket", func() {
+	var file *os.File
+
+	BeforeEach(func() {
+		var err error
+		file, err = ioutil.TempFile("", "bucket")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteString("some-contents")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.Seek(0, 0)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		file.Close()
+		os.Remove(file.Name())
+	})
+
+	Describe("Put", func() {
+		var (
+			uploader *awsfakes.FakeS3Uploader
+			bucket   *Bucket
+		)
+
+		BeforeEach(func() {
+			uploader = new(awsfakes.FakeS3Uploader)
+			bucket = &Bucket{S3: uploader, BucketName: "exports"}
+		})
+
+		It("puts the file as an object with the key", func() {
+			Expect(bucket.Put("billing/resource=AWS/year=2016/month=09/day=12/part.csv.gz", file)).To(Succeed())
+			Expect(uploader.PutObjectCallCount()).To(Equal(1))
+			input := uploader.PutObjectArgsForCall(0)
+			Expect(*input.Bucket).To(Equal("exports"))
+			Expect(*input.Key).To(Equal("billing/resource=AWS/year=2016/month=09/day=12/part.csv.gz"))
+			Expect(input.Body).To(Equal(file))
+		})
+
+		It("returns the error when the upload fails", func() {
+			uploader.PutObjectReturns(nil, errors.New("some-error"))
+			Expect(bucket.Put("key", file)).To(MatchError("some-error"))
+		})
+	})
+
+	Context("with an endpoint", func() {
+		var (
+			server   *httptest.Server
+			method   string
+			path     string
+			contents string
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				method, path, contents = r.Method, r.URL.Path, string(body)
+				w.Header().Set("ETag", `"some-etag"`)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("puts objects to the S3-compatible service at the endpoint, with the bucket in the path", func() {
+			sess, err := session.NewSession(&aws.Config{
+				Region:      aws.String("us-east-1"),
+				Credentials: credentials.NewStaticCredentials("some-id", "some-secret", ""),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			bucket := NewBucket(sess, "exports", server.URL)
+
+			Expect(bucket.Put("billing/part.csv", file)).To(Succeed())
+			Expect(method).To(Equal("PUT"))
+			Expect(path).To(Equal("/exports/billing/part.csv"))
+			Expect(contents).To(Equal("some-contents"))
+		})
+	})
+})
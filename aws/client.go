@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"archive/zip"
 	"bytes"
 	"fmt"
 	"io/ioutil"
@@ -30,6 +31,8 @@ type S3Client interface {
 
 type ReportsDatabase interface {
 	GetUsageMonthToDate(datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error)
+	GetLatestMonthToDateSnapshot(datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error)
+	SaveMonthToDateSnapshots([]datamodels.UsageMonthToDate) error
 }
 
 type Client struct {
@@ -40,9 +43,11 @@ type Client struct {
 	log           *logrus.Logger
 	location      *time.Location
 	db            ReportsDatabase
+
+	restatementWindow int
 }
 
-func NewClient(log *logrus.Logger, location *time.Location, az, bucketName string, accountNumber int64, s3Client S3Client, db ReportsDatabase) *Client {
+func NewClient(log *logrus.Logger, location *time.Location, az, bucketName string, accountNumber int64, s3Client S3Client, db ReportsDatabase, restatementWindow int) *Client {
 	return &Client{
 		Bucket:        bucketName,
 		AccountNumber: accountNumber,
@@ -51,6 +56,8 @@ func NewClient(log *logrus.Logger, location *time.Location, az, bucketName strin
 		log:           log,
 		location:      location,
 		db:            db,
+
+		restatementWindow: restatementWindow,
 	}
 }
 
@@ -58,46 +65,149 @@ func (c Client) Name() string {
 	return IAAS
 }
 
+// GetNormalizedUsage returns the daily usage derived from the billing file of
+// each month in the restatement window. Early in the month this includes the
+// previous month, whose final billing file keeps changing for a few days.
 func (c Client) GetNormalizedUsage() (datamodels.Reports, error) {
 	c.log.Info("Getting Monthly AWS Usage...")
 	c.log.Debug("Entering aws.GetNormalizedUsage")
 	defer c.log.Debug("Returning aws.GetNormalizedUsage")
 
-	awsMonthlyUsage, err := c.GetBillingData()
-	if err != nil {
-		c.log.Error("Failed to get AWS monthly usage")
-		return datamodels.Reports{}, errare.NewRequestError(err, "AWS")
+	normalizer := NewNormalizer(c.log, c.location, c.Region)
+	dailyReports := datamodels.Reports{}
+	for _, date := range calendar.LastDayOfEachMonth(calendar.RestatementWindow(c.location, c.restatementWindow)) {
+		awsMonthlyUsage, err := c.GetBillingData(date.Year(), date.Month())
+		if err != nil {
+			c.log.Error("Failed to get AWS monthly usage")
+			return datamodels.Reports{}, errare.NewRequestError(err, "AWS")
+		}
+		c.log.Debugf("Got AWS usage for %s", date.Format("2006-01"))
+
+		readerCleaner, err := csv.NewReaderCleaner(bytes.NewReader(awsMonthlyUsage), 29)
+		if err != nil {
+			return datamodels.Reports{}, csv.NewReadCleanError("AWS", err)
+		}
+		reports := []*Usage{}
+		err = csv.GenerateReports(readerCleaner, &reports)
+		if err != nil {
+			return datamodels.Reports{}, csv.NewReportParseError("AWS", err)
+		}
+
+		normalizedReports := normalizer.Normalize(reports, date.Year(), date.Month(), date.Day())
+		normalizedReports = datamodels.ConsolidateReports(normalizedReports)
+		normalizedReports, err = c.CalculateDailyUsages(normalizedReports)
+		if err != nil {
+			return datamodels.Reports{}, err
+		}
+		dailyReports = append(dailyReports, normalizedReports...)
 	}
-	c.log.Debug("Got Monthly AWS usage")
 
-	readerCleaner, err := csv.NewReaderCleaner(bytes.NewReader(awsMonthlyUsage), 29)
+	return dailyReports, nil
+}
+
+// GetInvoice reads what the month was invoiced from the InvoiceTotal records
+// of its billing file, or from its StatementTotal while the month is open.
+// Only LinkedLineItems are saved: negative PayerLineItems are credits and the
+// other PayerLineItems are dropped.
+func (c Client) GetInvoice(year int, month time.Month) (datamodels.Invoice, error) {
+	c.log.Debug("Entering aws.GetInvoice")
+	defer c.log.Debug("Returning aws.GetInvoice")
+
+	invoice := datamodels.Invoice{Resource: IAAS, Year: year, Month: month}
+	billingData, err := c.GetBillingData(year, month)
+	if err != nil {
+		return invoice, errare.NewRequestError(err, IAAS)
+	}
+	readerCleaner, err := csv.NewReaderCleaner(bytes.NewReader(billingData), 29)
 	if err != nil {
-		return datamodels.Reports{}, csv.NewReadCleanError("AWS", err)
+		return invoice, csv.NewReadCleanError(IAAS, err)
 	}
 	reports := []*Usage{}
 	err = csv.GenerateReports(readerCleaner, &reports)
 	if err != nil {
-		return datamodels.Reports{}, csv.NewReportParseError("AWS", err)
+		return invoice, csv.NewReportParseError(IAAS, err)
 	}
 
-	normalizer := NewNormalizer(c.log, c.location, c.Region)
-	normalizedReports := normalizer.Normalize(reports)
-	normalizedReports = datamodels.ConsolidateReports(normalizedReports)
-	normalizedReports, err = c.CalculateDailyUsages(normalizedReports)
-	if err != nil {
-		return datamodels.Reports{}, err
+	var (
+		invoiceTotal   float64
+		statementTotal float64
+		invoiced       bool
+		stated         bool
+	)
+	for _, usage := range reports {
+		switch usage.RecordType {
+		case "InvoiceTotal":
+			invoiceTotal += usage.TotalCost
+			invoiced = true
+		case "StatementTotal":
+			statementTotal += usage.TotalCost
+			stated = true
+		case "Rounding":
+			invoice.Rounding += usage.TotalCost
+		case "PayerLineItem":
+			if usage.TotalCost < 0 {
+				invoice.Credits += usage.TotalCost
+			} else {
+				invoice.Dropped += usage.TotalCost
+			}
+		}
+	}
+	switch {
+	case invoiced:
+		invoice.Total = invoiceTotal
+	case stated:
+		invoice.Total = statementTotal
+	default:
+		return invoice, fmt.Errorf("AWS billing file for %d-%s has no invoice or statement total", year, calendar.PadMonth(month))
 	}
+	return invoice, nil
+}
 
-	return normalizedReports, nil
+// GetResourceLevelUsage reads the line items of each day in the restatement
+// window from the detailed billing report with resources and tags, which
+// unlike the monthly billing file is broken down by hour and resource.
+func (c Client) GetResourceLevelUsage() (datamodels.Reports, error) {
+	c.log.Info("Getting AWS usage by resource...")
+	c.log.Debug("Entering aws.GetResourceLevelUsage")
+	defer c.log.Debug("Returning aws.GetResourceLevelUsage")
+
+	window := calendar.RestatementWindow(c.location, c.restatementWindow)
+	normalizer := NewNormalizer(c.log, c.location, c.Region)
+	normalizedReports := datamodels.Reports{}
+	for _, lastDate := range calendar.LastDayOfEachMonth(window) {
+		awsDetailedUsage, err := c.GetDetailedBillingData(lastDate.Year(), lastDate.Month())
+		if err != nil {
+			c.log.Error("Failed to get AWS detailed usage")
+			return datamodels.Reports{}, errare.NewRequestError(err, "AWS")
+		}
+		c.log.Debugf("Got detailed AWS usage for %s", lastDate.Format("2006-01"))
+
+		readerCleaner, err := csv.NewReaderCleaner(bytes.NewReader(awsDetailedUsage), 22)
+		if err != nil {
+			return datamodels.Reports{}, csv.NewReadCleanError("AWS", err)
+		}
+		reports := []*DetailedUsage{}
+		err = csv.GenerateReports(readerCleaner, &reports)
+		if err != nil {
+			return datamodels.Reports{}, csv.NewReportParseError("AWS", err)
+		}
+
+		for _, date := range window {
+			if date.Year() == lastDate.Year() && date.Month() == lastDate.Month() {
+				normalizedReports = append(normalizedReports, normalizer.NormalizeDetailed(reports, date.Year(), date.Month(), date.Day())...)
+			}
+		}
+	}
+	return datamodels.ConsolidateResourceReports(normalizedReports), nil
 }
 
-func (c Client) GetBillingData() ([]byte, error) {
+func (c Client) GetBillingData(year int, month time.Month) ([]byte, error) {
 	c.log.Debug("Entering aws.GetBillingData")
 	defer c.log.Debug("Returning aws.GetBillingData")
 
 	objectInput := &s3.GetObjectInput{
 		Bucket: aws.String(c.Bucket),
-		Key:    aws.String(c.monthlyBillingFileName()),
+		Key:    aws.String(c.monthlyBillingFileName(year, month)),
 	}
 	resp, err := c.s3.GetObject(objectInput)
 	if err != nil {
@@ -108,9 +218,55 @@ func (c Client) GetBillingData() ([]byte, error) {
 	return ioutil.ReadAll(resp.Body)
 }
 
+// GetDetailedBillingData downloads and unzips the given month's detailed
+// billing report with resources and tags.
+func (c Client) GetDetailedBillingData(year int, month time.Month) ([]byte, error) {
+	c.log.Debug("Entering aws.GetDetailedBillingData")
+	defer c.log.Debug("Returning aws.GetDetailedBillingData")
+
+	objectInput := &s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(c.detailedBillingFileName(year, month)),
+	}
+	resp, err := c.s3.GetObject(objectInput)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	compressed, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(compressed), int64(len(compressed)))
+	if err != nil {
+		return nil, err
+	}
+	if len(archive.File) == 0 {
+		return nil, csv.NewEmptyReportError("unzipping AWS detailed billing report")
+	}
+	file, err := archive.File[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ioutil.ReadAll(file)
+}
+
+// CalculateDailyUsages turns month-to-date reports into daily reports. Each
+// month-to-date report is stored as a snapshot and the daily usage is the
+// difference from the latest earlier snapshot of the month. When runs were
+// missed the difference is spread evenly over the days since that snapshot.
+// A service without a snapshot of its own only has its usage spread from the
+// first of the month when no snapshot was taken for any service that month;
+// otherwise it is spread over the days since the latest snapshot, as the days
+// before it are already settled.
 func (c Client) CalculateDailyUsages(reports datamodels.Reports) (datamodels.Reports, error) {
+	previousUsages := make([]datamodels.UsageMonthToDate, len(reports))
+	lastSnapshotDay := make(map[string]int)
 	for i, report := range reports {
-		usageToDate, err := c.db.GetUsageMonthToDate(datamodels.ReportIdentifier{
+		id := datamodels.ReportIdentifier{
 			AccountNumber: report.AccountNumber,
 			AccountName:   report.AccountName,
 			ServiceType:   report.ServiceType,
@@ -119,19 +275,101 @@ func (c Client) CalculateDailyUsages(reports datamodels.Reports) (datamodels.Rep
 			Year:          report.Year,
 			Resource:      report.Resource,
 			Region:        report.Region,
-		})
+		}
+		previous, snapshot, err := c.previousUsage(id)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to get usage month-to-date: %s", err.Error())
+			return nil, err
+		}
+		previousUsages[i] = previous
+		month := fmt.Sprintf("%04d%02d", report.Year, int(report.Month))
+		if snapshot && previous.Day > lastSnapshotDay[month] {
+			lastSnapshotDay[month] = previous.Day
 		}
+	}
+
+	dailyReports := datamodels.Reports{}
+	snapshots := []datamodels.UsageMonthToDate{}
+	for i, report := range reports {
+		previous := previousUsages[i]
+		if previous.Day == 0 {
+			previous.Day = lastSnapshotDay[fmt.Sprintf("%04d%02d", report.Year, int(report.Month))]
+		}
+
+		days := report.Day - previous.Day
+		for day := previous.Day + 1; day <= report.Day; day++ {
+			daily := report
+			daily.ID = reportID(report.AccountNumber, report.ServiceType, report.Region, report.Year, report.Month, day)
+			daily.Day = day
+			daily.UsageQuantity = (report.UsageQuantity - previous.UsageQuantity) / float64(days)
+			daily.Cost = (report.Cost - previous.Cost) / float64(days)
+			if report.NormalizedUnitOfMeasure == previous.NormalizedUnitOfMeasure || previousUsages[i].Day == 0 {
+				daily.NormalizedUsageQuantity = (report.NormalizedUsageQuantity - previous.NormalizedUsageQuantity) / float64(days)
+			} else {
+				daily.NormalizedUsageQuantity = 0
+				daily.NormalizedUnitOfMeasure = datamodels.MixedUnits
+			}
+			if daily.Cost < 0 {
+				c.log.Warnf("AWS month-to-date cost of %s for %s went down by %f on %d-%d-%d", report.ServiceType, report.AccountNumber, -daily.Cost, report.Year, report.Month, day)
+			}
+			dailyReports = append(dailyReports, daily)
+		}
+
+		snapshots = append(snapshots, datamodels.UsageMonthToDate{
+			AccountNumber: report.AccountNumber,
+			AccountName:   report.AccountName,
+			Day:           report.Day,
+			Month:         report.Month,
+			Year:          report.Year,
+			ServiceType:   report.ServiceType,
+			UsageQuantity: report.UsageQuantity,
+			Cost:          report.Cost,
+			Region:        report.Region,
+			UnitOfMeasure: report.UnitOfMeasure,
+			Resource:      report.Resource,
+
+			NormalizedUsageQuantity: report.NormalizedUsageQuantity,
+			NormalizedUnitOfMeasure: report.NormalizedUnitOfMeasure,
+		})
+	}
 
-		reports[i].UsageQuantity = reports[i].UsageQuantity - usageToDate.UsageQuantity
-		reports[i].Cost = reports[i].Cost - usageToDate.Cost
+	err := c.db.SaveMonthToDateSnapshots(snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to save month-to-date snapshots: %s", err.Error())
 	}
-	return reports, nil
+	return dailyReports, nil
 }
 
-func (c Client) monthlyBillingFileName() string {
-	year, month, _ := calendar.YesterdaysDate(c.location)
+// previousUsage returns the latest snapshot of the month taken before the
+// identified day, and whether it is a snapshot. Months that started before
+// snapshots were stored have no snapshot, so what was already saved to
+// resource_billing for the days before the identified day is used instead and
+// the remainder is attributed to the identified day.
+func (c Client) previousUsage(id datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, bool, error) {
+	snapshot, err := c.db.GetLatestMonthToDateSnapshot(id)
+	if err != nil {
+		return datamodels.UsageMonthToDate{}, false, fmt.Errorf("Failed to get month-to-date snapshot: %s", err.Error())
+	}
+	if snapshot.Day != 0 {
+		return snapshot, true, nil
+	}
+
+	usageToDate, err := c.db.GetUsageMonthToDate(id)
+	if err != nil {
+		return datamodels.UsageMonthToDate{}, false, fmt.Errorf("Failed to get usage month-to-date: %s", err.Error())
+	}
+	if usageToDate.Cost == 0 && usageToDate.UsageQuantity == 0 {
+		return datamodels.UsageMonthToDate{}, false, nil
+	}
+	usageToDate.Day = id.Day - 1
+	return usageToDate, false, nil
+}
+
+func (c Client) monthlyBillingFileName(year int, month time.Month) string {
 	monthStr := calendar.PadMonth(month)
 	return url.QueryEscape(strings.Join([]string{strconv.FormatInt(c.AccountNumber, 10), "aws", "billing", "csv", strconv.Itoa(year), monthStr}, "-") + ".csv")
 }
+
+func (c Client) detailedBillingFileName(year int, month time.Month) string {
+	monthStr := calendar.PadMonth(month)
+	return url.QueryEscape(strings.Join([]string{strconv.FormatInt(c.AccountNumber, 10), "aws", "billing", "detailed", "line", "items", "with", "resources", "and", "tags", strconv.Itoa(year), monthStr}, "-") + ".csv.zip")
+}
@@ -1,6 +1,8 @@
 package aws_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -35,7 +37,7 @@ var _ = Describe("Client", func() {
 		log.Out = logOutput
 		s3Client = new(awsfakes.FakeS3Client)
 		dbClient = new(awsfakes.FakeReportsDatabase)
-		client = NewClient(log, time.Now().Location(), "my-region", "my-bucket", 1234567890, s3Client, dbClient)
+		client = NewClient(log, time.Now().Location(), "my-region", "my-bucket", 1234567890, s3Client, dbClient, 1)
 	})
 
 	Describe("Name", func() {
@@ -55,7 +57,7 @@ var _ = Describe("Client", func() {
 		)
 
 		JustBeforeEach(func() {
-			usage, err = client.GetBillingData()
+			usage, err = client.GetBillingData(time.Now().Year(), time.Now().Month())
 		})
 
 		Context("when AWS returns a billing file", func() {
@@ -102,6 +104,131 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("GetInvoice", func() {
+		var (
+			billingFile string
+			invoice     datamodels.Invoice
+			err         error
+		)
+
+		BeforeEach(func() {
+			billingFile = monthlyBillingHeader +
+				monthlyBillingRow("LinkedLineItem", "10.00") +
+				monthlyBillingRow("PayerLineItem", "3.00") +
+				monthlyBillingRow("PayerLineItem", "-2.00") +
+				monthlyBillingRow("Rounding", "0.01") +
+				monthlyBillingRow("StatementTotal", "11.00") +
+				monthlyBillingRow("InvoiceTotal", "11.01")
+		})
+
+		JustBeforeEach(func() {
+			readCloser := new(awsfakes.FakeReadCloser)
+			readCloser.ReadStub = bytes.NewReader([]byte(billingFile)).Read
+			s3Client.GetObjectReturns(&s3.GetObjectOutput{Body: readCloser}, nil)
+			invoice, err = client.GetInvoice(2016, time.September)
+		})
+
+		It("reads the invoice total and what is not saved from the month's billing file", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*s3Client.GetObjectArgsForCall(0).Key).To(Equal("1234567890-aws-billing-csv-2016-09.csv"))
+			Expect(invoice).To(Equal(datamodels.Invoice{
+				Resource: "AWS",
+				Year:     2016,
+				Month:    time.September,
+				Total:    11.01,
+				Credits:  -2,
+				Rounding: 0.01,
+				Dropped:  3,
+			}))
+		})
+
+		Context("when the month has not been invoiced yet", func() {
+			BeforeEach(func() {
+				billingFile = monthlyBillingHeader +
+					monthlyBillingRow("LinkedLineItem", "10.00") +
+					monthlyBillingRow("StatementTotal", "10.00")
+			})
+
+			It("uses the statement total", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(invoice.Total).To(Equal(10.0))
+			})
+		})
+
+		Context("when the billing file has no total", func() {
+			BeforeEach(func() {
+				billingFile = monthlyBillingHeader + monthlyBillingRow("LinkedLineItem", "10.00")
+			})
+
+			It("errors", func() {
+				Expect(err).To(MatchError(ContainSubstring("has no invoice or statement total")))
+			})
+		})
+	})
+
+	Describe("GetResourceLevelUsage", func() {
+		var (
+			reports datamodels.Reports
+			err     error
+		)
+
+		JustBeforeEach(func() {
+			reports, err = client.GetResourceLevelUsage()
+		})
+
+		Context("when AWS returns a detailed billing report", func() {
+			BeforeEach(func() {
+				yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+				twoDaysAgo := time.Now().AddDate(0, 0, -2).Format("2006-01-02")
+				billingFile := detailedUsageHeader +
+					"1,2,111,LineItem,1,Amazon Elastic Compute Cloud,1,1,1,BoxUsage:m4.large,RunInstances,us-east-1a,N,$0.12 per On Demand Linux m4.large Instance Hour," + yesterday + " 00:00:00," + yesterday + " 01:00:00,1,0.12,0.12,0.12,0.12,i-1,some-tag\n" +
+					"1,2,111,LineItem,2,Amazon Elastic Compute Cloud,1,1,1,BoxUsage:m4.large,RunInstances,us-east-1a,N,$0.12 per On Demand Linux m4.large Instance Hour," + yesterday + " 01:00:00," + yesterday + " 02:00:00,1,0.12,0.12,0.12,0.12,i-1,some-tag\n" +
+					"1,2,111,LineItem,3,Amazon Elastic Compute Cloud,1,1,1,BoxUsage:m4.large,RunInstances,us-east-1a,N,$0.12 per On Demand Linux m4.large Instance Hour," + yesterday + " 00:00:00," + yesterday + " 01:00:00,1,0.12,0.12,0.12,0.12,i-2,some-tag\n" +
+					"1,2,111,LineItem,4,Amazon Elastic Compute Cloud,1,1,1,BoxUsage:m4.large,RunInstances,us-east-1a,N,$0.12 per On Demand Linux m4.large Instance Hour," + twoDaysAgo + " 00:00:00," + twoDaysAgo + " 01:00:00,1,0.12,0.12,0.12,0.12,i-1,some-tag\n" +
+					"1,2,,InvoiceTotal,5,,,,,,,,,Total amount,,,,,10.00,,10.00,,\n"
+
+				zipped := new(bytes.Buffer)
+				archive := zip.NewWriter(zipped)
+				file, zipErr := archive.Create("detailed.csv")
+				Expect(zipErr).NotTo(HaveOccurred())
+				_, zipErr = file.Write([]byte(billingFile))
+				Expect(zipErr).NotTo(HaveOccurred())
+				Expect(archive.Close()).To(Succeed())
+
+				readCloser := new(awsfakes.FakeReadCloser)
+				readCloser.ReadStub = bytes.NewReader(zipped.Bytes()).Read
+				s3Client.GetObjectReturns(&s3.GetObjectOutput{Body: readCloser}, nil)
+			})
+
+			It("requests the detailed billing report", func() {
+				Expect(err).NotTo(HaveOccurred())
+				object := s3Client.GetObjectArgsForCall(0)
+				Expect(*object.Key).To(HavePrefix("1234567890-aws-billing-detailed-line-items-with-resources-and-tags-"))
+				Expect(*object.Key).To(HaveSuffix(".csv.zip"))
+			})
+
+			It("returns yesterday's usage per resource", func() {
+				Expect(reports).To(HaveLen(2))
+				Expect(reports[0].ResourceID).To(Equal("i-1"))
+				Expect(reports[0].Cost).To(BeNumerically("~", 0.24, 0.00001))
+				Expect(reports[0].UnitOfMeasure).To(Equal("Hrs"))
+				Expect(reports[1].ResourceID).To(Equal("i-2"))
+				Expect(reports[1].Cost).To(BeNumerically("~", 0.12, 0.00001))
+			})
+		})
+
+		Context("when AWS returns an error", func() {
+			BeforeEach(func() {
+				s3Client.GetObjectReturns(nil, errors.New("request error"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("request error"))
+			})
+		})
+	})
+
 	Describe("CalculateDailyUsages", func() {
 		var (
 			originalReports  datamodels.Reports
@@ -118,7 +245,7 @@ var _ = Describe("Client", func() {
 					UsageQuantity: 10,
 					Cost:          100,
 					Resource:      "AWS",
-					Day:           1,
+					Day:           5,
 					Month:         time.Month(2),
 					Year:          1337,
 					Region:        "my-region",
@@ -130,7 +257,7 @@ var _ = Describe("Client", func() {
 					UsageQuantity: 9,
 					Cost:          20,
 					Resource:      "AWS",
-					Day:           1,
+					Day:           5,
 					Month:         time.Month(2),
 					Year:          1337,
 					Region:        "my-region",
@@ -142,116 +269,199 @@ var _ = Describe("Client", func() {
 			populatedReports, err = client.CalculateDailyUsages(originalReports)
 		})
 
-		Context("when a database is connected", func() {
-			It("fetches cost and usage month to date for each report", func() {
-				Expect(dbClient.GetUsageMonthToDateCallCount()).To(Equal(2))
-				Expect(dbClient.GetUsageMonthToDateArgsForCall(0)).To(Equal(datamodels.ReportIdentifier{
-					AccountNumber: "some-account-number",
-					AccountName:   "some-account-name",
-					ServiceType:   "some-service-type",
-					Resource:      "AWS",
-					Day:           1,
-					Month:         time.Month(2),
-					Year:          1337,
-					Region:        "my-region",
-				}))
-				Expect(dbClient.GetUsageMonthToDateArgsForCall(1)).To(Equal(datamodels.ReportIdentifier{
-					AccountNumber: "some-account-number",
-					AccountName:   "some-account-name",
-					ServiceType:   "some-other-service-type",
-					Resource:      "AWS",
-					Day:           1,
-					Month:         time.Month(2),
-					Year:          1337,
-					Region:        "my-region",
-				}))
-			})
+		It("looks up the latest snapshot before the day of each report", func() {
+			Expect(dbClient.GetLatestMonthToDateSnapshotCallCount()).To(Equal(2))
+			Expect(dbClient.GetLatestMonthToDateSnapshotArgsForCall(0)).To(Equal(datamodels.ReportIdentifier{
+				AccountNumber: "some-account-number",
+				AccountName:   "some-account-name",
+				ServiceType:   "some-service-type",
+				Resource:      "AWS",
+				Day:           5,
+				Month:         time.Month(2),
+				Year:          1337,
+				Region:        "my-region",
+			}))
+		})
 
-			Context("when the database returns found usage", func() {
-				var (
-					callCount      int
-					returnedUsages []datamodels.UsageMonthToDate
-				)
+		It("saves each report as a month-to-date snapshot", func() {
+			Expect(dbClient.SaveMonthToDateSnapshotsCallCount()).To(Equal(1))
+			snapshots := dbClient.SaveMonthToDateSnapshotsArgsForCall(0)
+			Expect(snapshots).To(HaveLen(2))
+			Expect(snapshots[0]).To(Equal(datamodels.UsageMonthToDate{
+				AccountNumber: "some-account-number",
+				AccountName:   "some-account-name",
+				Day:           5,
+				Month:         time.Month(2),
+				Year:          1337,
+				ServiceType:   "some-service-type",
+				UsageQuantity: 10,
+				Cost:          100,
+				Region:        "my-region",
+				Resource:      "AWS",
+			}))
+		})
 
-				BeforeEach(func() {
-					originalReports = append(originalReports,
-						datamodels.Report{
-							AccountName:   "some-linked-account-name",
-							AccountNumber: "some-linked-account-number",
-							ServiceType:   "some-other-service-type",
-							UsageQuantity: 1,
-							Cost:          1,
-						},
-					)
-					returnedUsages = []datamodels.UsageMonthToDate{
-						datamodels.UsageMonthToDate{
-							AccountNumber: "some-linked-account-number",
-							AccountName:   "some-linked-account-name",
-							Month:         time.Now().Month(),
-							Year:          time.Now().Year(),
-							ServiceType:   "some-service-type",
-							UsageQuantity: 9,
-							Cost:          90,
-							Region:        "my-region",
-							UnitOfMeasure: "GB",
-							Resource:      "AWS",
-						},
-						datamodels.UsageMonthToDate{
-							AccountNumber: "some-account-number",
-							AccountName:   "some-account-name",
-							Month:         time.Now().Month(),
-							Year:          time.Now().Year(),
-							ServiceType:   "some-other-service-type",
-							UsageQuantity: 7,
-							Cost:          19,
-							Region:        "my-region",
-							UnitOfMeasure: "GB",
-							Resource:      "AWS",
-						},
+		Context("when the previous snapshot is from the day before", func() {
+			BeforeEach(func() {
+				dbClient.GetLatestMonthToDateSnapshotStub = func(id datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error) {
+					if id.ServiceType == "some-service-type" {
+						return datamodels.UsageMonthToDate{Day: 4, UsageQuantity: 9, Cost: 90}, nil
 					}
-					dbClient.GetUsageMonthToDateStub = func(datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error) {
-						if callCount > 1 {
-							return datamodels.UsageMonthToDate{}, nil
-						}
-						retUsage := returnedUsages[callCount]
-						callCount++
-						return retUsage, nil
+					return datamodels.UsageMonthToDate{Day: 4, UsageQuantity: 7, Cost: 19}, nil
+				}
+			})
+
+			It("returns the difference between the snapshots", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(populatedReports).To(HaveLen(2))
+				Expect(populatedReports[0].Day).To(Equal(5))
+				Expect(populatedReports[0].UsageQuantity).To(Equal(float64(1)))
+				Expect(populatedReports[0].Cost).To(Equal(float64(10)))
+				Expect(populatedReports[1].UsageQuantity).To(Equal(float64(2)))
+				Expect(populatedReports[1].Cost).To(Equal(float64(1)))
+			})
+
+			It("does not fall back to the saved reports", func() {
+				Expect(dbClient.GetUsageMonthToDateCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the normalized unit changed since the previous snapshot", func() {
+			BeforeEach(func() {
+				originalReports = originalReports[:1]
+				originalReports[0].NormalizedUsageQuantity = 10
+				originalReports[0].NormalizedUnitOfMeasure = "Hours"
+				dbClient.GetLatestMonthToDateSnapshotReturns(datamodels.UsageMonthToDate{Day: 3, UsageQuantity: 4, Cost: 40, NormalizedUsageQuantity: 4, NormalizedUnitOfMeasure: "Seconds"}, nil)
+			})
+
+			It("marks the normalized usage of each day as mixed", func() {
+				Expect(populatedReports).To(HaveLen(2))
+				for _, report := range populatedReports {
+					Expect(report.NormalizedUsageQuantity).To(BeZero())
+					Expect(report.NormalizedUnitOfMeasure).To(Equal(datamodels.MixedUnits))
+					Expect(report.Cost).To(Equal(float64(30)))
+				}
+			})
+		})
+
+		Context("when runs were missed since the previous snapshot", func() {
+			BeforeEach(func() {
+				originalReports = originalReports[:1]
+				dbClient.GetLatestMonthToDateSnapshotReturns(datamodels.UsageMonthToDate{Day: 2, UsageQuantity: 4, Cost: 40}, nil)
+			})
+
+			It("spreads the difference over the missed days", func() {
+				Expect(populatedReports).To(HaveLen(3))
+				for i, report := range populatedReports {
+					Expect(report.Day).To(Equal(3 + i))
+					Expect(report.UsageQuantity).To(Equal(float64(2)))
+					Expect(report.Cost).To(Equal(float64(20)))
+				}
+			})
+
+			It("gives each day its own ID", func() {
+				Expect(populatedReports[0].ID).NotTo(Equal(populatedReports[1].ID))
+				Expect(populatedReports[1].ID).NotTo(Equal(populatedReports[2].ID))
+			})
+		})
+
+		Context("when a service first appears after snapshots were taken for the month", func() {
+			BeforeEach(func() {
+				dbClient.GetLatestMonthToDateSnapshotStub = func(id datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error) {
+					if id.ServiceType == "some-service-type" {
+						return datamodels.UsageMonthToDate{Day: 3, UsageQuantity: 8, Cost: 80}, nil
 					}
+					return datamodels.UsageMonthToDate{}, nil
+				}
+			})
+
+			It("only spreads its usage over the days since the latest snapshot", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(populatedReports).To(HaveLen(4))
+				Expect(populatedReports[2].ServiceType).To(Equal("some-other-service-type"))
+				Expect(populatedReports[2].Day).To(Equal(4))
+				Expect(populatedReports[2].Cost).To(Equal(float64(10)))
+				Expect(populatedReports[3].Day).To(Equal(5))
+				Expect(populatedReports[3].Cost).To(Equal(float64(10)))
+			})
+		})
+
+		Context("when there is no snapshot for the month", func() {
+			Context("and nothing was saved for the month", func() {
+				BeforeEach(func() {
+					originalReports = originalReports[:1]
 				})
 
-				AfterEach(func() {
-					callCount = 0
+				It("spreads the month-to-date usage from the first of the month", func() {
+					Expect(populatedReports).To(HaveLen(5))
+					Expect(populatedReports[0].Day).To(Equal(1))
+					Expect(populatedReports[0].Cost).To(Equal(float64(20)))
+					Expect(populatedReports[4].Day).To(Equal(5))
 				})
+			})
 
-				It("returns the right number of reports", func() {
-					Expect(populatedReports).To(HaveLen(3))
+			Context("and reports were saved before snapshots were stored", func() {
+				BeforeEach(func() {
+					originalReports = originalReports[:1]
+					dbClient.GetUsageMonthToDateReturns(datamodels.UsageMonthToDate{UsageQuantity: 9, Cost: 90}, nil)
 				})
 
-				It("calculates daily usages when previous use is found", func() {
-					Expect(populatedReports[0].UsageQuantity).To(Equal(float64(1)))
+				It("attributes the remainder to the day of the report", func() {
+					Expect(populatedReports).To(HaveLen(1))
+					Expect(populatedReports[0].Day).To(Equal(5))
 					Expect(populatedReports[0].Cost).To(Equal(float64(10)))
-					Expect(populatedReports[1].UsageQuantity).To(Equal(float64(2)))
-					Expect(populatedReports[1].Cost).To(Equal(float64(1)))
 				})
 
-				It("sets daily amounts to total amounts when no previous usage found", func() {
-					Expect(populatedReports[2].Cost).To(Equal(originalReports[2].Cost))
-					Expect(populatedReports[2].UsageQuantity).To(Equal(originalReports[2].UsageQuantity))
+				It("only counts what was saved before the day of the report", func() {
+					Expect(dbClient.GetUsageMonthToDateCallCount()).To(Equal(1))
+					Expect(dbClient.GetUsageMonthToDateArgsForCall(0).Day).To(Equal(5))
 				})
 			})
 
-			Context("when the database fails", func() {
+			Context("and the day is rerun after it was saved", func() {
 				BeforeEach(func() {
-					dbClient.GetUsageMonthToDateReturns(datamodels.UsageMonthToDate{}, errors.New("some-error"))
+					originalReports = originalReports[:1]
+					saved := map[int]float64{3: 30, 4: 60, 5: 10}
+					dbClient.GetUsageMonthToDateStub = func(id datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error) {
+						usage := datamodels.UsageMonthToDate{}
+						for day, cost := range saved {
+							if day < id.Day {
+								usage.Cost += cost
+							}
+						}
+						return usage, nil
+					}
 				})
 
-				It("errors", func() {
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring("some-error"))
+				It("does not subtract the day's own saved cost", func() {
+					Expect(populatedReports).To(HaveLen(1))
+					Expect(populatedReports[0].Day).To(Equal(5))
+					Expect(populatedReports[0].Cost).To(Equal(float64(10)))
 				})
 			})
 		})
 
+		Context("when the database fails", func() {
+			BeforeEach(func() {
+				dbClient.GetLatestMonthToDateSnapshotReturns(datamodels.UsageMonthToDate{}, errors.New("some-error"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("some-error"))
+			})
+		})
+
+		Context("when saving the snapshots fails", func() {
+			BeforeEach(func() {
+				dbClient.SaveMonthToDateSnapshotsReturns(errors.New("some-error"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("some-error"))
+			})
+		})
 	})
 })
 
@@ -259,3 +469,11 @@ var dailyUsageResponse = `
 			one, two, three, four, five
 			sometimes, you, might, think, you, want json
 			but really, we, know, you, want, CSV`
+
+var monthlyBillingHeader = "InvoiceID,PayerAccountId,LinkedAccountId,RecordType,RecordID,BillingPeriodStartDate,BillingPeriodEndDate,InvoiceDate,PayerAccountName,LinkedAccountName,TaxationAddress,PayerPONumber,ProductCode,ProductName,SellerOfRecord,UsageType,Operation,RateId,ItemDescription,UsageStartDate,UsageEndDate,UsageQuantity,BlendedRate,CurrencyCode,CostBeforeTax,Credits,TaxAmount,TaxType,TotalCost\n"
+
+func monthlyBillingRow(recordType, totalCost string) string {
+	return fmt.Sprintf("1,2,111,%s,1,2016/09/01 00:00:00,2016/09/30 23:59:59,2016/10/02 00:00:00,payer,linked,,,AmazonEC2,Amazon Elastic Compute Cloud,,BoxUsage,RunInstances,1,description,2016/09/01 00:00:00,2016/09/30 23:59:59,1,0.1,USD,%s,0,0,,%s\n", recordType, totalCost, totalCost)
+}
+
+var detailedUsageHeader = "InvoiceID,PayerAccountId,LinkedAccountId,RecordType,RecordId,ProductName,RateId,SubscriptionId,PricingPlanId,UsageType,Operation,AvailabilityZone,ReservedInstance,ItemDescription,UsageStartDate,UsageEndDate,UsageQuantity,BlendedRate,BlendedCost,UnBlendedRate,UnBlendedCost,ResourceId,user:Name\n"
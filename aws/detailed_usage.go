@@ -0,0 +1,46 @@
+package aws
+
+import "time"
+
+const detailedUsageTimeFormat = "2006-01-02 15:04:05"
+
+// DetailedUsage is a row of the detailed billing report with resources and
+// tags. Only the columns before the user defined tags are read.
+type DetailedUsage struct {
+	InvoiceID        string  `csv:"InvoiceID"`
+	PayerAccountId   string  `csv:"PayerAccountId"`
+	LinkedAccountId  string  `csv:"LinkedAccountId"`
+	RecordType       string  `csv:"RecordType"`
+	RecordId         string  `csv:"RecordId"`
+	ProductName      string  `csv:"ProductName"`
+	RateId           string  `csv:"RateId"`
+	SubscriptionId   string  `csv:"SubscriptionId"`
+	PricingPlanId    string  `csv:"PricingPlanId"`
+	UsageType        string  `csv:"UsageType"`
+	Operation        string  `csv:"Operation"`
+	AvailabilityZone string  `csv:"AvailabilityZone"`
+	ReservedInstance string  `csv:"ReservedInstance"`
+	ItemDescription  string  `csv:"ItemDescription"`
+	UsageStartDate   string  `csv:"UsageStartDate"`
+	UsageEndDate     string  `csv:"UsageEndDate"`
+	UsageQuantity    float64 `csv:"UsageQuantity"`
+	BlendedRate      string  `csv:"BlendedRate"`
+	BlendedCost      float64 `csv:"BlendedCost"`
+	UnBlendedRate    string  `csv:"UnBlendedRate"`
+	UnBlendedCost    float64 `csv:"UnBlendedCost"`
+	ResourceId       string  `csv:"ResourceId"`
+}
+
+// Hash matches Usage.Hash so resource-level rows roll up into the same
+// consolidated report as the monthly billing file.
+func (u DetailedUsage) Hash(az string, year int, month time.Month, day int) string {
+	return reportID(u.LinkedAccountId, u.ProductName, az, year, month, day)
+}
+
+func (u DetailedUsage) Unit() string {
+	return Usage{UsageType: u.UsageType, ItemDescription: u.ItemDescription}.Unit()
+}
+
+func (u DetailedUsage) StartDate() (time.Time, error) {
+	return time.Parse(detailedUsageTimeFormat, u.UsageStartDate)
+}
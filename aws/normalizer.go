@@ -5,6 +5,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/units"
 )
 
 type Normalizer struct {
@@ -21,7 +22,10 @@ func NewNormalizer(log *logrus.Logger, location *time.Location, az string) *Norm
 	}
 }
 
-func (n *Normalizer) Normalize(usageReports []*Usage) datamodels.Reports {
+// Normalize converts rows of the monthly billing file, which are cumulative
+// from the start of the month, into reports for the day the file was fetched
+// for.
+func (n *Normalizer) Normalize(usageReports []*Usage, year int, month time.Month, day int) datamodels.Reports {
 	n.log.Debug("Entering aws.Normalize")
 	defer n.log.Debug("Returnign aws.Normalize")
 
@@ -30,20 +34,67 @@ func (n *Normalizer) Normalize(usageReports []*Usage) datamodels.Reports {
 		if isNotLineItem(usage) {
 			continue
 		}
-		t := time.Now().In(n.location)
+		unit := usage.Unit()
+		normalizedQuantity, normalizedUnit := units.Normalize(usage.UsageQuantity, unit)
 		reports = append(reports, datamodels.Report{
-			ID:            usage.Hash(n.az),
+			ID:            usage.Hash(n.az, year, month, day),
 			AccountNumber: usage.LinkedAccountId,
 			AccountName:   usage.LinkedAccountName,
-			Day:           t.Day() - 1,
-			Month:         t.Month(),
-			Year:          t.Year(),
+			Day:           day,
+			Month:         month,
+			Year:          year,
 			ServiceType:   usage.ProductName,
 			UsageQuantity: usage.UsageQuantity,
 			Cost:          usage.TotalCost,
 			Region:        n.az,
-			UnitOfMeasure: "",
+			UnitOfMeasure: unit,
 			Resource:      IAAS,
+
+			NormalizedUsageQuantity: normalizedQuantity,
+			NormalizedUnitOfMeasure: normalizedUnit,
+			Currency:                usage.CurrencyCode,
+		})
+	}
+	return reports
+}
+
+// NormalizeDetailed converts rows of the detailed billing report into
+// resource-level reports for the given day.
+func (n *Normalizer) NormalizeDetailed(usageReports []*DetailedUsage, year int, month time.Month, day int) datamodels.Reports {
+	n.log.Debug("Entering aws.NormalizeDetailed")
+	defer n.log.Debug("Returning aws.NormalizeDetailed")
+
+	var reports datamodels.Reports
+	for _, usage := range usageReports {
+		if usage.RecordType != "LineItem" {
+			continue
+		}
+		start, err := usage.StartDate()
+		if err != nil {
+			n.log.Warnf("Skipping AWS line item %s with unparseable start date: %s", usage.RecordId, err.Error())
+			continue
+		}
+		if start.Year() != year || start.Month() != month || start.Day() != day {
+			continue
+		}
+		unit := usage.Unit()
+		normalizedQuantity, normalizedUnit := units.Normalize(usage.UsageQuantity, unit)
+		reports = append(reports, datamodels.Report{
+			ID:            usage.Hash(n.az, year, month, day),
+			AccountNumber: usage.LinkedAccountId,
+			Day:           day,
+			Month:         month,
+			Year:          year,
+			ServiceType:   usage.ProductName,
+			UsageQuantity: usage.UsageQuantity,
+			Cost:          usage.BlendedCost,
+			Region:        n.az,
+			UnitOfMeasure: unit,
+			Resource:      IAAS,
+
+			NormalizedUsageQuantity: normalizedQuantity,
+			NormalizedUnitOfMeasure: normalizedUnit,
+			ResourceID:              usage.ResourceId,
 		})
 	}
 	return reports
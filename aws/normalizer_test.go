@@ -69,7 +69,8 @@ var _ = Describe("Normalizer", func() {
 		})
 
 		JustBeforeEach(func() {
-			reports = normalizer.Normalize(usageReports)
+			yesterday := time.Now().AddDate(0, 0, -1)
+			reports = normalizer.Normalize(usageReports, yesterday.Year(), yesterday.Month(), yesterday.Day())
 		})
 
 		Context("with at least one report", func() {
@@ -79,21 +80,40 @@ var _ = Describe("Normalizer", func() {
 				})
 
 				It("returns properly converted reports", func() {
+					yesterday := time.Now().AddDate(0, 0, -1)
 					Expect(reports[0]).To(Equal(datamodels.Report{
-						ID:            usageReports[0].Hash("my-region"),
+						ID:            usageReports[0].Hash("my-region", yesterday.Year(), yesterday.Month(), yesterday.Day()),
 						AccountNumber: "some-linked-account-id",
 						AccountName:   "some-linked-account-name",
-						Day:           time.Now().Day() - 1,
-						Month:         time.Now().Month(),
-						Year:          time.Now().Year(),
+						Day:           yesterday.Day(),
+						Month:         yesterday.Month(),
+						Year:          yesterday.Year(),
 						ServiceType:   "some-product-name",
 						UsageQuantity: 0.51,
 						Cost:          1.20,
 						Region:        "my-region",
 						UnitOfMeasure: "",
 						Resource:      "AWS",
+
+						NormalizedUsageQuantity: 0.51,
+						NormalizedUnitOfMeasure: "",
+						Currency:                "some-currency-code",
 					}))
 				})
+
+				Context("when the unit can be derived from the line item", func() {
+					BeforeEach(func() {
+						usageReports[0].UsageType = "USW2-TimedStorage-ByteHrs"
+						usageReports[0].ItemDescription = "$0.023 per GB-Month of storage used"
+					})
+
+					It("records the raw and normalized units", func() {
+						Expect(reports[0].UnitOfMeasure).To(Equal("GB-Mo"))
+						Expect(reports[0].UsageQuantity).To(Equal(0.51))
+						Expect(reports[0].NormalizedUnitOfMeasure).To(Equal("GiB-Months"))
+						Expect(reports[0].NormalizedUsageQuantity).To(Equal(0.51))
+					})
+				})
 			})
 
 			Context("with rows that are not line items", func() {
@@ -142,7 +162,7 @@ var _ = Describe("Normalizer", func() {
 
 		Context("with no reports", func() {
 			It("returns empty", func() {
-				reports := normalizer.Normalize(nil)
+				reports := normalizer.Normalize(nil, 2016, time.September, 12)
 
 				Expect(reports).To(HaveLen(0))
 			})
@@ -193,7 +213,7 @@ var _ = Describe("Normalizer", func() {
 
 		Context("with no reports", func() {
 			It("returns empty", func() {
-				reports := normalizer.Normalize(nil)
+				reports := normalizer.Normalize(nil, 2016, time.September, 12)
 
 				Expect(reports).To(HaveLen(0))
 			})
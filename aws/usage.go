@@ -1,8 +1,10 @@
 package aws
 
 import (
+	"fmt"
 	"hash/fnv"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -38,9 +40,43 @@ type Usage struct {
 	TotalCost              float64 `csv:"TotalCost"`
 }
 
-func (u Usage) Hash(az string) string {
-	yr, mn, dy := time.Now().Date()
+// Hash identifies the usage of a linked account and product on the given day.
+func (u Usage) Hash(az string, year int, month time.Month, day int) string {
+	return reportID(u.LinkedAccountId, u.ProductName, az, year, month, day)
+}
+
+// reportID is keyed on the day the cost was incurred. The "daily" suffix keeps
+// it from colliding with IDs stored before daily costs were derived from
+// snapshots, which were keyed on the day of the run instead.
+func reportID(accountID, productName, az string, year int, month time.Month, day int) string {
 	h := fnv.New64a()
-	h.Write([]byte(u.LinkedAccountId + u.ProductName + az + IAAS))
-	return strconv.FormatUint(uint64(h.Sum64()), 10) + strconv.Itoa(yr) + strconv.Itoa(int(mn)) + strconv.Itoa(dy)
+	h.Write([]byte(accountID + productName + az + IAAS + "daily"))
+	return strconv.FormatUint(uint64(h.Sum64()), 10) + fmt.Sprintf("%04d%02d%02d", year, int(month), day)
+}
+
+// Unit returns the pricing unit of the line item. The billing file does not
+// carry it explicitly so it is read from the item description, falling back
+// to the usage type.
+func (u Usage) Unit() string {
+	description := strings.ToLower(u.ItemDescription)
+	usageType := strings.ToLower(u.UsageType)
+	switch {
+	case strings.Contains(description, "gb-mo"),
+		strings.Contains(usageType, "bytehrs"),
+		strings.Contains(usageType, "volumeusage"),
+		strings.Contains(usageType, "snapshotusage"):
+		return "GB-Mo"
+	case strings.Contains(description, "request"),
+		strings.Contains(usageType, "request"):
+		return "Requests"
+	case strings.Contains(description, "per gb"),
+		strings.Contains(usageType, "bytes"):
+		return "GB"
+	case strings.Contains(description, "hour"),
+		strings.Contains(usageType, "boxusage"),
+		strings.Contains(usageType, "hours"),
+		strings.Contains(usageType, "hrs"):
+		return "Hrs"
+	}
+	return ""
 }
@@ -1,6 +1,8 @@
 package aws_test
 
 import (
+	"time"
+
 	. "github.com/challiwill/meteorologica/aws"
 
 	. "github.com/onsi/ginkgo"
@@ -80,15 +82,50 @@ var _ = Describe("Usage", func() {
 		})
 
 		It("does not return empty string", func() {
-			Expect(usage.Hash("some-region")).NotTo(BeEmpty())
+			Expect(usage.Hash("some-region", 2016, time.September, 12)).NotTo(BeEmpty())
 		})
 
 		It("returns different hash for different structs", func() {
-			Expect(usage.Hash("some-region")).NotTo(Equal(otherUsage.Hash("some-region")))
+			Expect(usage.Hash("some-region", 2016, time.September, 12)).NotTo(Equal(otherUsage.Hash("some-region", 2016, time.September, 12)))
+		})
+
+		It("returns different hashes for different days", func() {
+			Expect(usage.Hash("some-region", 2016, time.September, 12)).NotTo(Equal(usage.Hash("some-region", 2016, time.September, 13)))
+		})
+
+		It("returns different hashes for days whose numbers run together", func() {
+			Expect(usage.Hash("some-region", 2016, time.January, 11)).NotTo(Equal(usage.Hash("some-region", 2016, time.November, 1)))
 		})
 
 		It("returns the same hash each time", func() {
-			Expect(usage.Hash("some-region")).To(Equal(usage.Hash("some-region")))
+			Expect(usage.Hash("some-region", 2016, time.September, 12)).To(Equal(usage.Hash("some-region", 2016, time.September, 12)))
+		})
+	})
+
+	Describe("Unit", func() {
+		It("reads storage units from the item description", func() {
+			usage := Usage{UsageType: "TimedStorage-ByteHrs", ItemDescription: "$0.03 per GB-Month of storage used"}
+			Expect(usage.Unit()).To(Equal("GB-Mo"))
+		})
+
+		It("reads instance hours from the usage type", func() {
+			usage := Usage{UsageType: "USW2-BoxUsage:m4.large"}
+			Expect(usage.Unit()).To(Equal("Hrs"))
+		})
+
+		It("reads requests from the usage type", func() {
+			usage := Usage{UsageType: "Requests-Tier1", ItemDescription: "$0.005 per 1,000 PUT, COPY, POST, or LIST requests"}
+			Expect(usage.Unit()).To(Equal("Requests"))
+		})
+
+		It("reads data transfer from the usage type", func() {
+			usage := Usage{UsageType: "DataTransfer-Out-Bytes", ItemDescription: "$0.090 per GB - first 10 TB / month data transfer out"}
+			Expect(usage.Unit()).To(Equal("GB"))
+		})
+
+		It("returns empty when the unit is unknown", func() {
+			usage := Usage{UsageType: "some-type", ItemDescription: "some-description"}
+			Expect(usage.Unit()).To(BeEmpty())
 		})
 	})
 })
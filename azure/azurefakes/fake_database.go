@@ -0,0 +1,211 @@
+// This file was generated by counterfeiter
+package azurefakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/azure"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type FakeDatabase struct {
+	SaveAzurePriceSheetStub        func([]datamodels.AzurePrice) error
+	saveAzurePriceSheetMutex       sync.RWMutex
+	saveAzurePriceSheetArgsForCall []struct {
+		arg1 []datamodels.AzurePrice
+	}
+	saveAzurePriceSheetReturns struct {
+		result1 error
+	}
+	SaveAzureBalancesStub        func([]datamodels.AzureBalance) error
+	saveAzureBalancesMutex       sync.RWMutex
+	saveAzureBalancesArgsForCall []struct {
+		arg1 []datamodels.AzureBalance
+	}
+	saveAzureBalancesReturns struct {
+		result1 error
+	}
+	SaveAzureCommitmentProjectionStub        func(datamodels.AzureCommitmentProjection) error
+	saveAzureCommitmentProjectionMutex       sync.RWMutex
+	saveAzureCommitmentProjectionArgsForCall []struct {
+		arg1 datamodels.AzureCommitmentProjection
+	}
+	saveAzureCommitmentProjectionReturns struct {
+		result1 error
+	}
+	GetDailyCostsStub        func(time.Time, time.Time) ([]datamodels.DailyCost, error)
+	getDailyCostsMutex       sync.RWMutex
+	getDailyCostsArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getDailyCostsReturns struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) SaveAzurePriceSheet(arg1 []datamodels.AzurePrice) error {
+	fake.saveAzurePriceSheetMutex.Lock()
+	fake.saveAzurePriceSheetArgsForCall = append(fake.saveAzurePriceSheetArgsForCall, struct {
+		arg1 []datamodels.AzurePrice
+	}{arg1})
+	fake.recordInvocation("SaveAzurePriceSheet", []interface{}{arg1})
+	fake.saveAzurePriceSheetMutex.Unlock()
+	if fake.SaveAzurePriceSheetStub != nil {
+		return fake.SaveAzurePriceSheetStub(arg1)
+	} else {
+		return fake.saveAzurePriceSheetReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveAzurePriceSheetCallCount() int {
+	fake.saveAzurePriceSheetMutex.RLock()
+	defer fake.saveAzurePriceSheetMutex.RUnlock()
+	return len(fake.saveAzurePriceSheetArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveAzurePriceSheetArgsForCall(i int) []datamodels.AzurePrice {
+	fake.saveAzurePriceSheetMutex.RLock()
+	defer fake.saveAzurePriceSheetMutex.RUnlock()
+	return fake.saveAzurePriceSheetArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveAzurePriceSheetReturns(result1 error) {
+	fake.SaveAzurePriceSheetStub = nil
+	fake.saveAzurePriceSheetReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) SaveAzureBalances(arg1 []datamodels.AzureBalance) error {
+	fake.saveAzureBalancesMutex.Lock()
+	fake.saveAzureBalancesArgsForCall = append(fake.saveAzureBalancesArgsForCall, struct {
+		arg1 []datamodels.AzureBalance
+	}{arg1})
+	fake.recordInvocation("SaveAzureBalances", []interface{}{arg1})
+	fake.saveAzureBalancesMutex.Unlock()
+	if fake.SaveAzureBalancesStub != nil {
+		return fake.SaveAzureBalancesStub(arg1)
+	} else {
+		return fake.saveAzureBalancesReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveAzureBalancesCallCount() int {
+	fake.saveAzureBalancesMutex.RLock()
+	defer fake.saveAzureBalancesMutex.RUnlock()
+	return len(fake.saveAzureBalancesArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveAzureBalancesArgsForCall(i int) []datamodels.AzureBalance {
+	fake.saveAzureBalancesMutex.RLock()
+	defer fake.saveAzureBalancesMutex.RUnlock()
+	return fake.saveAzureBalancesArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveAzureBalancesReturns(result1 error) {
+	fake.SaveAzureBalancesStub = nil
+	fake.saveAzureBalancesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) SaveAzureCommitmentProjection(arg1 datamodels.AzureCommitmentProjection) error {
+	fake.saveAzureCommitmentProjectionMutex.Lock()
+	fake.saveAzureCommitmentProjectionArgsForCall = append(fake.saveAzureCommitmentProjectionArgsForCall, struct {
+		arg1 datamodels.AzureCommitmentProjection
+	}{arg1})
+	fake.recordInvocation("SaveAzureCommitmentProjection", []interface{}{arg1})
+	fake.saveAzureCommitmentProjectionMutex.Unlock()
+	if fake.SaveAzureCommitmentProjectionStub != nil {
+		return fake.SaveAzureCommitmentProjectionStub(arg1)
+	} else {
+		return fake.saveAzureCommitmentProjectionReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveAzureCommitmentProjectionCallCount() int {
+	fake.saveAzureCommitmentProjectionMutex.RLock()
+	defer fake.saveAzureCommitmentProjectionMutex.RUnlock()
+	return len(fake.saveAzureCommitmentProjectionArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveAzureCommitmentProjectionArgsForCall(i int) datamodels.AzureCommitmentProjection {
+	fake.saveAzureCommitmentProjectionMutex.RLock()
+	defer fake.saveAzureCommitmentProjectionMutex.RUnlock()
+	return fake.saveAzureCommitmentProjectionArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveAzureCommitmentProjectionReturns(result1 error) {
+	fake.SaveAzureCommitmentProjectionStub = nil
+	fake.saveAzureCommitmentProjectionReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) GetDailyCosts(arg1 time.Time, arg2 time.Time) ([]datamodels.DailyCost, error) {
+	fake.getDailyCostsMutex.Lock()
+	fake.getDailyCostsArgsForCall = append(fake.getDailyCostsArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetDailyCosts", []interface{}{arg1, arg2})
+	fake.getDailyCostsMutex.Unlock()
+	if fake.GetDailyCostsStub != nil {
+		return fake.GetDailyCostsStub(arg1, arg2)
+	} else {
+		return fake.getDailyCostsReturns.result1, fake.getDailyCostsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetDailyCostsCallCount() int {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return len(fake.getDailyCostsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetDailyCostsArgsForCall(i int) (time.Time, time.Time) {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return fake.getDailyCostsArgsForCall[i].arg1, fake.getDailyCostsArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) GetDailyCostsReturns(result1 []datamodels.DailyCost, result2 error) {
+	fake.GetDailyCostsStub = nil
+	fake.getDailyCostsReturns = struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.saveAzurePriceSheetMutex.RLock()
+	defer fake.saveAzurePriceSheetMutex.RUnlock()
+	fake.saveAzureBalancesMutex.RLock()
+	defer fake.saveAzureBalancesMutex.RUnlock()
+	fake.saveAzureCommitmentProjectionMutex.RLock()
+	defer fake.saveAzureCommitmentProjectionMutex.RUnlock()
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ azure.Database = new(FakeDatabase)
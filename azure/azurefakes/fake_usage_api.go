@@ -0,0 +1,221 @@
+// This file was generated by counterfeiter
+package azurefakes
+
+import (
+	"github.com/challiwill/meteorologica/azure"
+	"sync"
+	"time"
+)
+
+type FakeUsageAPI struct {
+	GetUsageStub        func(int, time.Month) ([]*azure.Usage, error)
+	getUsageMutex       sync.RWMutex
+	getUsageArgsForCall []struct {
+		arg1 int
+		arg2 time.Month
+	}
+	getUsageReturns struct {
+		result1 []*azure.Usage
+		result2 error
+	}
+	GetMarketplaceChargesStub        func(int, time.Month) ([]*azure.MarketplaceCharge, error)
+	getMarketplaceChargesMutex       sync.RWMutex
+	getMarketplaceChargesArgsForCall []struct {
+		arg1 int
+		arg2 time.Month
+	}
+	getMarketplaceChargesReturns struct {
+		result1 []*azure.MarketplaceCharge
+		result2 error
+	}
+	GetPriceSheetStub        func(int, time.Month) ([]*azure.Price, error)
+	getPriceSheetMutex       sync.RWMutex
+	getPriceSheetArgsForCall []struct {
+		arg1 int
+		arg2 time.Month
+	}
+	getPriceSheetReturns struct {
+		result1 []*azure.Price
+		result2 error
+	}
+	GetBalanceStub        func(int, time.Month) (*azure.Balance, error)
+	getBalanceMutex       sync.RWMutex
+	getBalanceArgsForCall []struct {
+		arg1 int
+		arg2 time.Month
+	}
+	getBalanceReturns struct {
+		result1 *azure.Balance
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeUsageAPI) GetUsage(arg1 int, arg2 time.Month) ([]*azure.Usage, error) {
+	fake.getUsageMutex.Lock()
+	fake.getUsageArgsForCall = append(fake.getUsageArgsForCall, struct {
+		arg1 int
+		arg2 time.Month
+	}{arg1, arg2})
+	fake.recordInvocation("GetUsage", []interface{}{arg1, arg2})
+	fake.getUsageMutex.Unlock()
+	if fake.GetUsageStub != nil {
+		return fake.GetUsageStub(arg1, arg2)
+	} else {
+		return fake.getUsageReturns.result1, fake.getUsageReturns.result2
+	}
+}
+
+func (fake *FakeUsageAPI) GetUsageCallCount() int {
+	fake.getUsageMutex.RLock()
+	defer fake.getUsageMutex.RUnlock()
+	return len(fake.getUsageArgsForCall)
+}
+
+func (fake *FakeUsageAPI) GetUsageArgsForCall(i int) (int, time.Month) {
+	fake.getUsageMutex.RLock()
+	defer fake.getUsageMutex.RUnlock()
+	return fake.getUsageArgsForCall[i].arg1, fake.getUsageArgsForCall[i].arg2
+}
+
+func (fake *FakeUsageAPI) GetUsageReturns(result1 []*azure.Usage, result2 error) {
+	fake.GetUsageStub = nil
+	fake.getUsageReturns = struct {
+		result1 []*azure.Usage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUsageAPI) GetMarketplaceCharges(arg1 int, arg2 time.Month) ([]*azure.MarketplaceCharge, error) {
+	fake.getMarketplaceChargesMutex.Lock()
+	fake.getMarketplaceChargesArgsForCall = append(fake.getMarketplaceChargesArgsForCall, struct {
+		arg1 int
+		arg2 time.Month
+	}{arg1, arg2})
+	fake.recordInvocation("GetMarketplaceCharges", []interface{}{arg1, arg2})
+	fake.getMarketplaceChargesMutex.Unlock()
+	if fake.GetMarketplaceChargesStub != nil {
+		return fake.GetMarketplaceChargesStub(arg1, arg2)
+	} else {
+		return fake.getMarketplaceChargesReturns.result1, fake.getMarketplaceChargesReturns.result2
+	}
+}
+
+func (fake *FakeUsageAPI) GetMarketplaceChargesCallCount() int {
+	fake.getMarketplaceChargesMutex.RLock()
+	defer fake.getMarketplaceChargesMutex.RUnlock()
+	return len(fake.getMarketplaceChargesArgsForCall)
+}
+
+func (fake *FakeUsageAPI) GetMarketplaceChargesArgsForCall(i int) (int, time.Month) {
+	fake.getMarketplaceChargesMutex.RLock()
+	defer fake.getMarketplaceChargesMutex.RUnlock()
+	return fake.getMarketplaceChargesArgsForCall[i].arg1, fake.getMarketplaceChargesArgsForCall[i].arg2
+}
+
+func (fake *FakeUsageAPI) GetMarketplaceChargesReturns(result1 []*azure.MarketplaceCharge, result2 error) {
+	fake.GetMarketplaceChargesStub = nil
+	fake.getMarketplaceChargesReturns = struct {
+		result1 []*azure.MarketplaceCharge
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUsageAPI) GetPriceSheet(arg1 int, arg2 time.Month) ([]*azure.Price, error) {
+	fake.getPriceSheetMutex.Lock()
+	fake.getPriceSheetArgsForCall = append(fake.getPriceSheetArgsForCall, struct {
+		arg1 int
+		arg2 time.Month
+	}{arg1, arg2})
+	fake.recordInvocation("GetPriceSheet", []interface{}{arg1, arg2})
+	fake.getPriceSheetMutex.Unlock()
+	if fake.GetPriceSheetStub != nil {
+		return fake.GetPriceSheetStub(arg1, arg2)
+	} else {
+		return fake.getPriceSheetReturns.result1, fake.getPriceSheetReturns.result2
+	}
+}
+
+func (fake *FakeUsageAPI) GetPriceSheetCallCount() int {
+	fake.getPriceSheetMutex.RLock()
+	defer fake.getPriceSheetMutex.RUnlock()
+	return len(fake.getPriceSheetArgsForCall)
+}
+
+func (fake *FakeUsageAPI) GetPriceSheetArgsForCall(i int) (int, time.Month) {
+	fake.getPriceSheetMutex.RLock()
+	defer fake.getPriceSheetMutex.RUnlock()
+	return fake.getPriceSheetArgsForCall[i].arg1, fake.getPriceSheetArgsForCall[i].arg2
+}
+
+func (fake *FakeUsageAPI) GetPriceSheetReturns(result1 []*azure.Price, result2 error) {
+	fake.GetPriceSheetStub = nil
+	fake.getPriceSheetReturns = struct {
+		result1 []*azure.Price
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUsageAPI) GetBalance(arg1 int, arg2 time.Month) (*azure.Balance, error) {
+	fake.getBalanceMutex.Lock()
+	fake.getBalanceArgsForCall = append(fake.getBalanceArgsForCall, struct {
+		arg1 int
+		arg2 time.Month
+	}{arg1, arg2})
+	fake.recordInvocation("GetBalance", []interface{}{arg1, arg2})
+	fake.getBalanceMutex.Unlock()
+	if fake.GetBalanceStub != nil {
+		return fake.GetBalanceStub(arg1, arg2)
+	} else {
+		return fake.getBalanceReturns.result1, fake.getBalanceReturns.result2
+	}
+}
+
+func (fake *FakeUsageAPI) GetBalanceCallCount() int {
+	fake.getBalanceMutex.RLock()
+	defer fake.getBalanceMutex.RUnlock()
+	return len(fake.getBalanceArgsForCall)
+}
+
+func (fake *FakeUsageAPI) GetBalanceArgsForCall(i int) (int, time.Month) {
+	fake.getBalanceMutex.RLock()
+	defer fake.getBalanceMutex.RUnlock()
+	return fake.getBalanceArgsForCall[i].arg1, fake.getBalanceArgsForCall[i].arg2
+}
+
+func (fake *FakeUsageAPI) GetBalanceReturns(result1 *azure.Balance, result2 error) {
+	fake.GetBalanceStub = nil
+	fake.getBalanceReturns = struct {
+		result1 *azure.Balance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUsageAPI) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getUsageMutex.RLock()
+	defer fake.getUsageMutex.RUnlock()
+	fake.getMarketplaceChargesMutex.RLock()
+	defer fake.getMarketplaceChargesMutex.RUnlock()
+	fake.getPriceSheetMutex.RLock()
+	defer fake.getPriceSheetMutex.RUnlock()
+	fake.getBalanceMutex.RLock()
+	defer fake.getBalanceMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeUsageAPI) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ azure.UsageAPI = new(FakeUsageAPI)
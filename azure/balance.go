@@ -0,0 +1,17 @@
+package azure
+
+// Balance is the balance summary of a billing period of an enrollment: how its
+// monetary commitment was drawn down and what was charged on top of it.
+type Balance struct {
+	Currency                string  `json:"currency"`
+	BeginningBalance        float64 `json:"beginningBalance"`
+	NewPurchases            float64 `json:"newPurchases"`
+	Adjustments             float64 `json:"adjustments"`
+	Utilized                float64 `json:"utilized"`
+	ServiceOverage          float64 `json:"serviceOverage"`
+	ChargesBilledSeparately float64 `json:"chargesBilledSeparately"`
+	TotalOverage            float64 `json:"totalOverage"`
+	TotalUsage              float64 `json:"totalUsage"`
+	MarketplaceCharges      float64 `json:"azureMarketplaceServiceCharges"`
+	EndingBalance           float64 `json:"endingBalance"`
+}
@@ -1,6 +1,7 @@
 package azure
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -19,23 +20,43 @@ import (
 
 var IAAS = "Azure"
 
+// ReportingURL is where the balance summary of the Enterprise Agreement API
+// is read from.
+const ReportingURL = "https://consumption.azure.com"
+
+//go:generate counterfeiter . UsageAPI
+
+// UsageAPI returns the usage, marketplace charges, price sheet and balance of
+// a month from an Azure API other than the Enterprise Agreement API.
+type UsageAPI interface {
+	GetUsage(year int, month time.Month) ([]*Usage, error)
+	GetMarketplaceCharges(year int, month time.Month) ([]*MarketplaceCharge, error)
+	GetPriceSheet(year int, month time.Month) ([]*Price, error)
+	GetBalance(year int, month time.Month) (*Balance, error)
+}
+
 type Client struct {
-	URL        string
-	client     *http.Client
-	accessKey  string
-	enrollment int
-	log        *logrus.Logger
-	location   *time.Location
+	URL               string
+	ReportingURL      string
+	API               UsageAPI
+	client            *http.Client
+	accessKey         string
+	enrollment        int
+	restatementWindow int
+	log               *logrus.Logger
+	location          *time.Location
 }
 
-func NewClient(log *logrus.Logger, location *time.Location, serverURL, key string, enrollment int) *Client {
+func NewClient(log *logrus.Logger, location *time.Location, serverURL, key string, enrollment int, restatementWindow int) *Client {
 	return &Client{
-		URL:        serverURL,
-		client:     new(http.Client),
-		accessKey:  key,
-		enrollment: enrollment,
-		log:        log,
-		location:   location,
+		URL:               serverURL,
+		ReportingURL:      ReportingURL,
+		client:            new(http.Client),
+		accessKey:         key,
+		enrollment:        enrollment,
+		restatementWindow: restatementWindow,
+		log:               log,
+		location:          location,
 	}
 }
 
@@ -48,35 +69,249 @@ func (c Client) GetNormalizedUsage() (datamodels.Reports, error) {
 	c.log.Debug("Entering azure.GetNormalizedUsage")
 	defer c.log.Debug("Returning azure.GetNormalizedUsage")
 
-	azureMonthlyUsage, err := c.GetBillingData()
+	normalizedReports, err := c.getNormalizedReports()
 	if err != nil {
-		c.log.Error("Failed to get Azure monthly usage")
 		return datamodels.Reports{}, err
 	}
-	c.log.Debug("Got monthly Azure usage")
+	return datamodels.ConsolidateReports(normalizedReports), nil
+}
 
-	readerCleaner, err := csv.NewReaderCleaner(bytes.NewReader(azureMonthlyUsage), 31)
+func (c Client) GetResourceLevelUsage() (datamodels.Reports, error) {
+	c.log.Info("Getting monthly Azure usage by resource...")
+	c.log.Debug("Entering azure.GetResourceLevelUsage")
+	defer c.log.Debug("Returning azure.GetResourceLevelUsage")
+
+	normalizedReports, err := c.getNormalizedReports()
 	if err != nil {
-		return datamodels.Reports{}, csv.NewReadCleanError(IAAS, err)
+		return datamodels.Reports{}, err
 	}
-	reports := []*Usage{}
-	err = csv.GenerateReports(readerCleaner, &reports)
-	if err != nil {
-		return datamodels.Reports{}, csv.NewReportParseError(IAAS, err)
+	return datamodels.ConsolidateResourceReports(normalizedReports), nil
+}
+
+// getNormalizedReports returns the usage of each day in the restatement
+// window, fetching the usage report of every month the window spans.
+func (c Client) getNormalizedReports() (datamodels.Reports, error) {
+	window := calendar.RestatementWindow(c.location, c.restatementWindow)
+	inWindow := make(map[string]bool)
+	for _, date := range window {
+		inWindow[date.Format("2006-01-02")] = true
 	}
 
 	normalizer := NewNormalizer(c.log, c.location)
-	normalizedReports := normalizer.Normalize(reports)
-	normalizedReports = datamodels.ConsolidateReports(normalizedReports)
+	normalizedReports := datamodels.Reports{}
+	for _, date := range calendar.LastDayOfEachMonth(window) {
+		reports, err := c.getUsage(date.Year(), date.Month())
+		if err != nil {
+			c.log.Error("Failed to get Azure monthly usage")
+			return datamodels.Reports{}, err
+		}
+		c.log.Debugf("Got Azure usage for %s", date.Format("2006-01"))
+
+		for _, report := range normalizer.Normalize(reports) {
+			if inWindow[fmt.Sprintf("%d-%s-%02d", report.Year, calendar.PadMonth(report.Month), report.Day)] {
+				normalizedReports = append(normalizedReports, report)
+			}
+		}
+	}
 	return normalizedReports, nil
 }
 
-func (c Client) GetBillingData() ([]byte, error) {
+// GetInvoice returns what the balance summary of the month's billing period
+// states was charged, along with the month's usage report by day. The total
+// usage already includes the overage and with it the charges billed
+// separately; the marketplace charges are added as their rows are saved too.
+// Rows dated outside the month are dropped from it when saving.
+func (c Client) GetInvoice(year int, month time.Month) (datamodels.Invoice, error) {
+	c.log.Debug("Entering azure.GetInvoice")
+	defer c.log.Debug("Returning azure.GetInvoice")
+
+	invoice := datamodels.Invoice{Resource: IAAS, Year: year, Month: month, DailyCosts: make(map[int]float64)}
+	balance, err := c.getBalance(year, month)
+	if err != nil {
+		return invoice, err
+	}
+	invoice.Total = balance.TotalUsage + balance.MarketplaceCharges
+
+	reports, err := c.getUsage(year, month)
+	if err != nil {
+		return invoice, err
+	}
+	for _, usage := range reports {
+		if usage.Year != year || time.Month(usage.Month) != month {
+			invoice.Dropped += usage.ExtendedCost
+			continue
+		}
+		invoice.DailyCosts[usage.Day] += usage.ExtendedCost
+	}
+	return invoice, nil
+}
+
+// getUsage returns the month's usage followed by its marketplace charges, from
+// the API when one is set, otherwise from the reports. The marketplace charges
+// are left out, with a warning, when they cannot be read.
+func (c Client) getUsage(year int, month time.Month) ([]*Usage, error) {
+	var (
+		usages  []*Usage
+		charges []*MarketplaceCharge
+		err     error
+	)
+	if c.API != nil {
+		usages, err = c.API.GetUsage(year, month)
+		if err != nil {
+			return nil, err
+		}
+		charges, err = c.API.GetMarketplaceCharges(year, month)
+	} else {
+		err = c.getReport(year, month, "detail", 31, &usages)
+		if err != nil {
+			return nil, err
+		}
+		err = c.getReport(year, month, "storecharge", 24, &charges)
+	}
+	if err != nil {
+		c.log.Warnf("Failed to get Azure marketplace charges for %d-%s, leaving them out: %s", year, calendar.PadMonth(month), err.Error())
+		charges = nil
+	}
+	for _, charge := range charges {
+		usages = append(usages, charge.Usage())
+	}
+	return usages, nil
+}
+
+// GetPriceSheet returns the prices of the month's billing period.
+func (c Client) GetPriceSheet(year int, month time.Month) ([]datamodels.AzurePrice, error) {
+	c.log.Debug("Entering azure.GetPriceSheet")
+	defer c.log.Debug("Returning azure.GetPriceSheet")
+
+	var (
+		prices []*Price
+		err    error
+	)
+	if c.API != nil {
+		prices, err = c.API.GetPriceSheet(year, month)
+	} else {
+		err = c.getReport(year, month, "pricesheet", 5, &prices)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	priceSheet := []datamodels.AzurePrice{}
+	for _, p := range prices {
+		priceSheet = append(priceSheet, datamodels.AzurePrice{
+			Enrollment:       strconv.Itoa(c.enrollment),
+			Year:             year,
+			Month:            month,
+			MeterID:          p.MeterID,
+			MeterName:        p.MeterName,
+			MeterCategory:    p.MeterCategory,
+			MeterSubCategory: p.MeterSubCategory,
+			PartNumber:       p.PartNumber,
+			UnitOfMeasure:    p.UnitOfMeasure,
+			IncludedQuantity: p.IncludedQuantity,
+			UnitPrice:        p.UnitPrice,
+			Currency:         p.Currency,
+		})
+	}
+	return priceSheet, nil
+}
+
+// GetBalance returns the balance summary of the month's billing period.
+func (c Client) GetBalance(year int, month time.Month) (datamodels.AzureBalance, error) {
+	c.log.Debug("Entering azure.GetBalance")
+	defer c.log.Debug("Returning azure.GetBalance")
+
+	b, err := c.getBalance(year, month)
+	if err != nil {
+		return datamodels.AzureBalance{}, err
+	}
+	return datamodels.AzureBalance{
+		Enrollment:              strconv.Itoa(c.enrollment),
+		Year:                    year,
+		Month:                   month,
+		Currency:                b.Currency,
+		BeginningBalance:        b.BeginningBalance,
+		NewPurchases:            b.NewPurchases,
+		Adjustments:             b.Adjustments,
+		Utilized:                b.Utilized,
+		ServiceOverage:          b.ServiceOverage,
+		ChargesBilledSeparately: b.ChargesBilledSeparately,
+		TotalOverage:            b.TotalOverage,
+		TotalUsage:              b.TotalUsage,
+		MarketplaceCharges:      b.MarketplaceCharges,
+		EndingBalance:           b.EndingBalance,
+	}, nil
+}
+
+// getBalance returns the balance summary from the API when one is set,
+// otherwise from the reporting API.
+func (c Client) getBalance(year int, month time.Month) (*Balance, error) {
+	if c.API != nil {
+		return c.API.GetBalance(year, month)
+	}
+	return c.getBalanceSummary(year, month)
+}
+
+// getBalanceSummary reads the month's balance summary from the Enterprise
+// Agreement reporting API, which names the currency its currency code.
+func (c Client) getBalanceSummary(year int, month time.Month) (*Balance, error) {
+	reqString := strings.Join([]string{strings.TrimRight(c.ReportingURL, "/"), "v3", "enrollments", strconv.Itoa(c.enrollment), "billingPeriods", strconv.Itoa(year) + calendar.PadMonth(month), "balancesummary"}, "/")
+	c.log.Debug("Making Azure balance summary request to address: ", reqString)
+
+	req, err := http.NewRequest("GET", reqString, nil)
+	if err != nil {
+		return nil, errare.NewCreationError("Azure request", err.Error())
+	}
+	req.Header.Add("authorization", "bearer "+c.accessKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errare.NewRequestError(err, IAAS)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errare.NewResponseError(resp.Status, IAAS)
+	}
+
+	var summary struct {
+		Balance
+		CurrencyCode string `json:"currencyCode"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&summary)
+	if err != nil {
+		return nil, err
+	}
+	summary.Balance.Currency = summary.CurrencyCode
+	return &summary.Balance, nil
+}
+
+// getReport parses the month's report of the type, whose rows have rowLen
+// columns, into rows.
+func (c Client) getReport(year int, month time.Month, reportType string, rowLen int, rows interface{}) error {
+	data, err := c.getReportData(year, month, reportType)
+	if err != nil {
+		return err
+	}
+	readerCleaner, err := csv.NewReaderCleaner(bytes.NewReader(data), rowLen)
+	if err != nil {
+		return csv.NewReadCleanError(IAAS, err)
+	}
+	err = csv.GenerateReports(readerCleaner, rows)
+	if err != nil {
+		return csv.NewReportParseError(IAAS, err)
+	}
+	return nil
+}
+
+func (c Client) GetBillingData(year int, month time.Month) ([]byte, error) {
 	c.log.Debug("Entering azure.GetBillingData")
 	defer c.log.Debug("Returning azure.GetBillingData")
 
-	year, month, _ := calendar.YesterdaysDate(c.location)
-	reqString := strings.Join([]string{c.URL, "rest", strconv.Itoa(c.enrollment), fmt.Sprintf("usage-report?month=%d-%s&type=detail", year, calendar.PadMonth(month))}, "/")
+	return c.getReportData(year, month, "detail")
+}
+
+func (c Client) getReportData(year int, month time.Month, reportType string) ([]byte, error) {
+	reqString := strings.Join([]string{c.URL, "rest", strconv.Itoa(c.enrollment), fmt.Sprintf("usage-report?month=%d-%s&type=%s", year, calendar.PadMonth(month), reportType)}, "/")
 	c.log.Debug("Making Azure billing request to address: ", reqString)
 
 	req, err := http.NewRequest("GET", reqString, nil)
@@ -1,13 +1,16 @@
 package azure_test
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	. "github.com/challiwill/meteorologica/azure"
+	"github.com/challiwill/meteorologica/azure/azurefakes"
 	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/datamodels"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -22,7 +25,7 @@ var _ = Describe("Azure", func() {
 
 	BeforeEach(func() {
 		azureServer = ghttp.NewServer()
-		client = NewClient(logrus.New(), time.Now().Location(), azureServer.URL(), "some-key", 1337)
+		client = NewClient(logrus.New(), time.Now().Location(), azureServer.URL(), "some-key", 1337, 3)
 	})
 
 	AfterEach(func() {
@@ -35,8 +38,219 @@ var _ = Describe("Azure", func() {
 		})
 	})
 
-	XDescribe("GetNormalizedUsage", func() {
-		It("works", func() {})
+	Describe("GetNormalizedUsage", func() {
+		var (
+			reports datamodels.Reports
+			err     error
+		)
+
+		BeforeEach(func() {
+			yesterday := time.Now().AddDate(0, 0, -1)
+			longAgo := time.Now().AddDate(0, 0, -10)
+			azureServer.RouteToHandler("GET", "/rest/1337/usage-report", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("type") == "storecharge" {
+					w.Write([]byte(azureMarketplaceHeader))
+					return
+				}
+				body := azureUsageHeader
+				for _, date := range []time.Time{yesterday, longAgo} {
+					if r.URL.Query().Get("month") == date.Format("2006-01") {
+						body += azureUsageRow(date)
+					}
+				}
+				w.Write([]byte(body))
+			})
+		})
+
+		JustBeforeEach(func() {
+			reports, err = client.GetNormalizedUsage()
+		})
+
+		It("requests the usage of each month in the restatement window", func() {
+			Expect(err).NotTo(HaveOccurred())
+			months := map[string]bool{}
+			for i := 1; i <= 3; i++ {
+				months[time.Now().AddDate(0, 0, -i).Format("2006-01")] = true
+			}
+			Expect(azureServer.ReceivedRequests()).To(HaveLen(2 * len(months)))
+		})
+
+		It("returns only the usage of days in the restatement window", func() {
+			Expect(reports).To(HaveLen(1))
+			Expect(reports[0].Day).To(Equal(time.Now().AddDate(0, 0, -1).Day()))
+			Expect(reports[0].Cost).To(Equal(12.5))
+		})
+	})
+
+	Describe("when a usage API is set", func() {
+		var api *azurefakes.FakeUsageAPI
+
+		BeforeEach(func() {
+			api = new(azurefakes.FakeUsageAPI)
+			api.GetUsageReturns([]*Usage{{SubscriptionGuid: "some-guid", Year: 2016, Month: 9, Day: 12, ExtendedCost: 12.5}}, nil)
+			api.GetBalanceReturns(&Balance{TotalUsage: 12.5, TotalOverage: 1, ChargesBilledSeparately: 1, MarketplaceCharges: 1}, nil)
+			client.API = api
+		})
+
+		It("gets the usage from the API instead of the usage report", func() {
+			invoice, err := client.GetInvoice(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(invoice.Total).To(Equal(13.5))
+			Expect(invoice.DailyCosts).To(Equal(map[int]float64{12: 12.5}))
+			Expect(api.GetUsageCallCount()).To(Equal(1))
+			year, month := api.GetUsageArgsForCall(0)
+			Expect(year).To(Equal(2016))
+			Expect(month).To(Equal(time.September))
+			Expect(azureServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		Context("when the marketplace charges cannot be read", func() {
+			BeforeEach(func() {
+				api.GetMarketplaceChargesReturns(nil, errors.New("some-error"))
+			})
+
+			It("still returns the usage", func() {
+				invoice, err := client.GetInvoice(2016, time.September)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(invoice.DailyCosts).To(Equal(map[int]float64{12: 12.5}))
+			})
+		})
+	})
+
+	Describe("GetInvoice", func() {
+		It("states the total of the balance summary and the month's usage report by day", func() {
+			september := time.Date(2016, time.September, 12, 0, 0, 0, 0, time.UTC)
+			client.ReportingURL = azureServer.URL()
+			azureServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v3/enrollments/1337/billingPeriods/201609/balancesummary"),
+					ghttp.RespondWith(http.StatusOK, `{"currencyCode":"USD","totalUsage":37.5,"totalOverage":3.25,"chargesBilledSeparately":3.25,"azureMarketplaceServiceCharges":3}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/rest/1337/usage-report", "month=2016-09&type=detail"),
+					ghttp.RespondWith(http.StatusOK, azureUsageHeader+azureUsageRow(september)+azureUsageRow(september)+azureUsageRow(september.AddDate(0, 1, 0))),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/rest/1337/usage-report", "month=2016-09&type=storecharge"),
+					ghttp.RespondWith(http.StatusOK, azureMarketplaceHeader+azureMarketplaceRow(september)),
+				),
+			)
+
+			invoice, err := client.GetInvoice(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(invoice.Resource).To(Equal("Azure"))
+			Expect(invoice.Total).To(Equal(40.5))
+			Expect(invoice.Dropped).To(Equal(12.5))
+			Expect(invoice.DailyCosts).To(Equal(map[int]float64{12: 28}))
+		})
+	})
+
+	Describe("GetResourceLevelUsage", func() {
+		It("reports marketplace charges by publisher", func() {
+			yesterday := time.Now().AddDate(0, 0, -1)
+			azureServer.RouteToHandler("GET", "/rest/1337/usage-report", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("month") != yesterday.Format("2006-01") {
+					w.Write([]byte(azureUsageHeader))
+				} else if r.URL.Query().Get("type") == "storecharge" {
+					w.Write([]byte(azureMarketplaceHeader + azureMarketplaceRow(yesterday)))
+				} else {
+					w.Write([]byte(azureUsageHeader + azureUsageRow(yesterday)))
+				}
+			})
+
+			reports, err := client.GetResourceLevelUsage()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reports).To(HaveLen(2))
+			serviceTypes := map[string]float64{}
+			for _, r := range reports {
+				serviceTypes[r.ServiceType] = r.Cost
+			}
+			Expect(serviceTypes).To(Equal(map[string]float64{"Microsoft.Compute": 12.5, "Marketplace: some-publisher": 3}))
+		})
+
+		It("still reports the usage when the marketplace charges cannot be read", func() {
+			yesterday := time.Now().AddDate(0, 0, -1)
+			azureServer.RouteToHandler("GET", "/rest/1337/usage-report", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("type") == "storecharge" {
+					w.WriteHeader(http.StatusInternalServerError)
+				} else if r.URL.Query().Get("month") != yesterday.Format("2006-01") {
+					w.Write([]byte(azureUsageHeader))
+				} else {
+					w.Write([]byte(azureUsageHeader + azureUsageRow(yesterday)))
+				}
+			})
+
+			reports, err := client.GetResourceLevelUsage()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reports).To(HaveLen(1))
+			Expect(reports[0].ServiceType).To(Equal("Microsoft.Compute"))
+		})
+	})
+
+	Describe("GetPriceSheet", func() {
+		It("returns the prices of the month", func() {
+			azureServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/rest/1337/usage-report", "month=2016-09&type=pricesheet"),
+				ghttp.VerifyHeaderKV("authorization", "bearer some-key"),
+				ghttp.RespondWith(http.StatusOK, `"Price Sheet",
+"Service","Unit of Measure","Part Number","Unit Price","Currency Code",
+"Standard D2 VM","100 Hours","ABC-123","9.6","USD",
+`),
+			))
+
+			prices, err := client.GetPriceSheet(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(prices).To(Equal([]datamodels.AzurePrice{{
+				Enrollment:    "1337",
+				Year:          2016,
+				Month:         time.September,
+				MeterName:     "Standard D2 VM",
+				PartNumber:    "ABC-123",
+				UnitOfMeasure: "100 Hours",
+				UnitPrice:     9.6,
+				Currency:      "USD",
+			}}))
+		})
+	})
+
+	Describe("GetBalance", func() {
+		It("returns the balance summary of the month", func() {
+			client.ReportingURL = azureServer.URL()
+			azureServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v3/enrollments/1337/billingPeriods/201609/balancesummary"),
+				ghttp.VerifyHeaderKV("authorization", "bearer some-key"),
+				ghttp.RespondWith(http.StatusOK, `{
+					"id": "enrollments/1337/billingperiods/201609/balancesummaries",
+					"billingPeriodId": 201609,
+					"currencyCode": "USD",
+					"beginningBalance": 1000,
+					"endingBalance": 550,
+					"newPurchases": 0,
+					"adjustments": 0,
+					"utilized": 450,
+					"serviceOverage": 0,
+					"chargesBilledSeparately": 30,
+					"totalOverage": 0,
+					"totalUsage": 450,
+					"azureMarketplaceServiceCharges": 30
+				}`),
+			))
+
+			balance, err := client.GetBalance(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(balance).To(Equal(datamodels.AzureBalance{
+				Enrollment:              "1337",
+				Year:                    2016,
+				Month:                   time.September,
+				Currency:                "USD",
+				BeginningBalance:        1000,
+				Utilized:                450,
+				ChargesBilledSeparately: 30,
+				TotalUsage:              450,
+				MarketplaceCharges:      30,
+				EndingBalance:           550,
+			}))
+		})
 	})
 
 	Describe("GetBillingData", func() {
@@ -46,10 +260,9 @@ var _ = Describe("Azure", func() {
 		)
 
 		JustBeforeEach(func() {
-			monthlyUsageReport, err = client.GetBillingData()
+			monthlyUsageReport, err = client.GetBillingData(time.Now().Year(), time.Now().Month())
 		})
 
-		//TODO these tests will fail some days, that is bad
 		Context("when azure returns valid data", func() {
 			BeforeEach(func() {
 				azureServer.AppendHandlers(
@@ -99,6 +312,26 @@ var _ = Describe("Azure", func() {
 	})
 })
 
+var azureUsageHeader = `"Usage Data Extract",
+"",
+"AccountOwnerId","Account Name","ServiceAdministratorId","SubscriptionId","SubscriptionGuid","Subscription Name","Date","Month","Day","Year","Product","Meter ID","Meter Category","Meter Sub-Category","Meter Region","Meter Name","Consumed Quantity","ResourceRate","ExtendedCost","Resource Location","Consumed Service","Instance ID","ServiceInfo1","ServiceInfo2","AdditionalInfo","Tags","Store Service Identifier","Department Name","Cost Center","Unit Of Measure","Resource Group",
+`
+
+func azureUsageRow(date time.Time) string {
+	return fmt.Sprintf(`"owner","account","","123","some-guid","some-subscription","%s","%d","%d","%d","product","meter","category","sub-category","US East","Compute Hours","24","0.5","12.5","eastus","Microsoft.Compute","some-vm","","","","","","some-department","","Hours","some-group"
+`, date.Format("01/02/2006"), int(date.Month()), date.Day(), date.Year())
+}
+
+var azureMarketplaceHeader = `"Marketplace Charges",
+"",
+"AccountOwnerId","Account Name","ServiceAdministratorId","SubscriptionId","SubscriptionGuid","Subscription Name","Date","Month","Day","Year","Publisher Name","Offer Name","Plan Name","Consumed Quantity","ResourceRate","ExtendedCost","Unit Of Measure","Instance ID","Additional Info","Tags","Order Number","Department Name","Cost Center","Resource Group",
+`
+
+func azureMarketplaceRow(date time.Time) string {
+	return fmt.Sprintf(`"owner","account","","123","some-guid","some-subscription","%s","%d","%d","%d","some-publisher","some-offer","some-plan","1","3","3","Days","some-appliance","","","some-order","some-department","","some-group"
+`, date.Format("01/02/2006"), int(date.Month()), date.Day(), date.Year())
+}
+
 var monthlyUsageResponse = `
 one, two, three, four, five
 sometimes, you, might, think, you, want json
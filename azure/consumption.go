@@ -0,0 +1,445 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/errare"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	ManagementURL = "https://management.azure.com"
+	LoginURL      = "https://login.microsoftonline.com"
+
+	billingPeriodsAPIVersion = "2018-03-01-preview"
+	consumptionAPIVersion    = "2019-10-01"
+)
+
+// ServicePrincipal is the Azure Active Directory application the Consumption
+// API is called as. It needs the Enrollment Reader role of the enrollment.
+type ServicePrincipal struct {
+	TenantID     string `yaml:"tenant-id" env:"M_AZURE_TENANT_ID"`
+	ClientID     string `yaml:"client-id" env:"M_AZURE_CLIENT_ID"`
+	ClientSecret string `yaml:"client-secret" env:"M_AZURE_CLIENT_SECRET"`
+}
+
+// BillingPeriod is a month the enrollment is billed for, named YYYYMM.
+type BillingPeriod struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// ConsumptionAPI gets the usage details of an enrollment as JSON from the
+// Consumption API of Azure Resource Manager.
+type ConsumptionAPI struct {
+	URL        string
+	client     *http.Client
+	enrollment int
+	log        *logrus.Logger
+}
+
+func NewConsumptionAPI(log *logrus.Logger, managementURL, loginURL string, principal ServicePrincipal, enrollment int) *ConsumptionAPI {
+	managementURL = strings.TrimRight(managementURL, "/")
+	config := &clientcredentials.Config{
+		ClientID:     principal.ClientID,
+		ClientSecret: principal.ClientSecret,
+		TokenURL:     strings.TrimRight(loginURL, "/") + "/" + principal.TenantID + "/oauth2/v2.0/token",
+		Scopes:       []string{managementURL + "/.default"},
+	}
+	return &ConsumptionAPI{
+		URL:        managementURL,
+		client:     config.Client(context.WithValue(oauth2.NoContext, oauth2.HTTPClient, new(http.Client))),
+		enrollment: enrollment,
+		log:        log,
+	}
+}
+
+type billingPeriodsPage struct {
+	Value []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Start string `json:"billingPeriodStartDate"`
+			End   string `json:"billingPeriodEndDate"`
+		} `json:"properties"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+type usageDetailsPage struct {
+	Value    []usageDetail `json:"value"`
+	NextLink string        `json:"nextLink"`
+}
+
+type usageDetail struct {
+	Tags       map[string]string `json:"tags"`
+	Properties struct {
+		AccountOwnerID   string  `json:"accountOwnerId"`
+		AccountName      string  `json:"accountName"`
+		SubscriptionID   string  `json:"subscriptionId"`
+		SubscriptionName string  `json:"subscriptionName"`
+		Date             string  `json:"date"`
+		Product          string  `json:"product"`
+		MeterID          string  `json:"meterId"`
+		Quantity         float64 `json:"quantity"`
+		EffectivePrice   float64 `json:"effectivePrice"`
+		Cost             float64 `json:"cost"`
+		ResourceLocation string  `json:"resourceLocation"`
+		ConsumedService  string  `json:"consumedService"`
+		ResourceID       string  `json:"resourceId"`
+		ServiceInfo1     string  `json:"serviceInfo1"`
+		ServiceInfo2     string  `json:"serviceInfo2"`
+		AdditionalInfo   string  `json:"additionalInfo"`
+		InvoiceSection   string  `json:"invoiceSection"`
+		CostCenter       string  `json:"costCenter"`
+		ResourceGroup    string  `json:"resourceGroup"`
+		BillingCurrency  string  `json:"billingCurrency"`
+		MeterDetails     struct {
+			MeterName        string `json:"meterName"`
+			MeterCategory    string `json:"meterCategory"`
+			MeterSubCategory string `json:"meterSubCategory"`
+			UnitOfMeasure    string `json:"unitOfMeasure"`
+		} `json:"meterDetails"`
+	} `json:"properties"`
+}
+
+type marketplacesPage struct {
+	Value    []marketplace `json:"value"`
+	NextLink string        `json:"nextLink"`
+}
+
+type marketplace struct {
+	Tags       map[string]string `json:"tags"`
+	Properties struct {
+		AccountOwnerID   string  `json:"accountOwnerId"`
+		AccountName      string  `json:"accountName"`
+		SubscriptionGUID string  `json:"subscriptionGuid"`
+		SubscriptionName string  `json:"subscriptionName"`
+		UsageStart       string  `json:"usageStart"`
+		PublisherName    string  `json:"publisherName"`
+		OfferName        string  `json:"offerName"`
+		PlanName         string  `json:"planName"`
+		ConsumedQuantity float64 `json:"consumedQuantity"`
+		ResourceRate     float64 `json:"resourceRate"`
+		PretaxCost       float64 `json:"pretaxCost"`
+		UnitOfMeasure    string  `json:"unitOfMeasure"`
+		InstanceID       string  `json:"instanceId"`
+		AdditionalInfo   string  `json:"additionalInfo"`
+		OrderNumber      string  `json:"orderNumber"`
+		DepartmentName   string  `json:"departmentName"`
+		CostCenter       string  `json:"costCenter"`
+		ResourceGroup    string  `json:"resourceGroup"`
+		Currency         string  `json:"currency"`
+	} `json:"properties"`
+}
+
+type priceSheetPage struct {
+	Properties struct {
+		PriceSheets []struct {
+			MeterID          string  `json:"meterId"`
+			PartNumber       string  `json:"partNumber"`
+			UnitOfMeasure    string  `json:"unitOfMeasure"`
+			IncludedQuantity float64 `json:"includedQuantity"`
+			UnitPrice        float64 `json:"unitPrice"`
+			CurrencyCode     string  `json:"currencyCode"`
+			MeterDetails     struct {
+				MeterName        string `json:"meterName"`
+				MeterCategory    string `json:"meterCategory"`
+				MeterSubCategory string `json:"meterSubCategory"`
+			} `json:"meterDetails"`
+		} `json:"pricesheets"`
+		NextLink string `json:"nextLink"`
+	} `json:"properties"`
+}
+
+// BillingPeriods lists the billing periods of the enrollment.
+func (a *ConsumptionAPI) BillingPeriods() ([]BillingPeriod, error) {
+	a.log.Debug("Entering azure.BillingPeriods")
+	defer a.log.Debug("Returning azure.BillingPeriods")
+
+	periods := []BillingPeriod{}
+	next := a.billingAccount() + "/providers/Microsoft.Billing/billingPeriods?api-version=" + billingPeriodsAPIVersion
+	for next != "" {
+		var page billingPeriodsPage
+		err := a.get(next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page.Value {
+			start, err := time.Parse("2006-01-02", p.Properties.Start)
+			if err != nil {
+				return nil, fmt.Errorf("Azure billing period %s has an invalid start date: %s", p.Name, err.Error())
+			}
+			end, err := time.Parse("2006-01-02", p.Properties.End)
+			if err != nil {
+				return nil, fmt.Errorf("Azure billing period %s has an invalid end date: %s", p.Name, err.Error())
+			}
+			periods = append(periods, BillingPeriod{Name: p.Name, Start: start, End: end})
+		}
+		next = page.NextLink
+	}
+	return periods, nil
+}
+
+// GetUsage returns the usage details of the month's billing period.
+func (a *ConsumptionAPI) GetUsage(year int, month time.Month) ([]*Usage, error) {
+	a.log.Debug("Entering azure.GetUsage")
+	defer a.log.Debug("Returning azure.GetUsage")
+
+	name, err := a.billingPeriod(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := []*Usage{}
+	next := a.billingAccount() + "/providers/Microsoft.Billing/billingPeriods/" + name +
+		"/providers/Microsoft.Consumption/usageDetails?api-version=" + consumptionAPIVersion + "&$expand=meterDetails"
+	for next != "" {
+		var page usageDetailsPage
+		err := a.get(next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, detail := range page.Value {
+			usage, err := detail.usage()
+			if err != nil {
+				return nil, err
+			}
+			usages = append(usages, usage)
+		}
+		next = page.NextLink
+	}
+	return usages, nil
+}
+
+// GetMarketplaceCharges returns the marketplace charges of the month's billing
+// period.
+func (a *ConsumptionAPI) GetMarketplaceCharges(year int, month time.Month) ([]*MarketplaceCharge, error) {
+	a.log.Debug("Entering azure.GetMarketplaceCharges")
+	defer a.log.Debug("Returning azure.GetMarketplaceCharges")
+
+	name, err := a.billingPeriod(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	charges := []*MarketplaceCharge{}
+	next := a.billingAccount() + "/providers/Microsoft.Billing/billingPeriods/" + name +
+		"/providers/Microsoft.Consumption/marketplaces?api-version=" + consumptionAPIVersion
+	for next != "" {
+		var page marketplacesPage
+		err := a.get(next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, marketplace := range page.Value {
+			charge, err := marketplace.charge()
+			if err != nil {
+				return nil, err
+			}
+			charges = append(charges, charge)
+		}
+		next = page.NextLink
+	}
+	return charges, nil
+}
+
+// GetPriceSheet returns the prices of the month's billing period.
+func (a *ConsumptionAPI) GetPriceSheet(year int, month time.Month) ([]*Price, error) {
+	a.log.Debug("Entering azure.GetPriceSheet")
+	defer a.log.Debug("Returning azure.GetPriceSheet")
+
+	name, err := a.billingPeriod(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := []*Price{}
+	next := a.billingAccount() + "/providers/Microsoft.Billing/billingPeriods/" + name +
+		"/providers/Microsoft.Consumption/pricesheets/default?api-version=" + consumptionAPIVersion + "&$expand=properties/meterDetails"
+	for next != "" {
+		var page priceSheetPage
+		err := a.get(next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page.Properties.PriceSheets {
+			prices = append(prices, &Price{
+				MeterID:          p.MeterID,
+				MeterName:        p.MeterDetails.MeterName,
+				MeterCategory:    p.MeterDetails.MeterCategory,
+				MeterSubCategory: p.MeterDetails.MeterSubCategory,
+				PartNumber:       p.PartNumber,
+				UnitOfMeasure:    p.UnitOfMeasure,
+				IncludedQuantity: p.IncludedQuantity,
+				UnitPrice:        p.UnitPrice,
+				Currency:         p.CurrencyCode,
+			})
+		}
+		next = page.Properties.NextLink
+	}
+	return prices, nil
+}
+
+// GetBalance returns the balance summary of the month's billing period.
+func (a *ConsumptionAPI) GetBalance(year int, month time.Month) (*Balance, error) {
+	a.log.Debug("Entering azure.GetBalance")
+	defer a.log.Debug("Returning azure.GetBalance")
+
+	name, err := a.billingPeriod(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance struct {
+		Properties Balance `json:"properties"`
+	}
+	err = a.get(a.billingAccount()+"/providers/Microsoft.Billing/billingPeriods/"+name+
+		"/providers/Microsoft.Consumption/balances?api-version="+consumptionAPIVersion, &balance)
+	if err != nil {
+		return nil, err
+	}
+	return &balance.Properties, nil
+}
+
+// billingPeriod returns the name of the month's billing period. It errors if
+// the enrollment has no billing period for the month yet.
+func (a *ConsumptionAPI) billingPeriod(year int, month time.Month) (string, error) {
+	periods, err := a.BillingPeriods()
+	if err != nil {
+		return "", err
+	}
+	name := strconv.Itoa(year) + calendar.PadMonth(month)
+	for _, p := range periods {
+		if p.Name == name {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("Azure billing period %s is not available", name)
+}
+
+func (a *ConsumptionAPI) billingAccount() string {
+	return a.URL + "/providers/Microsoft.Billing/billingAccounts/" + strconv.Itoa(a.enrollment)
+}
+
+// get decodes the response of a request to the URL, which next links give
+// in full.
+func (a *ConsumptionAPI) get(url string, v interface{}) error {
+	a.log.Debug("Making Azure Consumption API request to address: ", url)
+	resp, err := a.client.Get(url)
+	if err != nil {
+		return errare.NewRequestError(err, IAAS)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errare.NewResponseError(resp.Status, IAAS)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// usage maps the usage detail onto the columns of the usage report. The
+// Consumption API names the department of an enrollment its invoice section,
+// and only states the location of the resource, which stands in for the meter
+// region.
+func (d usageDetail) usage() (*Usage, error) {
+	p := d.Properties
+	date, err := time.Parse(time.RFC3339, p.Date)
+	if err != nil {
+		return nil, fmt.Errorf("Azure usage detail has an invalid date: %s", err.Error())
+	}
+	tags, err := encodeTags(d.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return &Usage{
+		AccountOwnerId:   p.AccountOwnerID,
+		AccountName:      p.AccountName,
+		SubscriptionGuid: p.SubscriptionID,
+		SubscriptionName: p.SubscriptionName,
+		Date:             date.Format("01/02/2006"),
+		Month:            int(date.Month()),
+		Day:              date.Day(),
+		Year:             date.Year(),
+		Product:          p.Product,
+		MeterID:          p.MeterID,
+		MeterCategory:    p.MeterDetails.MeterCategory,
+		MeterSubCategory: p.MeterDetails.MeterSubCategory,
+		MeterRegion:      p.ResourceLocation,
+		MeterName:        p.MeterDetails.MeterName,
+		ConsumedQuantity: p.Quantity,
+		ResourceRate:     p.EffectivePrice,
+		ExtendedCost:     p.Cost,
+		ResourceLocation: p.ResourceLocation,
+		ConsumedService:  p.ConsumedService,
+		InstanceID:       p.ResourceID,
+		ServiceInfo1:     p.ServiceInfo1,
+		ServiceInfo2:     p.ServiceInfo2,
+		AdditionalInfo:   p.AdditionalInfo,
+		Tags:             tags,
+		DepartmentName:   p.InvoiceSection,
+		CostCenter:       p.CostCenter,
+		UnitOfMeasure:    p.MeterDetails.UnitOfMeasure,
+		ResourceGroup:    p.ResourceGroup,
+		Currency:         p.BillingCurrency,
+	}, nil
+}
+
+// charge maps the marketplace onto the columns of the marketplace charges
+// report.
+func (m marketplace) charge() (*MarketplaceCharge, error) {
+	p := m.Properties
+	date, err := time.Parse(time.RFC3339, p.UsageStart)
+	if err != nil {
+		return nil, fmt.Errorf("Azure marketplace charge has an invalid date: %s", err.Error())
+	}
+	tags, err := encodeTags(m.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return &MarketplaceCharge{
+		AccountOwnerId:   p.AccountOwnerID,
+		AccountName:      p.AccountName,
+		SubscriptionGuid: p.SubscriptionGUID,
+		SubscriptionName: p.SubscriptionName,
+		Date:             date.Format("01/02/2006"),
+		Month:            int(date.Month()),
+		Day:              date.Day(),
+		Year:             date.Year(),
+		PublisherName:    p.PublisherName,
+		OfferName:        p.OfferName,
+		PlanName:         p.PlanName,
+		ConsumedQuantity: p.ConsumedQuantity,
+		ResourceRate:     p.ResourceRate,
+		ExtendedCost:     p.PretaxCost,
+		UnitOfMeasure:    p.UnitOfMeasure,
+		InstanceID:       p.InstanceID,
+		AdditionalInfo:   p.AdditionalInfo,
+		Tags:             tags,
+		OrderNumber:      p.OrderNumber,
+		DepartmentName:   p.DepartmentName,
+		CostCenter:       p.CostCenter,
+		ResourceGroup:    p.ResourceGroup,
+		Currency:         p.Currency,
+	}, nil
+}
+
+// encodeTags encodes tags as JSON, the way the reports state them.
+func encodeTags(tags map[string]string) (string, error) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(tags)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,297 @@
+package azure_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/azure"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("ConsumptionAPI", func() {
+	const account = "/providers/Microsoft.Billing/billingAccounts/1337"
+
+	var (
+		api    *ConsumptionAPI
+		server *ghttp.Server
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("POST", "/some-tenant/oauth2/v2.0/token", ghttp.CombineHandlers(
+			ghttp.VerifyBasicAuth("some-client", "some-secret"),
+			ghttp.VerifyContentType("application/x-www-form-urlencoded"),
+			func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.ParseForm()).To(Succeed())
+				Expect(r.Form.Get("grant_type")).To(Equal("client_credentials"))
+				Expect(r.Form.Get("scope")).To(Equal(server.URL() + "/.default"))
+			},
+			ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+				"access_token": "some-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			}),
+		))
+		server.RouteToHandler("GET", account+"/providers/Microsoft.Billing/billingPeriods", ghttp.CombineHandlers(
+			ghttp.VerifyHeaderKV("Authorization", "Bearer some-token"),
+			ghttp.VerifyFormKV("api-version", "2018-03-01-preview"),
+			ghttp.RespondWith(http.StatusOK, `{"value": [
+				{"name": "201610", "properties": {"billingPeriodStartDate": "2016-10-01", "billingPeriodEndDate": "2016-10-31"}},
+				{"name": "201609", "properties": {"billingPeriodStartDate": "2016-09-01", "billingPeriodEndDate": "2016-09-30"}}
+			]}`),
+		))
+		api = NewConsumptionAPI(logrus.New(), server.URL(), server.URL(), ServicePrincipal{
+			TenantID:     "some-tenant",
+			ClientID:     "some-client",
+			ClientSecret: "some-secret",
+		}, 1337)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("BillingPeriods", func() {
+		It("lists the billing periods of the enrollment", func() {
+			periods, err := api.BillingPeriods()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(periods).To(Equal([]BillingPeriod{
+				{Name: "201610", Start: time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2016, time.October, 31, 0, 0, 0, 0, time.UTC)},
+				{Name: "201609", Start: time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC)},
+			}))
+		})
+	})
+
+	Describe("GetUsage", func() {
+		const usageDetails = account + "/providers/Microsoft.Billing/billingPeriods/201609/providers/Microsoft.Consumption/usageDetails"
+
+		BeforeEach(func() {
+			server.RouteToHandler("GET", usageDetails, func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Header.Get("Authorization")).To(Equal("Bearer some-token"))
+				Expect(r.URL.Query().Get("api-version")).To(Equal("2019-10-01"))
+				if r.URL.Query().Get("$skiptoken") == "" {
+					w.Write([]byte(`{"value": [` + usageDetail("2016-09-12T00:00:00.0000000Z", 12.5) + `], "nextLink": "` + server.URL() + usageDetails + `?api-version=2019-10-01&$skiptoken=page-2"}`))
+					return
+				}
+				w.Write([]byte(`{"value": [` + usageDetail("2016-09-13T00:00:00.0000000Z", 2.5) + `]}`))
+			})
+		})
+
+		It("follows the next links of the month's usage details", func() {
+			usages, err := api.GetUsage(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usages).To(HaveLen(2))
+			Expect(usages[0].ExtendedCost).To(Equal(12.5))
+			Expect(usages[1].Day).To(Equal(13))
+			Expect(usages[1].ExtendedCost).To(Equal(2.5))
+		})
+
+		It("maps the usage details onto the usage report columns", func() {
+			usages, err := api.GetUsage(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*usages[0]).To(Equal(Usage{
+				AccountOwnerId:   "owner",
+				AccountName:      "account",
+				SubscriptionGuid: "some-guid",
+				SubscriptionName: "some-subscription",
+				Date:             "09/12/2016",
+				Month:            9,
+				Day:              12,
+				Year:             2016,
+				Product:          "product",
+				MeterID:          "meter",
+				MeterCategory:    "category",
+				MeterSubCategory: "sub-category",
+				MeterRegion:      "eastus",
+				MeterName:        "Compute Hours",
+				ConsumedQuantity: 24,
+				ResourceRate:     0.5,
+				ExtendedCost:     12.5,
+				ResourceLocation: "eastus",
+				ConsumedService:  "Microsoft.Compute",
+				InstanceID:       "some-vm",
+				Tags:             `{"team":"some-team"}`,
+				DepartmentName:   "some-department",
+				CostCenter:       "some-cost-center",
+				UnitOfMeasure:    "Hours",
+				ResourceGroup:    "some-group",
+				Currency:         "EUR",
+			}))
+		})
+
+		It("errors when the month has no billing period", func() {
+			_, err := api.GetUsage(2016, time.August)
+			Expect(err).To(MatchError("Azure billing period 201608 is not available"))
+		})
+
+		It("errors when the usage details cannot be read", func() {
+			server.RouteToHandler("GET", usageDetails, ghttp.RespondWith(http.StatusForbidden, ""))
+			_, err := api.GetUsage(2016, time.September)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("GetMarketplaceCharges", func() {
+		It("returns the marketplace charges of the month", func() {
+			server.RouteToHandler("GET", account+"/providers/Microsoft.Billing/billingPeriods/201609/providers/Microsoft.Consumption/marketplaces", ghttp.CombineHandlers(
+				ghttp.VerifyFormKV("api-version", "2019-10-01"),
+				ghttp.RespondWith(http.StatusOK, `{"value": [{"tags": {"team": "some-team"}, "properties": {
+					"usageStart": "2016-09-12T00:00:00.0000000Z",
+					"subscriptionGuid": "some-guid",
+					"subscriptionName": "some-subscription",
+					"publisherName": "some-publisher",
+					"offerName": "some-offer",
+					"planName": "some-plan",
+					"consumedQuantity": 1,
+					"resourceRate": 3,
+					"pretaxCost": 3,
+					"unitOfMeasure": "Days",
+					"instanceId": "some-appliance",
+					"departmentName": "some-department",
+					"resourceGroup": "some-group",
+					"currency": "EUR"
+				}}]}`),
+			))
+
+			charges, err := api.GetMarketplaceCharges(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(charges).To(Equal([]*MarketplaceCharge{{
+				SubscriptionGuid: "some-guid",
+				SubscriptionName: "some-subscription",
+				Date:             "09/12/2016",
+				Month:            9,
+				Day:              12,
+				Year:             2016,
+				PublisherName:    "some-publisher",
+				OfferName:        "some-offer",
+				PlanName:         "some-plan",
+				ConsumedQuantity: 1,
+				ResourceRate:     3,
+				ExtendedCost:     3,
+				UnitOfMeasure:    "Days",
+				InstanceID:       "some-appliance",
+				Tags:             `{"team":"some-team"}`,
+				DepartmentName:   "some-department",
+				ResourceGroup:    "some-group",
+				Currency:         "EUR",
+			}}))
+		})
+	})
+
+	Describe("GetPriceSheet", func() {
+		It("follows the next links of the month's price sheet", func() {
+			const priceSheet = account + "/providers/Microsoft.Billing/billingPeriods/201609/providers/Microsoft.Consumption/pricesheets/default"
+			server.RouteToHandler("GET", priceSheet, func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Query().Get("$expand")).To(Equal("properties/meterDetails"))
+				if r.URL.Query().Get("$skiptoken") == "" {
+					w.Write([]byte(`{"properties": {"pricesheets": [{
+						"meterId": "some-meter",
+						"meterDetails": {"meterName": "D2 v3", "meterCategory": "Virtual Machines", "meterSubCategory": "Dv3 Series"},
+						"partNumber": "ABC-123",
+						"unitOfMeasure": "100 Hours",
+						"includedQuantity": 0,
+						"unitPrice": 9.6,
+						"currencyCode": "USD"
+					}], "nextLink": "` + server.URL() + priceSheet + `?api-version=2019-10-01&$expand=properties/meterDetails&$skiptoken=page-2"}}`))
+					return
+				}
+				w.Write([]byte(`{"properties": {"pricesheets": [{"meterId": "other-meter", "unitPrice": 0.05}]}}`))
+			})
+
+			prices, err := api.GetPriceSheet(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(prices).To(Equal([]*Price{
+				{
+					MeterID:          "some-meter",
+					MeterName:        "D2 v3",
+					MeterCategory:    "Virtual Machines",
+					MeterSubCategory: "Dv3 Series",
+					PartNumber:       "ABC-123",
+					UnitOfMeasure:    "100 Hours",
+					UnitPrice:        9.6,
+					Currency:         "USD",
+				},
+				{MeterID: "other-meter", UnitPrice: 0.05},
+			}))
+		})
+	})
+
+	Describe("GetBalance", func() {
+		It("returns the balance summary of the month", func() {
+			server.RouteToHandler("GET", account+"/providers/Microsoft.Billing/billingPeriods/201609/providers/Microsoft.Consumption/balances", ghttp.CombineHandlers(
+				ghttp.VerifyFormKV("api-version", "2019-10-01"),
+				ghttp.RespondWith(http.StatusOK, `{"properties": {
+					"currency": "USD",
+					"beginningBalance": 1000,
+					"endingBalance": 550,
+					"utilized": 450,
+					"serviceOverage": 0,
+					"totalOverage": 0,
+					"totalUsage": 450
+				}}`),
+			))
+
+			balance, err := api.GetBalance(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(balance).To(Equal(&Balance{Currency: "USD", BeginningBalance: 1000, Utilized: 450, TotalUsage: 450, EndingBalance: 550}))
+		})
+	})
+
+	Describe("Client", func() {
+		It("normalizes the usage of the API", func() {
+			server.RouteToHandler("GET", account+"/providers/Microsoft.Billing/billingPeriods/201609/providers/Microsoft.Consumption/usageDetails",
+				ghttp.RespondWith(http.StatusOK, `{"value": [`+usageDetail("2016-09-12T00:00:00.0000000Z", 12.5)+`]}`))
+			server.RouteToHandler("GET", account+"/providers/Microsoft.Billing/billingPeriods/201609/providers/Microsoft.Consumption/marketplaces",
+				ghttp.RespondWith(http.StatusOK, `{"value": []}`))
+			server.RouteToHandler("GET", account+"/providers/Microsoft.Billing/billingPeriods/201609/providers/Microsoft.Consumption/balances",
+				ghttp.RespondWith(http.StatusOK, `{"properties": {"totalUsage": 12.5}}`))
+			client := NewClient(logrus.New(), time.UTC, server.URL(), "", 1337, 3)
+			client.API = api
+
+			invoice, err := client.GetInvoice(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(invoice.Total).To(Equal(12.5))
+			Expect(invoice.DailyCosts).To(Equal(map[int]float64{12: 12.5}))
+		})
+	})
+})
+
+func usageDetail(date string, cost float64) string {
+	b, _ := json.Marshal(map[string]interface{}{
+		"id":   "some-id",
+		"type": "Microsoft.Consumption/usageDetails",
+		"kind": "legacy",
+		"tags": map[string]string{"team": "some-team"},
+		"properties": map[string]interface{}{
+			"accountOwnerId":   "owner",
+			"accountName":      "account",
+			"subscriptionId":   "some-guid",
+			"subscriptionName": "some-subscription",
+			"date":             date,
+			"product":          "product",
+			"meterId":          "meter",
+			"quantity":         24,
+			"effectivePrice":   0.5,
+			"cost":             cost,
+			"resourceLocation": "eastus",
+			"consumedService":  "Microsoft.Compute",
+			"resourceId":       "some-vm",
+			"invoiceSection":   "some-department",
+			"costCenter":       "some-cost-center",
+			"resourceGroup":    "some-group",
+			"billingCurrency":  "EUR",
+			"meterDetails": map[string]string{
+				"meterName":        "Compute Hours",
+				"meterCategory":    "category",
+				"meterSubCategory": "sub-category",
+				"unitOfMeasure":    "Hours",
+			},
+		},
+	})
+	return string(b)
+}
@@ -0,0 +1,167 @@
+package azure
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	SaveAzurePriceSheet([]datamodels.AzurePrice) error
+	SaveAzureBalances([]datamodels.AzureBalance) error
+	SaveAzureCommitmentProjection(datamodels.AzureCommitmentProjection) error
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+}
+
+// Importer saves the price sheet and balance summary of the enrollment for
+// every month a run saved Azure usage of, so that changes to the rates it pays
+// can be followed from month to month, and projects when its monetary
+// commitment runs out.
+type Importer struct {
+	log    *logrus.Logger
+	client *Client
+	db     Database
+
+	// BurnDays is how many days the daily burn of the commitment is averaged
+	// over.
+	BurnDays int
+}
+
+func NewImporter(log *logrus.Logger, client *Client, db Database, burnDays int) *Importer {
+	return &Importer{
+		log:    log,
+		client: client,
+		db:     db,
+
+		BurnDays: burnDays,
+	}
+}
+
+func (i *Importer) Name() string {
+	return "azure enrollment"
+}
+
+// Run saves the price sheet and balance summary of each month, and projects
+// when the commitment runs out from the balance of the last month. A month
+// whose price sheet or balance summary cannot be read is skipped.
+func (i *Importer) Run(_ datamodels.Run, reports datamodels.Reports) error {
+	i.log.Debug("Entering azure.Run")
+	defer i.log.Debug("Returning azure.Run")
+
+	prices := []datamodels.AzurePrice{}
+	balances := []datamodels.AzureBalance{}
+	for _, month := range reportedMonths(reports) {
+		priceSheet, err := i.client.GetPriceSheet(month.Year(), month.Month())
+		if err != nil {
+			i.log.Errorf("Failed to get the Azure price sheet of %s: %s", month.Format("2006-01"), err.Error())
+		} else {
+			prices = append(prices, priceSheet...)
+		}
+
+		balance, err := i.client.GetBalance(month.Year(), month.Month())
+		if err != nil {
+			i.log.Errorf("Failed to get the Azure balance summary of %s: %s", month.Format("2006-01"), err.Error())
+		} else {
+			balances = append(balances, balance)
+		}
+	}
+	if len(prices) > 0 {
+		err := i.db.SaveAzurePriceSheet(prices)
+		if err != nil {
+			return err
+		}
+	}
+	if len(balances) == 0 {
+		return nil
+	}
+	err := i.db.SaveAzureBalances(balances)
+	if err != nil {
+		return err
+	}
+
+	projection, err := i.project(balances[len(balances)-1], lastReportedDay(reports))
+	if err != nil {
+		return err
+	}
+	return i.db.SaveAzureCommitmentProjection(projection)
+}
+
+// project projects when the ending balance runs out, drawn down by the
+// average daily cost of Azure over the BurnDays up to asOf. Marketplace
+// charges are left out as they are billed separately from the commitment.
+func (i *Importer) project(balance datamodels.AzureBalance, asOf time.Time) (datamodels.AzureCommitmentProjection, error) {
+	projection := datamodels.AzureCommitmentProjection{
+		Enrollment: balance.Enrollment,
+		AsOf:       asOf.Format("2006-01-02"),
+		Balance:    balance.EndingBalance,
+		BurnDays:   i.BurnDays,
+	}
+	costs, err := i.db.GetDailyCosts(asOf.AddDate(0, 0, 1-i.BurnDays), asOf)
+	if err != nil {
+		return projection, err
+	}
+	burned := 0.0
+	for _, c := range costs {
+		if c.Resource == IAAS && !strings.HasPrefix(c.ServiceType, Marketplace+": ") {
+			burned += c.Cost
+		}
+	}
+	projection.DailyBurn = burned / float64(i.BurnDays)
+
+	switch {
+	case balance.EndingBalance <= 0:
+		projection.ExhaustedOn = projection.AsOf
+		i.log.Warnf("Azure commitment of enrollment %s has run out, its overage is %.2f %s", balance.Enrollment, balance.TotalOverage, balance.Currency)
+	case projection.DailyBurn > 0:
+		exhaustedOn := asOf.AddDate(0, 0, int(math.Ceil(balance.EndingBalance/projection.DailyBurn)))
+		projection.ExhaustedOn = exhaustedOn.Format("2006-01-02")
+		message := fmt.Sprintf("Azure commitment of enrollment %s (%.2f %s left, %.2f a day) is projected to run out on %s", balance.Enrollment, balance.EndingBalance, balance.Currency, projection.DailyBurn, projection.ExhaustedOn)
+		if exhaustedOn.Before(asOf.AddDate(0, 0, i.BurnDays+1)) {
+			i.log.Warn(message)
+		} else {
+			i.log.Info(message)
+		}
+	}
+	return projection, nil
+}
+
+type months []time.Time
+
+func (m months) Len() int           { return len(m) }
+func (m months) Less(i, j int) bool { return m[i].Before(m[j]) }
+func (m months) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// lastReportedDay returns the last day of the Azure reports.
+func lastReportedDay(reports datamodels.Reports) time.Time {
+	var last time.Time
+	for _, r := range reports {
+		day := time.Date(r.Year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)
+		if r.Resource == IAAS && day.After(last) {
+			last = day
+		}
+	}
+	return last
+}
+
+// reportedMonths returns the months of the Azure reports, in order.
+func reportedMonths(reports datamodels.Reports) months {
+	seen := make(map[time.Time]bool)
+	reported := months{}
+	for _, r := range reports {
+		month := time.Date(r.Year, r.Month, 1, 0, 0, 0, 0, time.UTC)
+		if r.Resource != IAAS || seen[month] {
+			continue
+		}
+		seen[month] = true
+		reported = append(reported, month)
+	}
+	sort.Sort(reported)
+	return reported
+}
@@ -0,0 +1,149 @@
+package azure_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/azure"
+	"github.com/challiwill/meteorologica/azure/azurefakes"
+	"github.com/challiwill/meteorologica/datamodels"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Importer", func() {
+	var (
+		log      *logrus.Logger
+		api      *azurefakes.FakeUsageAPI
+		db       *azurefakes.FakeDatabase
+		importer *Importer
+		reports  datamodels.Reports
+	)
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		api = new(azurefakes.FakeUsageAPI)
+		api.GetPriceSheetReturns([]*Price{{MeterID: "some-meter", UnitPrice: 9.6, Currency: "USD"}}, nil)
+		api.GetBalanceReturns(&Balance{Currency: "USD", BeginningBalance: 1000, Utilized: 400, EndingBalance: 600}, nil)
+		client := NewClient(log, time.UTC, "", "", 1337, 3)
+		client.API = api
+		db = new(azurefakes.FakeDatabase)
+		db.GetDailyCostsReturns([]datamodels.DailyCost{
+			{Resource: "Azure", ServiceType: "Microsoft.Compute", Cost: 300},
+			{Resource: "Azure", ServiceType: "Marketplace: some-publisher", Cost: 100},
+			{Resource: "AWS", ServiceType: "EC2", Cost: 50},
+		}, nil)
+		importer = NewImporter(log, client, db, 30)
+
+		reports = datamodels.Reports{
+			{Resource: "Azure", Year: 2016, Month: time.October, Day: 1, Cost: 1},
+			{Resource: "Azure", Year: 2016, Month: time.September, Day: 30, Cost: 1},
+			{Resource: "Azure", Year: 2016, Month: time.September, Day: 29, Cost: 1},
+			{Resource: "AWS", Year: 2016, Month: time.August, Day: 31, Cost: 1},
+		}
+	})
+
+	It("saves the price sheet of each month of Azure usage", func() {
+		Expect(importer.Name()).To(Equal("azure enrollment"))
+		Expect(importer.Run(datamodels.Run{}, reports)).To(Succeed())
+
+		Expect(api.GetPriceSheetCallCount()).To(Equal(2))
+		year, month := api.GetPriceSheetArgsForCall(0)
+		Expect(year).To(Equal(2016))
+		Expect(month).To(Equal(time.September))
+		_, month = api.GetPriceSheetArgsForCall(1)
+		Expect(month).To(Equal(time.October))
+
+		Expect(db.SaveAzurePriceSheetCallCount()).To(Equal(1))
+		prices := db.SaveAzurePriceSheetArgsForCall(0)
+		Expect(prices).To(HaveLen(2))
+		Expect(prices[0]).To(Equal(datamodels.AzurePrice{Enrollment: "1337", Year: 2016, Month: time.September, MeterID: "some-meter", UnitPrice: 9.6, Currency: "USD"}))
+		Expect(prices[1].Month).To(Equal(time.October))
+	})
+
+	It("saves the balance summary of each month of Azure usage", func() {
+		Expect(importer.Run(datamodels.Run{}, reports)).To(Succeed())
+
+		Expect(api.GetBalanceCallCount()).To(Equal(2))
+		Expect(db.SaveAzureBalancesCallCount()).To(Equal(1))
+		balances := db.SaveAzureBalancesArgsForCall(0)
+		Expect(balances).To(HaveLen(2))
+		Expect(balances[0]).To(Equal(datamodels.AzureBalance{Enrollment: "1337", Year: 2016, Month: time.September, Currency: "USD", BeginningBalance: 1000, Utilized: 400, EndingBalance: 600}))
+		Expect(balances[1].Month).To(Equal(time.October))
+	})
+
+	Describe("projecting when the commitment runs out", func() {
+		It("draws the last balance down by the daily burn of Azure without marketplace charges", func() {
+			Expect(importer.Run(datamodels.Run{}, reports)).To(Succeed())
+
+			from, to := db.GetDailyCostsArgsForCall(0)
+			Expect(from).To(Equal(time.Date(2016, time.September, 2, 0, 0, 0, 0, time.UTC)))
+			Expect(to).To(Equal(time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(db.SaveAzureCommitmentProjectionArgsForCall(0)).To(Equal(datamodels.AzureCommitmentProjection{
+				Enrollment:  "1337",
+				AsOf:        "2016-10-01",
+				Balance:     600,
+				DailyBurn:   10,
+				BurnDays:    30,
+				ExhaustedOn: "2016-11-30",
+			}))
+			Expect(log.Out).To(Say("level=info msg=\"Azure commitment of enrollment 1337 \\(600.00 USD left, 10.00 a day\\) is projected to run out on 2016-11-30\""))
+		})
+
+		It("warns when the commitment runs out within the burn days", func() {
+			api.GetBalanceReturns(&Balance{Currency: "USD", EndingBalance: 200}, nil)
+			Expect(importer.Run(datamodels.Run{}, reports)).To(Succeed())
+
+			Expect(db.SaveAzureCommitmentProjectionArgsForCall(0).ExhaustedOn).To(Equal("2016-10-21"))
+			Expect(log.Out).To(Say("level=warning msg=\"Azure commitment of enrollment 1337 .* is projected to run out on 2016-10-21\""))
+		})
+
+		It("warns when the commitment has run out", func() {
+			api.GetBalanceReturns(&Balance{Currency: "USD", EndingBalance: -50, TotalOverage: 50}, nil)
+			Expect(importer.Run(datamodels.Run{}, reports)).To(Succeed())
+
+			Expect(db.SaveAzureCommitmentProjectionArgsForCall(0).ExhaustedOn).To(Equal("2016-10-01"))
+			Expect(log.Out).To(Say("Azure commitment of enrollment 1337 has run out, its overage is 50.00 USD"))
+		})
+
+		It("does not project a day when nothing is drawn down", func() {
+			db.GetDailyCostsReturns(nil, nil)
+			Expect(importer.Run(datamodels.Run{}, reports)).To(Succeed())
+
+			Expect(db.SaveAzureCommitmentProjectionArgsForCall(0).ExhaustedOn).To(BeEmpty())
+		})
+
+		It("is not made without a balance summary", func() {
+			api.GetBalanceReturns(nil, errors.New("Azure billing period 201610 is not available"))
+			Expect(importer.Run(datamodels.Run{}, reports)).To(Succeed())
+
+			Expect(log.Out).To(Say("Failed to get the Azure balance summary of 2016-09"))
+			Expect(db.SaveAzureBalancesCallCount()).To(BeZero())
+			Expect(db.SaveAzureCommitmentProjectionCallCount()).To(BeZero())
+		})
+	})
+
+	It("skips months whose price sheet cannot be read", func() {
+		api.GetPriceSheetStub = func(year int, month time.Month) ([]*Price, error) {
+			if month == time.October {
+				return nil, errors.New("Azure billing period 201610 is not available")
+			}
+			return []*Price{{MeterID: "some-meter"}}, nil
+		}
+
+		Expect(importer.Run(datamodels.Run{}, reports)).To(Succeed())
+		Expect(log.Out).To(Say("Failed to get the Azure price sheet of 2016-10"))
+		Expect(db.SaveAzurePriceSheetArgsForCall(0)).To(HaveLen(1))
+	})
+
+	It("does not save anything without Azure usage", func() {
+		Expect(importer.Run(datamodels.Run{}, reports[3:])).To(Succeed())
+		Expect(api.GetPriceSheetCallCount()).To(BeZero())
+		Expect(db.SaveAzurePriceSheetCallCount()).To(BeZero())
+		Expect(db.SaveAzureBalancesCallCount()).To(BeZero())
+	})
+})
@@ -0,0 +1,71 @@
+package azure
+
+// Marketplace prefixes the consumed service of marketplace charges, which is
+// followed by the publisher of the offer.
+const Marketplace = "Marketplace"
+
+// MarketplaceCharge is a charge for a marketplace offer, which Azure bills
+// separately from the usage of the enrollment.
+type MarketplaceCharge struct {
+	AccountOwnerId         string  `csv:"AccountOwnerId"`
+	AccountName            string  `csv:"Account Name"`
+	ServiceAdministratorId string  `csv:"ServiceAdministratorId"`
+	SubscriptionId         string  `csv:"SubscriptionId"`
+	SubscriptionGuid       string  `csv:"SubscriptionGuid"`
+	SubscriptionName       string  `csv:"Subscription Name"`
+	Date                   string  `csv:"Date"`
+	Month                  int     `csv:"Month"`
+	Day                    int     `csv:"Day"`
+	Year                   int     `csv:"Year"`
+	PublisherName          string  `csv:"Publisher Name"`
+	OfferName              string  `csv:"Offer Name"`
+	PlanName               string  `csv:"Plan Name"`
+	ConsumedQuantity       float64 `csv:"Consumed Quantity"`
+	ResourceRate           float64 `csv:"ResourceRate"`
+	ExtendedCost           float64 `csv:"ExtendedCost"`
+	UnitOfMeasure          string  `csv:"Unit Of Measure"`
+	InstanceID             string  `csv:"Instance ID"`
+	AdditionalInfo         string  `csv:"Additional Info"`
+	Tags                   string  `csv:"Tags"`
+	OrderNumber            string  `csv:"Order Number"`
+	DepartmentName         string  `csv:"Department Name"`
+	CostCenter             string  `csv:"Cost Center"`
+	ResourceGroup          string  `csv:"Resource Group"`
+	// Currency is only stated by the Consumption API; the marketplace
+	// charges report does not have it.
+	Currency string `csv:"-"`
+}
+
+// Usage returns the charge as a row of the usage report, consumed by the
+// "Marketplace: <publisher>" service so that it is reported by publisher.
+func (m MarketplaceCharge) Usage() *Usage {
+	return &Usage{
+		AccountOwnerId:         m.AccountOwnerId,
+		AccountName:            m.AccountName,
+		ServiceAdministratorId: m.ServiceAdministratorId,
+		SubscriptionId:         m.SubscriptionId,
+		SubscriptionGuid:       m.SubscriptionGuid,
+		SubscriptionName:       m.SubscriptionName,
+		Date:                   m.Date,
+		Month:                  m.Month,
+		Day:                    m.Day,
+		Year:                   m.Year,
+		Product:                m.OfferName,
+		MeterCategory:          Marketplace,
+		MeterSubCategory:       m.OfferName,
+		MeterName:              m.PlanName,
+		ConsumedQuantity:       m.ConsumedQuantity,
+		ResourceRate:           m.ResourceRate,
+		ExtendedCost:           m.ExtendedCost,
+		ConsumedService:        Marketplace + ": " + m.PublisherName,
+		InstanceID:             m.InstanceID,
+		AdditionalInfo:         m.AdditionalInfo,
+		Tags:                   m.Tags,
+		StoreServiceIdentifier: m.OrderNumber,
+		DepartmentName:         m.DepartmentName,
+		CostCenter:             m.CostCenter,
+		UnitOfMeasure:          m.UnitOfMeasure,
+		ResourceGroup:          m.ResourceGroup,
+		Currency:               m.Currency,
+	}
+}
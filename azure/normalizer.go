@@ -1,10 +1,12 @@
 package azure
 
 import (
+	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/units"
 )
 
 type Normalizer struct {
@@ -26,7 +28,8 @@ func (n *Normalizer) Normalize(usageReports []*Usage) datamodels.Reports {
 	n.log.Debug("Normalizing Azure data...")
 	var reports datamodels.Reports
 	for _, usage := range usageReports {
-		reports = append(reports, datamodels.Report{
+		normalizedQuantity, normalizedUnit := units.Normalize(usage.ConsumedQuantity, usage.UnitOfMeasure)
+		report := datamodels.Report{
 			ID:            usage.Hash(),
 			AccountNumber: usage.SubscriptionGuid,
 			AccountName:   usage.SubscriptionName,
@@ -39,7 +42,21 @@ func (n *Normalizer) Normalize(usageReports []*Usage) datamodels.Reports {
 			Region:        usage.MeterRegion,
 			UnitOfMeasure: usage.UnitOfMeasure,
 			Resource:      IAAS,
-		})
+
+			NormalizedUsageQuantity: normalizedQuantity,
+			NormalizedUnitOfMeasure: normalizedUnit,
+			ResourceID:              usage.InstanceID,
+			ResourceGroup:           usage.ResourceGroup,
+			DepartmentName:          usage.DepartmentName,
+			CostCenter:              usage.CostCenter,
+			Tags:                    datamodels.EncodeTags(datamodels.DecodeTags(usage.Tags)),
+			Currency:                usage.Currency,
+		}
+		if usage.MeterCategory == Marketplace {
+			report.ChargeCategory = datamodels.Purchase
+			report.Publisher = strings.TrimPrefix(usage.ConsumedService, Marketplace+": ")
+		}
+		reports = append(reports, report)
 	}
 	return reports
 }
@@ -60,7 +60,7 @@ var _ = Describe("Normalizer", func() {
 					ServiceInfo1:           "some-info",
 					ServiceInfo2:           "some-other-info",
 					AdditionalInfo:         "some-really-other-info",
-					Tags:                   "some-tags",
+					Tags:                   `{"team":"some-team"}`,
 					StoreServiceIdentifier: "some-identifier",
 					DepartmentName:         "some-department-name",
 					CostCenter:             "some-cost-center",
@@ -127,6 +127,14 @@ var _ = Describe("Normalizer", func() {
 						Region:        "some-region",
 						UnitOfMeasure: "Hours",
 						Resource:      "Azure",
+
+						NormalizedUsageQuantity: 24.00,
+						NormalizedUnitOfMeasure: "Hours",
+						ResourceID:              "some-instance-id",
+						ResourceGroup:           "some-group",
+						DepartmentName:          "some-department-name",
+						CostCenter:              "some-cost-center",
+						Tags:                    `{"team":"some-team"}`,
 					}))
 					Expect(reports[1]).To(Equal(datamodels.Report{
 						ID:            usageReports[1].Hash(),
@@ -141,8 +149,45 @@ var _ = Describe("Normalizer", func() {
 						Region:        "some-other-region",
 						UnitOfMeasure: "Hours",
 						Resource:      "Azure",
+
+						NormalizedUsageQuantity: 22.00,
+						NormalizedUnitOfMeasure: "Hours",
+						ResourceID:              "some-other-instance-id",
+						ResourceGroup:           "some-other-group",
+						DepartmentName:          "some-other-department-name",
+						CostCenter:              "some-other-cost-center",
 					}))
 				})
+
+				It("records the currency stated with the usage", func() {
+					usageReports[0].Currency = "EUR"
+					reports = normalizer.Normalize(usageReports)
+					Expect(reports[0].Currency).To(Equal("EUR"))
+					Expect(reports[0].ChargeCategory).To(BeEmpty())
+					Expect(reports[0].Publisher).To(BeEmpty())
+				})
+			})
+
+			Context("with marketplace charges", func() {
+				BeforeEach(func() {
+					usageReports = []*Usage{MarketplaceCharge{
+						SubscriptionGuid: "some-guid",
+						Month:            10,
+						Day:              1,
+						Year:             2016,
+						PublisherName:    "some-publisher",
+						OfferName:        "some-offer",
+						ExtendedCost:     3,
+						Currency:         "EUR",
+					}.Usage()}
+				})
+
+				It("records them as purchases from their publisher", func() {
+					Expect(reports[0].ServiceType).To(Equal("Marketplace: some-publisher"))
+					Expect(reports[0].ChargeCategory).To(Equal(datamodels.Purchase))
+					Expect(reports[0].Publisher).To(Equal("some-publisher"))
+					Expect(reports[0].Currency).To(Equal("EUR"))
+				})
 			})
 		})
 
@@ -0,0 +1,17 @@
+package azure
+
+// Price is a row of the price sheet of an enrollment: the price it pays for a
+// meter in a billing period. The price sheet report of the Enterprise
+// Agreement API only states the Service of each price, which is taken as the
+// meter name.
+type Price struct {
+	MeterID          string  `csv:"Meter ID"`
+	MeterName        string  `csv:"Service"`
+	MeterCategory    string  `csv:"Meter Category"`
+	MeterSubCategory string  `csv:"Meter Sub-Category"`
+	PartNumber       string  `csv:"Part Number"`
+	UnitOfMeasure    string  `csv:"Unit of Measure"`
+	IncludedQuantity float64 `csv:"Included Quantity"`
+	UnitPrice        float64 `csv:"Unit Price"`
+	Currency         string  `csv:"Currency Code"`
+}
@@ -21,7 +21,7 @@ type Usage struct {
 	MeterCategory          string  `csv:"Meter Category"`
 	MeterSubCategory       string  `csv:"Meter Sub-Category"`
 	MeterRegion            string  `csv:"Meter Region"`
-	MeterName              string  `csv:"Meter Name`
+	MeterName              string  `csv:"Meter Name"`
 	ConsumedQuantity       float64 `csv:"Consumed Quantity"`
 	ResourceRate           float64 `csv:"ResourceRate"`
 	ExtendedCost           float64 `csv:"ExtendedCost"`
@@ -37,6 +37,9 @@ type Usage struct {
 	CostCenter             string  `csv:"Cost Center"`
 	UnitOfMeasure          string  `csv:"Unit Of Measure"`
 	ResourceGroup          string  `csv:"Resource Group"`
+	// Currency is only stated by the Consumption API; the usage report
+	// does not have it.
+	Currency string `csv:"-"`
 }
 
 func (u Usage) Hash() string {
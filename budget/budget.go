@@ -0,0 +1,82 @@
+package budget
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/forecast"
+)
+
+const (
+	Monthly   = "monthly"
+	Quarterly = "quarterly"
+)
+
+// DefaultThresholds are the percentages of a budget that are alerted on when
+// none are configured.
+var DefaultThresholds = []int{50, 80, 100}
+
+// Budget is the amount that may be spent each period. The spend is scoped by
+// the optional Resource, Account, ServiceType and Tag (key=value, or just the
+// key); a budget without any scope covers everything.
+type Budget struct {
+	Name        string
+	Amount      float64
+	Period      string
+	Resource    string
+	Account     string
+	ServiceType string `yaml:"service-type"`
+	Tag         string
+	Thresholds  []int
+}
+
+func (b Budget) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("Budgets must have a name")
+	}
+	if b.Amount <= 0 {
+		return fmt.Errorf("Budget %s must have a positive amount", b.Name)
+	}
+	if b.Period != Monthly && b.Period != Quarterly {
+		return fmt.Errorf("Budget %s must have a %s or %s period", b.Name, Monthly, Quarterly)
+	}
+	if strings.HasPrefix(b.Tag, "=") {
+		return fmt.Errorf("Budget %s is scoped by tag %q without a key, expected key=value or key", b.Name, b.Tag)
+	}
+	for _, t := range b.Thresholds {
+		if t <= 0 {
+			return fmt.Errorf("Budget %s has a threshold of %d%%, thresholds must be positive", b.Name, t)
+		}
+	}
+	return nil
+}
+
+// Matches returns whether a cost falls within the scope of the budget.
+func (b Budget) Matches(cost datamodels.DailyCost) bool {
+	return (b.Resource == "" || b.Resource == cost.Resource) &&
+		(b.Account == "" || b.Account == cost.AccountNumber) &&
+		(b.ServiceType == "" || b.ServiceType == cost.ServiceType) &&
+		(b.Tag == "" || datamodels.HasTag(cost.Tags, b.Tag))
+}
+
+// PeriodOf returns the first and last day of the period containing date, and
+// its name (2016-10 or 2016-Q4).
+func (b Budget) PeriodOf(date time.Time) (time.Time, time.Time, string) {
+	return b.forecastPeriod().Containing(date)
+}
+
+func (b Budget) forecastPeriod() forecast.Period {
+	if b.Period == Quarterly {
+		return forecast.Quarter
+	}
+	return forecast.Month
+}
+
+func (b Budget) thresholds() []int {
+	if len(b.Thresholds) == 0 {
+		return DefaultThresholds
+	}
+	return b.Thresholds
+}
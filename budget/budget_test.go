@@ -0,0 +1,66 @@
+package budget_test
+
+import (
+	"time"
+
+	. "github.com/challiwill/meteorologica/budget"
+	"github.com/challiwill/meteorologica/datamodels"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Budget", func() {
+	Describe("PeriodOf", func() {
+		It("returns the month of a monthly budget", func() {
+			start, end, name := Budget{Period: Monthly}.PeriodOf(time.Date(2016, time.February, 10, 0, 0, 0, 0, time.UTC))
+			Expect(start).To(Equal(time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(end).To(Equal(time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC)))
+			Expect(name).To(Equal("2016-02"))
+		})
+
+		It("returns the quarter of a quarterly budget", func() {
+			start, end, name := Budget{Period: Quarterly}.PeriodOf(time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC))
+			Expect(start).To(Equal(time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(end).To(Equal(time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC)))
+			Expect(name).To(Equal("2016-Q4"))
+		})
+	})
+
+	Describe("Matches", func() {
+		It("matches costs within every configured scope", func() {
+			b := Budget{Resource: "AWS", Account: "123"}
+			Expect(b.Matches(datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "any"})).To(BeTrue())
+			Expect(b.Matches(datamodels.DailyCost{Resource: "AWS", AccountNumber: "456"})).To(BeFalse())
+			Expect(b.Matches(datamodels.DailyCost{Resource: "GCP", AccountNumber: "123"})).To(BeFalse())
+		})
+	})
+
+	Describe("Validate", func() {
+		It("accepts a complete budget", func() {
+			Expect(Budget{Name: "team", Amount: 5000, Period: Monthly}.Validate()).To(Succeed())
+		})
+
+		It("rejects unknown periods", func() {
+			Expect(Budget{Name: "team", Amount: 5000, Period: "weekly"}.Validate()).NotTo(Succeed())
+		})
+
+		It("rejects budgets without an amount", func() {
+			Expect(Budget{Name: "team", Period: Monthly}.Validate()).NotTo(Succeed())
+		})
+
+		It("rejects tags without a key", func() {
+			Expect(Budget{Name: "team", Amount: 5000, Period: Monthly, Tag: "=x"}.Validate()).To(MatchError(ContainSubstring("without a key")))
+		})
+	})
+
+	Describe("Matches", func() {
+		It("scopes by tag", func() {
+			budget := Budget{Name: "team", Amount: 5000, Period: Monthly, Resource: "Azure", Tag: "team=x"}
+			Expect(budget.Validate()).To(Succeed())
+			Expect(budget.Matches(datamodels.DailyCost{Resource: "Azure", Tags: `{"team":"x"}`})).To(BeTrue())
+			Expect(budget.Matches(datamodels.DailyCost{Resource: "Azure", Tags: `{"team":"y"}`})).To(BeFalse())
+			Expect(budget.Matches(datamodels.DailyCost{Resource: "Azure"})).To(BeFalse())
+		})
+	})
+})
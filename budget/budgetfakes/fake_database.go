@@ -0,0 +1,79 @@
+// This file was generated by counterfeiter
+package budgetfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/budget"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type FakeDatabase struct {
+	SaveBudgetAlertStub        func(datamodels.BudgetAlert) (bool, error)
+	saveBudgetAlertMutex       sync.RWMutex
+	saveBudgetAlertArgsForCall []struct {
+		arg1 datamodels.BudgetAlert
+	}
+	saveBudgetAlertReturns struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) SaveBudgetAlert(arg1 datamodels.BudgetAlert) (bool, error) {
+	fake.saveBudgetAlertMutex.Lock()
+	fake.saveBudgetAlertArgsForCall = append(fake.saveBudgetAlertArgsForCall, struct {
+		arg1 datamodels.BudgetAlert
+	}{arg1})
+	fake.recordInvocation("SaveBudgetAlert", []interface{}{arg1})
+	fake.saveBudgetAlertMutex.Unlock()
+	if fake.SaveBudgetAlertStub != nil {
+		return fake.SaveBudgetAlertStub(arg1)
+	} else {
+		return fake.saveBudgetAlertReturns.result1, fake.saveBudgetAlertReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) SaveBudgetAlertCallCount() int {
+	fake.saveBudgetAlertMutex.RLock()
+	defer fake.saveBudgetAlertMutex.RUnlock()
+	return len(fake.saveBudgetAlertArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveBudgetAlertArgsForCall(i int) datamodels.BudgetAlert {
+	fake.saveBudgetAlertMutex.RLock()
+	defer fake.saveBudgetAlertMutex.RUnlock()
+	return fake.saveBudgetAlertArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveBudgetAlertReturns(result1 bool, result2 error) {
+	fake.SaveBudgetAlertStub = nil
+	fake.saveBudgetAlertReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.saveBudgetAlertMutex.RLock()
+	defer fake.saveBudgetAlertMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ budget.Database = new(FakeDatabase)
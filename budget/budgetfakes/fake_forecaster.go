@@ -0,0 +1,85 @@
+// This file was generated by counterfeiter
+package budgetfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/budget"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/forecast"
+)
+
+type FakeForecaster struct {
+	ForecastMatchingStub        func(forecast.Period, time.Time, func(datamodels.DailyCost) bool) (datamodels.Forecast, error)
+	forecastMatchingMutex       sync.RWMutex
+	forecastMatchingArgsForCall []struct {
+		arg1 forecast.Period
+		arg2 time.Time
+		arg3 func(datamodels.DailyCost) bool
+	}
+	forecastMatchingReturns struct {
+		result1 datamodels.Forecast
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeForecaster) ForecastMatching(arg1 forecast.Period, arg2 time.Time, arg3 func(datamodels.DailyCost) bool) (datamodels.Forecast, error) {
+	fake.forecastMatchingMutex.Lock()
+	fake.forecastMatchingArgsForCall = append(fake.forecastMatchingArgsForCall, struct {
+		arg1 forecast.Period
+		arg2 time.Time
+		arg3 func(datamodels.DailyCost) bool
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("ForecastMatching", []interface{}{arg1, arg2, arg3})
+	fake.forecastMatchingMutex.Unlock()
+	if fake.ForecastMatchingStub != nil {
+		return fake.ForecastMatchingStub(arg1, arg2, arg3)
+	} else {
+		return fake.forecastMatchingReturns.result1, fake.forecastMatchingReturns.result2
+	}
+}
+
+func (fake *FakeForecaster) ForecastMatchingCallCount() int {
+	fake.forecastMatchingMutex.RLock()
+	defer fake.forecastMatchingMutex.RUnlock()
+	return len(fake.forecastMatchingArgsForCall)
+}
+
+func (fake *FakeForecaster) ForecastMatchingArgsForCall(i int) (forecast.Period, time.Time, func(datamodels.DailyCost) bool) {
+	fake.forecastMatchingMutex.RLock()
+	defer fake.forecastMatchingMutex.RUnlock()
+	return fake.forecastMatchingArgsForCall[i].arg1, fake.forecastMatchingArgsForCall[i].arg2, fake.forecastMatchingArgsForCall[i].arg3
+}
+
+func (fake *FakeForecaster) ForecastMatchingReturns(result1 datamodels.Forecast, result2 error) {
+	fake.ForecastMatchingStub = nil
+	fake.forecastMatchingReturns = struct {
+		result1 datamodels.Forecast
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeForecaster) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.forecastMatchingMutex.RLock()
+	defer fake.forecastMatchingMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeForecaster) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ budget.Forecaster = new(FakeForecaster)
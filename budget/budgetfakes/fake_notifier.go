@@ -0,0 +1,77 @@
+// This file was generated by counterfeiter
+package budgetfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/budget"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type FakeNotifier struct {
+	NotifyBudgetStub        func(datamodels.BudgetAlert) error
+	notifyBudgetMutex       sync.RWMutex
+	notifyBudgetArgsForCall []struct {
+		arg1 datamodels.BudgetAlert
+	}
+	notifyBudgetReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeNotifier) NotifyBudget(arg1 datamodels.BudgetAlert) error {
+	fake.notifyBudgetMutex.Lock()
+	fake.notifyBudgetArgsForCall = append(fake.notifyBudgetArgsForCall, struct {
+		arg1 datamodels.BudgetAlert
+	}{arg1})
+	fake.recordInvocation("NotifyBudget", []interface{}{arg1})
+	fake.notifyBudgetMutex.Unlock()
+	if fake.NotifyBudgetStub != nil {
+		return fake.NotifyBudgetStub(arg1)
+	} else {
+		return fake.notifyBudgetReturns.result1
+	}
+}
+
+func (fake *FakeNotifier) NotifyBudgetCallCount() int {
+	fake.notifyBudgetMutex.RLock()
+	defer fake.notifyBudgetMutex.RUnlock()
+	return len(fake.notifyBudgetArgsForCall)
+}
+
+func (fake *FakeNotifier) NotifyBudgetArgsForCall(i int) datamodels.BudgetAlert {
+	fake.notifyBudgetMutex.RLock()
+	defer fake.notifyBudgetMutex.RUnlock()
+	return fake.notifyBudgetArgsForCall[i].arg1
+}
+
+func (fake *FakeNotifier) NotifyBudgetReturns(result1 error) {
+	fake.NotifyBudgetStub = nil
+	fake.notifyBudgetReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotifier) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.notifyBudgetMutex.RLock()
+	defer fake.notifyBudgetMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeNotifier) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ budget.Notifier = new(FakeNotifier)
@@ -0,0 +1,144 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/forecast"
+)
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	SaveBudgetAlert(datamodels.BudgetAlert) (bool, error)
+}
+
+//go:generate counterfeiter . Forecaster
+
+type Forecaster interface {
+	ForecastMatching(forecast.Period, time.Time, func(datamodels.DailyCost) bool) (datamodels.Forecast, error)
+}
+
+//go:generate counterfeiter . Notifier
+
+// Notifier sends budget alerts wherever people will see them.
+type Notifier interface {
+	NotifyBudget(datamodels.BudgetAlert) error
+}
+
+// Evaluator compares the spend of the current period of every budget, so far
+// and forecast to the end of the period, with the budget's thresholds. Each
+// threshold is alerted on once per period.
+type Evaluator struct {
+	log        *logrus.Logger
+	location   *time.Location
+	db         Database
+	forecaster Forecaster
+	notifier   Notifier
+
+	Budgets []Budget
+}
+
+func NewEvaluator(log *logrus.Logger, location *time.Location, db Database, forecaster Forecaster, notifier Notifier, budgets []Budget) (*Evaluator, error) {
+	for _, b := range budgets {
+		err := b.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Evaluator{
+		log:        log,
+		location:   location,
+		db:         db,
+		forecaster: forecaster,
+		notifier:   notifier,
+
+		Budgets: budgets,
+	}, nil
+}
+
+func (e *Evaluator) Name() string {
+	return "budgets"
+}
+
+// Run evaluates every budget up to and including the day before the run, the
+// last day with complete data.
+func (e *Evaluator) Run(run datamodels.Run, _ datamodels.Reports) error {
+	e.log.Debug("Entering budget.Run")
+	defer e.log.Debug("Returning budget.Run")
+
+	runDate := run.StartedAt.In(e.location)
+	asOf := time.Date(runDate.Year(), runDate.Month(), runDate.Day()-1, 0, 0, 0, 0, time.UTC)
+
+	var lastErr error
+	for _, b := range e.Budgets {
+		alerts, err := e.Evaluate(b, asOf)
+		if err != nil {
+			e.log.Errorf("Failed to evaluate budget %s: %s", b.Name, err.Error())
+			lastErr = err
+			continue
+		}
+		for _, alert := range alerts {
+			alert.RunID = run.ID
+			err = e.alert(alert)
+			if err != nil {
+				e.log.Errorf("Failed to alert on budget %s: %s", b.Name, err.Error())
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+// Evaluate returns an alert for every threshold the budget's period crossed
+// as of the given day. Thresholds the actual spend crossed are alerted on as
+// such, the others as forecast if the spend is on course to cross them.
+func (e *Evaluator) Evaluate(b Budget, asOf time.Time) ([]datamodels.BudgetAlert, error) {
+	start, end, period := b.PeriodOf(asOf)
+	spend, err := e.forecaster.ForecastMatching(b.forecastPeriod(), asOf, b.Matches)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, projected := spend.Actual, spend.Forecast
+	e.log.Debugf("Budget %s has spent %.2f of %.2f for %s, forecast %.2f", b.Name, actual, b.Amount, period, projected)
+
+	alerts := []datamodels.BudgetAlert{}
+	for _, threshold := range b.thresholds() {
+		limit := b.Amount * float64(threshold) / 100
+		kind := ""
+		if actual >= limit {
+			kind = datamodels.ActualSpend
+		} else if projected >= limit {
+			kind = datamodels.ForecastSpend
+		} else {
+			continue
+		}
+		alerts = append(alerts, datamodels.BudgetAlert{
+			Budget:      b.Name,
+			Period:      period,
+			PeriodStart: start,
+			PeriodEnd:   end,
+			Kind:        kind,
+			Threshold:   threshold,
+			Amount:      b.Amount,
+			Actual:      actual,
+			Forecast:    projected,
+		})
+	}
+	return alerts, nil
+}
+
+func (e *Evaluator) alert(alert datamodels.BudgetAlert) error {
+	isNew, err := e.db.SaveBudgetAlert(alert)
+	if err != nil {
+		return err
+	}
+	if !isNew {
+		e.log.Debugf("Already alerted on %s spend of budget %s reaching %d%% for %s", alert.Kind, alert.Budget, alert.Threshold, alert.Period)
+		return nil
+	}
+	e.log.Warnf("The %s spend of budget %s for %s reached %d%% of %.2f", alert.Kind, alert.Budget, alert.Period, alert.Threshold, alert.Amount)
+	return e.notifier.NotifyBudget(alert)
+}
@@ -0,0 +1,143 @@
+package budget_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/budget"
+	"github.com/challiwill/meteorologica/budget/budgetfakes"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/forecast"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Evaluator", func() {
+	var (
+		db         *budgetfakes.FakeDatabase
+		forecaster *budgetfakes.FakeForecaster
+		notifier   *budgetfakes.FakeNotifier
+		evaluator  *Evaluator
+		budgets    []Budget
+		run        datamodels.Run
+		err        error
+	)
+
+	BeforeEach(func() {
+		db = new(budgetfakes.FakeDatabase)
+		db.SaveBudgetAlertReturns(true, nil)
+		forecaster = new(budgetfakes.FakeForecaster)
+		notifier = new(budgetfakes.FakeNotifier)
+		budgets = []Budget{{Name: "team-x", Amount: 3100, Period: Monthly, Account: "123"}}
+		run = datamodels.NewRun(time.Date(2016, time.October, 11, 6, 0, 0, 0, time.UTC))
+	})
+
+	JustBeforeEach(func() {
+		log := logrus.New()
+		log.Out = NewBuffer()
+		evaluator, err = NewEvaluator(log, time.UTC, db, forecaster, notifier, budgets)
+		Expect(err).NotTo(HaveOccurred())
+		err = evaluator.Run(run, nil)
+	})
+
+	It("forecasts the spend within the budget's scope as of the day before the run", func() {
+		Expect(forecaster.ForecastMatchingCallCount()).To(Equal(1))
+		period, asOf, matches := forecaster.ForecastMatchingArgsForCall(0)
+		Expect(period).To(Equal(forecast.Month))
+		Expect(asOf).To(Equal(time.Date(2016, time.October, 10, 0, 0, 0, 0, time.UTC)))
+		Expect(matches(datamodels.DailyCost{AccountNumber: "123"})).To(BeTrue())
+		Expect(matches(datamodels.DailyCost{AccountNumber: "456"})).To(BeFalse())
+	})
+
+	Context("when the spend is within budget", func() {
+		BeforeEach(func() {
+			forecaster.ForecastMatchingReturns(datamodels.Forecast{Actual: 100, Forecast: 310}, nil)
+		})
+
+		It("does not alert", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(notifier.NotifyBudgetCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the spend is on course to exceed the budget", func() {
+		BeforeEach(func() {
+			forecaster.ForecastMatchingReturns(datamodels.Forecast{Actual: 1000, Forecast: 3100}, nil)
+		})
+
+		It("alerts on every forecast threshold", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(notifier.NotifyBudgetCallCount()).To(Equal(3))
+			alert := notifier.NotifyBudgetArgsForCall(2)
+			Expect(alert.Budget).To(Equal("team-x"))
+			Expect(alert.Period).To(Equal("2016-10"))
+			Expect(alert.PeriodStart).To(Equal(time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(alert.PeriodEnd).To(Equal(time.Date(2016, time.October, 31, 0, 0, 0, 0, time.UTC)))
+			Expect(alert.Kind).To(Equal(datamodels.ForecastSpend))
+			Expect(alert.Threshold).To(Equal(100))
+			Expect(alert.Actual).To(Equal(1000.0))
+			Expect(alert.Forecast).To(Equal(3100.0))
+			Expect(alert.RunID).To(Equal(run.ID))
+		})
+	})
+
+	Context("when the actual spend crossed a threshold", func() {
+		BeforeEach(func() {
+			forecaster.ForecastMatchingReturns(datamodels.Forecast{Actual: 1600, Forecast: 4960}, nil)
+		})
+
+		It("alerts on the actual spend rather than the forecast", func() {
+			Expect(notifier.NotifyBudgetArgsForCall(0).Kind).To(Equal(datamodels.ActualSpend))
+			Expect(notifier.NotifyBudgetArgsForCall(0).Threshold).To(Equal(50))
+			Expect(notifier.NotifyBudgetArgsForCall(1).Kind).To(Equal(datamodels.ForecastSpend))
+		})
+	})
+
+	Context("when the alert was already sent this period", func() {
+		BeforeEach(func() {
+			forecaster.ForecastMatchingReturns(datamodels.Forecast{Actual: 1600, Forecast: 4960}, nil)
+			db.SaveBudgetAlertReturns(false, nil)
+		})
+
+		It("does not send it again", func() {
+			Expect(db.SaveBudgetAlertCallCount()).To(Equal(3))
+			Expect(notifier.NotifyBudgetCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the spend cannot be forecast", func() {
+		BeforeEach(func() {
+			forecaster.ForecastMatchingReturns(datamodels.Forecast{}, errors.New("some-error"))
+		})
+
+		It("errors", func() {
+			Expect(err).To(MatchError("some-error"))
+		})
+	})
+
+	Context("with a quarterly budget", func() {
+		BeforeEach(func() {
+			budgets[0].Period = Quarterly
+		})
+
+		It("forecasts the quarter", func() {
+			period, _, _ := forecaster.ForecastMatchingArgsForCall(0)
+			Expect(period).To(Equal(forecast.Quarter))
+		})
+	})
+
+	Context("with custom thresholds", func() {
+		BeforeEach(func() {
+			budgets[0].Thresholds = []int{90}
+			forecaster.ForecastMatchingReturns(datamodels.Forecast{Actual: 1000, Forecast: 3100}, nil)
+		})
+
+		It("only alerts on those", func() {
+			Expect(notifier.NotifyBudgetCallCount()).To(Equal(1))
+			Expect(notifier.NotifyBudgetArgsForCall(0).Threshold).To(Equal(90))
+		})
+	})
+})
@@ -18,3 +18,52 @@ func PadMonth(month time.Month) string {
 	}
 	return m
 }
+
+// RestatementWindow returns the dates of the given number of days up to and
+// including yesterday, oldest first. Providers keep restating billing data for
+// a few days after the fact so these days are fetched again on every run.
+func RestatementWindow(location *time.Location, days int) []time.Time {
+	year, month, day := YesterdaysDate(location)
+	return DaysEndingOn(time.Date(year, month, day, 0, 0, 0, 0, location), days)
+}
+
+// DaysEndingOn returns the dates of the given number of days up to and
+// including last, oldest first. At least one day is returned.
+func DaysEndingOn(last time.Time, days int) []time.Time {
+	if days < 1 {
+		days = 1
+	}
+	dates := make([]time.Time, days)
+	for i := 0; i < days; i++ {
+		dates[i] = last.AddDate(0, 0, i-days+1)
+	}
+	return dates
+}
+
+// LastDayOfEachMonth returns the latest of the given dates in each month they
+// span, oldest first. The dates must be sorted.
+func LastDayOfEachMonth(dates []time.Time) []time.Time {
+	lastDays := []time.Time{}
+	for i, date := range dates {
+		if i+1 < len(dates) && dates[i+1].Month() == date.Month() && dates[i+1].Year() == date.Year() {
+			continue
+		}
+		lastDays = append(lastDays, date)
+	}
+	return lastDays
+}
+
+// MonthContaining returns the first and last day of the month containing date,
+// at midnight UTC, and its name (2016-10).
+func MonthContaining(date time.Time) (time.Time, time.Time, string) {
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, -1), start.Format("2006-01")
+}
+
+// QuarterContaining returns the first and last day of the quarter containing
+// date, at midnight UTC, and its name (2016-Q4).
+func QuarterContaining(date time.Time) (time.Time, time.Time, string) {
+	quarter := (int(date.Month()) - 1) / 3
+	start := time.Date(date.Year(), time.Month(quarter*3+1), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 3, -1), strconv.Itoa(date.Year()) + "-Q" + strconv.Itoa(quarter+1)
+}
@@ -35,4 +35,57 @@ var _ = Describe("Calendar", func() {
 	XDescribe("YesterdaysDate", func() {
 		It("works", func() {})
 	})
+
+	Describe("DaysEndingOn", func() {
+		It("returns the days up to and including the last day, oldest first", func() {
+			Expect(DaysEndingOn(time.Date(2016, time.September, 12, 0, 0, 0, 0, time.UTC), 3)).To(Equal([]time.Time{
+				time.Date(2016, time.September, 10, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.September, 11, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.September, 12, 0, 0, 0, 0, time.UTC),
+			}))
+		})
+
+		It("crosses month boundaries", func() {
+			Expect(DaysEndingOn(time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC), 2)).To(Equal([]time.Time{
+				time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC),
+			}))
+		})
+
+		It("returns at least one day", func() {
+			Expect(DaysEndingOn(time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC), 0)).To(HaveLen(1))
+		})
+	})
+
+	Describe("LastDayOfEachMonth", func() {
+		It("returns the latest day of each month", func() {
+			Expect(LastDayOfEachMonth([]time.Time{
+				time.Date(2016, time.December, 30, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2017, time.January, 2, 0, 0, 0, 0, time.UTC),
+			})).To(Equal([]time.Time{
+				time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2017, time.January, 2, 0, 0, 0, 0, time.UTC),
+			}))
+		})
+	})
+
+	Describe("MonthContaining", func() {
+		It("returns the first and last day of the month", func() {
+			start, end, name := MonthContaining(time.Date(2016, time.February, 10, 13, 0, 0, 0, time.UTC))
+			Expect(start).To(Equal(time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(end).To(Equal(time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC)))
+			Expect(name).To(Equal("2016-02"))
+		})
+	})
+
+	Describe("QuarterContaining", func() {
+		It("returns the first and last day of the quarter", func() {
+			start, end, name := QuarterContaining(time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC))
+			Expect(start).To(Equal(time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(end).To(Equal(time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC)))
+			Expect(name).To(Equal("2016-Q4"))
+		})
+	})
 })
@@ -0,0 +1,382 @@
+package cloudfoundry
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/errare"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+var IAAS = datamodels.CloudFoundry
+
+// Client collects the usage of the apps and service instances of a
+// foundation from the app_usage_events and service_usage_events of its Cloud
+// Controller.
+type Client struct {
+	Foundation        Foundation
+	client            *http.Client
+	restatementWindow int
+	log               *logrus.Logger
+	location          *time.Location
+}
+
+func NewClient(log *logrus.Logger, location *time.Location, foundation Foundation, restatementWindow int) (*Client, error) {
+	err := foundation.Validate()
+	if err != nil {
+		return nil, errare.NewCreationError("Cloud Foundry client", err.Error())
+	}
+	client := new(http.Client)
+	if foundation.SkipSSLValidation {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	return &Client{
+		Foundation:        foundation,
+		client:            client,
+		restatementWindow: restatementWindow,
+		log:               log,
+		location:          location,
+	}, nil
+}
+
+func (c *Client) Name() string {
+	return IAAS
+}
+
+func (c *Client) GetNormalizedUsage() (datamodels.Reports, error) {
+	c.log.Info("Getting Cloud Foundry usage...")
+	c.log.Debug("Entering cloudfoundry.GetNormalizedUsage")
+	defer c.log.Debug("Returning cloudfoundry.GetNormalizedUsage")
+
+	reports, err := c.GetResourceLevelUsage()
+	if err != nil {
+		return datamodels.Reports{}, err
+	}
+	return datamodels.ConsolidateReports(reports), nil
+}
+
+// GetResourceLevelUsage returns the daily usage of each app and service
+// instance, from the start of the month of the restatement window up to the
+// end of yesterday. The Cloud Controller only keeps usage events for a while,
+// so apps and service instances whose oldest kept event stops or deletes them,
+// or that have no kept events but are started or exist now, count from before
+// their oldest kept event.
+func (c *Client) GetResourceLevelUsage() (datamodels.Reports, error) {
+	c.log.Debug("Entering cloudfoundry.GetResourceLevelUsage")
+	defer c.log.Debug("Returning cloudfoundry.GetResourceLevelUsage")
+
+	window := calendar.RestatementWindow(c.location, c.restatementWindow)
+	from := time.Date(window[0].Year(), window[0].Month(), 1, 0, 0, 0, 0, c.location)
+	to := window[len(window)-1].AddDate(0, 0, 1)
+
+	api, err := c.authenticate()
+	if err != nil {
+		return datamodels.Reports{}, err
+	}
+
+	m := newMeter(c.location, from, to)
+	cache := make(map[string]json.RawMessage)
+	orgGUIDs, err := c.meterApps(api, m, cache)
+	if err != nil {
+		return datamodels.Reports{}, err
+	}
+	serviceOrgGUIDs, err := c.meterServiceInstances(api, m, cache)
+	if err != nil {
+		return datamodels.Reports{}, err
+	}
+
+	orgNames := make(map[string]string)
+	for _, guid := range append(orgGUIDs, serviceOrgGUIDs...) {
+		if _, ok := orgNames[guid]; ok {
+			continue
+		}
+		orgNames[guid], err = c.orgName(api, guid)
+		if err != nil {
+			return datamodels.Reports{}, err
+		}
+	}
+	return m.Reports(IAAS, c.Foundation, orgNames), nil
+}
+
+type appState struct {
+	usage     usage
+	instances int
+	memoryMB  int
+	since     time.Time
+}
+
+// meterApps meters the instances and memory of every app while it was
+// started, and returns the orgs of the apps.
+func (c *Client) meterApps(api *http.Client, m *meter, cache map[string]json.RawMessage) ([]string, error) {
+	started := make(map[string]appState)
+	seen := make(map[string]bool)
+	stop := func(guid string, at time.Time) {
+		app, ok := started[guid]
+		if !ok {
+			return
+		}
+		instances := app.usage
+		instances.serviceType, instances.unit, instances.rate = AppInstances, "Hours", c.Foundation.Rates.InstanceHour
+		m.add(instances, float64(app.instances), app.since, at)
+		memory := app.usage
+		memory.serviceType, memory.unit, memory.rate = AppMemory, GiBHours, c.Foundation.Rates.MemoryGiBHour
+		m.add(memory, float64(app.instances*app.memoryMB)/1024, app.since, at)
+		delete(started, guid)
+	}
+
+	orgGUIDs := []string{}
+	next := "/v2/app_usage_events?results-per-page=100"
+	for next != "" {
+		var page appUsageEventsPage
+		err := c.get(api, next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range page.Resources {
+			e := event.Entity
+			state := appState{
+				usage:     usage{orgGUID: e.OrgGUID, spaceGUID: e.SpaceGUID, spaceName: e.SpaceName, resourceID: e.AppGUID},
+				instances: e.InstanceCount,
+				memoryMB:  e.MemoryInMBPerInstance,
+				since:     event.Metadata.CreatedAt,
+			}
+			switch e.State {
+			case Started:
+				stop(e.AppGUID, event.Metadata.CreatedAt)
+				started[e.AppGUID] = state
+				seen[e.AppGUID] = true
+				orgGUIDs = append(orgGUIDs, e.OrgGUID)
+			case Stopped:
+				if !seen[e.AppGUID] {
+					state.since = m.from
+					started[e.AppGUID] = state
+					seen[e.AppGUID] = true
+					orgGUIDs = append(orgGUIDs, e.OrgGUID)
+				}
+				stop(e.AppGUID, event.Metadata.CreatedAt)
+			}
+		}
+		next = nextURL(page.NextURL)
+	}
+
+	next = "/v2/apps?q=state:STARTED&results-per-page=100"
+	for next != "" {
+		var page appsPage
+		err := c.get(api, next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, app := range page.Resources {
+			if seen[app.Metadata.GUID] {
+				continue
+			}
+			var s space
+			err = c.lookup(api, cache, "/v2/spaces/"+url.QueryEscape(app.Entity.SpaceGUID), &s)
+			if err != nil {
+				return nil, err
+			}
+			started[app.Metadata.GUID] = appState{
+				usage:     usage{orgGUID: s.Entity.OrganizationGUID, spaceGUID: app.Entity.SpaceGUID, spaceName: s.Entity.Name, resourceID: app.Metadata.GUID},
+				instances: app.Entity.Instances,
+				memoryMB:  app.Entity.Memory,
+				since:     m.from,
+			}
+			orgGUIDs = append(orgGUIDs, s.Entity.OrganizationGUID)
+		}
+		next = nextURL(page.NextURL)
+	}
+	for guid := range started {
+		stop(guid, m.to)
+	}
+	return orgGUIDs, nil
+}
+
+type serviceInstanceState struct {
+	usage usage
+	since time.Time
+}
+
+// meterServiceInstances meters every managed service instance while it
+// existed, by service and plan, and returns the orgs of the service instances.
+func (c *Client) meterServiceInstances(api *http.Client, m *meter, cache map[string]json.RawMessage) ([]string, error) {
+	created := make(map[string]serviceInstanceState)
+	seen := make(map[string]bool)
+	remove := func(guid string, at time.Time) {
+		instance, ok := created[guid]
+		if !ok {
+			return
+		}
+		m.add(instance.usage, 1, instance.since, at)
+		delete(created, guid)
+	}
+
+	orgGUIDs := []string{}
+	next := "/v2/service_usage_events?results-per-page=100"
+	for next != "" {
+		var page serviceUsageEventsPage
+		err := c.get(api, next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range page.Resources {
+			e := event.Entity
+			if e.ServiceInstanceType == userProvidedServiceInstance {
+				continue
+			}
+			state := serviceInstanceState{
+				usage: c.serviceInstanceUsage(e.OrgGUID, e.SpaceGUID, e.SpaceName, e.ServiceLabel, e.ServicePlanName, e.ServiceInstanceGUID),
+				since: event.Metadata.CreatedAt,
+			}
+			if (e.State == Updated || e.State == Deleted) && !seen[e.ServiceInstanceGUID] {
+				existing := state
+				existing.since = m.from
+				created[e.ServiceInstanceGUID] = existing
+				orgGUIDs = append(orgGUIDs, e.OrgGUID)
+			}
+			switch e.State {
+			case Created, Updated:
+				remove(e.ServiceInstanceGUID, event.Metadata.CreatedAt)
+				created[e.ServiceInstanceGUID] = state
+				seen[e.ServiceInstanceGUID] = true
+				orgGUIDs = append(orgGUIDs, e.OrgGUID)
+			case Deleted:
+				remove(e.ServiceInstanceGUID, event.Metadata.CreatedAt)
+				seen[e.ServiceInstanceGUID] = true
+			}
+		}
+		next = nextURL(page.NextURL)
+	}
+
+	next = "/v2/service_instances?results-per-page=100"
+	for next != "" {
+		var page serviceInstancesPage
+		err := c.get(api, next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range page.Resources {
+			if seen[instance.Metadata.GUID] {
+				continue
+			}
+			var (
+				s    space
+				plan servicePlan
+				svc  service
+			)
+			err = c.lookup(api, cache, "/v2/spaces/"+url.QueryEscape(instance.Entity.SpaceGUID), &s)
+			if err != nil {
+				return nil, err
+			}
+			err = c.lookup(api, cache, "/v2/service_plans/"+url.QueryEscape(instance.Entity.ServicePlanGUID), &plan)
+			if err != nil {
+				return nil, err
+			}
+			err = c.lookup(api, cache, "/v2/services/"+url.QueryEscape(plan.Entity.ServiceGUID), &svc)
+			if err != nil {
+				return nil, err
+			}
+			created[instance.Metadata.GUID] = serviceInstanceState{
+				usage: c.serviceInstanceUsage(s.Entity.OrganizationGUID, instance.Entity.SpaceGUID, s.Entity.Name, svc.Entity.Label, plan.Entity.Name, instance.Metadata.GUID),
+				since: m.from,
+			}
+			orgGUIDs = append(orgGUIDs, s.Entity.OrganizationGUID)
+		}
+		next = nextURL(page.NextURL)
+	}
+	for guid := range created {
+		remove(guid, m.to)
+	}
+	return orgGUIDs, nil
+}
+
+func (c *Client) serviceInstanceUsage(orgGUID, spaceGUID, spaceName, label, plan, guid string) usage {
+	return usage{
+		orgGUID:     orgGUID,
+		spaceGUID:   spaceGUID,
+		spaceName:   spaceName,
+		serviceType: strings.TrimSpace(ServiceInstances + ": " + label + " " + plan),
+		unit:        "Hours",
+		rate:        c.Foundation.Rates.ServiceInstanceHour,
+		resourceID:  guid,
+	}
+}
+
+// lookup gets the resource at the path once, keeping it in the cache for the
+// apps and service instances that share it.
+func (c *Client) lookup(api *http.Client, cache map[string]json.RawMessage, path string, v interface{}) error {
+	raw, ok := cache[path]
+	if !ok {
+		err := c.get(api, path, &raw)
+		if err != nil {
+			return err
+		}
+		cache[path] = raw
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// orgName is the name of the org, or its GUID if it has been deleted.
+func (c *Client) orgName(api *http.Client, guid string) (string, error) {
+	var org organization
+	err := c.get(api, "/v2/organizations/"+url.QueryEscape(guid), &org)
+	if err == errNotFound {
+		return guid, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return org.Entity.Name, nil
+}
+
+// authenticate returns a client that makes requests with a token of the UAA
+// the Cloud Controller uses.
+func (c *Client) authenticate() (*http.Client, error) {
+	var i info
+	err := c.get(c.client, "/v2/info", &i)
+	if err != nil {
+		return nil, err
+	}
+	config := &clientcredentials.Config{
+		ClientID:     c.Foundation.ClientID,
+		ClientSecret: c.Foundation.ClientSecret,
+		TokenURL:     strings.TrimRight(i.TokenEndpoint, "/") + "/oauth/token",
+	}
+	return config.Client(context.WithValue(oauth2.NoContext, oauth2.HTTPClient, c.client)), nil
+}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string { return "Not found" }
+
+var errNotFound error = notFoundError{}
+
+func (c *Client) get(client *http.Client, path string, v interface{}) error {
+	resp, err := client.Get(strings.TrimRight(c.Foundation.API, "/") + path)
+	if err != nil {
+		return errare.NewRequestError(err, IAAS)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errare.NewResponseError(resp.Status, IAAS)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func nextURL(next *string) string {
+	if next == nil {
+		return ""
+	}
+	return *next
+}
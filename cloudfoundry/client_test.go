@@ -0,0 +1,312 @@
+package cloudfoundry_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/cloudfoundry"
+	"github.com/challiwill/meteorologica/datamodels"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+func appEvent(state, app, org, space string, instances, memory int, at time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"guid": "event-" + app, "created_at": at.Format(time.RFC3339)},
+		"entity": map[string]interface{}{
+			"state":                     state,
+			"app_guid":                  app,
+			"app_name":                  app + "-name",
+			"space_guid":                space,
+			"space_name":                space + "-name",
+			"org_guid":                  org,
+			"instance_count":            instances,
+			"memory_in_mb_per_instance": memory,
+		},
+	}
+}
+
+func serviceEvent(state, instance, instanceType, label, plan, org, space string, at time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"guid": "event-" + instance, "created_at": at.Format(time.RFC3339)},
+		"entity": map[string]interface{}{
+			"state":                 state,
+			"service_instance_guid": instance,
+			"service_instance_name": instance + "-name",
+			"service_instance_type": instanceType,
+			"service_label":         label,
+			"service_plan_name":     plan,
+			"space_guid":            space,
+			"space_name":            space + "-name",
+			"org_guid":              org,
+		},
+	}
+}
+
+func app(guid, space string, instances, memory int) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"guid": guid},
+		"entity": map[string]interface{}{
+			"name":       guid + "-name",
+			"space_guid": space,
+			"state":      "STARTED",
+			"instances":  instances,
+			"memory":     memory,
+		},
+	}
+}
+
+func serviceInstance(guid, space, plan string) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"guid": guid},
+		"entity": map[string]interface{}{
+			"name":              guid + "-name",
+			"space_guid":        space,
+			"service_plan_guid": plan,
+		},
+	}
+}
+
+func page(next string, events ...map[string]interface{}) map[string]interface{} {
+	p := map[string]interface{}{"resources": events, "next_url": nil}
+	if next != "" {
+		p["next_url"] = next
+	}
+	return p
+}
+
+var _ = Describe("Client", func() {
+	var (
+		log        *logrus.Logger
+		server     *httptest.Server
+		foundation Foundation
+		yesterday  time.Time
+		from       time.Time
+		responses  map[string]interface{}
+		tokens     int
+	)
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		year, month, day := time.Now().UTC().Date()
+		yesterday = time.Date(year, month, day-1, 0, 0, 0, 0, time.UTC)
+		from = time.Date(yesterday.Year(), yesterday.Month(), 1, 0, 0, 0, 0, time.UTC)
+		tokens = 0
+
+		responses = map[string]interface{}{
+			"/v2/app_usage_events?results-per-page=100": page("/v2/app_usage_events?page=2",
+				appEvent("STARTED", "app-1", "org-1", "space-1", 2, 512, yesterday.Add(6*time.Hour)),
+				appEvent("BUILDPACK_SET", "app-1", "org-1", "space-1", 2, 512, yesterday.Add(7*time.Hour)),
+				appEvent("STARTED", "app-old", "org-gone", "space-3", 1, 1024, from.AddDate(0, 0, -10)),
+				appEvent("STOPPED", "app-old", "org-gone", "space-3", 1, 1024, yesterday.Add(time.Hour)),
+			),
+			"/v2/app_usage_events?page=2": page("",
+				appEvent("STARTED", "app-1", "org-1", "space-1", 4, 512, yesterday.Add(12*time.Hour)),
+				appEvent("STOPPED", "app-1", "org-1", "space-1", 4, 512, yesterday.Add(18*time.Hour)),
+				appEvent("STARTED", "app-2", "org-1", "space-2", 1, 1024, yesterday.Add(20*time.Hour)),
+				appEvent("STOPPED", "app-pruned", "org-1", "space-3", 2, 256, yesterday.Add(3*time.Hour)),
+			),
+			"/v2/apps?q=state:STARTED&results-per-page=100": page("",
+				app("app-2", "space-2", 1, 1024),
+				app("app-quiet", "space-4", 3, 2048),
+			),
+			"/v2/service_usage_events?results-per-page=100": page("",
+				serviceEvent("CREATED", "db-1", "managed_service_instance", "p-mysql", "100mb", "org-1", "space-1", yesterday),
+				serviceEvent("CREATED", "ups-1", "user_provided_service_instance", "", "", "org-1", "space-1", yesterday),
+				serviceEvent("UPDATED", "db-1", "managed_service_instance", "p-mysql", "1gb", "org-1", "space-1", yesterday.Add(12*time.Hour)),
+				serviceEvent("DELETED", "db-pruned", "managed_service_instance", "p-mysql", "100mb", "org-1", "space-1", yesterday.Add(6*time.Hour)),
+			),
+			"/v2/service_instances?results-per-page=100": page("",
+				serviceInstance("db-1", "space-1", "plan-1gb"),
+				serviceInstance("cache-quiet", "space-4", "plan-small"),
+			),
+			"/v2/spaces/space-2":           map[string]interface{}{"entity": map[string]interface{}{"name": "space-2-name", "organization_guid": "org-1"}},
+			"/v2/spaces/space-4":           map[string]interface{}{"entity": map[string]interface{}{"name": "space-4-name", "organization_guid": "org-1"}},
+			"/v2/service_plans/plan-small": map[string]interface{}{"entity": map[string]interface{}{"name": "small", "service_guid": "svc-redis"}},
+			"/v2/services/svc-redis":       map[string]interface{}{"entity": map[string]interface{}{"label": "p-redis"}},
+			"/v2/organizations/org-1":      map[string]interface{}{"entity": map[string]interface{}{"name": "engineering"}},
+		}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/info":
+				json.NewEncoder(w).Encode(map[string]string{"token_endpoint": "http://" + r.Host + "/uaa"})
+				return
+			case "/uaa/oauth/token":
+				id, secret, _ := r.BasicAuth()
+				if id != "some-client" || secret != "some-secret" || r.FormValue("grant_type") != "client_credentials" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				tokens++
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"access_token": "some-token", "token_type": "bearer", "expires_in": 3600}`)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer some-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			response, ok := responses[r.URL.RequestURI()]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(response)
+		}))
+
+		foundation = Foundation{
+			API:           server.URL,
+			ClientID:      "some-client",
+			ClientSecret:  "some-secret",
+			AccountNumber: "123456789",
+			AccountName:   "platform",
+			Region:        "us-east-1",
+			Rates:         Rates{InstanceHour: 0.1},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("requires the API, client and IAAS account to be configured", func() {
+		_, err := NewClient(log, time.UTC, Foundation{API: server.URL}, 1)
+		Expect(err).To(MatchError(ContainSubstring("Cloud Foundry requires api, client-id and client-secret to be configured")))
+		foundation.AccountNumber = ""
+		_, err = NewClient(log, time.UTC, foundation, 1)
+		Expect(err).To(MatchError(ContainSubstring("account-number")))
+	})
+
+	Describe("GetResourceLevelUsage", func() {
+		var reports datamodels.Reports
+
+		BeforeEach(func() {
+			client, err := NewClient(log, time.UTC, foundation, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Name()).To(Equal("CloudFoundry"))
+			reports, err = client.GetResourceLevelUsage()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tokens).To(Equal(1))
+		})
+
+		find := func(resourceID, serviceType string, date time.Time) datamodels.Report {
+			for _, r := range reports {
+				if r.ResourceID == resourceID && r.ServiceType == serviceType && r.Year == date.Year() && r.Month == date.Month() && r.Day == date.Day() {
+					return r
+				}
+			}
+			Fail(fmt.Sprintf("No %s report of %s on %s", serviceType, resourceID, date.Format("2006-01-02")))
+			return datamodels.Report{}
+		}
+
+		It("meters the instance hours and memory of each app while it was started, each day", func() {
+			instances := find("app-1", AppInstances, yesterday)
+			Expect(instances.UsageQuantity).To(BeNumerically("~", 2*6+4*6))
+			Expect(instances.UnitOfMeasure).To(Equal("Hours"))
+			Expect(instances.Cost).To(BeNumerically("~", 3.6))
+			memory := find("app-1", AppMemory, yesterday)
+			Expect(memory.UsageQuantity).To(BeNumerically("~", 2*0.5*6+4*0.5*6))
+			Expect(memory.UnitOfMeasure).To(Equal("GiB-Hours"))
+			Expect(memory.NormalizedUnitOfMeasure).To(Equal("GiB-Months"))
+			Expect(memory.Cost).To(BeZero())
+		})
+
+		It("meters apps that are still started up to the end of yesterday", func() {
+			Expect(find("app-2", AppInstances, yesterday).UsageQuantity).To(BeNumerically("~", 4))
+			Expect(find("app-2", AppMemory, yesterday).UsageQuantity).To(BeNumerically("~", 4))
+		})
+
+		It("meters from the start of the month of the restatement window", func() {
+			Expect(find("app-old", AppInstances, from).UsageQuantity).To(BeNumerically("~", 24))
+			Expect(find("app-old", AppInstances, yesterday).UsageQuantity).To(BeNumerically("~", 1))
+			for _, r := range reports {
+				Expect(time.Date(r.Year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)).NotTo(BeTemporally("<", from))
+			}
+		})
+
+		It("meters apps whose start is no longer kept from the start of the month", func() {
+			Expect(find("app-pruned", AppInstances, from).UsageQuantity).To(BeNumerically("~", 2*24))
+			Expect(find("app-pruned", AppInstances, yesterday).UsageQuantity).To(BeNumerically("~", 2*3))
+			Expect(find("app-pruned", AppInstances, yesterday).Space).To(Equal("space-3-name"))
+		})
+
+		It("meters started apps without kept events for the whole time", func() {
+			quiet := find("app-quiet", AppInstances, yesterday)
+			Expect(quiet.UsageQuantity).To(BeNumerically("~", 3*24))
+			Expect(quiet.Org).To(Equal("engineering"))
+			Expect(quiet.Space).To(Equal("space-4-name"))
+			Expect(find("app-quiet", AppMemory, from).UsageQuantity).To(BeNumerically("~", 3*2*24))
+		})
+
+		It("meters service instances whose creation is no longer kept", func() {
+			Expect(find("db-pruned", "Service Instances: p-mysql 100mb", yesterday).UsageQuantity).To(BeNumerically("~", 6))
+			quiet := find("cache-quiet", "Service Instances: p-redis small", from)
+			Expect(quiet.UsageQuantity).To(BeNumerically("~", 24))
+			Expect(quiet.Space).To(Equal("space-4-name"))
+		})
+
+		It("meters the hours of each managed service instance by service and plan", func() {
+			Expect(find("db-1", "Service Instances: p-mysql 100mb", yesterday).UsageQuantity).To(BeNumerically("~", 12))
+			Expect(find("db-1", "Service Instances: p-mysql 1gb", yesterday).UsageQuantity).To(BeNumerically("~", 12))
+			for _, r := range reports {
+				Expect(r.ResourceID).NotTo(Equal("ups-1"))
+			}
+		})
+
+		It("reports for the IAAS account of the foundation, with the org and space", func() {
+			report := find("app-1", AppInstances, yesterday)
+			Expect(report.Resource).To(Equal("CloudFoundry"))
+			Expect(report.AccountNumber).To(Equal("123456789"))
+			Expect(report.AccountName).To(Equal("platform"))
+			Expect(report.Region).To(Equal("us-east-1"))
+			Expect(report.Org).To(Equal("engineering"))
+			Expect(report.Space).To(Equal("space-1-name"))
+			Expect(report.DepartmentName).To(BeEmpty())
+			Expect(report.CostCenter).To(BeEmpty())
+		})
+
+		It("uses the GUID of orgs that no longer exist", func() {
+			Expect(find("app-old", AppInstances, yesterday).Org).To(Equal("org-gone"))
+		})
+	})
+
+	Describe("GetNormalizedUsage", func() {
+		It("consolidates the usage of the apps of each org and space", func() {
+			responses["/v2/app_usage_events?page=2"] = page("",
+				appEvent("STARTED", "app-3", "org-1", "space-1", 1, 256, yesterday.Add(22*time.Hour)),
+			)
+			client, err := NewClient(log, time.UTC, foundation, 1)
+			Expect(err).NotTo(HaveOccurred())
+			reports, err := client.GetNormalizedUsage()
+			Expect(err).NotTo(HaveOccurred())
+
+			var instances datamodels.Reports
+			for _, r := range reports {
+				Expect(r.ResourceID).To(BeEmpty())
+				if r.ServiceType == AppInstances && r.Space == "space-1-name" && r.Day == yesterday.Day() && r.Month == yesterday.Month() {
+					instances = append(instances, r)
+				}
+			}
+			Expect(instances).To(HaveLen(1))
+			Expect(instances[0].UsageQuantity).To(BeNumerically("~", 2*18+2))
+		})
+	})
+
+	It("returns an error when the Cloud Controller does", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		client, err := NewClient(log, time.UTC, foundation, 1)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.GetNormalizedUsage()
+		Expect(err).To(MatchError("CloudFoundry responded with error: 500 Internal Server Error"))
+	})
+})
@@ -0,0 +1,122 @@
+package cloudfoundry
+
+import "time"
+
+// The states of usage events that start and stop usage.
+const (
+	Started = "STARTED"
+	Stopped = "STOPPED"
+
+	Created = "CREATED"
+	Updated = "UPDATED"
+	Deleted = "DELETED"
+)
+
+const userProvidedServiceInstance = "user_provided_service_instance"
+
+type metadata struct {
+	GUID      string    `json:"guid"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AppUsageEvent struct {
+	Metadata metadata `json:"metadata"`
+	Entity   struct {
+		State                 string `json:"state"`
+		AppGUID               string `json:"app_guid"`
+		AppName               string `json:"app_name"`
+		SpaceGUID             string `json:"space_guid"`
+		SpaceName             string `json:"space_name"`
+		OrgGUID               string `json:"org_guid"`
+		InstanceCount         int    `json:"instance_count"`
+		MemoryInMBPerInstance int    `json:"memory_in_mb_per_instance"`
+	} `json:"entity"`
+}
+
+type ServiceUsageEvent struct {
+	Metadata metadata `json:"metadata"`
+	Entity   struct {
+		State               string `json:"state"`
+		ServiceInstanceGUID string `json:"service_instance_guid"`
+		ServiceInstanceName string `json:"service_instance_name"`
+		ServiceInstanceType string `json:"service_instance_type"`
+		ServiceLabel        string `json:"service_label"`
+		ServicePlanName     string `json:"service_plan_name"`
+		SpaceGUID           string `json:"space_guid"`
+		SpaceName           string `json:"space_name"`
+		OrgGUID             string `json:"org_guid"`
+	} `json:"entity"`
+}
+
+type appUsageEventsPage struct {
+	NextURL   *string         `json:"next_url"`
+	Resources []AppUsageEvent `json:"resources"`
+}
+
+type serviceUsageEventsPage struct {
+	NextURL   *string             `json:"next_url"`
+	Resources []ServiceUsageEvent `json:"resources"`
+}
+
+type organization struct {
+	Entity struct {
+		Name string `json:"name"`
+	} `json:"entity"`
+}
+
+type info struct {
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+// App is an app as the Cloud Controller currently knows it.
+type App struct {
+	Metadata metadata `json:"metadata"`
+	Entity   struct {
+		Name      string `json:"name"`
+		SpaceGUID string `json:"space_guid"`
+		State     string `json:"state"`
+		Instances int    `json:"instances"`
+		Memory    int    `json:"memory"`
+	} `json:"entity"`
+}
+
+// ServiceInstance is a managed service instance as the Cloud Controller
+// currently knows it.
+type ServiceInstance struct {
+	Metadata metadata `json:"metadata"`
+	Entity   struct {
+		Name            string `json:"name"`
+		SpaceGUID       string `json:"space_guid"`
+		ServicePlanGUID string `json:"service_plan_guid"`
+	} `json:"entity"`
+}
+
+type appsPage struct {
+	NextURL   *string `json:"next_url"`
+	Resources []App   `json:"resources"`
+}
+
+type serviceInstancesPage struct {
+	NextURL   *string           `json:"next_url"`
+	Resources []ServiceInstance `json:"resources"`
+}
+
+type space struct {
+	Entity struct {
+		Name             string `json:"name"`
+		OrganizationGUID string `json:"organization_guid"`
+	} `json:"entity"`
+}
+
+type servicePlan struct {
+	Entity struct {
+		Name        string `json:"name"`
+		ServiceGUID string `json:"service_guid"`
+	} `json:"entity"`
+}
+
+type service struct {
+	Entity struct {
+		Label string `json:"label"`
+	} `json:"entity"`
+}
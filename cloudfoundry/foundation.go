@@ -0,0 +1,37 @@
+package cloudfoundry
+
+import "fmt"
+
+// Foundation is a Cloud Foundry deployment and the IAAS account it runs in.
+type Foundation struct {
+	// API is the URL of the Cloud Controller.
+	API               string
+	ClientID          string `yaml:"client-id"`
+	ClientSecret      string `yaml:"client-secret"`
+	SkipSSLValidation bool   `yaml:"skip-ssl-validation"`
+
+	// AccountNumber and AccountName are of the IAAS account the foundation
+	// runs in, so its reports can be joined with the reports of the account.
+	AccountNumber string `yaml:"account-number"`
+	AccountName   string `yaml:"account-name"`
+	Region        string
+
+	Rates Rates
+}
+
+// Rates are what the usage of a foundation is charged at, if it is.
+type Rates struct {
+	InstanceHour        float64 `yaml:"instance-hour"`
+	MemoryGiBHour       float64 `yaml:"memory-gib-hour"`
+	ServiceInstanceHour float64 `yaml:"service-instance-hour"`
+}
+
+func (f Foundation) Validate() error {
+	if f.API == "" || f.ClientID == "" || f.ClientSecret == "" {
+		return fmt.Errorf("Cloud Foundry requires api, client-id and client-secret to be configured")
+	}
+	if f.AccountNumber == "" {
+		return fmt.Errorf("Cloud Foundry requires the account-number of the IAAS account it runs in to be configured")
+	}
+	return nil
+}
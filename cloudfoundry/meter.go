@@ -0,0 +1,126 @@
+package cloudfoundry
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/units"
+)
+
+// The service types of the reports of a foundation.
+const (
+	AppInstances     = "App Instances"
+	AppMemory        = "App Memory"
+	ServiceInstances = "Service Instances"
+)
+
+// GiBHours is the unit of AppMemory.
+const GiBHours = "GiB-Hours"
+
+// usage is what a report is for.
+type usage struct {
+	date        time.Time
+	orgGUID     string
+	spaceGUID   string
+	spaceName   string
+	serviceType string
+	unit        string
+	rate        float64
+	resourceID  string
+}
+
+// meter adds up how much of each usage there was each day between from and
+// to, from intervals of time usage was running at some amount per hour.
+type meter struct {
+	location *time.Location
+	from     time.Time
+	to       time.Time
+
+	quantities map[usage]float64
+}
+
+func newMeter(location *time.Location, from, to time.Time) *meter {
+	return &meter{
+		location:   location,
+		from:       from,
+		to:         to,
+		quantities: make(map[usage]float64),
+	}
+}
+
+// add records the usage running at perHour from start until end, split
+// across the days of the interval.
+func (m *meter) add(u usage, perHour float64, start, end time.Time) {
+	if start.Before(m.from) {
+		start = m.from
+	}
+	if end.After(m.to) {
+		end = m.to
+	}
+	for start.Before(end) {
+		year, month, day := start.In(m.location).Date()
+		u.date = time.Date(year, month, day, 0, 0, 0, 0, m.location)
+		next := u.date.AddDate(0, 0, 1)
+		if next.After(end) {
+			next = end
+		}
+		m.quantities[u] += perHour * next.Sub(start).Hours()
+		start = next
+	}
+}
+
+// Reports returns a report of each usage for the foundation, with the names
+// of the orgs.
+func (m *meter) Reports(resource string, foundation Foundation, orgNames map[string]string) datamodels.Reports {
+	usages := usages{}
+	for u := range m.quantities {
+		usages = append(usages, u)
+	}
+	sort.Sort(usages)
+
+	reports := datamodels.Reports{}
+	for _, u := range usages {
+		quantity := m.quantities[u]
+		normalizedQuantity, normalizedUnit := units.Normalize(quantity, u.unit)
+		report := datamodels.Report{
+			AccountNumber: foundation.AccountNumber,
+			AccountName:   foundation.AccountName,
+			Day:           u.date.Day(),
+			Month:         u.date.Month(),
+			Year:          u.date.Year(),
+			ServiceType:   u.serviceType,
+			Region:        foundation.Region,
+			Resource:      resource,
+			UsageQuantity: quantity,
+			UnitOfMeasure: u.unit,
+			Cost:          quantity * u.rate,
+			ResourceID:    u.resourceID,
+			Org:           orgNames[u.orgGUID],
+			Space:         u.spaceName,
+
+			NormalizedUsageQuantity: normalizedQuantity,
+			NormalizedUnitOfMeasure: normalizedUnit,
+		}
+		h := fnv.New64a()
+		h.Write([]byte(report.AccountNumber + report.ServiceType + report.Region + report.Resource + u.orgGUID + u.spaceGUID))
+		report.ID = strconv.FormatUint(uint64(h.Sum64()), 10) + fmt.Sprintf("%04d%02d%02d", report.Year, int(report.Month), report.Day)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+type usages []usage
+
+func (u usages) Len() int      { return len(u) }
+func (u usages) Swap(i, j int) { u[i], u[j] = u[j], u[i] }
+func (u usages) Less(i, j int) bool {
+	return u[i].sortKey() < u[j].sortKey()
+}
+
+func (u usage) sortKey() string {
+	return fmt.Sprintf("%s %s %s %s %s", u.date.Format("2006-01-02"), u.orgGUID, u.spaceGUID, u.serviceType, u.resourceID)
+}
@@ -0,0 +1,82 @@
+// This file was generated by counterfeiter
+package comparefakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/compare"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type FakeDatabase struct {
+	GetDailyCostsStub        func(time.Time, time.Time) ([]datamodels.DailyCost, error)
+	getDailyCostsMutex       sync.RWMutex
+	getDailyCostsArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getDailyCostsReturns struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) GetDailyCosts(arg1 time.Time, arg2 time.Time) ([]datamodels.DailyCost, error) {
+	fake.getDailyCostsMutex.Lock()
+	fake.getDailyCostsArgsForCall = append(fake.getDailyCostsArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetDailyCosts", []interface{}{arg1, arg2})
+	fake.getDailyCostsMutex.Unlock()
+	if fake.GetDailyCostsStub != nil {
+		return fake.GetDailyCostsStub(arg1, arg2)
+	} else {
+		return fake.getDailyCostsReturns.result1, fake.getDailyCostsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetDailyCostsCallCount() int {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return len(fake.getDailyCostsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetDailyCostsArgsForCall(i int) (time.Time, time.Time) {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return fake.getDailyCostsArgsForCall[i].arg1, fake.getDailyCostsArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) GetDailyCostsReturns(result1 []datamodels.DailyCost, result2 error) {
+	fake.GetDailyCostsStub = nil
+	fake.getDailyCostsReturns = struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ compare.Database = new(FakeDatabase)
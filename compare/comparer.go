@@ -0,0 +1,164 @@
+package compare
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// DefaultTop is how many of the largest increases and decreases are ranked
+// when no number is given.
+const DefaultTop = 10
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+}
+
+type Comparer struct {
+	log *logrus.Logger
+	db  Database
+}
+
+func NewComparer(log *logrus.Logger, db Database) *Comparer {
+	return &Comparer{log: log, db: db}
+}
+
+// Compare returns how the cost of every service of every account changed
+// between the periods, with the top largest increases and decreases.
+func (c *Comparer) Compare(current, previous Period, top int) (datamodels.Comparison, error) {
+	c.log.Debug("Entering compare.Compare")
+	defer c.log.Debug("Returning compare.Compare")
+
+	comparison := datamodels.Comparison{Previous: previous.Name, Current: current.Name}
+	previousCosts, err := c.db.GetDailyCosts(previous.From, previous.To)
+	if err != nil {
+		return comparison, err
+	}
+	currentCosts, err := c.db.GetDailyCosts(current.From, current.To)
+	if err != nil {
+		return comparison, err
+	}
+
+	services := make(map[lineKey]*datamodels.ComparisonLine)
+	accounts := make(map[lineKey]*datamodels.ComparisonLine)
+	add := func(costs []datamodels.DailyCost, isCurrent bool) {
+		for _, cost := range costs {
+			for _, key := range []lineKey{
+				{cost.Resource, cost.AccountNumber, cost.ServiceType},
+				{cost.Resource, cost.AccountNumber, ""},
+			} {
+				lines := services
+				if key.serviceType == "" {
+					lines = accounts
+				}
+				line, ok := lines[key]
+				if !ok {
+					line = &datamodels.ComparisonLine{Resource: key.resource, AccountNumber: key.accountNumber, ServiceType: key.serviceType}
+					lines[key] = line
+				}
+				if cost.AccountName != "" {
+					line.AccountName = cost.AccountName
+				}
+				if isCurrent {
+					line.Current += cost.Cost
+				} else {
+					line.Previous += cost.Cost
+				}
+			}
+			if isCurrent {
+				comparison.CurrentTotal += cost.Cost
+			} else {
+				comparison.PreviousTotal += cost.Cost
+			}
+		}
+	}
+	add(previousCosts, false)
+	add(currentCosts, true)
+
+	comparison.Lines = finish(services)
+	comparison.NewServices = withStatus(comparison.Lines, datamodels.New)
+	comparison.DisappearedServices = withStatus(comparison.Lines, datamodels.Disappeared)
+	accountLines := finish(accounts)
+	comparison.NewAccounts = withStatus(accountLines, datamodels.New)
+	comparison.DisappearedAccounts = withStatus(accountLines, datamodels.Disappeared)
+
+	byChange := make(changeSlice, len(comparison.Lines))
+	copy(byChange, comparison.Lines)
+	sort.Sort(byChange)
+	comparison.TopIncreases = []datamodels.ComparisonLine{}
+	for i := len(byChange) - 1; i >= 0 && len(comparison.TopIncreases) < top && byChange[i].Change > 0; i-- {
+		comparison.TopIncreases = append(comparison.TopIncreases, byChange[i])
+	}
+	comparison.TopDecreases = []datamodels.ComparisonLine{}
+	for i := 0; i < len(byChange) && len(comparison.TopDecreases) < top && byChange[i].Change < 0; i++ {
+		comparison.TopDecreases = append(comparison.TopDecreases, byChange[i])
+	}
+	return comparison, nil
+}
+
+type lineKey struct {
+	resource      string
+	accountNumber string
+	serviceType   string
+}
+
+// finish works out the change of every line and returns them sorted.
+func finish(lines map[lineKey]*datamodels.ComparisonLine) []datamodels.ComparisonLine {
+	sorted := lineSlice{}
+	for _, line := range lines {
+		line.Change = line.Current - line.Previous
+		switch {
+		case line.Previous == 0 && line.Current != 0:
+			line.Status = datamodels.New
+		case line.Previous != 0 && line.Current == 0:
+			line.Status = datamodels.Disappeared
+		default:
+			line.Status = datamodels.Changed
+		}
+		if line.Previous != 0 {
+			line.ChangePercent = round(line.Change / math.Abs(line.Previous) * 100)
+		}
+		sorted = append(sorted, *line)
+	}
+	sort.Sort(sorted)
+	return sorted
+}
+
+func withStatus(lines []datamodels.ComparisonLine, status string) []datamodels.ComparisonLine {
+	matching := []datamodels.ComparisonLine{}
+	for _, line := range lines {
+		if line.Status == status {
+			matching = append(matching, line)
+		}
+	}
+	return matching
+}
+
+func round(f float64) float64 {
+	return math.Floor(f*100+0.5) / 100
+}
+
+type lineSlice []datamodels.ComparisonLine
+
+func (s lineSlice) Len() int      { return len(s) }
+func (s lineSlice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s lineSlice) Less(i, j int) bool {
+	if s[i].Resource != s[j].Resource {
+		return s[i].Resource < s[j].Resource
+	}
+	if s[i].AccountNumber != s[j].AccountNumber {
+		return s[i].AccountNumber < s[j].AccountNumber
+	}
+	return s[i].ServiceType < s[j].ServiceType
+}
+
+type changeSlice []datamodels.ComparisonLine
+
+func (s changeSlice) Len() int           { return len(s) }
+func (s changeSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s changeSlice) Less(i, j int) bool { return s[i].Change < s[j].Change }
@@ -0,0 +1,160 @@
+package compare_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/compare"
+	"github.com/challiwill/meteorologica/compare/comparefakes"
+	"github.com/challiwill/meteorologica/datamodels"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Comparer", func() {
+	var (
+		db                *comparefakes.FakeDatabase
+		comparer          *Comparer
+		current, previous Period
+	)
+
+	cost := func(resource, account, service string, day int, month time.Month, amount float64) datamodels.DailyCost {
+		return datamodels.DailyCost{Resource: resource, AccountNumber: account, AccountName: "name-" + account, ServiceType: service, Day: day, Month: month, Year: 2016, Cost: amount}
+	}
+
+	BeforeEach(func() {
+		db = new(comparefakes.FakeDatabase)
+		db.GetDailyCostsStub = func(from, to time.Time) ([]datamodels.DailyCost, error) {
+			if from.Month() == time.August {
+				return []datamodels.DailyCost{
+					cost("AWS", "1", "EC2", 1, time.August, 100),
+					cost("AWS", "1", "EC2", 2, time.August, 100),
+					cost("AWS", "1", "S3", 1, time.August, 50),
+					cost("GCP", "2", "Compute", 1, time.August, 30),
+					cost("AWS", "3", "EC2", 1, time.August, 10),
+				}, nil
+			}
+			return []datamodels.DailyCost{
+				cost("AWS", "1", "EC2", 1, time.September, 150),
+				cost("AWS", "1", "S3", 1, time.September, 20),
+				cost("AWS", "1", "RDS", 1, time.September, 40),
+				cost("GCP", "2", "Compute", 1, time.September, 30),
+				cost("Azure", "4", "Storage", 1, time.September, 5),
+			}, nil
+		}
+		log := logrus.New()
+		log.Out = NewBuffer()
+		comparer = NewComparer(log, db)
+		current, _ = ParsePeriod("2016-09")
+		previous = current.Previous()
+	})
+
+	It("reads the costs of both periods", func() {
+		_, err := comparer.Compare(current, previous, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.GetDailyCostsCallCount()).To(Equal(2))
+		from, to := db.GetDailyCostsArgsForCall(0)
+		Expect(from).To(Equal(previous.From))
+		Expect(to).To(Equal(previous.To))
+	})
+
+	It("works out the change of every service of every account", func() {
+		comparison, err := comparer.Compare(current, previous, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comparison.Previous).To(Equal("2016-08"))
+		Expect(comparison.Current).To(Equal("2016-09"))
+		Expect(comparison.PreviousTotal).To(Equal(290.0))
+		Expect(comparison.CurrentTotal).To(Equal(245.0))
+		Expect(comparison.Lines).To(Equal([]datamodels.ComparisonLine{
+			{Resource: "AWS", AccountNumber: "1", AccountName: "name-1", ServiceType: "EC2", Previous: 200, Current: 150, Change: -50, ChangePercent: -25, Status: datamodels.Changed},
+			{Resource: "AWS", AccountNumber: "1", AccountName: "name-1", ServiceType: "RDS", Current: 40, Change: 40, Status: datamodels.New},
+			{Resource: "AWS", AccountNumber: "1", AccountName: "name-1", ServiceType: "S3", Previous: 50, Current: 20, Change: -30, ChangePercent: -60, Status: datamodels.Changed},
+			{Resource: "AWS", AccountNumber: "3", AccountName: "name-3", ServiceType: "EC2", Previous: 10, Change: -10, ChangePercent: -100, Status: datamodels.Disappeared},
+			{Resource: "Azure", AccountNumber: "4", AccountName: "name-4", ServiceType: "Storage", Current: 5, Change: 5, Status: datamodels.New},
+			{Resource: "GCP", AccountNumber: "2", AccountName: "name-2", ServiceType: "Compute", Previous: 30, Current: 30, Status: datamodels.Changed},
+		}))
+	})
+
+	It("ranks the top increases and decreases", func() {
+		comparison, err := comparer.Compare(current, previous, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comparison.TopIncreases).To(HaveLen(2))
+		Expect(comparison.TopIncreases[0].ServiceType).To(Equal("RDS"))
+		Expect(comparison.TopIncreases[1].ServiceType).To(Equal("Storage"))
+		Expect(comparison.TopDecreases).To(HaveLen(2))
+		Expect(comparison.TopDecreases[0].ServiceType).To(Equal("EC2"))
+		Expect(comparison.TopDecreases[0].Change).To(Equal(-50.0))
+		Expect(comparison.TopDecreases[1].ServiceType).To(Equal("S3"))
+	})
+
+	It("highlights new and disappeared services and accounts", func() {
+		comparison, err := comparer.Compare(current, previous, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comparison.NewServices).To(HaveLen(2))
+		Expect(comparison.NewServices[0].ServiceType).To(Equal("RDS"))
+		Expect(comparison.DisappearedServices).To(HaveLen(1))
+		Expect(comparison.DisappearedServices[0].AccountNumber).To(Equal("3"))
+
+		Expect(comparison.NewAccounts).To(Equal([]datamodels.ComparisonLine{
+			{Resource: "Azure", AccountNumber: "4", AccountName: "name-4", Current: 5, Change: 5, Status: datamodels.New},
+		}))
+		Expect(comparison.DisappearedAccounts).To(Equal([]datamodels.ComparisonLine{
+			{Resource: "AWS", AccountNumber: "3", AccountName: "name-3", Previous: 10, Change: -10, ChangePercent: -100, Status: datamodels.Disappeared},
+		}))
+	})
+
+	Context("when the costs cannot be read", func() {
+		BeforeEach(func() {
+			db.GetDailyCostsStub = nil
+			db.GetDailyCostsReturns(nil, errors.New("no db"))
+		})
+
+		It("returns the error", func() {
+			_, err := comparer.Compare(current, previous, 10)
+			Expect(err).To(MatchError("no db"))
+		})
+	})
+
+	Describe("Write", func() {
+		var comparison datamodels.Comparison
+
+		BeforeEach(func() {
+			var err error
+			comparison, err = comparer.Compare(current, previous, 10)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("writes every line as CSV", func() {
+			out := new(bytes.Buffer)
+			Expect(Write(out, comparison, CSV)).To(Succeed())
+			Expect(out.String()).To(HavePrefix("Resource,Account Number,Account Name,Service Type,Previous,Current,Change,Change Percent,Status\n"))
+			Expect(out.String()).To(ContainSubstring("AWS,1,name-1,EC2,200,150,-50,-25,changed\n"))
+		})
+
+		It("writes the whole comparison as JSON", func() {
+			out := new(bytes.Buffer)
+			Expect(Write(out, comparison, JSON)).To(Succeed())
+			var written datamodels.Comparison
+			Expect(json.Unmarshal(out.Bytes(), &written)).To(Succeed())
+			Expect(written).To(Equal(comparison))
+		})
+
+		It("writes a table of the movers as text", func() {
+			out := new(bytes.Buffer)
+			Expect(Write(out, comparison, Text)).To(Succeed())
+			Expect(out.String()).To(HavePrefix("2016-09 compared with 2016-08: 290.00 -> 245.00 (-45.00)\n"))
+			Expect(out.String()).To(ContainSubstring("Top increases"))
+			Expect(out.String()).To(MatchRegexp(`AWS\s+name-1 \(1\)\s+EC2\s+200.00\s+150.00\s+-50.00\s+-25.0%`))
+			Expect(out.String()).To(MatchRegexp(`Disappeared accounts\n\s+Resource\s+Account`))
+		})
+
+		It("rejects an unknown format", func() {
+			Expect(Write(new(bytes.Buffer), comparison, "xml")).To(MatchError(ContainSubstring("Unknown format")))
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/gocarina/gocsv"
+)
+
+const (
+	CSV  = "csv"
+	JSON = "json"
+	Text = "text"
+)
+
+// Write writes the comparison as CSV (every line), JSON (the whole comparison)
+// or a text table for people.
+func Write(w io.Writer, comparison datamodels.Comparison, format string) error {
+	switch format {
+	case CSV:
+		return gocsv.Marshal(&comparison.Lines, w)
+	case JSON:
+		return json.NewEncoder(w).Encode(comparison)
+	case Text:
+		return writeText(w, comparison)
+	}
+	return fmt.Errorf("Unknown format %q, must be %s, %s or %s", format, CSV, JSON, Text)
+}
+
+func writeText(w io.Writer, comparison datamodels.Comparison) error {
+	fmt.Fprintf(w, "%s compared with %s: %.2f -> %.2f (%+.2f)\n", comparison.Current, comparison.Previous, comparison.PreviousTotal, comparison.CurrentTotal, comparison.CurrentTotal-comparison.PreviousTotal)
+
+	sections := []struct {
+		title string
+		lines []datamodels.ComparisonLine
+	}{
+		{"Top increases", comparison.TopIncreases},
+		{"Top decreases", comparison.TopDecreases},
+		{"New services", comparison.NewServices},
+		{"Disappeared services", comparison.DisappearedServices},
+		{"New accounts", comparison.NewAccounts},
+		{"Disappeared accounts", comparison.DisappearedAccounts},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(w, "\n%s\n", section.title)
+		if len(section.lines) == 0 {
+			fmt.Fprintln(w, "  none")
+			continue
+		}
+		table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "  Resource\tAccount\tService\tPrevious\tCurrent\tChange\t%")
+		for _, line := range section.lines {
+			account := line.AccountNumber
+			if line.AccountName != "" {
+				account = line.AccountName + " (" + line.AccountNumber + ")"
+			}
+			percent := "-"
+			if line.Previous != 0 {
+				percent = fmt.Sprintf("%+.1f%%", line.ChangePercent)
+			}
+			fmt.Fprintf(table, "  %s\t%s\t%s\t%.2f\t%.2f\t%+.2f\t%s\n", line.Resource, account, line.ServiceType, line.Previous, line.Current, line.Change, percent)
+		}
+		err := table.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package compare
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/challiwill/meteorologica/calendar"
+)
+
+var quarterPattern = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+
+// Period is a month or a quarter, from its first to its last day.
+type Period struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// ParsePeriod parses a month (2016-09) or a quarter (2016-Q3).
+func ParsePeriod(period string) (Period, error) {
+	if match := quarterPattern.FindStringSubmatch(period); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		quarter, _ := strconv.Atoi(match[2])
+		return quarterContaining(time.Date(year, time.Month(quarter*3), 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+	month, err := time.Parse("2006-01", period)
+	if err != nil {
+		return Period{}, fmt.Errorf("Invalid period %q, expected a month (YYYY-MM) or a quarter (YYYY-Qn)", period)
+	}
+	return monthContaining(month), nil
+}
+
+// LastMonth is the month before the one containing date.
+func LastMonth(date time.Time) Period {
+	return monthContaining(time.Date(date.Year(), date.Month(), 0, 0, 0, 0, 0, time.UTC))
+}
+
+// Previous is the month or quarter before the period.
+func (p Period) Previous() Period {
+	if quarterPattern.MatchString(p.Name) {
+		return quarterContaining(p.From.AddDate(0, 0, -1))
+	}
+	return monthContaining(p.From.AddDate(0, 0, -1))
+}
+
+func monthContaining(date time.Time) Period {
+	from, to, name := calendar.MonthContaining(date)
+	return Period{Name: name, From: from, To: to}
+}
+
+func quarterContaining(date time.Time) Period {
+	from, to, name := calendar.QuarterContaining(date)
+	return Period{Name: name, From: from, To: to}
+}
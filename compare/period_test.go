@@ -0,0 +1,39 @@
+package compare_test
+
+import (
+	"time"
+
+	. "github.com/challiwill/meteorologica/compare"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Period", func() {
+	It("parses a month", func() {
+		period, err := ParsePeriod("2016-09")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Name).To(Equal("2016-09"))
+		Expect(period.From).To(Equal(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(period.To).To(Equal(time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC)))
+		Expect(period.Previous().Name).To(Equal("2016-08"))
+	})
+
+	It("parses a quarter", func() {
+		period, err := ParsePeriod("2016-Q1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Name).To(Equal("2016-Q1"))
+		Expect(period.From).To(Equal(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(period.To).To(Equal(time.Date(2016, time.March, 31, 0, 0, 0, 0, time.UTC)))
+		Expect(period.Previous().Name).To(Equal("2015-Q4"))
+	})
+
+	It("rejects anything else", func() {
+		_, err := ParsePeriod("2016-Q5")
+		Expect(err).To(MatchError(ContainSubstring("expected a month (YYYY-MM) or a quarter (YYYY-Qn)")))
+	})
+
+	It("knows last month", func() {
+		Expect(LastMonth(time.Date(2016, time.January, 15, 0, 0, 0, 0, time.UTC)).Name).To(Equal("2015-12"))
+	})
+})
@@ -0,0 +1,40 @@
+package datamodels
+
+import (
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// Allocation is the share of a report's cost that an allocation rule charges
+// to a team. SourceReportID is the ID of the report the cost was split from.
+type Allocation struct {
+	SourceReportID string     `csv:"Source Report ID"`
+	Rule           string     `csv:"Rule"`
+	Team           string     `csv:"Team"`
+	Share          float64    `csv:"Share"`
+	AccountNumber  string     `csv:"Account Number"`
+	AccountName    string     `csv:"Account Name"`
+	Day            int        `csv:"Day"`
+	Month          time.Month `csv:"Month"`
+	Year           int        `csv:"Year"`
+	ServiceType    string     `csv:"Service Type"`
+	Region         string     `csv:"Region"`
+	Resource       string     `csv:"Resource"`
+	Cost           float64    `csv:"Cost"`
+}
+
+// ID is unique for each team a report's cost is allocated to.
+func (a Allocation) ID() string {
+	h := fnv.New64a()
+	h.Write([]byte(a.SourceReportID + a.Team))
+	return strconv.FormatUint(uint64(h.Sum64()), 10)
+}
+
+// AllocatedCost is the cost allocated to a team on a day from the reports of
+// a service in an account. The DailyCost is that of the source reports, so
+// their owner can be told apart from the team they are allocated to.
+type AllocatedCost struct {
+	Team string
+	DailyCost
+}
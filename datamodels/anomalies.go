@@ -0,0 +1,64 @@
+package datamodels
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// DailyCost is the total cost of a service in an account on a day.
+type DailyCost struct {
+	Resource       string
+	AccountNumber  string
+	AccountName    string
+	ServiceType    string
+	DepartmentName string
+	CostCenter     string
+	Tags           string
+	Year           int
+	Month          time.Month
+	Day            int
+	Cost           float64
+}
+
+// Date returns the day of the cost as midnight UTC.
+func (c DailyCost) Date() time.Time {
+	return time.Date(c.Year, c.Month, c.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// Anomaly is a day on which the cost of an account deviated from its
+// baseline.
+type Anomaly struct {
+	ID            string     `json:"id"`
+	RunID         string     `json:"run_id"`
+	Resource      string     `json:"resource"`
+	AccountNumber string     `json:"account_number"`
+	Year          int        `json:"year"`
+	Month         time.Month `json:"month"`
+	Day           int        `json:"day"`
+	Method        string     `json:"method"`
+	Cost          float64    `json:"cost"`
+	Expected      float64    `json:"expected"`
+	Magnitude     float64    `json:"magnitude"`
+	Score         float64    `json:"score"`
+
+	ContributingServices []ServiceContribution `json:"contributing_services"`
+}
+
+// ServiceContribution is how much a service moved an anomalous day's cost
+// away from what was expected.
+type ServiceContribution struct {
+	ServiceType string  `json:"service_type"`
+	Cost        float64 `json:"cost"`
+	Expected    float64 `json:"expected"`
+	Magnitude   float64 `json:"magnitude"`
+}
+
+// AnomalyID identifies an anomaly by the account and day it was found on, so
+// detecting it again replaces it.
+func AnomalyID(resource, accountNumber string, year int, month time.Month, day int) string {
+	h := fnv.New64a()
+	h.Write([]byte(resource + accountNumber + "anomaly"))
+	return strconv.FormatUint(uint64(h.Sum64()), 10) + fmt.Sprintf("%04d%02d%02d", year, int(month), day)
+}
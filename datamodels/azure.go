@@ -0,0 +1,52 @@
+package datamodels
+
+import "time"
+
+// AzurePrice is the unit price of a meter in a billing period of the price
+// sheet of an Azure enrollment.
+type AzurePrice struct {
+	Enrollment       string
+	Year             int
+	Month            time.Month
+	MeterID          string
+	MeterName        string
+	MeterCategory    string
+	MeterSubCategory string
+	PartNumber       string
+	UnitOfMeasure    string
+	IncludedQuantity float64
+	UnitPrice        float64
+	Currency         string
+}
+
+// AzureBalance is the balance summary of a billing period of an Azure
+// enrollment: its monetary commitment at the start of the period, what was
+// added to and drawn from it, and the overage and charges billed on top of it.
+type AzureBalance struct {
+	Enrollment              string
+	Year                    int
+	Month                   time.Month
+	Currency                string
+	BeginningBalance        float64
+	NewPurchases            float64
+	Adjustments             float64
+	Utilized                float64
+	ServiceOverage          float64
+	ChargesBilledSeparately float64
+	TotalOverage            float64
+	TotalUsage              float64
+	MarketplaceCharges      float64
+	EndingBalance           float64
+}
+
+// AzureCommitmentProjection is when the monetary commitment of an Azure
+// enrollment is expected to run out, if it keeps being drawn down at its
+// average daily burn. ExhaustedOn is empty when nothing is being drawn down.
+type AzureCommitmentProjection struct {
+	Enrollment  string
+	AsOf        string
+	Balance     float64
+	DailyBurn   float64
+	BurnDays    int
+	ExhaustedOn string
+}
@@ -0,0 +1,26 @@
+package datamodels
+
+import "time"
+
+const (
+	// ActualSpend alerts are sent when the spend so far crossed a threshold.
+	ActualSpend = "actual"
+	// ForecastSpend alerts are sent when the spend is on course to cross a
+	// threshold by the end of the period.
+	ForecastSpend = "forecast"
+)
+
+// BudgetAlert is sent the first time the spend of a budget period crosses one
+// of its thresholds.
+type BudgetAlert struct {
+	RunID       string    `json:"run_id"`
+	Budget      string    `json:"budget"`
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Kind        string    `json:"kind"`
+	Threshold   int       `json:"threshold"`
+	Amount      float64   `json:"amount"`
+	Actual      float64   `json:"actual"`
+	Forecast    float64   `json:"forecast"`
+}
@@ -0,0 +1,36 @@
+package datamodels
+
+const (
+	Changed     = "changed"
+	New         = "new"
+	Disappeared = "disappeared"
+)
+
+// ComparisonLine is how the cost of a service of an account, or of a whole
+// account when ServiceType is empty, changed between two periods.
+type ComparisonLine struct {
+	Resource      string  `csv:"Resource" json:"resource"`
+	AccountNumber string  `csv:"Account Number" json:"account_number"`
+	AccountName   string  `csv:"Account Name" json:"account_name"`
+	ServiceType   string  `csv:"Service Type" json:"service_type,omitempty"`
+	Previous      float64 `csv:"Previous" json:"previous"`
+	Current       float64 `csv:"Current" json:"current"`
+	Change        float64 `csv:"Change" json:"change"`
+	ChangePercent float64 `csv:"Change Percent" json:"change_percent"`
+	Status        string  `csv:"Status" json:"status"`
+}
+
+// Comparison is what changed between the Previous and the Current period.
+type Comparison struct {
+	Previous            string           `json:"previous"`
+	Current             string           `json:"current"`
+	PreviousTotal       float64          `json:"previous_total"`
+	CurrentTotal        float64          `json:"current_total"`
+	Lines               []ComparisonLine `json:"lines"`
+	TopIncreases        []ComparisonLine `json:"top_increases"`
+	TopDecreases        []ComparisonLine `json:"top_decreases"`
+	NewServices         []ComparisonLine `json:"new_services"`
+	DisappearedServices []ComparisonLine `json:"disappeared_services"`
+	NewAccounts         []ComparisonLine `json:"new_accounts"`
+	DisappearedAccounts []ComparisonLine `json:"disappeared_accounts"`
+}
@@ -0,0 +1,23 @@
+package datamodels
+
+// SpendLine is the spend of an IAAS, or of one of its accounts, over a week
+// compared with the week before.
+type SpendLine struct {
+	Resource      string  `json:"resource"`
+	AccountNumber string  `json:"account_number,omitempty"`
+	AccountName   string  `json:"account_name,omitempty"`
+	Current       float64 `json:"current"`
+	Previous      float64 `json:"previous"`
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// Digest summarises the spend of the week From to To (YYYY-MM-DD).
+type Digest struct {
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Total       SpendLine   `json:"total"`
+	Clouds      []SpendLine `json:"clouds"`
+	TopAccounts []SpendLine `json:"top_accounts"`
+	Anomalies   []Anomaly   `json:"anomalies"`
+}
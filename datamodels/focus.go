@@ -0,0 +1,31 @@
+package datamodels
+
+// FocusRow is a report as a row of the FinOps Open Cost and Usage
+// Specification (FOCUS). Columns FOCUS does not define are prefixed x_.
+type FocusRow struct {
+	BillingAccountId   string  `csv:"BillingAccountId" json:"BillingAccountId"`
+	BillingCurrency    string  `csv:"BillingCurrency" json:"BillingCurrency"`
+	BillingPeriodStart string  `csv:"BillingPeriodStart" json:"BillingPeriodStart"`
+	BillingPeriodEnd   string  `csv:"BillingPeriodEnd" json:"BillingPeriodEnd"`
+	ChargeCategory     string  `csv:"ChargeCategory" json:"ChargeCategory"`
+	ChargePeriodStart  string  `csv:"ChargePeriodStart" json:"ChargePeriodStart"`
+	ChargePeriodEnd    string  `csv:"ChargePeriodEnd" json:"ChargePeriodEnd"`
+	BilledCost         float64 `csv:"BilledCost" json:"BilledCost"`
+	EffectiveCost      float64 `csv:"EffectiveCost" json:"EffectiveCost"`
+	ProviderName       string  `csv:"ProviderName" json:"ProviderName"`
+	PublisherName      string  `csv:"PublisherName" json:"PublisherName"`
+	InvoiceIssuerName  string  `csv:"InvoiceIssuerName" json:"InvoiceIssuerName"`
+	SubAccountId       string  `csv:"SubAccountId" json:"SubAccountId"`
+	SubAccountName     string  `csv:"SubAccountName" json:"SubAccountName"`
+	ServiceName        string  `csv:"ServiceName" json:"ServiceName"`
+	ServiceCategory    string  `csv:"ServiceCategory" json:"ServiceCategory"`
+	RegionId           string  `csv:"RegionId" json:"RegionId"`
+	ResourceId         string  `csv:"ResourceId" json:"ResourceId"`
+	PricingQuantity    float64 `csv:"PricingQuantity" json:"PricingQuantity"`
+	PricingUnit        string  `csv:"PricingUnit" json:"PricingUnit"`
+	ConsumedQuantity   float64 `csv:"ConsumedQuantity" json:"ConsumedQuantity"`
+	ConsumedUnit       string  `csv:"ConsumedUnit" json:"ConsumedUnit"`
+	ResourceGroup      string  `csv:"x_ResourceGroup" json:"x_ResourceGroup"`
+	DepartmentName     string  `csv:"x_DepartmentName" json:"x_DepartmentName"`
+	CostCenter         string  `csv:"x_CostCenter" json:"x_CostCenter"`
+}
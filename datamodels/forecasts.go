@@ -0,0 +1,23 @@
+package datamodels
+
+// AllAccounts is the account number of a forecast of every account of a
+// resource.
+const AllAccounts = "All"
+
+// Forecast is the spend expected by the end of a period, with the bounds it is
+// expected to fall within.
+type Forecast struct {
+	Resource      string  `csv:"Resource" json:"resource"`
+	AccountNumber string  `csv:"Account Number" json:"account_number"`
+	Period        string  `csv:"Period" json:"period"`
+	PeriodStart   string  `csv:"Period Start" json:"period_start"`
+	PeriodEnd     string  `csv:"Period End" json:"period_end"`
+	AsOf          string  `csv:"As Of" json:"as_of"`
+	Model         string  `csv:"Model" json:"model"`
+	Actual        float64 `csv:"Actual" json:"actual"`
+	Forecast      float64 `csv:"Forecast" json:"forecast"`
+	Lower         float64 `csv:"Lower Bound" json:"lower"`
+	Upper         float64 `csv:"Upper Bound" json:"upper"`
+	Backtests     int     `csv:"Backtests" json:"backtests"`
+	BacktestError float64 `csv:"Backtest Error" json:"backtest_error"`
+}
@@ -0,0 +1,26 @@
+package datamodels
+
+import "time"
+
+// IdleNamespace is the namespace of the cost of the capacity of a cluster
+// that no namespace requested or used.
+const IdleNamespace = "__idle__"
+
+// NamespaceCost is the share of the cost of the nodes of a cluster a
+// namespace is charged for a day, for the greater of the CPU and memory it
+// requested and used.
+type NamespaceCost struct {
+	Cluster   string
+	Namespace string
+	Year      int
+	Month     time.Month
+	Day       int
+
+	CPURequestCoreHours   float64
+	CPUUsageCoreHours     float64
+	MemoryRequestGiBHours float64
+	MemoryUsageGiBHours   float64
+	CPUCost               float64
+	MemoryCost            float64
+	Cost                  float64
+}
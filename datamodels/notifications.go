@@ -0,0 +1,19 @@
+package datamodels
+
+// JobFailure is sent when a run could not collect or save the usage of an
+// IAAS, with how many runs in a row have failed to.
+type JobFailure struct {
+	RunID               string `json:"run_id"`
+	Resource            string `json:"resource"`
+	Error               string `json:"error"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+}
+
+// OutputFailure is sent when a run could not write the usage of an IAAS to
+// one of its outputs other than the database.
+type OutputFailure struct {
+	RunID    string `json:"run_id"`
+	Resource string `json:"resource"`
+	Output   string `json:"output"`
+	Error    string `json:"error"`
+}
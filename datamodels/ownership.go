@@ -0,0 +1,51 @@
+package datamodels
+
+// Unallocated is the team that costs no ownership mapping applies to are
+// charged to.
+const Unallocated = "Unallocated"
+
+// Team is a node of the organization's team hierarchy that costs are charged
+// to.
+type Team struct {
+	Name       string `json:"name" yaml:"name" csv:"Team"`
+	Parent     string `json:"parent" yaml:"parent" csv:"Parent"`
+	CostCenter string `json:"cost_center" yaml:"cost-center" csv:"Cost Center"`
+}
+
+// OwnershipMapping assigns the costs it matches to a team from EffectiveFrom
+// until EffectiveTo (YYYY-MM-DD, inclusive, open ended when empty). Empty
+// match fields match anything.
+type OwnershipMapping struct {
+	ID              int64  `json:"id" yaml:"-" csv:"-"`
+	Team            string `json:"team" yaml:"team" csv:"Team"`
+	Resource        string `json:"resource" yaml:"resource" csv:"Resource"`
+	AccountNumber   string `json:"account_number" yaml:"account" csv:"Account Number"`
+	AzureDepartment string `json:"azure_department" yaml:"azure-department" csv:"Azure Department"`
+	AzureCostCenter string `json:"azure_cost_center" yaml:"azure-cost-center" csv:"Azure Cost Center"`
+	Tag             string `json:"tag" yaml:"tag" csv:"Tag"`
+	EffectiveFrom   string `json:"effective_from" yaml:"effective-from" csv:"Effective From"`
+	EffectiveTo     string `json:"effective_to" yaml:"effective-to" csv:"Effective To"`
+}
+
+// ChargebackStatement is what a team is charged for a month. Total is charged
+// to the team directly and RolledUpTotal includes the teams below it.
+type ChargebackStatement struct {
+	Team          string           `json:"team"`
+	Path          string           `json:"path"`
+	CostCenter    string           `json:"cost_center"`
+	Period        string           `json:"period"`
+	Total         float64          `json:"total"`
+	RolledUpTotal float64          `json:"rolled_up_total"`
+	Lines         []ChargebackLine `json:"lines"`
+}
+
+// ChargebackLine is the cost of a service in an account charged to a team.
+type ChargebackLine struct {
+	Team          string  `json:"-" csv:"Team"`
+	Period        string  `json:"-" csv:"Period"`
+	Resource      string  `json:"resource" csv:"Resource"`
+	AccountNumber string  `json:"account_number" csv:"Account Number"`
+	AccountName   string  `json:"account_name" csv:"Account Name"`
+	ServiceType   string  `json:"service_type" csv:"Service Type"`
+	Cost          float64 `json:"cost" csv:"Cost"`
+}
@@ -0,0 +1,37 @@
+package datamodels
+
+import "time"
+
+// Invoice is the total a provider states it charges for a month, with what
+// the provider tells about the parts of it that are not in the saved rows.
+// DailyCosts is the cost of the rows of each day of the month that are saved,
+// for providers that break their billing down by day.
+type Invoice struct {
+	Resource   string
+	Year       int
+	Month      time.Month
+	Total      float64
+	Credits    float64
+	Rounding   float64
+	Dropped    float64
+	DailyCosts map[int]float64
+}
+
+// Reconciliation compares what a provider invoiced for a month with the sum of
+// the saved rows. The gap is broken down into its causes, and what the causes
+// do not account for is Unexplained.
+type Reconciliation struct {
+	Resource        string  `csv:"Resource" json:"resource"`
+	Period          string  `csv:"Period" json:"period"`
+	InvoiceTotal    float64 `csv:"Invoice Total" json:"invoice_total"`
+	StoredTotal     float64 `csv:"Stored Total" json:"stored_total"`
+	Gap             float64 `csv:"Gap" json:"gap"`
+	Rounding        float64 `csv:"Rounding" json:"rounding"`
+	Credits         float64 `csv:"Credits" json:"credits"`
+	DroppedRows     float64 `csv:"Dropped Rows" json:"dropped_rows"`
+	MissingDays     float64 `csv:"Missing Days" json:"missing_days"`
+	MissingDates    string  `csv:"Missing Dates" json:"missing_dates"`
+	Unexplained     float64 `csv:"Unexplained" json:"unexplained"`
+	Tolerance       float64 `csv:"Tolerance" json:"tolerance"`
+	WithinTolerance bool    `csv:"Within Tolerance" json:"within_tolerance"`
+}
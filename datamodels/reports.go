@@ -1,6 +1,29 @@
 package datamodels
 
-import "time"
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// Run is one execution of the usage data job. Every report saved by a run is
+// versioned with its ID and start time.
+type Run struct {
+	ID         string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// NewRun returns a run started at the given time, to the microsecond as that
+// is what the database keeps.
+func NewRun(startedAt time.Time) Run {
+	startedAt = startedAt.Truncate(time.Microsecond)
+	return Run{
+		ID:        startedAt.UTC().Format("20060102T150405.000000"),
+		StartedAt: startedAt,
+	}
+}
 
 type ReportIdentifier struct {
 	AccountNumber string
@@ -13,9 +36,12 @@ type ReportIdentifier struct {
 	Region        string
 }
 
+// UsageMonthToDate is the cumulative usage from the start of the month up to
+// and including Day.
 type UsageMonthToDate struct {
 	AccountNumber string
 	AccountName   string
+	Day           int
 	Month         time.Month
 	Year          int
 	ServiceType   string
@@ -24,28 +50,76 @@ type UsageMonthToDate struct {
 	Region        string
 	UnitOfMeasure string
 	Resource      string
+
+	NormalizedUsageQuantity float64
+	NormalizedUnitOfMeasure string
 }
 
+// SnapshotID identifies a month-to-date snapshot by what it measures and the
+// day it was taken for.
+func (u UsageMonthToDate) SnapshotID() string {
+	h := fnv.New64a()
+	h.Write([]byte(u.AccountNumber + u.ServiceType + u.Region + u.Resource))
+	return strconv.FormatUint(uint64(h.Sum64()), 10) + fmt.Sprintf("%04d%02d%02d", u.Year, int(u.Month), u.Day)
+}
+
+// MixedUnits marks a normalized quantity that was consolidated from rows
+// measured in different units and so cannot be compared to other rows.
+const MixedUnits = "Mixed"
+
 type Report struct {
-	ID            string     `csv:"ID"`
-	AccountNumber string     `csv:"Account Number"`
-	AccountName   string     `csv:"Account Name"`
-	Day           int        `csv:"Day"`
-	Month         time.Month `csv:"Month"`
-	Year          int        `csv:"Year"`
-	ServiceType   string     `csv:"Service Type"`
-	Region        string     `csv:"Region"`
-	Resource      string     `csv:"Resource"`
-	UsageQuantity float64    `csv:"Usage Quantity"`
-	UnitOfMeasure string     `csv:"Unit Of Measurement"`
-	Cost          float64    `csv:"Cost"`
+	ID                      string     `csv:"ID"`
+	AccountNumber           string     `csv:"Account Number"`
+	AccountName             string     `csv:"Account Name"`
+	Day                     int        `csv:"Day"`
+	Month                   time.Month `csv:"Month"`
+	Year                    int        `csv:"Year"`
+	ServiceType             string     `csv:"Service Type"`
+	Region                  string     `csv:"Region"`
+	Resource                string     `csv:"Resource"`
+	UsageQuantity           float64    `csv:"Usage Quantity"`
+	UnitOfMeasure           string     `csv:"Unit Of Measurement"`
+	Cost                    float64    `csv:"Cost"`
+	NormalizedUsageQuantity float64    `csv:"Normalized Usage Quantity"`
+	NormalizedUnitOfMeasure string     `csv:"Normalized Unit Of Measurement"`
+	ResourceID              string     `csv:"Resource ID"`
+	ResourceGroup           string     `csv:"Resource Group"`
+	DepartmentName          string     `csv:"Department Name"`
+	CostCenter              string     `csv:"Cost Center"`
+	Org                     string     `csv:"Org"`
+	Space                   string     `csv:"Space"`
+	Tags                    string     `csv:"Tags"`
+	Currency                string     `csv:"Currency"`
+	ChargeCategory          string     `csv:"Charge Category"`
+	Publisher               string     `csv:"Publisher"`
+}
+
+// Purchase is the charge category of reports of purchases, such as
+// marketplace offers, rather than of usage.
+const Purchase = "Purchase"
+
+// CloudFoundry is the resource of the reports of Cloud Foundry foundations.
+// They attribute the usage of the IAAS accounts the foundations run in to
+// orgs and spaces, so their cost is already in the reports of those accounts.
+const CloudFoundry = "CloudFoundry"
+
+// ResourceReportID identifies a report at resource-level granularity, that is
+// the consolidated report it rolls up into plus the resource it describes.
+func (r Report) ResourceReportID() string {
+	h := fnv.New64a()
+	h.Write([]byte(r.ID + r.ResourceGroup + r.ResourceID))
+	return strconv.FormatUint(uint64(h.Sum64()), 10)
 }
 
 type Reports []Report
 
+// ConsolidateReports collapses reports with the same ID into one, dropping any
+// resource-level detail. Only the tags every report shares are kept.
 func ConsolidateReports(reports Reports) Reports {
 	consolidatedReports := make(map[string]Report)
 	for _, r := range reports {
+		r.ResourceID = ""
+		r.ResourceGroup = ""
 		if _, ok := consolidatedReports[r.ID]; ok {
 			consolidatedReports[r.ID] = sumReports(consolidatedReports[r.ID], r)
 			continue
@@ -60,8 +134,76 @@ func ConsolidateReports(reports Reports) Reports {
 	return consolidatedReportsSlice
 }
 
+// ConsolidateResourceReports collapses reports with the same ID that describe
+// the same resource into one.
+func ConsolidateResourceReports(reports Reports) Reports {
+	consolidatedReports := make(map[string]Report)
+	var order []string
+	for _, r := range reports {
+		key := r.ResourceReportID()
+		if _, ok := consolidatedReports[key]; ok {
+			consolidatedReports[key] = sumReports(consolidatedReports[key], r)
+			continue
+		}
+		consolidatedReports[key] = r
+		order = append(order, key)
+	}
+
+	consolidatedReportsSlice := Reports{}
+	for _, k := range order {
+		consolidatedReportsSlice = append(consolidatedReportsSlice, consolidatedReports[k])
+	}
+	return consolidatedReportsSlice
+}
+
 func sumReports(one Report, two Report) Report {
 	one.UsageQuantity += two.UsageQuantity
 	one.Cost += two.Cost
+	if one.NormalizedUnitOfMeasure != two.NormalizedUnitOfMeasure {
+		one.NormalizedUnitOfMeasure = MixedUnits
+		one.NormalizedUsageQuantity = 0
+	}
+	if one.NormalizedUnitOfMeasure != MixedUnits {
+		one.NormalizedUsageQuantity += two.NormalizedUsageQuantity
+	}
+	if one.DepartmentName != two.DepartmentName {
+		one.DepartmentName = ""
+	}
+	if one.CostCenter != two.CostCenter {
+		one.CostCenter = ""
+	}
+	if one.Org != two.Org {
+		one.Org = ""
+	}
+	if one.Space != two.Space {
+		one.Space = ""
+	}
+	if one.Currency != two.Currency {
+		one.Currency = ""
+	}
+	if one.ChargeCategory != two.ChargeCategory {
+		one.ChargeCategory = ""
+	}
+	if one.Publisher != two.Publisher {
+		one.Publisher = ""
+	}
+	one.Tags = CommonTags(one.Tags, two.Tags)
 	return one
 }
+
+const (
+	// Block keeps the usage an issue was found in from being saved.
+	Block = "block"
+	// Annotate saves the usage an issue was found in and records the issue.
+	Annotate = "annotate"
+)
+
+// RunIssue is a data-quality problem found in the usage of a resource during a
+// run.
+type RunIssue struct {
+	RunID    string `json:"run_id"`
+	Resource string `json:"resource"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
@@ -164,5 +164,137 @@ var _ = Describe("Reports", func() {
 				))
 			})
 		})
+
+		Context("with reports measured in different units", func() {
+			BeforeEach(func() {
+				reports = Reports{
+					Report{
+						ID:                      "a",
+						UsageQuantity:           2,
+						UnitOfMeasure:           "Hrs",
+						Cost:                    1,
+						NormalizedUsageQuantity: 2,
+						NormalizedUnitOfMeasure: "Hours",
+					},
+					Report{
+						ID:                      "a",
+						UsageQuantity:           3,
+						UnitOfMeasure:           "Hrs",
+						Cost:                    1,
+						NormalizedUsageQuantity: 3,
+						NormalizedUnitOfMeasure: "Hours",
+					},
+					Report{
+						ID:                      "b",
+						UsageQuantity:           2,
+						UnitOfMeasure:           "Hrs",
+						Cost:                    1,
+						NormalizedUsageQuantity: 2,
+						NormalizedUnitOfMeasure: "Hours",
+					},
+					Report{
+						ID:                      "b",
+						UsageQuantity:           3,
+						UnitOfMeasure:           "GB-Mo",
+						Cost:                    1,
+						NormalizedUsageQuantity: 3,
+						NormalizedUnitOfMeasure: "GiB-Months",
+					},
+				}
+			})
+
+			It("sums normalized quantities that share a unit", func() {
+				Expect(usageReports).To(ContainElement(Report{
+					ID:                      "a",
+					UsageQuantity:           5,
+					UnitOfMeasure:           "Hrs",
+					Cost:                    2,
+					NormalizedUsageQuantity: 5,
+					NormalizedUnitOfMeasure: "Hours",
+				}))
+			})
+
+			It("marks normalized quantities with different units as mixed", func() {
+				Expect(usageReports).To(ContainElement(Report{
+					ID:                      "b",
+					UsageQuantity:           5,
+					UnitOfMeasure:           "Hrs",
+					Cost:                    2,
+					NormalizedUsageQuantity: 0,
+					NormalizedUnitOfMeasure: "Mixed",
+				}))
+			})
+		})
+	})
+
+	Describe("ConsolidateResourceReports", func() {
+		var reports Reports
+
+		BeforeEach(func() {
+			reports = Reports{
+				Report{ID: "a", ResourceID: "vm-1", ResourceGroup: "group", UsageQuantity: 1, Cost: 1},
+				Report{ID: "a", ResourceID: "vm-2", ResourceGroup: "group", UsageQuantity: 1, Cost: 2},
+				Report{ID: "a", ResourceID: "vm-1", ResourceGroup: "group", UsageQuantity: 1, Cost: 3},
+				Report{ID: "b", ResourceID: "vm-1", ResourceGroup: "group", UsageQuantity: 1, Cost: 4},
+			}
+		})
+
+		It("consolidates reports for the same resource", func() {
+			Expect(ConsolidateResourceReports(reports)).To(Equal(Reports{
+				Report{ID: "a", ResourceID: "vm-1", ResourceGroup: "group", UsageQuantity: 2, Cost: 4},
+				Report{ID: "a", ResourceID: "vm-2", ResourceGroup: "group", UsageQuantity: 1, Cost: 2},
+				Report{ID: "b", ResourceID: "vm-1", ResourceGroup: "group", UsageQuantity: 1, Cost: 4},
+			}))
+		})
+
+		It("rolls up into the same reports as ConsolidateReports without the resource detail", func() {
+			Expect(ConsolidateReports(ConsolidateResourceReports(reports))).To(ConsistOf(
+				Report{ID: "a", UsageQuantity: 3, Cost: 6},
+				Report{ID: "b", UsageQuantity: 1, Cost: 4},
+			))
+		})
+	})
+
+	Describe("Tags", func() {
+		It("keeps the tags every consolidated report shares", func() {
+			reports := ConsolidateReports(Reports{
+				{ID: "a", Cost: 1, Tags: `{"env":"prod","team":"a"}`},
+				{ID: "a", Cost: 2, Tags: `{"env":"prod","team":"b"}`},
+			})
+			Expect(reports).To(HaveLen(1))
+			Expect(reports[0].Tags).To(Equal(`{"env":"prod"}`))
+		})
+
+		It("matches tags by key and value, or by key alone", func() {
+			tags := EncodeTags(map[string]string{"Team": "a"})
+			Expect(HasTag(tags, "team=a")).To(BeTrue())
+			Expect(HasTag(tags, "team")).To(BeTrue())
+			Expect(HasTag(tags, "team=b")).To(BeFalse())
+			Expect(HasTag(tags, "env")).To(BeFalse())
+			Expect(HasTag("not-json", "team")).To(BeFalse())
+		})
+	})
+
+	Describe("SnapshotID", func() {
+		It("differs between days whose numbers run together", func() {
+			january := UsageMonthToDate{AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.January, Day: 11}
+			november := UsageMonthToDate{AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.November, Day: 1}
+			Expect(january.SnapshotID()).NotTo(Equal(november.SnapshotID()))
+		})
+	})
+
+	Describe("AnomalyID", func() {
+		It("differs between days whose numbers run together", func() {
+			Expect(AnomalyID("AWS", "123", 2016, time.January, 11)).NotTo(Equal(AnomalyID("AWS", "123", 2016, time.November, 1)))
+		})
+	})
+
+	Describe("ResourceReportID", func() {
+		It("differs between resources of the same report", func() {
+			one := Report{ID: "a", ResourceID: "vm-1"}
+			two := Report{ID: "a", ResourceID: "vm-2"}
+			Expect(one.ResourceReportID()).NotTo(Equal(two.ResourceReportID()))
+			Expect(one.ResourceReportID()).To(Equal(one.ResourceReportID()))
+		})
 	})
 })
@@ -0,0 +1,66 @@
+package datamodels
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// EncodeTags encodes tags as a JSON object, the way Azure states them. The
+// keys are sorted so the same tags are always encoded the same way.
+func EncodeTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(tags)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// DecodeTags decodes tags encoded as a JSON object. Tags that cannot be
+// decoded are treated as no tags.
+func DecodeTags(tags string) map[string]string {
+	decoded := make(map[string]string)
+	if tags == "" {
+		return decoded
+	}
+	err := json.Unmarshal([]byte(tags), &decoded)
+	if err != nil {
+		return make(map[string]string)
+	}
+	return decoded
+}
+
+// CommonTags returns the encoded tags that both encoded tags have.
+func CommonTags(one, two string) string {
+	if one == two {
+		return one
+	}
+	common := DecodeTags(one)
+	other := DecodeTags(two)
+	for key, value := range common {
+		if v, ok := other[key]; !ok || v != value {
+			delete(common, key)
+		}
+	}
+	return EncodeTags(common)
+}
+
+// HasTag returns whether the encoded tags include the tag, given as key=value
+// or as just a key to match any value. Keys are compared ignoring case, as
+// Azure does.
+func HasTag(tags, tag string) bool {
+	key, value := tag, ""
+	withValue := strings.Contains(tag, "=")
+	if withValue {
+		parts := strings.SplitN(tag, "=", 2)
+		key, value = parts[0], parts[1]
+	}
+	for k, v := range DecodeTags(tags) {
+		if strings.EqualFold(k, key) && (!withValue || v == value) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,9 +2,12 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/Sirupsen/logrus"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 
 	"github.com/challiwill/meteorologica/datamodels"
 	"github.com/challiwill/meteorologica/errare"
@@ -14,6 +17,7 @@ import (
 
 type DB interface {
 	Exec(string, ...interface{}) (sql.Result, error)
+	Query(string, ...interface{}) (*sql.Rows, error)
 	QueryRow(string, ...interface{}) *sql.Row
 	Close() error
 	Ping() error
@@ -67,9 +71,15 @@ func (c *Client) SaveReports(reports datamodels.Reports) error {
 		}
 		_, err := c.Conn.Exec(`
 		INSERT INTO resource_billing
-		(id, account_number, account_name, day, month, year, service_type, region, resource, usage_quantity, unit_of_measure, cost)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`, r.ID, r.AccountNumber, r.AccountName, r.Day, r.Month, r.Year, r.ServiceType, r.Region, r.Resource, r.UsageQuantity, r.UnitOfMeasure, r.Cost)
+		(id, account_number, account_name, day, month, year, service_type, region, resource, usage_quantity, unit_of_measure, cost, normalized_usage_quantity, normalized_unit_of_measure, department_name, cost_center, org, space, tags, currency, charge_category, publisher)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		account_name=VALUES(account_name), usage_quantity=VALUES(usage_quantity), unit_of_measure=VALUES(unit_of_measure), cost=VALUES(cost),
+		normalized_usage_quantity=VALUES(normalized_usage_quantity), normalized_unit_of_measure=VALUES(normalized_unit_of_measure),
+		department_name=VALUES(department_name), cost_center=VALUES(cost_center), org=VALUES(org), space=VALUES(space), tags=VALUES(tags),
+		currency=VALUES(currency), charge_category=VALUES(charge_category), publisher=VALUES(publisher)
+		`, r.ID, r.AccountNumber, r.AccountName, r.Day, r.Month, r.Year, r.ServiceType, r.Region, r.Resource, r.UsageQuantity, r.UnitOfMeasure, r.Cost, r.NormalizedUsageQuantity, r.NormalizedUnitOfMeasure, r.DepartmentName, r.CostCenter, r.Org, r.Space, r.Tags,
+			r.Currency, r.ChargeCategory, r.Publisher)
 		if err != nil {
 			c.Log.Warn("Failed to save report to database: ", err.Error())
 			multiErr.errs = append(multiErr.errs, err)
@@ -82,6 +92,37 @@ func (c *Client) SaveReports(reports datamodels.Reports) error {
 	return nil
 }
 
+// SaveResourceReports saves resource-level reports to their own table. The
+// consolidated reports they roll up into are saved with SaveReports.
+func (c *Client) SaveResourceReports(reports datamodels.Reports) error {
+	c.Log.Debug("Entering db.SaveResourceReports")
+	defer c.Log.Debug("Returning db.SaveResourceReports")
+
+	var multiErr MultiErr
+	for i, r := range reports {
+		if i%1000 == 0 {
+			c.Log.Debugf("Saving resource report to database %d of %d...", i, len(reports))
+		}
+		_, err := c.Conn.Exec(`
+		INSERT INTO resource_billing_details
+		(id, report_id, account_number, account_name, day, month, year, service_type, region, resource, resource_id, resource_group, usage_quantity, unit_of_measure, normalized_usage_quantity, normalized_unit_of_measure, cost)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, r.ResourceReportID(), r.ID, r.AccountNumber, r.AccountName, r.Day, r.Month, r.Year, r.ServiceType, r.Region, r.Resource, r.ResourceID, r.ResourceGroup, r.UsageQuantity, r.UnitOfMeasure, r.NormalizedUsageQuantity, r.NormalizedUnitOfMeasure, r.Cost)
+		if err != nil {
+			c.Log.Warn("Failed to save resource report to database: ", err.Error())
+			multiErr.errs = append(multiErr.errs, err)
+		}
+	}
+
+	if len(multiErr.errs) != 0 && len(multiErr.errs) == len(reports) {
+		return multiErr
+	}
+	return nil
+}
+
+// GetUsageMonthToDate returns the usage saved for the month before the
+// identified day. The identified day itself is left out so that rerunning it
+// does not count its own saved usage as earlier usage.
 func (c *Client) GetUsageMonthToDate(id datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error) {
 	c.Log.Debug("Entering db.GetUsageMonthToDate")
 	defer c.Log.Debug("Returning db.GetUsageMonthToDate")
@@ -94,15 +135,17 @@ func (c *Client) GetUsageMonthToDate(id datamodels.ReportIdentifier) (datamodels
 
 	usageToDate := datamodels.UsageMonthToDate{}
 	err := c.Conn.QueryRow(`
-		SELECT account_number, account_name, month, year, service_type, SUM(usage_quantity), SUM(cost), region, unit_of_measure, resource
+		SELECT account_number, MAX(account_name), month, year, service_type, SUM(usage_quantity), SUM(cost), region, MAX(unit_of_measure), resource
 		FROM resource_billing
 		WHERE account_number=?
 		AND month=?
 		AND year=?
+		AND day<?
 		AND service_type=?
 		AND region=?
-		AND resource=?`,
-		id.AccountNumber, id.Month, id.Year, id.ServiceType, id.Region, id.Resource).Scan(
+		AND resource=?
+		GROUP BY account_number, month, year, service_type, region, resource`,
+		id.AccountNumber, id.Month, id.Year, id.Day, id.ServiceType, id.Region, id.Resource).Scan(
 		&usageToDate.AccountNumber,
 		&accountName,
 		&usageToDate.Month,
@@ -117,6 +160,9 @@ func (c *Client) GetUsageMonthToDate(id datamodels.ReportIdentifier) (datamodels
 	if err == sql.ErrNoRows {
 		return datamodels.UsageMonthToDate{}, nil
 	}
+	if err != nil {
+		return datamodels.UsageMonthToDate{}, err
+	}
 
 	if accountName.Valid {
 		usageToDate.AccountName = accountName.String
@@ -131,6 +177,757 @@ func (c *Client) GetUsageMonthToDate(id datamodels.ReportIdentifier) (datamodels
 	return usageToDate, nil
 }
 
+// GetDailyCost returns the total cost saved for a resource on a day.
+func (c *Client) GetDailyCost(resource string, year int, month time.Month, day int) (float64, error) {
+	c.Log.Debug("Entering db.GetDailyCost")
+	defer c.Log.Debug("Returning db.GetDailyCost")
+
+	var cost float64
+	err := c.Conn.QueryRow(`
+		SELECT COALESCE(SUM(cost), 0)
+		FROM resource_billing
+		WHERE resource=?
+		AND year=?
+		AND month=?
+		AND day=?`,
+		resource, year, month, day).Scan(&cost)
+	if err != nil {
+		return 0, err
+	}
+	return cost, nil
+}
+
+// SaveMonthToDateSnapshots saves cumulative month-to-date usage, replacing any
+// snapshot already taken for the same day.
+func (c *Client) SaveMonthToDateSnapshots(snapshots []datamodels.UsageMonthToDate) error {
+	c.Log.Debug("Entering db.SaveMonthToDateSnapshots")
+	defer c.Log.Debug("Returning db.SaveMonthToDateSnapshots")
+
+	var multiErr MultiErr
+	fetchedAt := time.Now().UTC()
+	for _, s := range snapshots {
+		_, err := c.Conn.Exec(`
+		REPLACE INTO month_to_date_snapshots
+		(id, account_number, account_name, day, month, year, service_type, region, resource, usage_quantity, unit_of_measure, normalized_usage_quantity, normalized_unit_of_measure, cost, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, s.SnapshotID(), s.AccountNumber, s.AccountName, s.Day, s.Month, s.Year, s.ServiceType, s.Region, s.Resource, s.UsageQuantity, s.UnitOfMeasure, s.NormalizedUsageQuantity, s.NormalizedUnitOfMeasure, s.Cost, fetchedAt)
+		if err != nil {
+			c.Log.Warn("Failed to save month-to-date snapshot to database: ", err.Error())
+			multiErr.errs = append(multiErr.errs, err)
+		}
+	}
+
+	if len(multiErr.errs) != 0 && len(multiErr.errs) == len(snapshots) {
+		return multiErr
+	}
+	return nil
+}
+
+// GetLatestMonthToDateSnapshot returns the most recent snapshot taken in the
+// identified month before the identified day. If there is none the returned
+// snapshot is empty and its Day is 0.
+func (c *Client) GetLatestMonthToDateSnapshot(id datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error) {
+	c.Log.Debug("Entering db.GetLatestMonthToDateSnapshot")
+	defer c.Log.Debug("Returning db.GetLatestMonthToDateSnapshot")
+
+	var (
+		accountName             sql.NullString
+		region                  sql.NullString
+		unitOfMeasure           sql.NullString
+		normalizedUnitOfMeasure sql.NullString
+	)
+
+	snapshot := datamodels.UsageMonthToDate{}
+	err := c.Conn.QueryRow(`
+		SELECT account_number, account_name, day, month, year, service_type, usage_quantity, cost, region, unit_of_measure, resource, normalized_usage_quantity, normalized_unit_of_measure
+		FROM month_to_date_snapshots
+		WHERE account_number=?
+		AND service_type=?
+		AND region=?
+		AND resource=?
+		AND year=?
+		AND month=?
+		AND day<?
+		ORDER BY day DESC
+		LIMIT 1`,
+		id.AccountNumber, id.ServiceType, id.Region, id.Resource, id.Year, id.Month, id.Day).Scan(
+		&snapshot.AccountNumber,
+		&accountName,
+		&snapshot.Day,
+		&snapshot.Month,
+		&snapshot.Year,
+		&snapshot.ServiceType,
+		&snapshot.UsageQuantity,
+		&snapshot.Cost,
+		&region,
+		&unitOfMeasure,
+		&snapshot.Resource,
+		&snapshot.NormalizedUsageQuantity,
+		&normalizedUnitOfMeasure,
+	)
+	if err == sql.ErrNoRows {
+		return datamodels.UsageMonthToDate{}, nil
+	}
+	if err != nil {
+		return datamodels.UsageMonthToDate{}, err
+	}
+
+	snapshot.AccountName = accountName.String
+	snapshot.Region = region.String
+	snapshot.UnitOfMeasure = unitOfMeasure.String
+	snapshot.NormalizedUnitOfMeasure = normalizedUnitOfMeasure.String
+
+	return snapshot, nil
+}
+
+// StartRun records the start of a run. Report versions saved by the run are
+// stamped with its ID and start time.
+func (c *Client) StartRun(run datamodels.Run) error {
+	c.Log.Debug("Entering db.StartRun")
+	defer c.Log.Debug("Returning db.StartRun")
+
+	_, err := c.Conn.Exec(`
+		INSERT INTO runs (id, started_at)
+		VALUES (?, ?)
+		`, run.ID, run.StartedAt.UTC())
+	return err
+}
+
+// FinishRun records the end of a run.
+func (c *Client) FinishRun(run datamodels.Run) error {
+	c.Log.Debug("Entering db.FinishRun")
+	defer c.Log.Debug("Returning db.FinishRun")
+
+	_, err := c.Conn.Exec(`
+		UPDATE runs SET finished_at=?
+		WHERE id=?
+		`, run.FinishedAt.UTC(), run.ID)
+	return err
+}
+
+// SaveRunIssues records the data-quality issues found during a run.
+func (c *Client) SaveRunIssues(issues []datamodels.RunIssue) error {
+	c.Log.Debug("Entering db.SaveRunIssues")
+	defer c.Log.Debug("Returning db.SaveRunIssues")
+
+	for _, issue := range issues {
+		_, err := c.Conn.Exec(`
+		INSERT INTO run_issues (run_id, resource, rule, severity, message)
+		VALUES (?, ?, ?, ?, ?)
+		`, issue.RunID, issue.Resource, issue.Rule, issue.Severity, issue.Message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveReportVersions keeps the history of every report. A report that differs
+// from its current version supersedes it, and a report that is new or changed
+// is stored as a new version ingested by the run. Unchanged reports keep their
+// current version.
+func (c *Client) SaveReportVersions(run datamodels.Run, reports datamodels.Reports) error {
+	c.Log.Debug("Entering db.SaveReportVersions")
+	defer c.Log.Debug("Returning db.SaveReportVersions")
+
+	var multiErr MultiErr
+	ingestedAt := run.StartedAt.UTC()
+	for i, r := range reports {
+		if i%1000 == 0 {
+			c.Log.Debugf("Saving report version to database %d of %d...", i, len(reports))
+		}
+		_, err := c.Conn.Exec(`
+		UPDATE resource_billing_versions SET superseded_at=?
+		WHERE id=?
+		AND superseded_at IS NULL
+		AND NOT (account_name <=> ? AND usage_quantity <=> ? AND unit_of_measure <=> ? AND cost <=> ? AND normalized_usage_quantity <=> ? AND normalized_unit_of_measure <=> ?)
+		`, ingestedAt, r.ID, r.AccountName, r.UsageQuantity, r.UnitOfMeasure, r.Cost, r.NormalizedUsageQuantity, r.NormalizedUnitOfMeasure)
+		if err != nil {
+			c.Log.Warn("Failed to supersede report version in database: ", err.Error())
+			multiErr.errs = append(multiErr.errs, err)
+			continue
+		}
+		_, err = c.Conn.Exec(`
+		INSERT INTO resource_billing_versions
+		(id, run_id, ingested_at, account_number, account_name, day, month, year, service_type, region, resource, usage_quantity, unit_of_measure, cost, normalized_usage_quantity, normalized_unit_of_measure)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		FROM DUAL
+		WHERE NOT EXISTS (SELECT 1 FROM resource_billing_versions WHERE id=? AND superseded_at IS NULL)
+		`, r.ID, run.ID, ingestedAt, r.AccountNumber, r.AccountName, r.Day, r.Month, r.Year, r.ServiceType, r.Region, r.Resource, r.UsageQuantity, r.UnitOfMeasure, r.Cost, r.NormalizedUsageQuantity, r.NormalizedUnitOfMeasure, r.ID)
+		if err != nil {
+			c.Log.Warn("Failed to save report version to database: ", err.Error())
+			multiErr.errs = append(multiErr.errs, err)
+		}
+	}
+
+	if len(multiErr.errs) != 0 && len(multiErr.errs) == len(reports) {
+		return multiErr
+	}
+	return nil
+}
+
+// GetReportsAsOf returns the reports of a month exactly as they were saved at
+// the given time, ignoring any version ingested later.
+func (c *Client) GetReportsAsOf(asOf time.Time, year int, month time.Month) (datamodels.Reports, error) {
+	c.Log.Debug("Entering db.GetReportsAsOf")
+	defer c.Log.Debug("Returning db.GetReportsAsOf")
+
+	rows, err := c.Conn.Query(`
+		SELECT id, account_number, account_name, day, month, year, service_type, region, resource, usage_quantity, unit_of_measure, cost, normalized_usage_quantity, normalized_unit_of_measure
+		FROM resource_billing_versions
+		WHERE year=?
+		AND month=?
+		AND ingested_at<=?
+		AND (superseded_at IS NULL OR superseded_at>?)
+		ORDER BY day, resource, account_number, service_type`,
+		year, month, asOf.UTC(), asOf.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := datamodels.Reports{}
+	for rows.Next() {
+		var (
+			r                       datamodels.Report
+			accountName             sql.NullString
+			region                  sql.NullString
+			unitOfMeasure           sql.NullString
+			normalizedUnitOfMeasure sql.NullString
+		)
+		err = rows.Scan(
+			&r.ID,
+			&r.AccountNumber,
+			&accountName,
+			&r.Day,
+			&r.Month,
+			&r.Year,
+			&r.ServiceType,
+			&region,
+			&r.Resource,
+			&r.UsageQuantity,
+			&unitOfMeasure,
+			&r.Cost,
+			&r.NormalizedUsageQuantity,
+			&normalizedUnitOfMeasure,
+		)
+		if err != nil {
+			return nil, err
+		}
+		r.AccountName = accountName.String
+		r.Region = region.String
+		r.UnitOfMeasure = unitOfMeasure.String
+		r.NormalizedUnitOfMeasure = normalizedUnitOfMeasure.String
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// GetReportsAsOfRun returns the reports of a month exactly as they were after
+// the given run.
+func (c *Client) GetReportsAsOfRun(runID string, year int, month time.Month) (datamodels.Reports, error) {
+	c.Log.Debug("Entering db.GetReportsAsOfRun")
+	defer c.Log.Debug("Returning db.GetReportsAsOfRun")
+
+	run, err := c.GetRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetReportsAsOf(run.StartedAt, year, month)
+}
+
+// GetRun returns the identified run. Its FinishedAt is zero if it never
+// finished.
+func (c *Client) GetRun(runID string) (datamodels.Run, error) {
+	c.Log.Debug("Entering db.GetRun")
+	defer c.Log.Debug("Returning db.GetRun")
+
+	var (
+		run        datamodels.Run
+		startedAt  mysql.NullTime
+		finishedAt mysql.NullTime
+	)
+	err := c.Conn.QueryRow(`
+		SELECT id, started_at, finished_at
+		FROM runs
+		WHERE id=?`,
+		runID).Scan(&run.ID, &startedAt, &finishedAt)
+	if err == sql.ErrNoRows {
+		return datamodels.Run{}, fmt.Errorf("No run with ID %s", runID)
+	}
+	if err != nil {
+		return datamodels.Run{}, err
+	}
+	run.StartedAt = startedAt.Time
+	run.FinishedAt = finishedAt.Time
+	return run, nil
+}
+
+// GetReports returns the saved reports of every day from from to to,
+// inclusive.
+func (c *Client) GetReports(from, to time.Time) (datamodels.Reports, error) {
+	c.Log.Debug("Entering db.GetReports")
+	defer c.Log.Debug("Returning db.GetReports")
+
+	rows, err := c.Conn.Query(`
+		SELECT id, account_number, COALESCE(account_name, ''), day, month, year, service_type, COALESCE(region, ''), resource, usage_quantity, COALESCE(unit_of_measure, ''), cost,
+		normalized_usage_quantity, COALESCE(normalized_unit_of_measure, ''), COALESCE(department_name, ''), COALESCE(cost_center, ''), COALESCE(org, ''), COALESCE(space, ''), COALESCE(tags, ''),
+		COALESCE(currency, ''), COALESCE(charge_category, ''), COALESCE(publisher, '')
+		FROM resource_billing
+		WHERE year*10000+month*100+day BETWEEN ? AND ?
+		ORDER BY year, month, day, resource, account_number, service_type`,
+		dateKey(from), dateKey(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := datamodels.Reports{}
+	for rows.Next() {
+		var r datamodels.Report
+		err = rows.Scan(&r.ID, &r.AccountNumber, &r.AccountName, &r.Day, &r.Month, &r.Year, &r.ServiceType, &r.Region, &r.Resource, &r.UsageQuantity, &r.UnitOfMeasure, &r.Cost,
+			&r.NormalizedUsageQuantity, &r.NormalizedUnitOfMeasure, &r.DepartmentName, &r.CostCenter, &r.Org, &r.Space, &r.Tags,
+			&r.Currency, &r.ChargeCategory, &r.Publisher)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// GetDailyCosts returns the cost of every service in every account on each
+// day from from to to, inclusive, along with the account's name and, for
+// Azure, its department, cost center and tags. Cloud Foundry reports are left out
+// as their cost is already in the accounts the foundations run in.
+func (c *Client) GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error) {
+	c.Log.Debug("Entering db.GetDailyCosts")
+	defer c.Log.Debug("Returning db.GetDailyCosts")
+
+	rows, err := c.Conn.Query(`
+		SELECT resource, account_number, COALESCE(account_name, ''), service_type, COALESCE(department_name, ''), COALESCE(cost_center, ''), COALESCE(tags, ''), year, month, day, SUM(cost)
+		FROM resource_billing
+		WHERE year*10000+month*100+day BETWEEN ? AND ?
+		AND resource<>?
+		GROUP BY resource, account_number, account_name, service_type, department_name, cost_center, tags, year, month, day
+		ORDER BY year, month, day`,
+		dateKey(from), dateKey(to), datamodels.CloudFoundry)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	costs := []datamodels.DailyCost{}
+	for rows.Next() {
+		var cost datamodels.DailyCost
+		err = rows.Scan(&cost.Resource, &cost.AccountNumber, &cost.AccountName, &cost.ServiceType, &cost.DepartmentName, &cost.CostCenter, &cost.Tags, &cost.Year, &cost.Month, &cost.Day, &cost.Cost)
+		if err != nil {
+			return nil, err
+		}
+		costs = append(costs, cost)
+	}
+	return costs, rows.Err()
+}
+
+// GetAllocatedCosts returns the cost allocated to each team on each day from
+// the reports of each service in an account, with the details of those
+// reports that tell who owns them.
+func (c *Client) GetAllocatedCosts(from, to time.Time) ([]datamodels.AllocatedCost, error) {
+	c.Log.Debug("Entering db.GetAllocatedCosts")
+	defer c.Log.Debug("Returning db.GetAllocatedCosts")
+
+	rows, err := c.Conn.Query(`
+		SELECT a.team, r.resource, r.account_number, COALESCE(r.account_name, ''), r.service_type, COALESCE(r.department_name, ''), COALESCE(r.cost_center, ''), COALESCE(r.tags, ''), a.year, a.month, a.day, SUM(a.cost)
+		FROM allocated_billing a
+		JOIN resource_billing r ON r.id=a.source_report_id
+		WHERE a.year*10000+a.month*100+a.day BETWEEN ? AND ?
+		AND a.resource<>?
+		GROUP BY a.team, r.resource, r.account_number, r.account_name, r.service_type, r.department_name, r.cost_center, r.tags, a.year, a.month, a.day
+		ORDER BY a.year, a.month, a.day`,
+		dateKey(from), dateKey(to), datamodels.CloudFoundry)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	costs := []datamodels.AllocatedCost{}
+	for rows.Next() {
+		var cost datamodels.AllocatedCost
+		err = rows.Scan(&cost.Team, &cost.Resource, &cost.AccountNumber, &cost.AccountName, &cost.ServiceType, &cost.DepartmentName, &cost.CostCenter, &cost.Tags, &cost.Year, &cost.Month, &cost.Day, &cost.Cost)
+		if err != nil {
+			return nil, err
+		}
+		costs = append(costs, cost)
+	}
+	return costs, rows.Err()
+}
+
+// SaveAnomalies saves detected anomalies, replacing any anomaly already found
+// for the same account and day.
+func (c *Client) SaveAnomalies(anomalies []datamodels.Anomaly) error {
+	c.Log.Debug("Entering db.SaveAnomalies")
+	defer c.Log.Debug("Returning db.SaveAnomalies")
+
+	var multiErr MultiErr
+	for _, a := range anomalies {
+		services, err := json.Marshal(a.ContributingServices)
+		if err != nil {
+			return err
+		}
+		_, err = c.Conn.Exec(`
+		REPLACE INTO anomalies
+		(id, run_id, resource, account_number, day, month, year, method, cost, expected, magnitude, score, contributing_services)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, a.ID, a.RunID, a.Resource, a.AccountNumber, a.Day, a.Month, a.Year, a.Method, a.Cost, a.Expected, a.Magnitude, a.Score, string(services))
+		if err != nil {
+			c.Log.Warn("Failed to save anomaly to database: ", err.Error())
+			multiErr.errs = append(multiErr.errs, err)
+		}
+	}
+
+	if len(multiErr.errs) != 0 && len(multiErr.errs) == len(anomalies) {
+		return multiErr
+	}
+	return nil
+}
+
+// GetAnomalies returns the anomalies found on days from from to to, inclusive.
+func (c *Client) GetAnomalies(from, to time.Time) ([]datamodels.Anomaly, error) {
+	c.Log.Debug("Entering db.GetAnomalies")
+	defer c.Log.Debug("Returning db.GetAnomalies")
+
+	rows, err := c.Conn.Query(`
+		SELECT id, run_id, resource, account_number, day, month, year, method, cost, expected, magnitude, score, contributing_services
+		FROM anomalies
+		WHERE year*10000+month*100+day BETWEEN ? AND ?
+		ORDER BY year, month, day, ABS(magnitude) DESC`,
+		dateKey(from), dateKey(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	anomalies := []datamodels.Anomaly{}
+	for rows.Next() {
+		var (
+			a        datamodels.Anomaly
+			services sql.NullString
+		)
+		err = rows.Scan(&a.ID, &a.RunID, &a.Resource, &a.AccountNumber, &a.Day, &a.Month, &a.Year, &a.Method, &a.Cost, &a.Expected, &a.Magnitude, &a.Score, &services)
+		if err != nil {
+			return nil, err
+		}
+		a.ContributingServices = []datamodels.ServiceContribution{}
+		if services.Valid && services.String != "" {
+			err = json.Unmarshal([]byte(services.String), &a.ContributingServices)
+			if err != nil {
+				return nil, err
+			}
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, rows.Err()
+}
+
+// SaveBudgetAlert records that an alert was sent. It returns false without
+// recording anything if the alert was already sent for the budget period.
+func (c *Client) SaveBudgetAlert(alert datamodels.BudgetAlert) (bool, error) {
+	c.Log.Debug("Entering db.SaveBudgetAlert")
+	defer c.Log.Debug("Returning db.SaveBudgetAlert")
+
+	result, err := c.Conn.Exec(`
+		INSERT IGNORE INTO budget_alerts
+		(budget, period, kind, threshold, run_id, amount, actual, forecast, alerted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, alert.Budget, alert.Period, alert.Kind, alert.Threshold, alert.RunID, alert.Amount, alert.Actual, alert.Forecast, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted == 1, nil
+}
+
+func (c *Client) GetTeams() ([]datamodels.Team, error) {
+	c.Log.Debug("Entering db.GetTeams")
+	defer c.Log.Debug("Returning db.GetTeams")
+
+	rows, err := c.Conn.Query(`
+		SELECT name, parent, cost_center
+		FROM teams
+		ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []datamodels.Team{}
+	for rows.Next() {
+		var team datamodels.Team
+		err = rows.Scan(&team.Name, &team.Parent, &team.CostCenter)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
+
+// SaveTeam creates the team or updates the team of the same name.
+func (c *Client) SaveTeam(team datamodels.Team) error {
+	c.Log.Debug("Entering db.SaveTeam")
+	defer c.Log.Debug("Returning db.SaveTeam")
+
+	_, err := c.Conn.Exec(`
+		INSERT INTO teams (name, parent, cost_center)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE parent=VALUES(parent), cost_center=VALUES(cost_center)
+		`, team.Name, team.Parent, team.CostCenter)
+	return err
+}
+
+func (c *Client) DeleteTeam(name string) error {
+	c.Log.Debug("Entering db.DeleteTeam")
+	defer c.Log.Debug("Returning db.DeleteTeam")
+
+	_, err := c.Conn.Exec(`DELETE FROM teams WHERE name=?`, name)
+	return err
+}
+
+func (c *Client) GetOwnershipMappings() ([]datamodels.OwnershipMapping, error) {
+	c.Log.Debug("Entering db.GetOwnershipMappings")
+	defer c.Log.Debug("Returning db.GetOwnershipMappings")
+
+	rows, err := c.Conn.Query(`
+		SELECT id, team, resource, account_number, azure_department, azure_cost_center, tag, effective_from, effective_to
+		FROM ownership_mappings
+		ORDER BY team, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mappings := []datamodels.OwnershipMapping{}
+	for rows.Next() {
+		var m datamodels.OwnershipMapping
+		err = rows.Scan(&m.ID, &m.Team, &m.Resource, &m.AccountNumber, &m.AzureDepartment, &m.AzureCostCenter, &m.Tag, &m.EffectiveFrom, &m.EffectiveTo)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+// SaveOwnershipMapping updates the mapping with the ID of the given one, or
+// when it has none creates the mapping, or updates when it ends if the same
+// mapping starting on the same day already exists.
+func (c *Client) SaveOwnershipMapping(m datamodels.OwnershipMapping) error {
+	c.Log.Debug("Entering db.SaveOwnershipMapping")
+	defer c.Log.Debug("Returning db.SaveOwnershipMapping")
+
+	if m.ID != 0 {
+		_, err := c.Conn.Exec(`
+			UPDATE ownership_mappings
+			SET team=?, resource=?, account_number=?, azure_department=?, azure_cost_center=?, tag=?, effective_from=?, effective_to=?
+			WHERE id=?
+			`, m.Team, m.Resource, m.AccountNumber, m.AzureDepartment, m.AzureCostCenter, m.Tag, m.EffectiveFrom, m.EffectiveTo, m.ID)
+		return err
+	}
+	_, err := c.Conn.Exec(`
+		INSERT INTO ownership_mappings
+		(team, resource, account_number, azure_department, azure_cost_center, tag, effective_from, effective_to)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE effective_to=VALUES(effective_to)
+		`, m.Team, m.Resource, m.AccountNumber, m.AzureDepartment, m.AzureCostCenter, m.Tag, m.EffectiveFrom, m.EffectiveTo)
+	return err
+}
+
+func (c *Client) DeleteOwnershipMapping(id int64) error {
+	c.Log.Debug("Entering db.DeleteOwnershipMapping")
+	defer c.Log.Debug("Returning db.DeleteOwnershipMapping")
+
+	_, err := c.Conn.Exec(`DELETE FROM ownership_mappings WHERE id=?`, id)
+	return err
+}
+
+// ClearAllocations deletes the allocations of the reports of an IAAS on a
+// day, so that reports no longer matched by a rule, or matched by a rule with
+// other teams, are not left allocated when the day is allocated again.
+func (c *Client) ClearAllocations(resource string, year int, month time.Month, day int) error {
+	c.Log.Debug("Entering db.ClearAllocations")
+	defer c.Log.Debug("Returning db.ClearAllocations")
+
+	_, err := c.Conn.Exec(`DELETE FROM allocated_billing WHERE resource=? AND year=? AND month=? AND day=?`, resource, year, month, day)
+	return err
+}
+
+// SaveAllocations saves the allocations. The days they are for should be
+// cleared first with ClearAllocations.
+func (c *Client) SaveAllocations(allocations []datamodels.Allocation) error {
+	c.Log.Debug("Entering db.SaveAllocations")
+	defer c.Log.Debug("Returning db.SaveAllocations")
+
+	var multiErr MultiErr
+	for _, a := range allocations {
+		_, err := c.Conn.Exec(`
+		INSERT INTO allocated_billing
+		(id, source_report_id, rule, team, share, account_number, account_name, day, month, year, service_type, region, resource, cost)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, a.ID(), a.SourceReportID, a.Rule, a.Team, a.Share, a.AccountNumber, a.AccountName, a.Day, a.Month, a.Year, a.ServiceType, a.Region, a.Resource, a.Cost)
+		if err != nil {
+			c.Log.Warn("Failed to save allocation to database: ", err.Error())
+			multiErr.errs = append(multiErr.errs, err)
+		}
+	}
+
+	if len(multiErr.errs) != 0 && len(multiErr.errs) == len(allocations) {
+		return multiErr
+	}
+	return nil
+}
+
+// SaveNamespaceCosts replaces the namespace costs of each cluster and day.
+func (c *Client) SaveNamespaceCosts(costs []datamodels.NamespaceCost) error {
+	c.Log.Debug("Entering db.SaveNamespaceCosts")
+	defer c.Log.Debug("Returning db.SaveNamespaceCosts")
+
+	cleared := make(map[string]bool)
+	for _, n := range costs {
+		day := fmt.Sprintf("%s %d-%d-%d", n.Cluster, n.Year, n.Month, n.Day)
+		if !cleared[day] {
+			_, err := c.Conn.Exec(`DELETE FROM kubernetes_namespace_costs WHERE cluster=? AND year=? AND month=? AND day=?`, n.Cluster, n.Year, n.Month, n.Day)
+			if err != nil {
+				return err
+			}
+			cleared[day] = true
+		}
+		_, err := c.Conn.Exec(`
+		INSERT INTO kubernetes_namespace_costs
+		(cluster, namespace, year, month, day, cpu_request_core_hours, cpu_usage_core_hours, memory_request_gib_hours, memory_usage_gib_hours, cpu_cost, memory_cost, cost)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, n.Cluster, n.Namespace, n.Year, n.Month, n.Day, n.CPURequestCoreHours, n.CPUUsageCoreHours, n.MemoryRequestGiBHours, n.MemoryUsageGiBHours, n.CPUCost, n.MemoryCost, n.Cost)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveAzurePriceSheet replaces the price sheet of each enrollment and month.
+func (c *Client) SaveAzurePriceSheet(prices []datamodels.AzurePrice) error {
+	c.Log.Debug("Entering db.SaveAzurePriceSheet")
+	defer c.Log.Debug("Returning db.SaveAzurePriceSheet")
+
+	cleared := make(map[string]bool)
+	for _, p := range prices {
+		period := fmt.Sprintf("%s %d-%d", p.Enrollment, p.Year, p.Month)
+		if !cleared[period] {
+			_, err := c.Conn.Exec(`DELETE FROM azure_price_sheets WHERE enrollment=? AND year=? AND month=?`, p.Enrollment, p.Year, p.Month)
+			if err != nil {
+				return err
+			}
+			cleared[period] = true
+		}
+		_, err := c.Conn.Exec(`
+		INSERT INTO azure_price_sheets
+		(enrollment, year, month, meter_id, meter_name, meter_category, meter_sub_category, part_number, unit_of_measure, included_quantity, unit_price, currency)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, p.Enrollment, p.Year, p.Month, p.MeterID, p.MeterName, p.MeterCategory, p.MeterSubCategory, p.PartNumber, p.UnitOfMeasure, p.IncludedQuantity, p.UnitPrice, p.Currency)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveAzureBalances replaces the balance summary of each enrollment and
+// month.
+func (c *Client) SaveAzureBalances(balances []datamodels.AzureBalance) error {
+	c.Log.Debug("Entering db.SaveAzureBalances")
+	defer c.Log.Debug("Returning db.SaveAzureBalances")
+
+	for _, b := range balances {
+		_, err := c.Conn.Exec(`
+		REPLACE INTO azure_balances
+		(enrollment, year, month, currency, beginning_balance, new_purchases, adjustments, utilized, service_overage, charges_billed_separately, total_overage, total_usage, marketplace_charges, ending_balance)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, b.Enrollment, b.Year, b.Month, b.Currency, b.BeginningBalance, b.NewPurchases, b.Adjustments, b.Utilized, b.ServiceOverage, b.ChargesBilledSeparately, b.TotalOverage, b.TotalUsage, b.MarketplaceCharges, b.EndingBalance)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveAzureCommitmentProjection replaces the projection of the enrollment made
+// as of the same day.
+func (c *Client) SaveAzureCommitmentProjection(p datamodels.AzureCommitmentProjection) error {
+	c.Log.Debug("Entering db.SaveAzureCommitmentProjection")
+	defer c.Log.Debug("Returning db.SaveAzureCommitmentProjection")
+
+	_, err := c.Conn.Exec(`
+		REPLACE INTO azure_commitment_projections
+		(enrollment, as_of, balance, daily_burn, burn_days, exhausted_on)
+		VALUES (?, ?, ?, ?, ?, ?)
+		`, p.Enrollment, p.AsOf, p.Balance, p.DailyBurn, p.BurnDays, p.ExhaustedOn)
+	return err
+}
+
+// SaveReconciliations replaces the reconciliation of each provider and month.
+func (c *Client) SaveReconciliations(reconciliations []datamodels.Reconciliation) error {
+	c.Log.Debug("Entering db.SaveReconciliations")
+	defer c.Log.Debug("Returning db.SaveReconciliations")
+
+	for _, r := range reconciliations {
+		_, err := c.Conn.Exec(`
+		REPLACE INTO reconciliations
+		(resource, period, invoice_total, stored_total, gap, rounding, credits, dropped_rows, missing_days, missing_dates, unexplained, tolerance, within_tolerance, reconciled_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, r.Resource, r.Period, r.InvoiceTotal, r.StoredTotal, r.Gap, r.Rounding, r.Credits, r.DroppedRows, r.MissingDays, r.MissingDates, r.Unexplained, r.Tolerance, r.WithinTolerance, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetReconciliations returns the reconciliation of each provider for the
+// month (2016-09).
+func (c *Client) GetReconciliations(period string) ([]datamodels.Reconciliation, error) {
+	c.Log.Debug("Entering db.GetReconciliations")
+	defer c.Log.Debug("Returning db.GetReconciliations")
+
+	rows, err := c.Conn.Query(`
+		SELECT resource, period, invoice_total, stored_total, gap, rounding, credits, dropped_rows, missing_days, missing_dates, unexplained, tolerance, within_tolerance
+		FROM reconciliations
+		WHERE period=?
+		ORDER BY resource`, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reconciliations := []datamodels.Reconciliation{}
+	for rows.Next() {
+		var r datamodels.Reconciliation
+		err = rows.Scan(&r.Resource, &r.Period, &r.InvoiceTotal, &r.StoredTotal, &r.Gap, &r.Rounding, &r.Credits, &r.DroppedRows, &r.MissingDays, &r.MissingDates, &r.Unexplained, &r.Tolerance, &r.WithinTolerance)
+		if err != nil {
+			return nil, err
+		}
+		reconciliations = append(reconciliations, r)
+	}
+	return reconciliations, rows.Err()
+}
+
+// dateKey turns a date into an integer such as 20160912 that can be compared
+// with the year, month and day columns.
+func dateKey(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
 func (c *Client) Close() error {
 	c.Log.Debug("Entering db.Close")
 	defer c.Log.Debug("Returning db.Close")
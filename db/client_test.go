@@ -1,6 +1,7 @@
 package db_test
 
 import (
+	"errors"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -61,6 +62,15 @@ var _ = Describe("Client", func() {
 						Region:        "some-region",
 						UnitOfMeasure: "GB",
 						Resource:      "MySpecialIAAS",
+
+						NormalizedUsageQuantity: 0.65,
+						NormalizedUnitOfMeasure: "GiB",
+						Org:                     "some-org",
+						Space:                   "some-space",
+						Tags:                    `{"team":"a"}`,
+						Currency:                "EUR",
+						ChargeCategory:          "Purchase",
+						Publisher:               "some-publisher",
 					},
 					datamodels.Report{
 						ID:            "some-other-id",
@@ -97,6 +107,14 @@ var _ = Describe("Client", func() {
 				Expect(args0[9]).To(Equal(0.65))
 				Expect(args0[10]).To(Equal("GB"))
 				Expect(args0[11]).To(Equal(12.58))
+				Expect(args0[12]).To(Equal(0.65))
+				Expect(args0[13]).To(Equal("GiB"))
+				Expect(args0[16]).To(Equal("some-org"))
+				Expect(args0[17]).To(Equal("some-space"))
+				Expect(args0[18]).To(Equal(`{"team":"a"}`))
+				Expect(args0[19]).To(Equal("EUR"))
+				Expect(args0[20]).To(Equal("Purchase"))
+				Expect(args0[21]).To(Equal("some-publisher"))
 				_, args1 := fakedb.ExecArgsForCall(1)
 				Expect(args1[0]).To(Equal("some-other-id"))
 				Expect(args1[1]).To(Equal("12345"))
@@ -110,6 +128,8 @@ var _ = Describe("Client", func() {
 				Expect(args1[9]).To(Equal(0.65))
 				Expect(args1[10]).To(Equal("GB"))
 				Expect(args1[11]).To(Equal(12.58))
+				Expect(args1[12]).To(Equal(0.0))
+				Expect(args1[13]).To(Equal(""))
 			})
 		})
 
@@ -123,4 +143,364 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("SaveResourceReports", func() {
+		var (
+			report datamodels.Report
+			err    error
+		)
+
+		BeforeEach(func() {
+			report = datamodels.Report{
+				ID:            "some-id",
+				AccountNumber: "12345",
+				AccountName:   "my-account",
+				Day:           17,
+				Month:         time.Month(3),
+				Year:          1337,
+				ServiceType:   "some-service",
+				UsageQuantity: 0.65,
+				Cost:          12.58,
+				Region:        "some-region",
+				UnitOfMeasure: "GB",
+				Resource:      "MySpecialIAAS",
+				ResourceID:    "some-vm",
+				ResourceGroup: "some-group",
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = client.SaveResourceReports(datamodels.Reports{report})
+		})
+
+		It("saves the report with its resource and the report it rolls up into", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakedb.ExecCallCount()).To(Equal(1))
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("INSERT INTO resource_billing_details"))
+			Expect(args[0]).To(Equal(report.ResourceReportID()))
+			Expect(args[1]).To(Equal("some-id"))
+			Expect(args[10]).To(Equal("some-vm"))
+			Expect(args[11]).To(Equal("some-group"))
+			Expect(args[16]).To(Equal(12.58))
+		})
+
+		Context("when every insert fails", func() {
+			BeforeEach(func() {
+				fakedb.ExecReturns(nil, errors.New("some-error"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("SaveMonthToDateSnapshots", func() {
+		var (
+			snapshot datamodels.UsageMonthToDate
+			err      error
+		)
+
+		BeforeEach(func() {
+			snapshot = datamodels.UsageMonthToDate{
+				AccountNumber: "12345",
+				AccountName:   "my-account",
+				Day:           17,
+				Month:         time.Month(3),
+				Year:          1337,
+				ServiceType:   "some-service",
+				UsageQuantity: 0.65,
+				Cost:          12.58,
+				Region:        "some-region",
+				UnitOfMeasure: "GB",
+				Resource:      "MySpecialIAAS",
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = client.SaveMonthToDateSnapshots([]datamodels.UsageMonthToDate{snapshot})
+		})
+
+		It("replaces the snapshot for the day", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakedb.ExecCallCount()).To(Equal(1))
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("REPLACE INTO month_to_date_snapshots"))
+			Expect(args[0]).To(Equal(snapshot.SnapshotID()))
+			Expect(args[3]).To(Equal(17))
+			Expect(args[13]).To(Equal(12.58))
+		})
+	})
+
+	Describe("SaveReportVersions", func() {
+		var (
+			run    datamodels.Run
+			report datamodels.Report
+			err    error
+		)
+
+		BeforeEach(func() {
+			run = datamodels.NewRun(time.Date(2016, time.October, 18, 6, 0, 0, 0, time.UTC))
+			report = datamodels.Report{
+				ID:            "some-id",
+				AccountNumber: "12345",
+				Day:           17,
+				Month:         time.Month(3),
+				Year:          1337,
+				ServiceType:   "some-service",
+				Cost:          12.58,
+				Resource:      "MySpecialIAAS",
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = client.SaveReportVersions(run, datamodels.Reports{report})
+		})
+
+		It("supersedes the current version if it changed", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakedb.ExecCallCount()).To(Equal(2))
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("UPDATE resource_billing_versions SET superseded_at=?"))
+			Expect(args[0]).To(Equal(run.StartedAt))
+			Expect(args[1]).To(Equal("some-id"))
+			Expect(args[5]).To(Equal(12.58))
+		})
+
+		It("saves a version ingested by the run unless the current one is unchanged", func() {
+			query, args := fakedb.ExecArgsForCall(1)
+			Expect(query).To(ContainSubstring("INSERT INTO resource_billing_versions"))
+			Expect(query).To(ContainSubstring("WHERE NOT EXISTS"))
+			Expect(args[0]).To(Equal("some-id"))
+			Expect(args[1]).To(Equal("20161018T060000.000000"))
+			Expect(args[2]).To(Equal(run.StartedAt))
+			Expect(args[13]).To(Equal(12.58))
+			Expect(args[16]).To(Equal("some-id"))
+		})
+
+		Context("when superseding fails", func() {
+			BeforeEach(func() {
+				fakedb.ExecReturns(nil, errors.New("some-error"))
+			})
+
+			It("does not save a new version", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(fakedb.ExecCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("GetReportsAsOf", func() {
+		It("reads the versions that were current at that time", func() {
+			fakedb.QueryReturns(nil, errors.New("some-error"))
+			asOf := time.Date(2016, time.October, 18, 6, 0, 0, 0, time.UTC)
+
+			_, err := client.GetReportsAsOf(asOf, 2016, time.September)
+			Expect(err).To(MatchError("some-error"))
+
+			Expect(fakedb.QueryCallCount()).To(Equal(1))
+			query, args := fakedb.QueryArgsForCall(0)
+			Expect(query).To(ContainSubstring("FROM resource_billing_versions"))
+			Expect(query).To(ContainSubstring("ingested_at<=?"))
+			Expect(query).To(ContainSubstring("(superseded_at IS NULL OR superseded_at>?)"))
+			Expect(args).To(Equal([]interface{}{2016, time.September, asOf, asOf}))
+		})
+	})
+
+	Describe("GetReports", func() {
+		It("reads the reports of the days", func() {
+			fakedb.QueryReturns(nil, errors.New("some-error"))
+
+			_, err := client.GetReports(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC))
+			Expect(err).To(MatchError("some-error"))
+
+			query, args := fakedb.QueryArgsForCall(0)
+			Expect(query).To(ContainSubstring("FROM resource_billing\n"))
+			Expect(query).To(ContainSubstring("year*10000+month*100+day BETWEEN ? AND ?"))
+			Expect(args).To(Equal([]interface{}{20160901, 20160930}))
+		})
+	})
+
+	Describe("GetDailyCosts", func() {
+		It("leaves out the Cloud Foundry reports", func() {
+			fakedb.QueryReturns(nil, errors.New("some-error"))
+
+			_, err := client.GetDailyCosts(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC))
+			Expect(err).To(MatchError("some-error"))
+
+			query, args := fakedb.QueryArgsForCall(0)
+			Expect(query).To(ContainSubstring("AND resource<>?"))
+			Expect(args).To(Equal([]interface{}{20160901, 20160930, "CloudFoundry"}))
+		})
+	})
+
+	Describe("GetAllocatedCosts", func() {
+		It("reads the allocations with the reports they were split from", func() {
+			fakedb.QueryReturns(nil, errors.New("some-error"))
+
+			_, err := client.GetAllocatedCosts(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC))
+			Expect(err).To(MatchError("some-error"))
+
+			query, args := fakedb.QueryArgsForCall(0)
+			Expect(query).To(ContainSubstring("JOIN resource_billing r ON r.id=a.source_report_id"))
+			Expect(args).To(Equal([]interface{}{20160901, 20160930, "CloudFoundry"}))
+		})
+	})
+
+	Describe("StartRun", func() {
+		It("records the run", func() {
+			run := datamodels.NewRun(time.Date(2016, time.October, 18, 6, 0, 0, 0, time.UTC))
+			Expect(client.StartRun(run)).To(Succeed())
+
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("INSERT INTO runs"))
+			Expect(args).To(Equal([]interface{}{"20161018T060000.000000", run.StartedAt}))
+		})
+	})
+
+	Describe("SaveRunIssues", func() {
+		It("records each issue with its run", func() {
+			err := client.SaveRunIssues([]datamodels.RunIssue{
+				{RunID: "some-run", Resource: "AWS", Rule: "non-negative-cost", Severity: "block", Message: "some-message"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("INSERT INTO run_issues"))
+			Expect(args).To(Equal([]interface{}{"some-run", "AWS", "non-negative-cost", "block", "some-message"}))
+		})
+	})
+
+	Describe("SaveBudgetAlert", func() {
+		It("only records the first alert of a budget period", func() {
+			fakedb.ExecReturns(rowsAffected(1), nil)
+			isNew, err := client.SaveBudgetAlert(datamodels.BudgetAlert{Budget: "team-x", Period: "2016-10", Kind: "actual", Threshold: 50})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isNew).To(BeTrue())
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("INSERT IGNORE INTO budget_alerts"))
+			Expect(args[:4]).To(Equal([]interface{}{"team-x", "2016-10", "actual", 50}))
+
+			fakedb.ExecReturns(rowsAffected(0), nil)
+			isNew, err = client.SaveBudgetAlert(datamodels.BudgetAlert{Budget: "team-x", Period: "2016-10", Kind: "actual", Threshold: 50})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isNew).To(BeFalse())
+		})
+	})
+
+	Describe("ClearAllocations", func() {
+		It("deletes the allocations of the IAAS and day", func() {
+			Expect(client.ClearAllocations("AWS", 2016, time.October, 3)).To(Succeed())
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("DELETE FROM allocated_billing WHERE resource=? AND year=? AND month=? AND day=?"))
+			Expect(args).To(Equal([]interface{}{"AWS", 2016, time.October, 3}))
+		})
+	})
+
+	Describe("SaveAllocations", func() {
+		It("saves each allocation", func() {
+			err := client.SaveAllocations([]datamodels.Allocation{
+				{SourceReportID: "shared", Rule: "cf", Team: "a", Share: 0.5, Cost: 5},
+				{SourceReportID: "shared", Rule: "cf", Team: "b", Share: 0.5, Cost: 5},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakedb.ExecCallCount()).To(Equal(2))
+			query, args := fakedb.ExecArgsForCall(1)
+			Expect(query).To(ContainSubstring("INSERT INTO allocated_billing"))
+			Expect(args[1:5]).To(Equal([]interface{}{"shared", "cf", "b", 0.5}))
+		})
+	})
+
+	Describe("SaveNamespaceCosts", func() {
+		It("replaces the namespace costs of each cluster and day", func() {
+			err := client.SaveNamespaceCosts([]datamodels.NamespaceCost{
+				{Cluster: "prod", Namespace: "web", Year: 2016, Month: time.September, Day: 12, CPURequestCoreHours: 24, Cost: 6},
+				{Cluster: "prod", Namespace: datamodels.IdleNamespace, Year: 2016, Month: time.September, Day: 12, Cost: 4},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakedb.ExecCallCount()).To(Equal(3))
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("DELETE FROM kubernetes_namespace_costs WHERE cluster=? AND year=? AND month=? AND day=?"))
+			Expect(args).To(Equal([]interface{}{"prod", 2016, time.September, 12}))
+			query, args = fakedb.ExecArgsForCall(1)
+			Expect(query).To(ContainSubstring("INSERT INTO kubernetes_namespace_costs"))
+			Expect(args).To(Equal([]interface{}{"prod", "web", 2016, time.September, 12, 24.0, 0.0, 0.0, 0.0, 0.0, 0.0, 6.0}))
+		})
+	})
+
+	Describe("SaveAzurePriceSheet", func() {
+		It("replaces the price sheet of each enrollment and month", func() {
+			err := client.SaveAzurePriceSheet([]datamodels.AzurePrice{
+				{Enrollment: "1337", Year: 2016, Month: time.September, MeterID: "some-meter", MeterName: "D2 v3", PartNumber: "ABC-123", UnitOfMeasure: "100 Hours", UnitPrice: 9.6, Currency: "USD"},
+				{Enrollment: "1337", Year: 2016, Month: time.September, MeterID: "other-meter", UnitPrice: 0.05, Currency: "USD"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakedb.ExecCallCount()).To(Equal(3))
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("DELETE FROM azure_price_sheets WHERE enrollment=? AND year=? AND month=?"))
+			Expect(args).To(Equal([]interface{}{"1337", 2016, time.September}))
+			query, args = fakedb.ExecArgsForCall(1)
+			Expect(query).To(ContainSubstring("INSERT INTO azure_price_sheets"))
+			Expect(args).To(Equal([]interface{}{"1337", 2016, time.September, "some-meter", "D2 v3", "", "", "ABC-123", "100 Hours", 0.0, 9.6, "USD"}))
+		})
+	})
+
+	Describe("SaveAzureBalances", func() {
+		It("replaces the balance summary of the enrollment and month", func() {
+			err := client.SaveAzureBalances([]datamodels.AzureBalance{
+				{Enrollment: "1337", Year: 2016, Month: time.September, Currency: "USD", BeginningBalance: 1000, Utilized: 400, ServiceOverage: 0, ChargesBilledSeparately: 20, TotalUsage: 400, MarketplaceCharges: 20, EndingBalance: 600},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("REPLACE INTO azure_balances"))
+			Expect(args).To(Equal([]interface{}{"1337", 2016, time.September, "USD", 1000.0, 0.0, 0.0, 400.0, 0.0, 20.0, 0.0, 400.0, 20.0, 600.0}))
+		})
+	})
+
+	Describe("SaveAzureCommitmentProjection", func() {
+		It("replaces the projection of the enrollment as of the day", func() {
+			err := client.SaveAzureCommitmentProjection(datamodels.AzureCommitmentProjection{Enrollment: "1337", AsOf: "2016-09-12", Balance: 600, DailyBurn: 20, BurnDays: 30, ExhaustedOn: "2016-10-12"})
+			Expect(err).NotTo(HaveOccurred())
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("REPLACE INTO azure_commitment_projections"))
+			Expect(args).To(Equal([]interface{}{"1337", "2016-09-12", 600.0, 20.0, 30, "2016-10-12"}))
+		})
+	})
+
+	Describe("SaveReconciliations", func() {
+		It("replaces the reconciliation of the provider and month", func() {
+			err := client.SaveReconciliations([]datamodels.Reconciliation{
+				{Resource: "AWS", Period: "2016-09", InvoiceTotal: 10, StoredTotal: 9, Gap: 1, DroppedRows: 1, Tolerance: 1, WithinTolerance: true},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("REPLACE INTO reconciliations"))
+			Expect(args[:13]).To(Equal([]interface{}{"AWS", "2016-09", 10.0, 9.0, 1.0, 0.0, 0.0, 1.0, 0.0, "", 0.0, 1.0, true}))
+		})
+	})
+
+	Describe("SaveOwnershipMapping", func() {
+		It("creates the mapping or updates when it ends", func() {
+			err := client.SaveOwnershipMapping(datamodels.OwnershipMapping{Team: "platform", Resource: "AWS", AccountNumber: "123", EffectiveFrom: "2016-10-01"})
+			Expect(err).NotTo(HaveOccurred())
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("INSERT INTO ownership_mappings"))
+			Expect(query).To(ContainSubstring("ON DUPLICATE KEY UPDATE effective_to=VALUES(effective_to)"))
+			Expect(args).To(Equal([]interface{}{"platform", "AWS", "123", "", "", "", "2016-10-01", ""}))
+		})
+
+		It("updates the mapping with the same ID", func() {
+			err := client.SaveOwnershipMapping(datamodels.OwnershipMapping{ID: 3, Team: "databases", Resource: "AWS", EffectiveFrom: "2016-10-01", EffectiveTo: "2016-12-31"})
+			Expect(err).NotTo(HaveOccurred())
+			query, args := fakedb.ExecArgsForCall(0)
+			Expect(query).To(ContainSubstring("UPDATE ownership_mappings"))
+			Expect(query).To(ContainSubstring("WHERE id=?"))
+			Expect(args).To(Equal([]interface{}{"databases", "AWS", "", "", "", "", "2016-10-01", "2016-12-31", int64(3)}))
+		})
+	})
 })
+
+type rowsAffected int64
+
+func (r rowsAffected) LastInsertId() (int64, error) { return 0, nil }
+func (r rowsAffected) RowsAffected() (int64, error) { return int64(r), nil }
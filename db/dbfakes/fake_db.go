@@ -19,6 +19,16 @@ type FakeDB struct {
 		result1 sql.Result
 		result2 error
 	}
+	QueryStub        func(string, ...interface{}) (*sql.Rows, error)
+	queryMutex       sync.RWMutex
+	queryArgsForCall []struct {
+		arg1 string
+		arg2 []interface{}
+	}
+	queryReturns struct {
+		result1 *sql.Rows
+		result2 error
+	}
 	QueryRowStub        func(string, ...interface{}) *sql.Row
 	queryRowMutex       sync.RWMutex
 	queryRowArgsForCall []struct {
@@ -86,6 +96,41 @@ func (fake *FakeDB) ExecReturns(result1 sql.Result, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeDB) Query(arg1 string, arg2 ...interface{}) (*sql.Rows, error) {
+	fake.queryMutex.Lock()
+	fake.queryArgsForCall = append(fake.queryArgsForCall, struct {
+		arg1 string
+		arg2 []interface{}
+	}{arg1, arg2})
+	fake.recordInvocation("Query", []interface{}{arg1, arg2})
+	fake.queryMutex.Unlock()
+	if fake.QueryStub != nil {
+		return fake.QueryStub(arg1, arg2...)
+	} else {
+		return fake.queryReturns.result1, fake.queryReturns.result2
+	}
+}
+
+func (fake *FakeDB) QueryCallCount() int {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	return len(fake.queryArgsForCall)
+}
+
+func (fake *FakeDB) QueryArgsForCall(i int) (string, []interface{}) {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	return fake.queryArgsForCall[i].arg1, fake.queryArgsForCall[i].arg2
+}
+
+func (fake *FakeDB) QueryReturns(result1 *sql.Rows, result2 error) {
+	fake.QueryStub = nil
+	fake.queryReturns = struct {
+		result1 *sql.Rows
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDB) QueryRow(arg1 string, arg2 ...interface{}) *sql.Row {
 	fake.queryRowMutex.Lock()
 	fake.queryRowArgsForCall = append(fake.queryRowArgsForCall, struct {
@@ -201,6 +246,8 @@ func (fake *FakeDB) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.execMutex.RLock()
 	defer fake.execMutex.RUnlock()
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
 	fake.queryRowMutex.RLock()
 	defer fake.queryRowMutex.RUnlock()
 	fake.closeMutex.RLock()
@@ -0,0 +1,25 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateAnomalies(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE anomalies (
+						id VARCHAR(40) PRIMARY KEY,
+						run_id VARCHAR(30) NOT NULL,
+						resource VARCHAR(255) NOT NULL,
+						account_number VARCHAR(255) NOT NULL,
+						day TINYINT(2) NOT NULL,
+						month TINYINT(2) NOT NULL,
+						year SMALLINT(4) NOT NULL,
+						method VARCHAR(20) NOT NULL,
+						cost DOUBLE NOT NULL,
+						expected DOUBLE NOT NULL,
+						magnitude DOUBLE NOT NULL,
+						score DOUBLE NOT NULL,
+						contributing_services TEXT,
+						INDEX (year, month, day)
+					)
+	`)
+	return err
+}
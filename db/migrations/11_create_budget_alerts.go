@@ -0,0 +1,21 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateBudgetAlerts(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE budget_alerts (
+						budget VARCHAR(255) NOT NULL,
+						period VARCHAR(10) NOT NULL,
+						kind VARCHAR(10) NOT NULL,
+						threshold SMALLINT NOT NULL,
+						run_id VARCHAR(30) NOT NULL,
+						amount DOUBLE NOT NULL,
+						actual DOUBLE NOT NULL,
+						forecast DOUBLE NOT NULL,
+						alerted_at DATETIME NOT NULL,
+						PRIMARY KEY (budget, period, kind, threshold)
+					)
+	`)
+	return err
+}
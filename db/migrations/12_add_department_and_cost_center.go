@@ -0,0 +1,12 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func AddDepartmentAndCostCenter(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					ALTER TABLE resource_billing
+					ADD COLUMN department_name VARCHAR(255),
+					ADD COLUMN cost_center VARCHAR(255)
+	`)
+	return err
+}
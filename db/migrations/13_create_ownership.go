@@ -0,0 +1,32 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateOwnership(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE teams (
+						name VARCHAR(255) PRIMARY KEY,
+						parent VARCHAR(255) NOT NULL DEFAULT '',
+						cost_center VARCHAR(255) NOT NULL DEFAULT ''
+					)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+					CREATE TABLE ownership_mappings (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						team VARCHAR(255) NOT NULL,
+						resource VARCHAR(50) NOT NULL DEFAULT '',
+						account_number VARCHAR(100) NOT NULL DEFAULT '',
+						azure_department VARCHAR(100) NOT NULL DEFAULT '',
+						azure_cost_center VARCHAR(100) NOT NULL DEFAULT '',
+						tag VARCHAR(100) NOT NULL DEFAULT '',
+						effective_from VARCHAR(10) NOT NULL DEFAULT '',
+						effective_to VARCHAR(10) NOT NULL DEFAULT '',
+						UNIQUE KEY (team, resource, account_number, azure_department, azure_cost_center, tag, effective_from)
+					)
+	`)
+	return err
+}
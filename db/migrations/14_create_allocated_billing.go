@@ -0,0 +1,27 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateAllocatedBilling(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE allocated_billing (
+						id VARCHAR(30) PRIMARY KEY,
+						source_report_id VARCHAR(30) NOT NULL,
+						rule VARCHAR(255) NOT NULL,
+						team VARCHAR(255) NOT NULL,
+						share DOUBLE NOT NULL,
+						account_number VARCHAR(255) NOT NULL,
+						account_name VARCHAR(255),
+						day TINYINT(2) NOT NULL,
+						month TINYINT(2) NOT NULL,
+						year SMALLINT(4) NOT NULL,
+						service_type VARCHAR(255) NOT NULL,
+						region VARCHAR(255),
+						resource VARCHAR(255) NOT NULL,
+						cost DOUBLE NOT NULL,
+						INDEX (source_report_id),
+						INDEX (year, month, day)
+					)
+	`)
+	return err
+}
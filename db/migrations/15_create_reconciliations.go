@@ -0,0 +1,26 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateReconciliations(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE reconciliations (
+						resource VARCHAR(50) NOT NULL,
+						period VARCHAR(7) NOT NULL,
+						invoice_total DOUBLE NOT NULL,
+						stored_total DOUBLE NOT NULL,
+						gap DOUBLE NOT NULL,
+						rounding DOUBLE NOT NULL,
+						credits DOUBLE NOT NULL,
+						dropped_rows DOUBLE NOT NULL,
+						missing_days DOUBLE NOT NULL,
+						missing_dates TEXT NOT NULL,
+						unexplained DOUBLE NOT NULL,
+						tolerance DOUBLE NOT NULL,
+						within_tolerance BOOLEAN NOT NULL,
+						reconciled_at DATETIME NOT NULL,
+						PRIMARY KEY (resource, period)
+					)
+	`)
+	return err
+}
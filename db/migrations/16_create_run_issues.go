@@ -0,0 +1,18 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateRunIssues(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE run_issues (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						run_id VARCHAR(30) NOT NULL,
+						resource VARCHAR(50) NOT NULL,
+						rule VARCHAR(50) NOT NULL,
+						severity VARCHAR(10) NOT NULL,
+						message TEXT NOT NULL,
+						INDEX (run_id)
+					)
+	`)
+	return err
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateKubernetesNamespaceCosts(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE kubernetes_namespace_costs (
+						cluster VARCHAR(255) NOT NULL,
+						namespace VARCHAR(255) NOT NULL,
+						year SMALLINT(4) NOT NULL,
+						month TINYINT(2) NOT NULL,
+						day TINYINT(2) NOT NULL,
+						cpu_request_core_hours DOUBLE NOT NULL,
+						cpu_usage_core_hours DOUBLE NOT NULL,
+						memory_request_gib_hours DOUBLE NOT NULL,
+						memory_usage_gib_hours DOUBLE NOT NULL,
+						cpu_cost DOUBLE NOT NULL,
+						memory_cost DOUBLE NOT NULL,
+						cost DOUBLE NOT NULL,
+						PRIMARY KEY (cluster, year, month, day, namespace)
+					)
+	`)
+	return err
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateAzurePriceSheets(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE azure_price_sheets (
+						enrollment VARCHAR(255) NOT NULL,
+						year SMALLINT(4) NOT NULL,
+						month TINYINT(2) NOT NULL,
+						meter_id VARCHAR(255) NOT NULL,
+						meter_name VARCHAR(255) NOT NULL,
+						meter_category VARCHAR(255) NOT NULL,
+						meter_sub_category VARCHAR(255) NOT NULL,
+						part_number VARCHAR(255) NOT NULL,
+						unit_of_measure VARCHAR(255) NOT NULL,
+						included_quantity DOUBLE NOT NULL,
+						unit_price DOUBLE NOT NULL,
+						currency VARCHAR(3) NOT NULL,
+						KEY (enrollment, year, month),
+						KEY (part_number, meter_id)
+					)
+	`)
+	return err
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateAzureBalances(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE azure_balances (
+						enrollment VARCHAR(255) NOT NULL,
+						year SMALLINT(4) NOT NULL,
+						month TINYINT(2) NOT NULL,
+						currency VARCHAR(3) NOT NULL,
+						beginning_balance DOUBLE NOT NULL,
+						new_purchases DOUBLE NOT NULL,
+						adjustments DOUBLE NOT NULL,
+						utilized DOUBLE NOT NULL,
+						service_overage DOUBLE NOT NULL,
+						charges_billed_separately DOUBLE NOT NULL,
+						total_overage DOUBLE NOT NULL,
+						total_usage DOUBLE NOT NULL,
+						marketplace_charges DOUBLE NOT NULL,
+						ending_balance DOUBLE NOT NULL,
+						PRIMARY KEY (enrollment, year, month)
+					)
+	`)
+	return err
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateAzureCommitmentProjections(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE azure_commitment_projections (
+						enrollment VARCHAR(255) NOT NULL,
+						as_of VARCHAR(10) NOT NULL,
+						balance DOUBLE NOT NULL,
+						daily_burn DOUBLE NOT NULL,
+						burn_days SMALLINT NOT NULL,
+						exhausted_on VARCHAR(10) NOT NULL,
+						PRIMARY KEY (enrollment, as_of)
+					)
+	`)
+	return err
+}
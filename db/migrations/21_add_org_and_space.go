@@ -0,0 +1,12 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func AddOrgAndSpace(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					ALTER TABLE resource_billing
+					ADD COLUMN org VARCHAR(255),
+					ADD COLUMN space VARCHAR(255)
+	`)
+	return err
+}
@@ -0,0 +1,11 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func AddTags(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					ALTER TABLE resource_billing
+					ADD COLUMN tags TEXT
+	`)
+	return err
+}
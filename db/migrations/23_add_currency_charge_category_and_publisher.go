@@ -0,0 +1,13 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func AddCurrencyChargeCategoryAndPublisher(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					ALTER TABLE resource_billing
+					ADD COLUMN currency VARCHAR(3),
+					ADD COLUMN charge_category VARCHAR(255),
+					ADD COLUMN publisher VARCHAR(255)
+	`)
+	return err
+}
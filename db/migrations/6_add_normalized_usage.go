@@ -0,0 +1,16 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func AddNormalizedUsage(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					ALTER TABLE resource_billing
+					ADD COLUMN normalized_usage_quantity DOUBLE NOT NULL DEFAULT 0,
+					ADD COLUMN normalized_unit_of_measure VARCHAR(255)
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
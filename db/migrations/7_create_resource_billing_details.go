@@ -0,0 +1,34 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateResourceBillingDetails(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE resource_billing_details (
+						id VARCHAR(30) PRIMARY KEY,
+						report_id VARCHAR(30) NOT NULL,
+						account_number VARCHAR(255) NOT NULL,
+						account_name VARCHAR(255),
+						day TINYINT(2) NOT NULL,
+						month TINYINT(2) NOT NULL,
+						year SMALLINT(4) NOT NULL,
+						service_type VARCHAR(255) NOT NULL,
+						region VARCHAR(255),
+						resource VARCHAR(255) NOT NULL,
+						resource_id VARCHAR(1024),
+						resource_group VARCHAR(255),
+						usage_quantity DOUBLE NOT NULL,
+						unit_of_measure VARCHAR(255),
+						normalized_usage_quantity DOUBLE NOT NULL DEFAULT 0,
+						normalized_unit_of_measure VARCHAR(255),
+						cost DOUBLE NOT NULL,
+						INDEX (report_id),
+						INDEX (year, month, day)
+					)
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
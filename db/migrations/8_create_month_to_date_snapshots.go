@@ -0,0 +1,31 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateMonthToDateSnapshots(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE month_to_date_snapshots (
+						id VARCHAR(30) PRIMARY KEY,
+						account_number VARCHAR(255) NOT NULL,
+						account_name VARCHAR(255),
+						day TINYINT(2) NOT NULL,
+						month TINYINT(2) NOT NULL,
+						year SMALLINT(4) NOT NULL,
+						service_type VARCHAR(255) NOT NULL,
+						region VARCHAR(255),
+						resource VARCHAR(255) NOT NULL,
+						usage_quantity DOUBLE NOT NULL,
+						unit_of_measure VARCHAR(255),
+						normalized_usage_quantity DOUBLE NOT NULL DEFAULT 0,
+						normalized_unit_of_measure VARCHAR(255),
+						cost DOUBLE NOT NULL,
+						fetched_at DATETIME NOT NULL,
+						INDEX (account_number, service_type, year, month, day)
+					)
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
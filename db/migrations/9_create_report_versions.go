@@ -0,0 +1,64 @@
+package migrations
+
+import "github.com/BurntSushi/migration"
+
+func CreateReportVersions(tx migration.LimitedTx) error {
+	_, err := tx.Exec(`
+					CREATE TABLE runs (
+						id VARCHAR(30) PRIMARY KEY,
+						started_at DATETIME(6) NOT NULL,
+						finished_at DATETIME(6)
+					)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+					CREATE TABLE resource_billing_versions (
+						id VARCHAR(30) NOT NULL,
+						run_id VARCHAR(30) NOT NULL,
+						ingested_at DATETIME(6) NOT NULL,
+						superseded_at DATETIME(6),
+						account_number VARCHAR(255) NOT NULL,
+						account_name VARCHAR(255),
+						day TINYINT(2) NOT NULL,
+						month TINYINT(2) NOT NULL,
+						year SMALLINT(4) NOT NULL,
+						service_type VARCHAR(255) NOT NULL,
+						region VARCHAR(255),
+						resource VARCHAR(255) NOT NULL,
+						usage_quantity DOUBLE NOT NULL,
+						unit_of_measure VARCHAR(255),
+						cost DOUBLE NOT NULL,
+						normalized_usage_quantity DOUBLE NOT NULL DEFAULT 0,
+						normalized_unit_of_measure VARCHAR(255),
+						PRIMARY KEY (id, run_id),
+						INDEX (year, month, ingested_at, superseded_at)
+					)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Everything saved so far becomes the first version
+	_, err = tx.Exec(`
+					INSERT INTO runs (id, started_at, finished_at)
+					VALUES ('initial', NOW(6), NOW(6))
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+					INSERT INTO resource_billing_versions
+					(id, run_id, ingested_at, account_number, account_name, day, month, year, service_type, region, resource, usage_quantity, unit_of_measure, cost, normalized_usage_quantity, normalized_unit_of_measure)
+					SELECT id, 'initial', (SELECT started_at FROM runs WHERE id='initial'), account_number, account_name, day, month, year, service_type, region, resource, usage_quantity, unit_of_measure, cost, normalized_usage_quantity, normalized_unit_of_measure
+					FROM resource_billing
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -8,4 +8,22 @@ var Migrations = []migration.Migrator{
 	DoNotLimitLengthOfValues,
 	ForgotNotNull,
 	LengthenIDsAgain,
+	AddNormalizedUsage,
+	CreateResourceBillingDetails,
+	CreateMonthToDateSnapshots,
+	CreateReportVersions,
+	CreateAnomalies,
+	CreateBudgetAlerts,
+	AddDepartmentAndCostCenter,
+	CreateOwnership,
+	CreateAllocatedBilling,
+	CreateReconciliations,
+	CreateRunIssues,
+	CreateKubernetesNamespaceCosts,
+	CreateAzurePriceSheets,
+	CreateAzureBalances,
+	CreateAzureCommitmentProjections,
+	AddOrgAndSpace,
+	AddTags,
+	AddCurrencyChargeCategoryAndPublisher,
 }
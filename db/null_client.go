@@ -1,6 +1,8 @@
 package db
 
 import (
+	"time"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/challiwill/meteorologica/datamodels"
 )
@@ -18,12 +20,162 @@ func (c *NullClient) GetUsageMonthToDate(datamodels.ReportIdentifier) (datamodel
 	return datamodels.UsageMonthToDate{}, nil
 }
 
+func (c *NullClient) GetLatestMonthToDateSnapshot(datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return datamodels.UsageMonthToDate{}, nil
+}
+
+func (c *NullClient) SaveMonthToDateSnapshots([]datamodels.UsageMonthToDate) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) GetDailyCost(string, int, time.Month, int) (float64, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return 0, nil
+}
+
 func (c *NullClient) SaveReports(datamodels.Reports) error {
 	c.log.Debug("No-op: using db.NullClient")
 	return nil
 }
 
+func (c *NullClient) SaveResourceReports(datamodels.Reports) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
 func (c *NullClient) Close() error {
 	c.log.Debug("No-op: using db.NullClient")
 	return nil
 }
+
+func (c *NullClient) StartRun(datamodels.Run) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) FinishRun(datamodels.Run) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) SaveReportVersions(datamodels.Run, datamodels.Reports) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) GetReportsAsOf(time.Time, int, time.Month) (datamodels.Reports, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return datamodels.Reports{}, nil
+}
+
+func (c *NullClient) GetReportsAsOfRun(string, int, time.Month) (datamodels.Reports, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return datamodels.Reports{}, nil
+}
+
+func (c *NullClient) GetReports(time.Time, time.Time) (datamodels.Reports, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return datamodels.Reports{}, nil
+}
+
+func (c *NullClient) GetDailyCosts(time.Time, time.Time) ([]datamodels.DailyCost, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return []datamodels.DailyCost{}, nil
+}
+
+func (c *NullClient) SaveAnomalies([]datamodels.Anomaly) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) GetAnomalies(time.Time, time.Time) ([]datamodels.Anomaly, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return []datamodels.Anomaly{}, nil
+}
+
+func (c *NullClient) SaveBudgetAlert(datamodels.BudgetAlert) (bool, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return true, nil
+}
+
+func (c *NullClient) SaveNamespaceCosts([]datamodels.NamespaceCost) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) SaveAzurePriceSheet([]datamodels.AzurePrice) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) SaveAzureBalances([]datamodels.AzureBalance) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) SaveAzureCommitmentProjection(datamodels.AzureCommitmentProjection) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) ClearAllocations(string, int, time.Month, int) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) SaveAllocations([]datamodels.Allocation) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) GetAllocatedCosts(time.Time, time.Time) ([]datamodels.AllocatedCost, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return []datamodels.AllocatedCost{}, nil
+}
+
+func (c *NullClient) SaveReconciliations([]datamodels.Reconciliation) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) GetReconciliations(string) ([]datamodels.Reconciliation, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return []datamodels.Reconciliation{}, nil
+}
+
+func (c *NullClient) SaveRunIssues([]datamodels.RunIssue) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) GetTeams() ([]datamodels.Team, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return []datamodels.Team{}, nil
+}
+
+func (c *NullClient) SaveTeam(datamodels.Team) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) DeleteTeam(string) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) GetOwnershipMappings() ([]datamodels.OwnershipMapping, error) {
+	c.log.Debug("No-op: using db.NullClient")
+	return []datamodels.OwnershipMapping{}, nil
+}
+
+func (c *NullClient) SaveOwnershipMapping(datamodels.OwnershipMapping) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
+
+func (c *NullClient) DeleteOwnershipMapping(int64) error {
+	c.log.Debug("No-op: using db.NullClient")
+	return nil
+}
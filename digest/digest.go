@@ -0,0 +1,167 @@
+package digest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+const dateFormat = "2006-01-02"
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+	GetAnomalies(from, to time.Time) ([]datamodels.Anomaly, error)
+}
+
+//go:generate counterfeiter . Mailer
+
+// Mailer delivers a digest, as plain text and as HTML, to its recipients.
+type Mailer interface {
+	Send(subject, text, html string) error
+}
+
+// Job sends a digest of the last week's spend. It is a cron job so it
+// can run on its own schedule, separate from collecting usage.
+type Job struct {
+	log      *logrus.Logger
+	location *time.Location
+	db       Database
+	mailer   Mailer
+	top      int
+}
+
+func NewJob(log *logrus.Logger, location *time.Location, db Database, mailer Mailer, top int) *Job {
+	return &Job{
+		log:      log,
+		location: location,
+		db:       db,
+		mailer:   mailer,
+		top:      top,
+	}
+}
+
+// Run sends the digest of the seven days up to and including yesterday.
+func (j *Job) Run() {
+	j.log.Debug("Entering digest.Run")
+	defer j.log.Debug("Returning digest.Run")
+
+	now := time.Now().In(j.location)
+	yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	err := j.Send(yesterday)
+	if err != nil {
+		j.log.Error("Failed to send digest: ", err.Error())
+		return
+	}
+	j.log.Info("Sent digest")
+}
+
+// Send builds, renders and mails the digest of the week ending on the date.
+func (j *Job) Send(to time.Time) error {
+	digest, err := j.Build(to)
+	if err != nil {
+		return err
+	}
+	text, html, err := Render(digest)
+	if err != nil {
+		return err
+	}
+	return j.mailer.Send(fmt.Sprintf("Spend digest %s to %s", digest.From, digest.To), text, html)
+}
+
+// Build summarises the spend of the seven days ending on the date, compared
+// with the seven days before.
+func (j *Job) Build(to time.Time) (datamodels.Digest, error) {
+	from := to.AddDate(0, 0, -6)
+	previousFrom := from.AddDate(0, 0, -7)
+	digest := datamodels.Digest{
+		From:  from.Format(dateFormat),
+		To:    to.Format(dateFormat),
+		Total: datamodels.SpendLine{Resource: "All"},
+	}
+
+	costs, err := j.db.GetDailyCosts(previousFrom, to)
+	if err != nil {
+		return digest, err
+	}
+	clouds := make(map[string]*datamodels.SpendLine)
+	accounts := make(map[string]*datamodels.SpendLine)
+	for _, cost := range costs {
+		cloud, ok := clouds[cost.Resource]
+		if !ok {
+			cloud = &datamodels.SpendLine{Resource: cost.Resource}
+			clouds[cost.Resource] = cloud
+		}
+		key := cost.Resource + "/" + cost.AccountNumber
+		account, ok := accounts[key]
+		if !ok {
+			account = &datamodels.SpendLine{Resource: cost.Resource, AccountNumber: cost.AccountNumber}
+			accounts[key] = account
+		}
+		if cost.AccountName != "" {
+			account.AccountName = cost.AccountName
+		}
+		for _, line := range []*datamodels.SpendLine{&digest.Total, cloud, account} {
+			if cost.Date().Before(from) {
+				line.Previous += cost.Cost
+			} else {
+				line.Current += cost.Cost
+			}
+		}
+	}
+
+	digest.Total = withChange(digest.Total)
+	digest.Clouds = []datamodels.SpendLine{}
+	for _, cloud := range clouds {
+		digest.Clouds = append(digest.Clouds, withChange(*cloud))
+	}
+	sort.Sort(byResource(digest.Clouds))
+	top := []datamodels.SpendLine{}
+	for _, account := range accounts {
+		top = append(top, withChange(*account))
+	}
+	sort.Sort(byCurrent(top))
+	if len(top) > j.top {
+		top = top[:j.top]
+	}
+	digest.TopAccounts = top
+
+	digest.Anomalies, err = j.db.GetAnomalies(from, to)
+	if err != nil {
+		return digest, err
+	}
+	return digest, nil
+}
+
+func withChange(line datamodels.SpendLine) datamodels.SpendLine {
+	line.Change = line.Current - line.Previous
+	if line.Previous != 0 {
+		line.ChangePercent = math.Floor(line.Change/math.Abs(line.Previous)*10000+0.5) / 100
+	}
+	return line
+}
+
+type byResource []datamodels.SpendLine
+
+func (s byResource) Len() int           { return len(s) }
+func (s byResource) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byResource) Less(i, j int) bool { return s[i].Resource < s[j].Resource }
+
+type byCurrent []datamodels.SpendLine
+
+func (s byCurrent) Len() int      { return len(s) }
+func (s byCurrent) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byCurrent) Less(i, j int) bool {
+	if s[i].Current != s[j].Current {
+		return s[i].Current > s[j].Current
+	}
+	if s[i].Resource != s[j].Resource {
+		return s[i].Resource < s[j].Resource
+	}
+	return s[i].AccountNumber < s[j].AccountNumber
+}
@@ -0,0 +1,150 @@
+package digest_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/digest"
+	"github.com/challiwill/meteorologica/digest/digestfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Job", func() {
+	var (
+		db     *digestfakes.FakeDatabase
+		mailer *digestfakes.FakeMailer
+		logs   *Buffer
+		job    *Job
+		to     time.Time
+	)
+
+	cost := func(resource, account string, day int, amount float64) datamodels.DailyCost {
+		return datamodels.DailyCost{Resource: resource, AccountNumber: account, AccountName: "name-" + account, ServiceType: "Compute", Year: 2016, Month: time.October, Day: day, Cost: amount}
+	}
+
+	BeforeEach(func() {
+		db = new(digestfakes.FakeDatabase)
+		db.GetDailyCostsReturns([]datamodels.DailyCost{
+			cost("AWS", "1", 3, 100),
+			cost("AWS", "1", 10, 150),
+			cost("AWS", "2", 9, 20),
+			cost("GCP", "3", 4, 50),
+			cost("GCP", "3", 16, 40),
+			cost("Azure", "4", 12, 10),
+		}, nil)
+		db.GetAnomaliesReturns([]datamodels.Anomaly{
+			{Resource: "AWS", AccountNumber: "1", Year: 2016, Month: time.October, Day: 10, Cost: 150, Expected: 100, Magnitude: 50},
+		}, nil)
+		mailer = new(digestfakes.FakeMailer)
+		logs = NewBuffer()
+		log := logrus.New()
+		log.Out = logs
+		job = NewJob(log, time.UTC, db, mailer, 2)
+		to = time.Date(2016, time.October, 16, 0, 0, 0, 0, time.UTC)
+	})
+
+	Describe("Build", func() {
+		It("reads this week and last week", func() {
+			_, err := job.Build(to)
+			Expect(err).NotTo(HaveOccurred())
+			from, until := db.GetDailyCostsArgsForCall(0)
+			Expect(from).To(Equal(time.Date(2016, time.October, 3, 0, 0, 0, 0, time.UTC)))
+			Expect(until).To(Equal(to))
+			from, until = db.GetAnomaliesArgsForCall(0)
+			Expect(from).To(Equal(time.Date(2016, time.October, 10, 0, 0, 0, 0, time.UTC)))
+			Expect(until).To(Equal(to))
+		})
+
+		It("summarises the spend by cloud and top accounts week over week", func() {
+			digest, err := job.Build(to)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest.From).To(Equal("2016-10-10"))
+			Expect(digest.To).To(Equal("2016-10-16"))
+			Expect(digest.Total).To(Equal(datamodels.SpendLine{Resource: "All", Current: 200, Previous: 170, Change: 30, ChangePercent: 17.65}))
+			Expect(digest.Clouds).To(Equal([]datamodels.SpendLine{
+				{Resource: "AWS", Current: 150, Previous: 120, Change: 30, ChangePercent: 25},
+				{Resource: "Azure", Current: 10, Change: 10},
+				{Resource: "GCP", Current: 40, Previous: 50, Change: -10, ChangePercent: -20},
+			}))
+			Expect(digest.TopAccounts).To(Equal([]datamodels.SpendLine{
+				{Resource: "AWS", AccountNumber: "1", AccountName: "name-1", Current: 150, Previous: 100, Change: 50, ChangePercent: 50},
+				{Resource: "GCP", AccountNumber: "3", AccountName: "name-3", Current: 40, Previous: 50, Change: -10, ChangePercent: -20},
+			}))
+			Expect(digest.Anomalies).To(HaveLen(1))
+		})
+
+		Context("when the costs cannot be read", func() {
+			BeforeEach(func() {
+				db.GetDailyCostsReturns(nil, errors.New("no db"))
+			})
+
+			It("returns the error", func() {
+				_, err := job.Build(to)
+				Expect(err).To(MatchError("no db"))
+			})
+		})
+	})
+
+	Describe("Render", func() {
+		It("renders the digest as text and HTML", func() {
+			digest, err := job.Build(to)
+			Expect(err).NotTo(HaveOccurred())
+			text, html, err := Render(digest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(text).To(HavePrefix("Spend from 2016-10-10 to 2016-10-16: 200.00 (+30.00, +17.6% week over week)\n"))
+			Expect(text).To(ContainSubstring("  Azure: 10.00 (+10.00, new)\n"))
+			Expect(text).To(ContainSubstring("  GCP name-3 (3): 40.00 (-10.00, -20.0%)\n"))
+			Expect(text).To(ContainSubstring("  2016-10-10 AWS 1: 150.00, expected 100.00 (+50.00)\n"))
+
+			Expect(html).To(ContainSubstring("<h1>Spend from 2016-10-10 to 2016-10-16</h1>"))
+			Expect(html).To(ContainSubstring("<tr><td>AWS</td><td>name-1 (1)</td><td>150.00</td><td>100.00</td><td>&#43;50.00</td><td>&#43;50.0%</td></tr>"))
+			Expect(html).To(ContainSubstring("<td>2016-10-10</td>"))
+		})
+
+		It("says when there are no anomalies", func() {
+			text, html, err := Render(datamodels.Digest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(text).To(HaveSuffix("Anomalies\n  none\n"))
+			Expect(html).To(ContainSubstring("<p>None</p>"))
+		})
+	})
+
+	Describe("Send", func() {
+		It("mails the rendered digest", func() {
+			Expect(job.Send(to)).To(Succeed())
+			Expect(mailer.SendCallCount()).To(Equal(1))
+			subject, text, html := mailer.SendArgsForCall(0)
+			Expect(subject).To(Equal("Spend digest 2016-10-10 to 2016-10-16"))
+			Expect(text).To(ContainSubstring("Top accounts"))
+			Expect(html).To(ContainSubstring("<h2>Top accounts</h2>"))
+		})
+	})
+
+	Describe("Run", func() {
+		It("sends the digest of the week up to yesterday", func() {
+			job.Run()
+			from, until := db.GetAnomaliesArgsForCall(0)
+			now := time.Now().UTC()
+			Expect(until).To(Equal(time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)))
+			Expect(from).To(Equal(until.AddDate(0, 0, -6)))
+			Expect(logs).To(Say("Sent digest"))
+		})
+
+		Context("when the mail cannot be sent", func() {
+			BeforeEach(func() {
+				mailer.SendReturns(errors.New("no smtp"))
+			})
+
+			It("logs the error", func() {
+				job.Run()
+				Expect(logs).To(Say("Failed to send digest: no smtp"))
+			})
+		})
+	})
+})
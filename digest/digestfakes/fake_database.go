@@ -0,0 +1,129 @@
+// This file was generated by counterfeiter
+package digestfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/digest"
+)
+
+type FakeDatabase struct {
+	GetDailyCostsStub        func(time.Time, time.Time) ([]datamodels.DailyCost, error)
+	getDailyCostsMutex       sync.RWMutex
+	getDailyCostsArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getDailyCostsReturns struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}
+	GetAnomaliesStub        func(time.Time, time.Time) ([]datamodels.Anomaly, error)
+	getAnomaliesMutex       sync.RWMutex
+	getAnomaliesArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getAnomaliesReturns struct {
+		result1 []datamodels.Anomaly
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) GetDailyCosts(arg1 time.Time, arg2 time.Time) ([]datamodels.DailyCost, error) {
+	fake.getDailyCostsMutex.Lock()
+	fake.getDailyCostsArgsForCall = append(fake.getDailyCostsArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetDailyCosts", []interface{}{arg1, arg2})
+	fake.getDailyCostsMutex.Unlock()
+	if fake.GetDailyCostsStub != nil {
+		return fake.GetDailyCostsStub(arg1, arg2)
+	} else {
+		return fake.getDailyCostsReturns.result1, fake.getDailyCostsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetDailyCostsCallCount() int {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return len(fake.getDailyCostsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetDailyCostsArgsForCall(i int) (time.Time, time.Time) {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return fake.getDailyCostsArgsForCall[i].arg1, fake.getDailyCostsArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) GetDailyCostsReturns(result1 []datamodels.DailyCost, result2 error) {
+	fake.GetDailyCostsStub = nil
+	fake.getDailyCostsReturns = struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) GetAnomalies(arg1 time.Time, arg2 time.Time) ([]datamodels.Anomaly, error) {
+	fake.getAnomaliesMutex.Lock()
+	fake.getAnomaliesArgsForCall = append(fake.getAnomaliesArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetAnomalies", []interface{}{arg1, arg2})
+	fake.getAnomaliesMutex.Unlock()
+	if fake.GetAnomaliesStub != nil {
+		return fake.GetAnomaliesStub(arg1, arg2)
+	} else {
+		return fake.getAnomaliesReturns.result1, fake.getAnomaliesReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetAnomaliesCallCount() int {
+	fake.getAnomaliesMutex.RLock()
+	defer fake.getAnomaliesMutex.RUnlock()
+	return len(fake.getAnomaliesArgsForCall)
+}
+
+func (fake *FakeDatabase) GetAnomaliesArgsForCall(i int) (time.Time, time.Time) {
+	fake.getAnomaliesMutex.RLock()
+	defer fake.getAnomaliesMutex.RUnlock()
+	return fake.getAnomaliesArgsForCall[i].arg1, fake.getAnomaliesArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) GetAnomaliesReturns(result1 []datamodels.Anomaly, result2 error) {
+	fake.GetAnomaliesStub = nil
+	fake.getAnomaliesReturns = struct {
+		result1 []datamodels.Anomaly
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	fake.getAnomaliesMutex.RLock()
+	defer fake.getAnomaliesMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ digest.Database = new(FakeDatabase)
@@ -0,0 +1,80 @@
+// This file was generated by counterfeiter
+package digestfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/digest"
+)
+
+type FakeMailer struct {
+	SendStub        func(string, string, string) error
+	sendMutex       sync.RWMutex
+	sendArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	sendReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeMailer) Send(arg1 string, arg2 string, arg3 string) error {
+	fake.sendMutex.Lock()
+	fake.sendArgsForCall = append(fake.sendArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("Send", []interface{}{arg1, arg2, arg3})
+	fake.sendMutex.Unlock()
+	if fake.SendStub != nil {
+		return fake.SendStub(arg1, arg2, arg3)
+	} else {
+		return fake.sendReturns.result1
+	}
+}
+
+func (fake *FakeMailer) SendCallCount() int {
+	fake.sendMutex.RLock()
+	defer fake.sendMutex.RUnlock()
+	return len(fake.sendArgsForCall)
+}
+
+func (fake *FakeMailer) SendArgsForCall(i int) (string, string, string) {
+	fake.sendMutex.RLock()
+	defer fake.sendMutex.RUnlock()
+	return fake.sendArgsForCall[i].arg1, fake.sendArgsForCall[i].arg2, fake.sendArgsForCall[i].arg3
+}
+
+func (fake *FakeMailer) SendReturns(result1 error) {
+	fake.SendStub = nil
+	fake.sendReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMailer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.sendMutex.RLock()
+	defer fake.sendMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeMailer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ digest.Mailer = new(FakeMailer)
@@ -0,0 +1,85 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+var funcs = map[string]interface{}{
+	"money":   func(f float64) string { return fmt.Sprintf("%.2f", f) },
+	"change":  func(f float64) string { return fmt.Sprintf("%+.2f", f) },
+	"percent": percent,
+	"account": account,
+	"date": func(a datamodels.Anomaly) string {
+		return fmt.Sprintf("%04d-%02d-%02d", a.Year, a.Month, a.Day)
+	},
+}
+
+func percent(line datamodels.SpendLine) string {
+	if line.Previous == 0 {
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", line.ChangePercent)
+}
+
+func account(line datamodels.SpendLine) string {
+	if line.AccountName == "" {
+		return line.AccountNumber
+	}
+	return line.AccountName + " (" + line.AccountNumber + ")"
+}
+
+var textDigest = texttemplate.Must(texttemplate.New("digest").Funcs(funcs).Parse(`Spend from {{.From}} to {{.To}}: {{money .Total.Current}} ({{change .Total.Change}}, {{percent .Total}} week over week)
+
+Spend by cloud
+{{range .Clouds}}  {{.Resource}}: {{money .Current}} ({{change .Change}}, {{percent .}})
+{{end}}
+Top accounts
+{{range .TopAccounts}}  {{.Resource}} {{account .}}: {{money .Current}} ({{change .Change}}, {{percent .}})
+{{end}}
+Anomalies
+{{range .Anomalies}}  {{date .}} {{.Resource}} {{.AccountNumber}}: {{money .Cost}}, expected {{money .Expected}} ({{change .Magnitude}})
+{{else}}  none
+{{end}}`))
+
+var htmlDigest = htmltemplate.Must(htmltemplate.New("digest").Funcs(funcs).Parse(`<html>
+<body>
+<h1>Spend from {{.From}} to {{.To}}</h1>
+<p><strong>{{money .Total.Current}}</strong> ({{change .Total.Change}}, {{percent .Total}} week over week)</p>
+<h2>Spend by cloud</h2>
+<table>
+<tr><th>Cloud</th><th>This week</th><th>Last week</th><th>Change</th><th>%</th></tr>
+{{range .Clouds}}<tr><td>{{.Resource}}</td><td>{{money .Current}}</td><td>{{money .Previous}}</td><td>{{change .Change}}</td><td>{{percent .}}</td></tr>
+{{end}}</table>
+<h2>Top accounts</h2>
+<table>
+<tr><th>Cloud</th><th>Account</th><th>This week</th><th>Last week</th><th>Change</th><th>%</th></tr>
+{{range .TopAccounts}}<tr><td>{{.Resource}}</td><td>{{account .}}</td><td>{{money .Current}}</td><td>{{money .Previous}}</td><td>{{change .Change}}</td><td>{{percent .}}</td></tr>
+{{end}}</table>
+<h2>Anomalies</h2>
+{{if .Anomalies}}<table>
+<tr><th>Day</th><th>Cloud</th><th>Account</th><th>Cost</th><th>Expected</th><th>Change</th></tr>
+{{range .Anomalies}}<tr><td>{{date .}}</td><td>{{.Resource}}</td><td>{{.AccountNumber}}</td><td>{{money .Cost}}</td><td>{{money .Expected}}</td><td>{{change .Magnitude}}</td></tr>
+{{end}}</table>{{else}}<p>None</p>{{end}}
+</body>
+</html>
+`))
+
+// Render renders the digest as plain text and as HTML.
+func Render(digest datamodels.Digest) (string, string, error) {
+	text := new(bytes.Buffer)
+	err := textDigest.Execute(text, digest)
+	if err != nil {
+		return "", "", err
+	}
+	html := new(bytes.Buffer)
+	err = htmlDigest.Execute(html, digest)
+	if err != nil {
+		return "", "", err
+	}
+	return text.String(), html.String(), nil
+}
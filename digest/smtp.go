@@ -0,0 +1,82 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// SMTPMailer sends digests as multipart/alternative emails through an SMTP
+// server, authenticating when a username is given.
+type SMTPMailer struct {
+	address string
+	auth    smtp.Auth
+	from    string
+	to      []string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string, to []string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		address: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth:    auth,
+		from:    from,
+		to:      to,
+	}
+}
+
+func (m *SMTPMailer) Send(subject, text, html string) error {
+	message, err := m.message(subject, text, html)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.address, m.auth, m.from, m.to, message)
+}
+
+func (m *SMTPMailer) message(subject, text, html string) ([]byte, error) {
+	body := new(bytes.Buffer)
+	parts := multipart.NewWriter(body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain", text},
+		{"text/html", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		_, err = qp.Write([]byte(part.content))
+		if err != nil {
+			return nil, err
+		}
+		err = qp.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := parts.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	message := new(bytes.Buffer)
+	fmt.Fprintf(message, "From: %s\r\n", m.from)
+	fmt.Fprintf(message, "To: %s\r\n", strings.Join(m.to, ", "))
+	fmt.Fprintf(message, "Subject: %s\r\n", subject)
+	fmt.Fprint(message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	_, err = body.WriteTo(message)
+	return message.Bytes(), err
+}
@@ -0,0 +1,122 @@
+package digest_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	. "github.com/challiwill/meteorologica/digest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// receivedMail is what a local SMTP stand-in was sent.
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// serveSMTP accepts a single SMTP session, just enough of the protocol for
+// net/smtp, and sends what it received on the channel.
+func serveSMTP(listener net.Listener, received chan<- receivedMail) {
+	defer GinkgoRecover()
+	conn, err := listener.Accept()
+	Expect(err).NotTo(HaveOccurred())
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	Expect(text.PrintfLine("220 localhost ready")).To(Succeed())
+	mail := receivedMail{}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			Expect(text.PrintfLine("250 localhost")).To(Succeed())
+		case "MAIL":
+			mail.from = strings.TrimSuffix(strings.TrimPrefix(line, "MAIL FROM:<"), ">")
+			Expect(text.PrintfLine("250 OK")).To(Succeed())
+		case "RCPT":
+			mail.to = append(mail.to, strings.TrimSuffix(strings.TrimPrefix(line, "RCPT TO:<"), ">"))
+			Expect(text.PrintfLine("250 OK")).To(Succeed())
+		case "DATA":
+			Expect(text.PrintfLine("354 Go ahead")).To(Succeed())
+			data, err := text.ReadDotBytes()
+			Expect(err).NotTo(HaveOccurred())
+			mail.data = string(data)
+			Expect(text.PrintfLine("250 OK")).To(Succeed())
+		case "QUIT":
+			Expect(text.PrintfLine("221 Bye")).To(Succeed())
+			received <- mail
+			return
+		default:
+			Expect(text.PrintfLine("502 Not implemented")).To(Succeed())
+		}
+	}
+}
+
+var _ = Describe("SMTPMailer", func() {
+	var (
+		listener net.Listener
+		received chan receivedMail
+		mailer   *SMTPMailer
+	)
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		received = make(chan receivedMail, 1)
+		go serveSMTP(listener, received)
+
+		host, port, err := net.SplitHostPort(listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		portNumber, err := strconv.Atoi(port)
+		Expect(err).NotTo(HaveOccurred())
+		mailer = NewSMTPMailer(host, portNumber, "", "", "meteorologica@example.com", []string{"finance@example.com", "ops@example.com"})
+	})
+
+	AfterEach(func() {
+		Expect(listener.Close()).To(Succeed())
+	})
+
+	It("sends the digest as text and HTML alternatives", func() {
+		Expect(mailer.Send("Spend digest", "Spend: 10.00", "<p>Spend: 10.00</p>")).To(Succeed())
+
+		var sent receivedMail
+		Eventually(received).Should(Receive(&sent))
+		Expect(sent.from).To(Equal("meteorologica@example.com"))
+		Expect(sent.to).To(Equal([]string{"finance@example.com", "ops@example.com"}))
+
+		message, err := mail.ReadMessage(strings.NewReader(sent.data))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(message.Header.Get("Subject")).To(Equal("Spend digest"))
+		Expect(message.Header.Get("To")).To(Equal("finance@example.com, ops@example.com"))
+		mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mediaType).To(Equal("multipart/alternative"))
+
+		parts := multipart.NewReader(bufio.NewReader(message.Body), params["boundary"])
+		var contents []string
+		for _, contentType := range []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"} {
+			part, err := parts.NextPart()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(part.Header.Get("Content-Type")).To(Equal(contentType))
+			content, err := ioutil.ReadAll(part)
+			Expect(err).NotTo(HaveOccurred())
+			contents = append(contents, string(content))
+		}
+		Expect(contents).To(Equal([]string{"Spend: 10.00", "<p>Spend: 10.00</p>"}))
+	})
+})
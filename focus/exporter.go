@@ -0,0 +1,104 @@
+package focus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/gocarina/gocsv"
+)
+
+const (
+	CSV    = "csv"
+	NDJSON = "ndjson"
+)
+
+// timeFormat is how FOCUS dates and times are written.
+const timeFormat = "2006-01-02T15:04:05Z"
+
+// Exporter writes reports as FOCUS rows, as CSV or as newline-delimited JSON.
+type Exporter struct {
+	format          string
+	billingAccounts map[string]string
+}
+
+// NewExporter returns an exporter writing the given format. The billing
+// account of each IAAS, such as the AWS master account or the Azure
+// enrollment, is given by its resource name.
+func NewExporter(format string, billingAccounts map[string]string) (*Exporter, error) {
+	if format != CSV && format != NDJSON {
+		return nil, fmt.Errorf("Unknown FOCUS format %q, must be %s or %s", format, CSV, NDJSON)
+	}
+	return &Exporter{format: format, billingAccounts: billingAccounts}, nil
+}
+
+func (e *Exporter) Name() string {
+	return "focus"
+}
+
+func (e *Exporter) Extension() string {
+	return "." + e.format
+}
+
+// Write writes the reports, with the CSV header row when header is true.
+func (e *Exporter) Write(w io.Writer, reports datamodels.Reports, header bool) error {
+	rows := e.Rows(reports)
+	if e.format == NDJSON {
+		encoder := json.NewEncoder(w)
+		for _, row := range rows {
+			err := encoder.Encode(row)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if header {
+		return gocsv.Marshal(&rows, w)
+	}
+	return gocsv.MarshalWithoutHeaders(&rows, w)
+}
+
+// Rows maps each report to a FOCUS row. Meteorologica only keeps the cost
+// that was billed, so it is also the effective cost.
+func (e *Exporter) Rows(reports datamodels.Reports) []datamodels.FocusRow {
+	rows := []datamodels.FocusRow{}
+	for _, r := range reports {
+		day := time.Date(r.Year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)
+		month := time.Date(r.Year, r.Month, 1, 0, 0, 0, 0, time.UTC)
+		consumedUnit, consumedQuantity := r.NormalizedUnitOfMeasure, r.NormalizedUsageQuantity
+		if consumedUnit == datamodels.MixedUnits {
+			consumedUnit, consumedQuantity = "", 0
+		}
+		rows = append(rows, datamodels.FocusRow{
+			BillingAccountId:   e.billingAccounts[r.Resource],
+			BillingCurrency:    billingCurrency(r),
+			BillingPeriodStart: month.Format(timeFormat),
+			BillingPeriodEnd:   month.AddDate(0, 1, 0).Format(timeFormat),
+			ChargeCategory:     chargeCategory(r),
+			ChargePeriodStart:  day.Format(timeFormat),
+			ChargePeriodEnd:    day.AddDate(0, 0, 1).Format(timeFormat),
+			BilledCost:         r.Cost,
+			EffectiveCost:      r.Cost,
+			ProviderName:       providerName(r.Resource),
+			PublisherName:      publisherName(r),
+			InvoiceIssuerName:  providerName(r.Resource),
+			SubAccountId:       r.AccountNumber,
+			SubAccountName:     r.AccountName,
+			ServiceName:        r.ServiceType,
+			ServiceCategory:    serviceCategory(r.ServiceType),
+			RegionId:           r.Region,
+			ResourceId:         r.ResourceID,
+			PricingQuantity:    r.UsageQuantity,
+			PricingUnit:        r.UnitOfMeasure,
+			ConsumedQuantity:   consumedQuantity,
+			ConsumedUnit:       consumedUnit,
+			ResourceGroup:      r.ResourceGroup,
+			DepartmentName:     r.DepartmentName,
+			CostCenter:         r.CostCenter,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,134 @@
+package focus_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/focus"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Exporter", func() {
+	var (
+		reports  datamodels.Reports
+		exporter *Exporter
+	)
+
+	BeforeEach(func() {
+		reports = datamodels.Reports{
+			{ID: "1", Resource: "AWS", AccountNumber: "123", AccountName: "prod", ServiceType: "AmazonEC2", Region: "us-east-1", Year: 2016, Month: time.September, Day: 30, UsageQuantity: 24, UnitOfMeasure: "Hrs", NormalizedUsageQuantity: 86400, NormalizedUnitOfMeasure: "s", Cost: 12.5, ResourceID: "i-1"},
+			{ID: "2", Resource: "Azure", AccountNumber: "456", ServiceType: "Storage", Year: 2016, Month: time.September, Day: 1, Cost: 3, NormalizedUnitOfMeasure: datamodels.MixedUnits, NormalizedUsageQuantity: 0, ResourceGroup: "rg", DepartmentName: "eng", CostCenter: "42"},
+			{ID: "3", Resource: "GCP", AccountNumber: "789", ServiceType: "Cloud SQL", Year: 2016, Month: time.September, Day: 1, Cost: 1},
+		}
+		var err error
+		exporter, err = NewExporter(CSV, map[string]string{"AWS": "999", "Azure": "100"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("maps each report to a FOCUS row", func() {
+		rows := exporter.Rows(reports)
+		Expect(rows).To(HaveLen(3))
+		Expect(rows[0]).To(Equal(datamodels.FocusRow{
+			BillingAccountId:   "999",
+			BillingCurrency:    "USD",
+			BillingPeriodStart: "2016-09-01T00:00:00Z",
+			BillingPeriodEnd:   "2016-10-01T00:00:00Z",
+			ChargeCategory:     "Usage",
+			ChargePeriodStart:  "2016-09-30T00:00:00Z",
+			ChargePeriodEnd:    "2016-10-01T00:00:00Z",
+			BilledCost:         12.5,
+			EffectiveCost:      12.5,
+			ProviderName:       "Amazon Web Services",
+			PublisherName:      "Amazon Web Services",
+			InvoiceIssuerName:  "Amazon Web Services",
+			SubAccountId:       "123",
+			SubAccountName:     "prod",
+			ServiceName:        "AmazonEC2",
+			ServiceCategory:    "Compute",
+			RegionId:           "us-east-1",
+			ResourceId:         "i-1",
+			PricingQuantity:    24,
+			PricingUnit:        "Hrs",
+			ConsumedQuantity:   86400,
+			ConsumedUnit:       "s",
+		}))
+	})
+
+	It("keeps the Azure and resource-level detail as extra columns", func() {
+		row := exporter.Rows(reports)[1]
+		Expect(row.ProviderName).To(Equal("Microsoft Azure"))
+		Expect(row.BillingAccountId).To(Equal("100"))
+		Expect(row.ServiceCategory).To(Equal("Storage"))
+		Expect(row.ResourceGroup).To(Equal("rg"))
+		Expect(row.DepartmentName).To(Equal("eng"))
+		Expect(row.CostCenter).To(Equal("42"))
+	})
+
+	It("takes the currency, charge category and publisher from the report", func() {
+		rows := exporter.Rows(datamodels.Reports{
+			{Resource: "Azure", ServiceType: "Marketplace: some-publisher", Currency: "EUR", ChargeCategory: datamodels.Purchase, Publisher: "some-publisher"},
+			{Resource: "Azure", ServiceType: "Microsoft.Compute", Currency: "EUR"},
+		})
+		Expect(rows[0].BillingCurrency).To(Equal("EUR"))
+		Expect(rows[0].ChargeCategory).To(Equal("Purchase"))
+		Expect(rows[0].PublisherName).To(Equal("some-publisher"))
+		Expect(rows[0].ProviderName).To(Equal("Microsoft Azure"))
+		Expect(rows[1].BillingCurrency).To(Equal("EUR"))
+		Expect(rows[1].ChargeCategory).To(Equal("Usage"))
+		Expect(rows[1].PublisherName).To(Equal("Microsoft Azure"))
+	})
+
+	It("does not report a consumed quantity in mixed units", func() {
+		row := exporter.Rows(reports)[1]
+		Expect(row.ConsumedUnit).To(BeEmpty())
+		Expect(row.ConsumedQuantity).To(BeZero())
+	})
+
+	It("categorises services it recognises and leaves the billing account of unknown ones empty", func() {
+		row := exporter.Rows(reports)[2]
+		Expect(row.ProviderName).To(Equal("Google Cloud"))
+		Expect(row.ServiceCategory).To(Equal("Databases"))
+		Expect(row.BillingAccountId).To(BeEmpty())
+		Expect(exporter.Rows(datamodels.Reports{{ServiceType: "Support"}})[0].ServiceCategory).To(Equal("Other"))
+	})
+
+	It("writes CSV with or without the header", func() {
+		out := new(bytes.Buffer)
+		Expect(exporter.Write(out, reports[:1], true)).To(Succeed())
+		Expect(exporter.Write(out, reports[2:], false)).To(Succeed())
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(HavePrefix("BillingAccountId,BillingCurrency,BillingPeriodStart,"))
+		Expect(lines[0]).To(HaveSuffix(",x_ResourceGroup,x_DepartmentName,x_CostCenter"))
+		Expect(lines[1]).To(HavePrefix("999,USD,2016-09-01T00:00:00Z,"))
+		Expect(lines[2]).To(HavePrefix(",USD,"))
+		Expect(exporter.Name()).To(Equal("focus"))
+		Expect(exporter.Extension()).To(Equal(".csv"))
+	})
+
+	It("writes NDJSON", func() {
+		var err error
+		exporter, err = NewExporter(NDJSON, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exporter.Extension()).To(Equal(".ndjson"))
+
+		out := new(bytes.Buffer)
+		Expect(exporter.Write(out, reports, true)).To(Succeed())
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(3))
+		var row datamodels.FocusRow
+		Expect(json.Unmarshal([]byte(lines[0]), &row)).To(Succeed())
+		Expect(row.SubAccountId).To(Equal("123"))
+		Expect(lines[1]).To(ContainSubstring(`"x_CostCenter":"42"`))
+	})
+
+	It("rejects an unknown format", func() {
+		_, err := NewExporter("parquet", nil)
+		Expect(err).To(MatchError(`Unknown FOCUS format "parquet", must be csv or ndjson`))
+	})
+})
@@ -0,0 +1,13 @@
+package focus_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFocus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Focus Suite")
+}
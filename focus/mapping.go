@@ -0,0 +1,78 @@
+package focus
+
+import (
+	"strings"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// providerNames are the names FOCUS data from each IAAS uses for it.
+var providerNames = map[string]string{
+	"AWS":   "Amazon Web Services",
+	"GCP":   "Google Cloud",
+	"Azure": "Microsoft Azure",
+}
+
+func providerName(resource string) string {
+	if name, ok := providerNames[resource]; ok {
+		return name
+	}
+	return resource
+}
+
+// billingCurrency returns the currency a report was billed in, or USD when the
+// IAAS does not state it.
+func billingCurrency(r datamodels.Report) string {
+	if r.Currency != "" {
+		return r.Currency
+	}
+	return "USD"
+}
+
+// chargeCategory returns the FOCUS charge category of a report, which is usage
+// unless the report is of a purchase such as a marketplace offer.
+func chargeCategory(r datamodels.Report) string {
+	if r.ChargeCategory != "" {
+		return r.ChargeCategory
+	}
+	return "Usage"
+}
+
+// publisherName returns who published the service of a report, which is the
+// IAAS itself unless the report is of a marketplace offer.
+func publisherName(r datamodels.Report) string {
+	if r.Publisher != "" {
+		return r.Publisher
+	}
+	return providerName(r.Resource)
+}
+
+// serviceCategories maps words found in the service types of each IAAS to the
+// FOCUS service category, checked in order.
+var serviceCategories = []struct {
+	category string
+	words    []string
+}{
+	{"AI and Machine Learning", []string{"machine learning", "sagemaker", "cognitive", "vision", "translate", "speech"}},
+	{"Databases", []string{"database", "rds", "sql", "dynamodb", "redshift", "elasticache", "bigtable", "datastore", "spanner", "cosmos", "redis"}},
+	{"Analytics", []string{"bigquery", "dataflow", "dataproc", "emr", "athena", "kinesis", "hdinsight", "pub/sub", "analytics"}},
+	{"Storage", []string{"storage", "s3", "glacier", "ebs", "backup", "disk"}},
+	{"Networking", []string{"network", "cloudfront", "cdn", "dns", "route 53", "vpc", "load balanc", "bandwidth", "data transfer", "gateway", "egress"}},
+	{"Security", []string{"security", "kms", "key vault", "waf", "guardduty", "shield"}},
+	{"Management and Governance", []string{"cloudwatch", "cloudtrail", "config", "monitor", "logging", "stackdriver", "insights"}},
+	{"Compute", []string{"compute", "ec2", "virtual machine", "lambda", "functions", "app engine", "app service", "container", "kubernetes", "batch", "cloud services"}},
+}
+
+// serviceCategory returns the FOCUS service category of a service type, or
+// Other when it is not recognised.
+func serviceCategory(serviceType string) string {
+	lower := strings.ToLower(serviceType)
+	for _, c := range serviceCategories {
+		for _, word := range c.words {
+			if strings.Contains(lower, word) {
+				return c.category
+			}
+		}
+	}
+	return "Other"
+}
@@ -0,0 +1,81 @@
+package forecast
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/gocarina/gocsv"
+)
+
+// ForecastDir is the directory of the billing data the forecasts are written
+// to. Tools reading the partitions skip it as it starts with _.
+const ForecastDir = "_forecasts"
+
+// CSVExporter writes the month-end and quarter-end forecasts to a CSV file
+// alongside the normalized billing data in dir after each run.
+type CSVExporter struct {
+	log        *logrus.Logger
+	location   *time.Location
+	forecaster *Forecaster
+	dir        string
+}
+
+func NewCSVExporter(log *logrus.Logger, location *time.Location, forecaster *Forecaster, dir string) *CSVExporter {
+	return &CSVExporter{
+		log:        log,
+		location:   location,
+		forecaster: forecaster,
+		dir:        dir,
+	}
+}
+
+func (e *CSVExporter) Name() string {
+	return "forecast export"
+}
+
+// Run forecasts from the day before the run, the last day with complete data.
+// The file is named after the month forecast, so a run on the first of a month
+// updates the forecast of the month that just closed.
+func (e *CSVExporter) Run(run datamodels.Run, _ datamodels.Reports) error {
+	e.log.Debug("Entering forecast.Run")
+	defer e.log.Debug("Returning forecast.Run")
+
+	runDate := run.StartedAt.In(e.location)
+	asOf := time.Date(runDate.Year(), runDate.Month(), runDate.Day()-1, 0, 0, 0, 0, time.UTC)
+
+	forecasts := []datamodels.Forecast{}
+	for _, period := range []Period{Month, Quarter} {
+		periodForecasts, err := e.forecaster.Forecast(period, asOf)
+		if err != nil {
+			return err
+		}
+		forecasts = append(forecasts, periodForecasts...)
+	}
+
+	fileName := filepath.Join(e.dir, ForecastDir, strings.Join([]string{
+		strconv.Itoa(asOf.Year()),
+		asOf.Month().String(),
+		"forecast.csv",
+	}, "-"))
+	err := os.MkdirAll(filepath.Dir(fileName), 0755)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = gocsv.MarshalFile(&forecasts, file)
+	if err != nil {
+		return err
+	}
+	e.log.Debugf("Wrote forecasts to %s", fileName)
+	return nil
+}
@@ -0,0 +1,63 @@
+package forecast_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/forecast"
+	"github.com/challiwill/meteorologica/forecast/forecastfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("CSVExporter", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "forecast")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("writes the month and quarter forecasts as of the day before the run", func() {
+		log := logrus.New()
+		log.Out = NewBuffer()
+		db := new(forecastfakes.FakeDatabase)
+		db.GetDailyCostsReturns(dailyCosts("123", time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, time.October, 10, 0, 0, 0, 0, time.UTC), constant), nil)
+		exporter := NewCSVExporter(log, time.UTC, NewForecaster(log, db, 3), filepath.Join(dir, "billing-data"))
+
+		Expect(exporter.Run(datamodels.NewRun(time.Date(2016, time.October, 11, 6, 0, 0, 0, time.UTC)), nil)).To(Succeed())
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, "billing-data", "_forecasts", "2016-October-forecast.csv"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("Resource,Account Number,Period,"))
+		Expect(string(contents)).To(ContainSubstring("AWS,123,2016-10,2016-10-01,2016-10-31,2016-10-10,run-rate,1000,3100,3100,3100"))
+		Expect(string(contents)).To(ContainSubstring("AWS,123,2016-Q4,"))
+	})
+
+	It("names the file after the month forecast when the run is on the first of the next month", func() {
+		log := logrus.New()
+		log.Out = NewBuffer()
+		db := new(forecastfakes.FakeDatabase)
+		db.GetDailyCostsReturns(dailyCosts("123", time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, time.October, 31, 0, 0, 0, 0, time.UTC), constant), nil)
+		exporter := NewCSVExporter(log, time.UTC, NewForecaster(log, db, 3), filepath.Join(dir, "billing-data"))
+
+		Expect(exporter.Run(datamodels.NewRun(time.Date(2016, time.November, 1, 6, 0, 0, 0, time.UTC)), nil)).To(Succeed())
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, "billing-data", "_forecasts", "2016-October-forecast.csv"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("AWS,123,2016-10,2016-10-01,2016-10-31,2016-10-31,"))
+		_, err = os.Stat(filepath.Join(dir, "billing-data", "_forecasts", "2016-November-forecast.csv"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,13 @@
+package forecast_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestForecast(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Forecast Suite")
+}
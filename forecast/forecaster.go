@@ -0,0 +1,269 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+type Period string
+
+const (
+	Month   Period = "month"
+	Quarter Period = "quarter"
+)
+
+func ParsePeriod(period string) (Period, error) {
+	switch Period(period) {
+	case Month, Quarter:
+		return Period(period), nil
+	}
+	return "", fmt.Errorf("Unknown forecast period %q, must be %q or %q", period, Month, Quarter)
+}
+
+// Containing returns the first and last day of the period containing date,
+// and its name.
+func (p Period) Containing(date time.Time) (time.Time, time.Time, string) {
+	if p == Quarter {
+		return calendar.QuarterContaining(date)
+	}
+	return calendar.MonthContaining(date)
+}
+
+func (p Period) months() int {
+	if p == Quarter {
+		return 3
+	}
+	return 1
+}
+
+type Model string
+
+const (
+	// RunRate assumes the rest of the period costs as much per day as the
+	// period so far.
+	RunRate Model = "run-rate"
+	// Weekday assumes each remaining day costs what the same weekday cost on
+	// average over the last eight weeks.
+	Weekday Model = "weekday"
+)
+
+// lookbackDays is how far back the weekday model averages each weekday.
+const lookbackDays = 56
+
+// boundsZ makes the bounds cover 90% of forecast errors, assuming they are
+// normally distributed.
+const boundsZ = 1.645
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+}
+
+// Forecaster forecasts the spend of a period from the days of it so far. Both
+// models are back-tested on the same days of previous periods and the one
+// that was closest is used, with bounds based on how far off it was.
+type Forecaster struct {
+	log *logrus.Logger
+	db  Database
+
+	BacktestPeriods int
+}
+
+func NewForecaster(log *logrus.Logger, db Database, backtestPeriods int) *Forecaster {
+	return &Forecaster{
+		log: log,
+		db:  db,
+
+		BacktestPeriods: backtestPeriods,
+	}
+}
+
+// Forecast forecasts the spend of each account of each resource, and of each
+// resource as a whole, by the end of the period containing asOf. The costs
+// of asOf are expected to be complete.
+func (f *Forecaster) Forecast(period Period, asOf time.Time) ([]datamodels.Forecast, error) {
+	f.log.Debug("Entering forecast.Forecast")
+	defer f.log.Debug("Returning forecast.Forecast")
+
+	asOf = day(asOf)
+	costs, err := f.load(period, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[account]*series)
+	add := func(key account, c datamodels.DailyCost) {
+		if accounts[key] == nil {
+			accounts[key] = newSeries()
+		}
+		accounts[key].add(c.Date(), c.Cost)
+	}
+	for _, c := range costs {
+		add(account{c.Resource, c.AccountNumber}, c)
+		add(account{c.Resource, datamodels.AllAccounts}, c)
+	}
+
+	keys := accountKeys{}
+	for key := range accounts {
+		keys = append(keys, key)
+	}
+	sort.Sort(keys)
+
+	forecasts := []datamodels.Forecast{}
+	for _, key := range keys {
+		forecast := f.forecast(accounts[key], period, asOf)
+		forecast.Resource = key.resource
+		forecast.AccountNumber = key.accountNumber
+		forecasts = append(forecasts, forecast)
+	}
+	return forecasts, nil
+}
+
+// ForecastMatching forecasts the spend of the costs that match by the end of
+// the period containing asOf.
+func (f *Forecaster) ForecastMatching(period Period, asOf time.Time, matches func(datamodels.DailyCost) bool) (datamodels.Forecast, error) {
+	f.log.Debug("Entering forecast.ForecastMatching")
+	defer f.log.Debug("Returning forecast.ForecastMatching")
+
+	asOf = day(asOf)
+	costs, err := f.load(period, asOf)
+	if err != nil {
+		return datamodels.Forecast{}, err
+	}
+
+	s := newSeries()
+	for _, c := range costs {
+		if matches(c) {
+			s.add(c.Date(), c.Cost)
+		}
+	}
+	return f.forecast(s, period, asOf), nil
+}
+
+// load returns the costs needed to back-test and forecast the period.
+func (f *Forecaster) load(period Period, asOf time.Time) ([]datamodels.DailyCost, error) {
+	start, _, _ := period.Containing(asOf)
+	return f.db.GetDailyCosts(start.AddDate(0, -f.BacktestPeriods*period.months(), -lookbackDays), asOf)
+}
+
+func (f *Forecaster) forecast(s *series, period Period, asOf time.Time) datamodels.Forecast {
+	start, end, name := period.Containing(asOf)
+	forecast := datamodels.Forecast{
+		Period:      name,
+		PeriodStart: start.Format("2006-01-02"),
+		PeriodEnd:   end.Format("2006-01-02"),
+		AsOf:        asOf.Format("2006-01-02"),
+		Model:       string(RunRate),
+	}
+	if s.empty() {
+		return forecast
+	}
+
+	errs := f.backtest(s, period, start, asOf)
+	model := RunRate
+	if len(errs[RunRate]) > 0 && meanAbsolute(errs[Weekday]) < meanAbsolute(errs[RunRate]) {
+		model = Weekday
+	}
+
+	actual := s.sum(start, asOf)
+	predicted := predict(s, model, start, end, asOf)
+	remaining := days(asOf, end) - 1
+
+	lower, upper := actual, actual
+	if remaining > 0 && len(errs[model]) > 0 {
+		spread := boundsZ * rootMeanSquare(errs[model])
+		lower, upper = predicted*(1-spread), predicted*(1+spread)
+	} else if remaining > 0 {
+		margin := boundsZ * s.dailyDeviation(asOf) * math.Sqrt(float64(remaining))
+		lower, upper = predicted-margin, predicted+margin
+	}
+
+	forecast.Model = string(model)
+	forecast.Actual = actual
+	forecast.Forecast = predicted
+	forecast.Lower = math.Max(lower, actual)
+	forecast.Upper = upper
+	forecast.Backtests = len(errs[model])
+	forecast.BacktestError = meanAbsolute(errs[model])
+	return forecast
+}
+
+// backtest returns the relative error each model made forecasting previous
+// periods from the same number of days into them.
+func (f *Forecaster) backtest(s *series, period Period, start, asOf time.Time) map[Model][]float64 {
+	errs := map[Model][]float64{RunRate: []float64{}, Weekday: []float64{}}
+	elapsed := days(start, asOf)
+	for i := 1; i <= f.BacktestPeriods; i++ {
+		pastStart, pastEnd, _ := period.Containing(start.AddDate(0, -i*period.months(), 0))
+		if s.first.After(pastStart) {
+			break
+		}
+		total := s.sum(pastStart, pastEnd)
+		if total <= 0 {
+			continue
+		}
+		pastAsOf := pastStart.AddDate(0, 0, elapsed-1)
+		if pastAsOf.After(pastEnd) {
+			pastAsOf = pastEnd
+		}
+		for _, model := range []Model{RunRate, Weekday} {
+			errs[model] = append(errs[model], (predict(s, model, pastStart, pastEnd, pastAsOf)-total)/total)
+		}
+	}
+	return errs
+}
+
+// predict returns the spend of the period from start to end using the costs
+// up to and including asOf.
+func predict(s *series, model Model, start, end, asOf time.Time) float64 {
+	if !asOf.Before(end) {
+		return s.sum(start, end)
+	}
+	actual := s.sum(start, asOf)
+	if model == Weekday {
+		means := s.weekdayMeans(asOf)
+		for d := asOf.AddDate(0, 0, 1); !d.After(end); d = d.AddDate(0, 0, 1) {
+			actual += means[d.Weekday()]
+		}
+		return actual
+	}
+	return actual / float64(days(start, asOf)) * float64(days(start, end))
+}
+
+// days returns the number of days from from to to, inclusive.
+func days(from, to time.Time) int {
+	return int(to.Sub(from).Hours()/24) + 1
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func meanAbsolute(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += math.Abs(v)
+	}
+	return sum / float64(len(values))
+}
+
+func rootMeanSquare(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
@@ -0,0 +1,166 @@
+package forecast_test
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/forecast"
+	"github.com/challiwill/meteorologica/forecast/forecastfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+// dailyCosts returns the cost of an account on every day from from to to,
+// inclusive, as given by cost.
+func dailyCosts(accountNumber string, from, to time.Time, cost func(time.Time) float64) []datamodels.DailyCost {
+	costs := []datamodels.DailyCost{}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		costs = append(costs, datamodels.DailyCost{Resource: "AWS", AccountNumber: accountNumber, ServiceType: "compute", Year: d.Year(), Month: d.Month(), Day: d.Day(), Cost: cost(d)})
+	}
+	return costs
+}
+
+func weekdaysOnly(d time.Time) float64 {
+	if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		return 0
+	}
+	return 100
+}
+
+func constant(d time.Time) float64 {
+	return 100
+}
+
+var _ = Describe("Forecaster", func() {
+	var (
+		db         *forecastfakes.FakeDatabase
+		forecaster *Forecaster
+		asOf       time.Time
+	)
+
+	BeforeEach(func() {
+		log := logrus.New()
+		log.Out = NewBuffer()
+		db = new(forecastfakes.FakeDatabase)
+		forecaster = NewForecaster(log, db, 3)
+		asOf = time.Date(2016, time.October, 10, 0, 0, 0, 0, time.UTC)
+	})
+
+	Describe("Forecast", func() {
+		It("reads the costs needed to back-test the previous periods", func() {
+			_, err := forecaster.Forecast(Month, asOf)
+			Expect(err).NotTo(HaveOccurred())
+			from, to := db.GetDailyCostsArgsForCall(0)
+			Expect(from).To(Equal(time.Date(2016, time.May, 6, 0, 0, 0, 0, time.UTC)))
+			Expect(to).To(Equal(asOf))
+		})
+
+		Context("without any history", func() {
+			BeforeEach(func() {
+				db.GetDailyCostsReturns(dailyCosts("123", time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC), asOf, constant), nil)
+			})
+
+			It("forecasts the run rate", func() {
+				forecasts, err := forecaster.Forecast(Month, asOf)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(forecasts).To(HaveLen(2))
+				Expect(forecasts[0]).To(Equal(datamodels.Forecast{
+					Resource:      "AWS",
+					AccountNumber: "123",
+					Period:        "2016-10",
+					PeriodStart:   "2016-10-01",
+					PeriodEnd:     "2016-10-31",
+					AsOf:          "2016-10-10",
+					Model:         "run-rate",
+					Actual:        1000,
+					Forecast:      3100,
+					Lower:         3100,
+					Upper:         3100,
+				}))
+				Expect(forecasts[1].AccountNumber).To(Equal(datamodels.AllAccounts))
+			})
+		})
+
+		Context("when the spend depends on the weekday", func() {
+			BeforeEach(func() {
+				db.GetDailyCostsReturns(append(
+					dailyCosts("123", time.Date(2016, time.May, 6, 0, 0, 0, 0, time.UTC), asOf, weekdaysOnly),
+					dailyCosts("456", time.Date(2016, time.May, 6, 0, 0, 0, 0, time.UTC), asOf, constant)...,
+				), nil)
+			})
+
+			It("uses the weekday model where it back-tested better", func() {
+				forecasts, err := forecaster.Forecast(Month, asOf)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(forecasts).To(HaveLen(3))
+
+				Expect(forecasts[0].AccountNumber).To(Equal("123"))
+				Expect(forecasts[0].Model).To(Equal("weekday"))
+				Expect(forecasts[0].Actual).To(Equal(600.0))
+				Expect(forecasts[0].Forecast).To(BeNumerically("~", 2100, 0.001))
+				Expect(forecasts[0].Backtests).To(Equal(3))
+				Expect(forecasts[0].BacktestError).To(BeNumerically("~", 0, 0.001))
+
+				Expect(forecasts[1].AccountNumber).To(Equal("456"))
+				Expect(forecasts[1].Model).To(Equal("run-rate"))
+				Expect(forecasts[1].Forecast).To(BeNumerically("~", 3100, 0.001))
+
+				Expect(forecasts[2].AccountNumber).To(Equal(datamodels.AllAccounts))
+				Expect(forecasts[2].Actual).To(Equal(1600.0))
+			})
+
+			It("forecasts the quarter", func() {
+				forecasts, err := forecaster.Forecast(Quarter, asOf)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(forecasts[1].Period).To(Equal("2016-Q4"))
+				Expect(forecasts[1].PeriodEnd).To(Equal("2016-12-31"))
+				Expect(forecasts[1].Forecast).To(BeNumerically("~", 9200, 0.001))
+			})
+		})
+	})
+
+	Context("when the spend keeps growing", func() {
+		BeforeEach(func() {
+			db.GetDailyCostsReturns(dailyCosts("123", time.Date(2016, time.May, 6, 0, 0, 0, 0, time.UTC), asOf, func(d time.Time) float64 {
+				return float64(d.YearDay())
+			}), nil)
+		})
+
+		It("bounds the forecast by how far off the back-tests were", func() {
+			forecasts, err := forecaster.Forecast(Month, asOf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(forecasts[0].BacktestError).To(BeNumerically(">", 0))
+			Expect(forecasts[0].Lower).To(BeNumerically("<", forecasts[0].Forecast))
+			Expect(forecasts[0].Upper).To(BeNumerically(">", forecasts[0].Forecast))
+			Expect(forecasts[0].Lower).To(BeNumerically(">=", forecasts[0].Actual))
+		})
+	})
+
+	Describe("ForecastMatching", func() {
+		BeforeEach(func() {
+			db.GetDailyCostsReturns(append(
+				dailyCosts("123", time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC), asOf, constant),
+				dailyCosts("456", time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC), asOf, constant)...,
+			), nil)
+		})
+
+		It("forecasts the matching costs together", func() {
+			forecast, err := forecaster.ForecastMatching(Month, asOf, func(c datamodels.DailyCost) bool {
+				return c.AccountNumber == "456"
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(forecast.Actual).To(Equal(1000.0))
+			Expect(forecast.Forecast).To(Equal(3100.0))
+		})
+
+		It("forecasts nothing when nothing matches", func() {
+			forecast, err := forecaster.ForecastMatching(Month, asOf, func(datamodels.DailyCost) bool { return false })
+			Expect(err).NotTo(HaveOccurred())
+			Expect(forecast.Forecast).To(Equal(0.0))
+			Expect(forecast.Period).To(Equal("2016-10"))
+		})
+	})
+})
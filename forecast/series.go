@@ -0,0 +1,108 @@
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+// series is a daily cost. Days without cost count as zero from the first day
+// that had any.
+type series struct {
+	first time.Time
+	costs map[time.Time]float64
+}
+
+func newSeries() *series {
+	return &series{costs: make(map[time.Time]float64)}
+}
+
+func (s *series) add(date time.Time, cost float64) {
+	if s.first.IsZero() || date.Before(s.first) {
+		s.first = date
+	}
+	s.costs[date] += cost
+}
+
+func (s *series) empty() bool {
+	return s.first.IsZero()
+}
+
+// sum returns the cost from from to to, inclusive.
+func (s *series) sum(from, to time.Time) float64 {
+	total := 0.0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		total += s.costs[d]
+	}
+	return total
+}
+
+// lookback returns the days of the lookback ending on asOf that the series
+// covers.
+func (s *series) lookback(asOf time.Time) []time.Time {
+	dates := []time.Time{}
+	for d := asOf.AddDate(0, 0, 1-lookbackDays); !d.After(asOf); d = d.AddDate(0, 0, 1) {
+		if !d.Before(s.first) {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+// weekdayMeans returns the average cost of each weekday over the lookback,
+// or of any day for weekdays the lookback does not cover.
+func (s *series) weekdayMeans(asOf time.Time) [7]float64 {
+	var (
+		means  [7]float64
+		counts [7]float64
+		total  float64
+	)
+	dates := s.lookback(asOf)
+	for _, d := range dates {
+		means[d.Weekday()] += s.costs[d]
+		counts[d.Weekday()]++
+		total += s.costs[d]
+	}
+	for i := range means {
+		if counts[i] > 0 {
+			means[i] /= counts[i]
+		} else if len(dates) > 0 {
+			means[i] = total / float64(len(dates))
+		}
+	}
+	return means
+}
+
+// dailyDeviation returns the standard deviation of the daily cost over the
+// lookback.
+func (s *series) dailyDeviation(asOf time.Time) float64 {
+	dates := s.lookback(asOf)
+	if len(dates) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, d := range dates {
+		mean += s.costs[d]
+	}
+	mean /= float64(len(dates))
+	sum := 0.0
+	for _, d := range dates {
+		sum += (s.costs[d] - mean) * (s.costs[d] - mean)
+	}
+	return math.Sqrt(sum / float64(len(dates)-1))
+}
+
+type account struct {
+	resource      string
+	accountNumber string
+}
+
+type accountKeys []account
+
+func (a accountKeys) Len() int      { return len(a) }
+func (a accountKeys) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a accountKeys) Less(i, j int) bool {
+	if a[i].resource != a[j].resource {
+		return a[i].resource < a[j].resource
+	}
+	return a[i].accountNumber < a[j].accountNumber
+}
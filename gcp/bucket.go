@@ -0,0 +1,35 @@
+package gcp
+
+import (
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	storage "google.golang.org/api/storage/v1"
+)
+
+// Bucket uploads files as objects to a GCS bucket.
+type Bucket struct {
+	StorageService StorageService
+	BucketName     string
+}
+
+func NewBucket(jsonCredentials []byte, bucketName string) (*Bucket, error) {
+	jwtConfig, err := google.JWTConfigFromJSON(jsonCredentials, "https://www.googleapis.com/auth/devstorage.read_write")
+	if err != nil {
+		return nil, err
+	}
+	service, err := storage.New(jwtConfig.Client(oauth2.NoContext))
+	if err != nil {
+		return nil, err
+	}
+	return &Bucket{
+		StorageService: &storageService{service: service},
+		BucketName:     bucketName,
+	}, nil
+}
+
+func (b *Bucket) Put(key string, file *os.File) error {
+	_, err := b.StorageService.Insert(b.BucketName, &storage.Object{Name: key}, file)
+	return err
+}
@@ -0,0 +1,48 @@
+package gcp_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+
+	. "github.com/challiwill/meteorologica/gcp"
+	"github.com/challiwill/meteorologica/gcp/gcpfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bucket", func() {
+	var (
+		service *gcpfakes.FakeStorageService
+		bucket  *Bucket
+		file    *os.File
+	)
+
+	BeforeEach(func() {
+		service = new(gcpfakes.FakeStorageService)
+		bucket = &Bucket{StorageService: service, BucketName: "exports"}
+		var err error
+		file, err = ioutil.TempFile("", "bucket")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		file.Close()
+		os.Remove(file.Name())
+	})
+
+	It("inserts the file as an object with the key as its name", func() {
+		Expect(bucket.Put("billing/2016-September-normalized-billing-data.csv", file)).To(Succeed())
+		Expect(service.InsertCallCount()).To(Equal(1))
+		bucketName, object, media := service.InsertArgsForCall(0)
+		Expect(bucketName).To(Equal("exports"))
+		Expect(object.Name).To(Equal("billing/2016-September-normalized-billing-data.csv"))
+		Expect(media).To(Equal(file))
+	})
+
+	It("returns the error when the upload fails", func() {
+		service.InsertReturns(nil, errors.New("some-error"))
+		Expect(bucket.Put("key", file)).To(MatchError("some-error"))
+	})
+})
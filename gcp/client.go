@@ -30,16 +30,22 @@ type StorageService interface {
 	Insert(string, *storage.Object, *os.File) (*storage.Object, error)
 }
 
-type DetailedUsageReport [][]byte
+type DailyUsage struct {
+	Date  time.Time
+	Usage []byte
+}
+
+type DetailedUsageReport []DailyUsage
 
 type Client struct {
-	StorageService StorageService
-	BucketName     string
-	Log            *logrus.Logger
-	Location       *time.Location
+	StorageService    StorageService
+	BucketName        string
+	RestatementWindow int
+	Log               *logrus.Logger
+	Location          *time.Location
 }
 
-func NewClient(log *logrus.Logger, location *time.Location, jsonCredentials []byte, bucketName string) (*Client, error) {
+func NewClient(log *logrus.Logger, location *time.Location, jsonCredentials []byte, bucketName string, restatementWindow int) (*Client, error) {
 	jwtConfig, err := google.JWTConfigFromJSON(jsonCredentials, "https://www.googleapis.com/auth/devstorage.read_write")
 	if err != nil {
 		return nil, err
@@ -49,10 +55,11 @@ func NewClient(log *logrus.Logger, location *time.Location, jsonCredentials []by
 		return nil, err
 	}
 	return &Client{
-		StorageService: &storageService{service: service},
-		BucketName:     bucketName,
-		Log:            log,
-		Location:       location,
+		StorageService:    &storageService{service: service},
+		BucketName:        bucketName,
+		RestatementWindow: restatementWindow,
+		Log:               log,
+		Location:          location,
 	}, nil
 }
 
@@ -73,9 +80,9 @@ func (c Client) GetNormalizedUsage() (datamodels.Reports, error) {
 	c.Log.Debug("Got monthly GCP usage")
 
 	monthlyReport := []*Usage{}
-	for i, usage := range gcpMonthlyUsage {
+	for _, daily := range gcpMonthlyUsage {
 		var readerCleaner *csv.ReaderCleaner
-		readerCleaner, err = csv.NewReaderCleaner(bytes.NewReader(usage), 18, 14) // ambiguously 18 and 14...
+		readerCleaner, err = csv.NewReaderCleaner(bytes.NewReader(daily.Usage), 18, 14) // ambiguously 18 and 14...
 		if err != nil {
 			return datamodels.Reports{}, err
 		}
@@ -83,10 +90,10 @@ func (c Client) GetNormalizedUsage() (datamodels.Reports, error) {
 		dailyReport := []*Usage{}
 		err = csv.GenerateReports(readerCleaner, &dailyReport)
 		if err != nil {
-			c.Log.Errorf("Failed to parse GCP usage for day: %d %s: %s", i+1, time.Now().In(c.Location).Month().String(), err.Error())
+			c.Log.Errorf("Failed to parse GCP usage for %s: %s", daily.Date.Format("2006-01-02"), err.Error())
 			continue
 		}
-		dailyReport = c.setDate(dailyReport, i+1)
+		dailyReport = setDate(dailyReport, daily.Date)
 		monthlyReport = append(monthlyReport, dailyReport...)
 	}
 	if len(monthlyReport) == 0 {
@@ -99,19 +106,54 @@ func (c Client) GetNormalizedUsage() (datamodels.Reports, error) {
 	return normalizedReports, nil
 }
 
+// GetInvoice adds up the billing files of every day of the month up to and
+// including yesterday. The costs of the files are saved, the credits they
+// carry alongside are not.
+func (c Client) GetInvoice(year int, month time.Month) (datamodels.Invoice, error) {
+	c.Log.Debug("Entering gcp.GetInvoice")
+	defer c.Log.Debug("Returning gcp.GetInvoice")
+
+	invoice := datamodels.Invoice{Resource: IAAS, Year: year, Month: month, DailyCosts: make(map[int]float64)}
+	yesterYear, yesterMonth, yesterday := calendar.YesterdaysDate(c.Location)
+	last := time.Date(yesterYear, yesterMonth, yesterday, 0, 0, 0, 0, time.UTC)
+	for date := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC); date.Month() == month && !date.After(last); date = date.AddDate(0, 0, 1) {
+		usage, err := c.DailyUsageReport(date.Year(), date.Month(), date.Day())
+		if err != nil {
+			c.Log.Warnf("Failed to get GCP Daily Usage for %s: %s", date.Format("2006-01-02"), err.Error())
+			continue
+		}
+		readerCleaner, err := csv.NewReaderCleaner(bytes.NewReader(usage), 18, 14)
+		if err != nil {
+			return invoice, err
+		}
+		dailyReport := []*Usage{}
+		err = csv.GenerateReports(readerCleaner, &dailyReport)
+		if err != nil {
+			c.Log.Errorf("Failed to parse GCP usage for %s: %s", date.Format("2006-01-02"), err.Error())
+			continue
+		}
+		for _, u := range dailyReport {
+			credit, _ := strconv.ParseFloat(u.Credit1Amount, 64)
+			invoice.Total += u.Cost + credit
+			invoice.Credits += credit
+			invoice.DailyCosts[date.Day()] += u.Cost
+		}
+	}
+	return invoice, nil
+}
+
 func (c Client) GetBillingData() (DetailedUsageReport, error) {
 	c.Log.Debug("Entering gcp.GetBillingData")
 	defer c.Log.Debug("Returning gcp.GetBillingData")
 
 	monthlyUsageReport := DetailedUsageReport{}
-	year, month, day := calendar.YesterdaysDate(c.Location)
-	for i := 1; i < day; i++ {
-		dailyUsage, err := c.DailyUsageReport(year, month, i)
+	for _, date := range calendar.RestatementWindow(c.Location, c.RestatementWindow) {
+		dailyUsage, err := c.DailyUsageReport(date.Year(), date.Month(), date.Day())
 		if err != nil {
-			c.Log.Warnf("Failed to get GCP Daily Usage for %s, %d: %s", time.Now().In(c.Location).Month().String(), i, err.Error())
+			c.Log.Warnf("Failed to get GCP Daily Usage for %s: %s", date.Format("2006-01-02"), err.Error())
 			continue
 		}
-		monthlyUsageReport = append(monthlyUsageReport, dailyUsage)
+		monthlyUsageReport = append(monthlyUsageReport, DailyUsage{Date: date, Usage: dailyUsage})
 	}
 	return monthlyUsageReport, nil
 }
@@ -146,10 +188,9 @@ func padDay(day int) string {
 	return d
 }
 
-func (c Client) setDate(usages []*Usage, day int) []*Usage {
-	year, month, _ := calendar.YesterdaysDate(c.Location)
+func setDate(usages []*Usage, date time.Time) []*Usage {
 	for i, _ := range usages {
-		usages[i].TimeFetched = time.Date(year, month, day, 0, 0, 0, 0, c.Location)
+		usages[i].TimeFetched = date
 	}
 	return usages
 }
@@ -47,8 +47,97 @@ var _ = Describe("Gcp", func() {
 		It("works", func() {})
 	})
 
-	XDescribe("GetBillingData", func() {
-		It("works", func() {})
+	Describe("GetInvoice", func() {
+		BeforeEach(func() {
+			service.DailyUsageStub = func(_, fileName string) (*http.Response, error) {
+				if fileName == "Billing-2016-09-03.csv" {
+					return nil, errors.New("some-error")
+				}
+				readCloser := new(gcpfakes.FakeReadCloser)
+				readCloser.ReadStub = func(p []byte) (int, error) {
+					return copy(p, gcpUsageHeader+gcpUsageRow), io.EOF
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: readCloser}, nil
+			}
+		})
+
+		It("adds up the billing file of every day of the month", func() {
+			invoice, err := client.GetInvoice(2016, time.September)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service.DailyUsageCallCount()).To(Equal(30))
+			Expect(invoice.Resource).To(Equal("GCP"))
+			Expect(invoice.Total).To(BeNumerically("~", 29*1.5, 0.0001))
+			Expect(invoice.Credits).To(BeNumerically("~", 29*-0.5, 0.0001))
+			Expect(invoice.DailyCosts).To(HaveLen(29))
+			Expect(invoice.DailyCosts[1]).To(Equal(2.0))
+			Expect(invoice.DailyCosts).NotTo(HaveKey(3))
+		})
+	})
+
+	Describe("GetBillingData", func() {
+		var (
+			report DetailedUsageReport
+			err    error
+		)
+
+		BeforeEach(func() {
+			client.RestatementWindow = 3
+		})
+
+		JustBeforeEach(func() {
+			report, err = client.GetBillingData()
+		})
+
+		Context("when the storage service returns every day", func() {
+			BeforeEach(func() {
+				service.DailyUsageStub = func(string, string) (*http.Response, error) {
+					readCloser := new(gcpfakes.FakeReadCloser)
+					readCloser.ReadStub = func(p []byte) (int, error) {
+						return copy(p, "some-usage"), io.EOF
+					}
+					return &http.Response{StatusCode: http.StatusOK, Body: readCloser}, nil
+				}
+			})
+
+			It("fetches each day of the restatement window up to and including yesterday", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(service.DailyUsageCallCount()).To(Equal(3))
+				for i := 0; i < 3; i++ {
+					date := time.Now().AddDate(0, 0, i-3)
+					_, fileName := service.DailyUsageArgsForCall(i)
+					Expect(fileName).To(Equal(fmt.Sprintf("Billing-%s.csv", date.Format("2006-01-02"))))
+				}
+			})
+
+			It("dates each day's usage", func() {
+				Expect(report).To(HaveLen(3))
+				yesterday := time.Now().AddDate(0, 0, -1)
+				Expect(report[2].Date.Format("2006-01-02")).To(Equal(yesterday.Format("2006-01-02")))
+				Expect(string(report[2].Usage)).To(Equal("some-usage"))
+			})
+		})
+
+		Context("when a day is missing", func() {
+			BeforeEach(func() {
+				service.DailyUsageStub = func(string, string) (*http.Response, error) {
+					if service.DailyUsageCallCount() == 2 {
+						return nil, errors.New("some-error")
+					}
+					readCloser := new(gcpfakes.FakeReadCloser)
+					readCloser.ReadStub = func(p []byte) (int, error) {
+						return copy(p, "some-usage"), io.EOF
+					}
+					return &http.Response{StatusCode: http.StatusOK, Body: readCloser}, nil
+				}
+			})
+
+			It("keeps the dates of the other days", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report).To(HaveLen(2))
+				Expect(report[0].Date.Format("2006-01-02")).To(Equal(time.Now().AddDate(0, 0, -3).Format("2006-01-02")))
+				Expect(report[1].Date.Format("2006-01-02")).To(Equal(time.Now().AddDate(0, 0, -1).Format("2006-01-02")))
+			})
+		})
 	})
 
 	Describe("DailyUsageReport", func() {
@@ -149,3 +238,7 @@ but really, we, know, you, want, CSV
 		})
 	})
 })
+
+var gcpUsageHeader = "Account ID,Line Item,Start Time,End Time,Project,Measurement1,Measurement1 Total Consumption,Measurement1 Units,Credit1,Credit1 Amount,Credit1 Currency,Cost,Currency,Project Number,Project ID,Project Name,Project Labels,Description\n"
+
+var gcpUsageRow = "account,com.google.cloud/services/compute-engine/VmimageN1Standard_1,2016-09-01T00:00:00-07:00,2016-09-02T00:00:00-07:00,project,com.google.cloud/services/compute-engine/VmimageN1Standard_1,86400,seconds,Sustained use discount,-0.5,USD,2,USD,123,some-project,Some Project,,Compute Engine\n"
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/units"
 )
 
 type Normalizer struct {
@@ -25,6 +26,7 @@ func (n *Normalizer) Normalize(usageReports []*Usage) datamodels.Reports {
 
 	var reports datamodels.Reports
 	for _, usage := range usageReports {
+		normalizedQuantity, normalizedUnit := units.Normalize(usage.Measurement1TotalConsumption, usage.Measurement1Units)
 		reports = append(reports, datamodels.Report{
 			ID:            usage.Hash(),
 			AccountNumber: usage.ProjectID,
@@ -38,6 +40,10 @@ func (n *Normalizer) Normalize(usageReports []*Usage) datamodels.Reports {
 			Region:        "",
 			UnitOfMeasure: usage.Measurement1Units,
 			Resource:      IAAS,
+
+			NormalizedUsageQuantity: normalizedQuantity,
+			NormalizedUnitOfMeasure: normalizedUnit,
+			Currency:                usage.Currency,
 		})
 	}
 	return reports
@@ -0,0 +1,168 @@
+package generic
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/errare"
+)
+
+// Client collects the usage of a Source.
+type Client struct {
+	Source            Source
+	client            *http.Client
+	restatementWindow int
+	log               *logrus.Logger
+	location          *time.Location
+}
+
+func NewClient(log *logrus.Logger, location *time.Location, source Source, restatementWindow int) (*Client, error) {
+	err := source.Validate()
+	if err != nil {
+		return nil, errare.NewCreationError("generic client", err.Error())
+	}
+	return &Client{
+		Source:            source,
+		client:            new(http.Client),
+		restatementWindow: restatementWindow,
+		log:               log,
+		location:          location,
+	}, nil
+}
+
+func (c Client) Name() string {
+	return c.Source.Name
+}
+
+func (c Client) GetNormalizedUsage() (datamodels.Reports, error) {
+	c.log.Infof("Getting %s usage...", c.Source.Name)
+	c.log.Debug("Entering generic.GetNormalizedUsage")
+	defer c.log.Debug("Returning generic.GetNormalizedUsage")
+
+	reports, err := c.GetResourceLevelUsage()
+	if err != nil {
+		return datamodels.Reports{}, err
+	}
+	return datamodels.ConsolidateReports(reports), nil
+}
+
+// GetResourceLevelUsage returns a report for each row of the source dated in
+// the months of the restatement window or later.
+func (c Client) GetResourceLevelUsage() (datamodels.Reports, error) {
+	c.log.Debug("Entering generic.GetResourceLevelUsage")
+	defer c.log.Debug("Returning generic.GetResourceLevelUsage")
+
+	from := calendar.RestatementWindow(c.location, c.restatementWindow)[0]
+	from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, c.location)
+
+	reports := datamodels.Reports{}
+	err := c.read(func(name string, rows []Row) error {
+		skipped := 0
+		for i, row := range rows {
+			report, err := c.Source.Mapping.Report(c.Source.Name, row, c.location)
+			if err != nil {
+				return fmt.Errorf("Failed to read row %d of %s: %s", i+1, name, err.Error())
+			}
+			if time.Date(report.Year, report.Month, report.Day, 0, 0, 0, 0, c.location).Before(from) {
+				skipped++
+				continue
+			}
+			reports = append(reports, report)
+		}
+		c.log.Debugf("Read %d rows of %s, skipping %d dated before %s", len(rows), name, skipped, from.Format("2006-01-02"))
+		return nil
+	})
+	if err != nil {
+		return datamodels.Reports{}, err
+	}
+	return reports, nil
+}
+
+// read calls found with the rows of the URL, or of each file of the path.
+func (c Client) read(found func(string, []Row) error) error {
+	if c.Source.URL != "" {
+		rows, err := c.fetch()
+		if err != nil {
+			return err
+		}
+		return found(c.Source.URL, rows)
+	}
+
+	paths, err := c.files()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		rows, err := c.rows(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("Failed to read %s: %s", path, err.Error())
+		}
+		err = found(path, rows)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// files are the path if it is a file, or the files in it, in order of name
+// and skipping hidden ones, if it is a directory.
+func (c Client) files() ([]string, error) {
+	info, err := os.Stat(c.Source.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{c.Source.Path}, nil
+	}
+
+	infos, err := ioutil.ReadDir(c.Source.Path)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, info := range infos {
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		paths = append(paths, filepath.Join(c.Source.Path, info.Name()))
+	}
+	return paths, nil
+}
+
+func (c Client) fetch() ([]Row, error) {
+	resp, err := c.client.Get(c.Source.URL)
+	if err != nil {
+		return nil, errare.NewRequestError(err, c.Source.Name)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errare.NewResponseError(resp.Status, c.Source.Name)
+	}
+	rows, err := c.rows(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %s", c.Source.URL, err.Error())
+	}
+	return rows, nil
+}
+
+func (c Client) rows(r io.Reader) ([]Row, error) {
+	if c.Source.Format == JSON {
+		return ReadJSON(r)
+	}
+	return ReadCSV(r, c.Source.Delimiter)
+}
@@ -0,0 +1,151 @@
+package generic_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/challiwill/meteorologica/generic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		log       *logrus.Logger
+		source    Source
+		yesterday time.Time
+		lastYear  time.Time
+	)
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		year, month, day := time.Now().Date()
+		yesterday = time.Date(year, month, day-1, 0, 0, 0, 0, time.UTC)
+		lastYear = yesterday.AddDate(-1, 0, 0)
+		source = Source{
+			Name: "vSphere",
+			Mapping: Mapping{
+				Columns: map[string]string{
+					AccountNumber: "Org",
+					ServiceType:   "Item",
+					Date:          "Day",
+					Cost:          "Charge",
+					ResourceID:    "VM",
+				},
+			},
+		}
+	})
+
+	It("validates the source", func() {
+		_, err := NewClient(log, time.UTC, Source{Name: "vSphere"}, 1)
+		Expect(err).To(MatchError(ContainSubstring("Source vSphere must have either a path or a url")))
+	})
+
+	Context("with a directory", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "generic")
+			Expect(err).NotTo(HaveOccurred())
+			source.Path = dir
+
+			Expect(ioutil.WriteFile(filepath.Join(dir, "a.csv"), []byte(fmt.Sprintf(
+				"Org,Item,Day,Charge,VM\neng,vCPU,%s,1.5,vm-1\neng,vCPU,%s,2,vm-2\n",
+				yesterday.Format("2006-01-02"), yesterday.Format("2006-01-02"),
+			)), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "b.csv"), []byte(fmt.Sprintf(
+				"Org,Item,Day,Charge,VM\nops,Storage,%s,4,\neng,vCPU,%s,100,vm-1\n",
+				yesterday.Format("2006-01-02"), lastYear.Format("2006-01-02"),
+			)), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, ".hidden.csv"), []byte("not,a\nbilling,file\n"), 0644)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("returns a report for each row of every file dated in the restatement window's months", func() {
+			client, err := NewClient(log, time.UTC, source, 1)
+			Expect(err).NotTo(HaveOccurred())
+			reports, err := client.GetResourceLevelUsage()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reports).To(HaveLen(3))
+			Expect(reports[0].Resource).To(Equal("vSphere"))
+			Expect(reports[0].ResourceID).To(Equal("vm-1"))
+			Expect(reports[2].AccountNumber).To(Equal("ops"))
+		})
+
+		It("consolidates the reports of each account, service and day", func() {
+			client, err := NewClient(log, time.UTC, source, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Name()).To(Equal("vSphere"))
+			reports, err := client.GetNormalizedUsage()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reports).To(HaveLen(2))
+			costs := map[string]float64{}
+			for _, r := range reports {
+				costs[r.AccountNumber] = r.Cost
+				Expect(r.ResourceID).To(BeEmpty())
+			}
+			Expect(costs).To(Equal(map[string]float64{"eng": 3.5, "ops": 4}))
+		})
+
+		It("returns an error naming the file and row that cannot be read", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "c.csv"), []byte("Org,Item,Day,Charge\neng,vCPU,yesterday,1\n"), 0644)).To(Succeed())
+			client, err := NewClient(log, time.UTC, source, 1)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.GetNormalizedUsage()
+			Expect(err).To(MatchError(fmt.Sprintf(`Failed to read row 1 of %s: Invalid date "yesterday"`, filepath.Join(dir, "c.csv"))))
+		})
+	})
+
+	Context("with a URL", func() {
+		var (
+			server *httptest.Server
+			status int
+		)
+
+		BeforeEach(func() {
+			status = http.StatusOK
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+				fmt.Fprintf(w, `[{"Org": "eng", "Item": "Seats", "Day": %q, "Charge": 12.5}]`, yesterday.Format("2006-01-02"))
+			}))
+			source.URL = server.URL
+			source.Format = JSON
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("returns a report for each object", func() {
+			client, err := NewClient(log, time.UTC, source, 1)
+			Expect(err).NotTo(HaveOccurred())
+			reports, err := client.GetNormalizedUsage()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reports).To(HaveLen(1))
+			Expect(reports[0].ServiceType).To(Equal("Seats"))
+			Expect(reports[0].Cost).To(Equal(12.5))
+			Expect(reports[0].Day).To(Equal(yesterday.Day()))
+		})
+
+		It("returns an error when the server does", func() {
+			status = http.StatusInternalServerError
+			client, err := NewClient(log, time.UTC, source, 1)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.GetNormalizedUsage()
+			Expect(err).To(MatchError("vSphere responded with error: 500 Internal Server Error"))
+		})
+	})
+})
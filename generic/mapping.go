@@ -0,0 +1,91 @@
+package generic
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/units"
+)
+
+// Report returns the report of the resource for a row, with the fields the
+// mapping gives it.
+func (m Mapping) Report(resource string, row Row, location *time.Location) (datamodels.Report, error) {
+	date, err := m.date(row, location)
+	if err != nil {
+		return datamodels.Report{}, err
+	}
+	cost, err := m.number(row, Cost)
+	if err != nil {
+		return datamodels.Report{}, err
+	}
+	quantity, err := m.number(row, UsageQuantity)
+	if err != nil {
+		return datamodels.Report{}, err
+	}
+
+	report := datamodels.Report{
+		AccountNumber:  m.value(row, AccountNumber),
+		AccountName:    m.value(row, AccountName),
+		Year:           date.Year(),
+		Month:          date.Month(),
+		Day:            date.Day(),
+		ServiceType:    m.value(row, ServiceType),
+		Region:         m.value(row, Region),
+		Resource:       resource,
+		UsageQuantity:  quantity,
+		UnitOfMeasure:  m.value(row, UnitOfMeasure),
+		Cost:           cost,
+		ResourceID:     m.value(row, ResourceID),
+		ResourceGroup:  m.value(row, ResourceGroup),
+		DepartmentName: m.value(row, DepartmentName),
+		CostCenter:     m.value(row, CostCenter),
+	}
+	report.NormalizedUsageQuantity, report.NormalizedUnitOfMeasure = units.Normalize(report.UsageQuantity, report.UnitOfMeasure)
+	report.ID = reportID(report)
+	return report, nil
+}
+
+// value is the value of the column mapped to the field or, if there is none,
+// the constant of the field.
+func (m Mapping) value(row Row, field string) string {
+	if column, ok := m.Columns[field]; ok {
+		return row[column]
+	}
+	return m.Constants[field]
+}
+
+func (m Mapping) number(row Row, field string) (float64, error) {
+	value := m.value(row, field)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s %q", field, value)
+	}
+	return number, nil
+}
+
+func (m Mapping) date(row Row, location *time.Location) (time.Time, error) {
+	value := m.value(row, Date)
+	formats := m.DateFormats
+	if len(formats) == 0 {
+		formats = []string{DefaultDateFormat}
+	}
+	for _, format := range formats {
+		date, err := time.ParseInLocation(format, value, location)
+		if err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid %s %q", Date, value)
+}
+
+func reportID(r datamodels.Report) string {
+	h := fnv.New64a()
+	h.Write([]byte(r.AccountNumber + r.ServiceType + r.Region + r.Resource))
+	return strconv.FormatUint(uint64(h.Sum64()), 10) + fmt.Sprintf("%04d%02d%02d", r.Year, int(r.Month), r.Day)
+}
@@ -0,0 +1,82 @@
+package generic_test
+
+import (
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/generic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mapping", func() {
+	var mapping Mapping
+
+	BeforeEach(func() {
+		mapping = Mapping{
+			Columns: map[string]string{
+				AccountNumber: "Org",
+				ServiceType:   "Item",
+				Date:          "Day",
+				UsageQuantity: "Hours",
+				Cost:          "Charge",
+				ResourceID:    "VM",
+			},
+			Constants: map[string]string{
+				Region:        "on-prem",
+				UnitOfMeasure: "Hours",
+			},
+			DateFormats: []string{"01/02/2006", "2006-01-02"},
+		}
+	})
+
+	It("sets the fields of the report from the mapped columns and constants", func() {
+		report, err := mapping.Report("vSphere", Row{"Org": "eng", "Item": "vCPU", "Day": "09/12/2016", "Hours": "24", "Charge": "3.5", "VM": "vm-1"}, time.UTC)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.ID).NotTo(BeEmpty())
+		report.ID = ""
+		Expect(report).To(Equal(datamodels.Report{
+			AccountNumber:           "eng",
+			Year:                    2016,
+			Month:                   time.September,
+			Day:                     12,
+			ServiceType:             "vCPU",
+			Region:                  "on-prem",
+			Resource:                "vSphere",
+			UsageQuantity:           24,
+			UnitOfMeasure:           "Hours",
+			Cost:                    3.5,
+			NormalizedUsageQuantity: 24,
+			NormalizedUnitOfMeasure: "Hours",
+			ResourceID:              "vm-1",
+		}))
+	})
+
+	It("tries each date format in order", func() {
+		report, err := mapping.Report("vSphere", Row{"Day": "2016-09-13", "Charge": "1"}, time.UTC)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Day).To(Equal(13))
+	})
+
+	It("gives reports of the same account, service and day the same ID", func() {
+		first, err := mapping.Report("vSphere", Row{"Org": "eng", "Item": "vCPU", "Day": "2016-09-12", "Charge": "1", "VM": "vm-1"}, time.UTC)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := mapping.Report("vSphere", Row{"Org": "eng", "Item": "vCPU", "Day": "2016-09-12", "Charge": "2", "VM": "vm-2"}, time.UTC)
+		Expect(err).NotTo(HaveOccurred())
+		other, err := mapping.Report("vSphere", Row{"Org": "eng", "Item": "vCPU", "Day": "2016-09-13", "Charge": "2", "VM": "vm-2"}, time.UTC)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.ID).To(Equal(second.ID))
+		Expect(first.ID).NotTo(Equal(other.ID))
+	})
+
+	It("returns an error when the date is in none of the formats", func() {
+		_, err := mapping.Report("vSphere", Row{"Day": "12 Sep 2016", "Charge": "1"}, time.UTC)
+		Expect(err).To(MatchError(`Invalid date "12 Sep 2016"`))
+	})
+
+	It("returns an error when the cost is not a number", func() {
+		_, err := mapping.Report("vSphere", Row{"Day": "2016-09-12", "Charge": "$1"}, time.UTC)
+		Expect(err).To(MatchError(`Invalid cost "$1"`))
+	})
+})
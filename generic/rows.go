@@ -0,0 +1,132 @@
+package generic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Row is the value of each column of a row of a source.
+type Row map[string]string
+
+// ReadCSV returns a row for each line of the CSV after the header, keyed by
+// the columns of the header.
+func ReadCSV(r io.Reader, delimiter string) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if delimiter != "" {
+		reader.Comma = []rune(delimiter)[0]
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	rows := []Row{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(Row)
+		empty := true
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			row[header[i]] = strings.TrimSpace(value)
+			if row[header[i]] != "" {
+				empty = false
+			}
+		}
+		if !empty {
+			rows = append(rows, row)
+		}
+	}
+}
+
+// ReadJSON returns a row for each object of a JSON array, or of a file with
+// a JSON object per line, keyed by the names of its members.
+func ReadJSON(r io.Reader) ([]Row, error) {
+	reader := bufio.NewReader(r)
+	first, err := firstByte(reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	objects := []map[string]interface{}{}
+	if first == '[' {
+		err = decoder.Decode(&objects)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for {
+			var object map[string]interface{}
+			err = decoder.Decode(&object)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, object)
+		}
+	}
+
+	rows := []Row{}
+	for _, object := range objects {
+		row := make(Row)
+		for name, value := range object {
+			switch v := value.(type) {
+			case nil:
+				row[name] = ""
+			case string:
+				row[name] = strings.TrimSpace(v)
+			case json.Number, bool:
+				row[name] = fmt.Sprint(v)
+			default:
+				return nil, fmt.Errorf("Member %q is not a string, number or boolean", name)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// firstByte returns the first byte of the reader that is not white space,
+// without consuming it.
+func firstByte(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsRune([]byte(" \t\r\n"), rune(b)) {
+			return b, reader.UnreadByte()
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package generic_test
+
+import (
+	"strings"
+
+	. "github.com/challiwill/meteorologica/generic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rows", func() {
+	Describe("ReadCSV", func() {
+		It("keys each line by the columns of the header, skipping empty lines", func() {
+			rows, err := ReadCSV(strings.NewReader("\ufeffOrg; Charge\neng;1.5\n;\nops; 2\n"), ";")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(Equal([]Row{{"Org": "eng", "Charge": "1.5"}, {"Org": "ops", "Charge": "2"}}))
+		})
+
+		It("returns no rows when it is empty", func() {
+			rows, err := ReadCSV(strings.NewReader(""), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(BeEmpty())
+		})
+	})
+
+	Describe("ReadJSON", func() {
+		It("reads an array of objects", func() {
+			rows, err := ReadJSON(strings.NewReader(` [{"org": "eng", "charge": 1.50, "billable": true, "note": null}]`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(Equal([]Row{{"org": "eng", "charge": "1.50", "billable": "true", "note": ""}}))
+		})
+
+		It("reads an object per line", func() {
+			rows, err := ReadJSON(strings.NewReader("{\"org\": \"eng\"}\n{\"org\": \"ops\"}\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(Equal([]Row{{"org": "eng"}, {"org": "ops"}}))
+		})
+
+		It("returns an error when a member is an object or array", func() {
+			_, err := ReadJSON(strings.NewReader(`[{"tags": {"team": "eng"}}]`))
+			Expect(err).To(MatchError(`Member "tags" is not a string, number or boolean`))
+		})
+	})
+})
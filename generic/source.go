@@ -0,0 +1,105 @@
+package generic
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	CSV  = "csv"
+	JSON = "json"
+)
+
+// The fields of a report a Mapping can set.
+const (
+	AccountNumber  = "account-number"
+	AccountName    = "account-name"
+	ServiceType    = "service-type"
+	Region         = "region"
+	Date           = "date"
+	UsageQuantity  = "usage-quantity"
+	UnitOfMeasure  = "unit-of-measure"
+	Cost           = "cost"
+	ResourceID     = "resource-id"
+	ResourceGroup  = "resource-group"
+	DepartmentName = "department-name"
+	CostCenter     = "cost-center"
+)
+
+var fields = []string{
+	AccountNumber, AccountName, ServiceType, Region, Date, UsageQuantity,
+	UnitOfMeasure, Cost, ResourceID, ResourceGroup, DepartmentName, CostCenter,
+}
+
+// DefaultDateFormat is the layout dates are parsed with when a Mapping has
+// no DateFormats.
+const DefaultDateFormat = "2006-01-02"
+
+// Source is billing data Meteorologica has no client of its own for, read
+// from the files in a local directory or from a URL.
+type Source struct {
+	// Name is the Resource of the reports of the source.
+	Name string
+	// Format is csv (the default) or json, either an array of objects or an
+	// object per line.
+	Format string
+	// Path is a file, or a directory whose files are all read.
+	Path string
+	URL  string
+	// Delimiter separates the columns of csv files, a comma by default.
+	Delimiter string
+	Mapping   Mapping
+}
+
+// Mapping says which column of a source becomes each field of a report, and
+// which fields are the same for every report.
+type Mapping struct {
+	Columns   map[string]string
+	Constants map[string]string
+	// DateFormats are the layouts, in the format of Go's time package, the
+	// date column is tried with in order.
+	DateFormats []string `yaml:"date-formats"`
+}
+
+func (s Source) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("Sources must have a name")
+	}
+	if s.Format != "" && s.Format != CSV && s.Format != JSON {
+		return fmt.Errorf("Source %s has unknown format %q, must be csv or json", s.Name, s.Format)
+	}
+	if (s.Path == "") == (s.URL == "") {
+		return fmt.Errorf("Source %s must have either a path or a url", s.Name)
+	}
+	if len([]rune(s.Delimiter)) > 1 {
+		return fmt.Errorf("Source %s must have a single character delimiter", s.Name)
+	}
+	for field := range s.Mapping.Columns {
+		if !isField(field) {
+			return fmt.Errorf("Source %s maps a column to unknown field %q, must be one of %s", s.Name, field, strings.Join(fields, ", "))
+		}
+	}
+	for field := range s.Mapping.Constants {
+		if !isField(field) {
+			return fmt.Errorf("Source %s has a constant for unknown field %q, must be one of %s", s.Name, field, strings.Join(fields, ", "))
+		}
+		if field == Date {
+			return fmt.Errorf("Source %s cannot have a constant date", s.Name)
+		}
+	}
+	for _, field := range []string{Date, Cost} {
+		if s.Mapping.Columns[field] == "" {
+			return fmt.Errorf("Source %s must map a column to %s", s.Name, field)
+		}
+	}
+	return nil
+}
+
+func isField(field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
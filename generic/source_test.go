@@ -0,0 +1,52 @@
+package generic_test
+
+import (
+	. "github.com/challiwill/meteorologica/generic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Source", func() {
+	var source Source
+
+	BeforeEach(func() {
+		source = Source{
+			Name: "colo",
+			Path: "./invoices",
+			Mapping: Mapping{
+				Columns:   map[string]string{Date: "Invoice Date", Cost: "Amount"},
+				Constants: map[string]string{ServiceType: "Rack space"},
+			},
+		}
+	})
+
+	It("is valid with a name, a path or url, and the date and cost mapped", func() {
+		Expect(source.Validate()).To(Succeed())
+	})
+
+	It("must have either a path or a url", func() {
+		source.URL = "https://example.com/invoices.csv"
+		Expect(source.Validate()).To(MatchError("Source colo must have either a path or a url"))
+		source.Path, source.URL = "", ""
+		Expect(source.Validate()).To(MatchError("Source colo must have either a path or a url"))
+	})
+
+	It("must have a known format", func() {
+		source.Format = "xml"
+		Expect(source.Validate()).To(MatchError(`Source colo has unknown format "xml", must be csv or json`))
+	})
+
+	It("must map the date and cost", func() {
+		delete(source.Mapping.Columns, Cost)
+		Expect(source.Validate()).To(MatchError("Source colo must map a column to cost"))
+	})
+
+	It("must only map known fields", func() {
+		source.Mapping.Columns["colour"] = "Colour"
+		Expect(source.Validate()).To(MatchError(ContainSubstring(`Source colo maps a column to unknown field "colour"`)))
+		delete(source.Mapping.Columns, "colour")
+		source.Mapping.Constants["colour"] = "red"
+		Expect(source.Validate()).To(MatchError(ContainSubstring(`Source colo has a constant for unknown field "colour"`)))
+	})
+})
@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	SaveNamespaceCosts([]datamodels.NamespaceCost) error
+}
+
+// Attributor splits the cost of the nodes of each cluster saved by a run
+// between the namespaces of the cluster.
+type Attributor struct {
+	log      *logrus.Logger
+	location *time.Location
+	db       Database
+
+	Clusters []Cluster
+	// Sources are where the metrics of each cluster are read from, by name.
+	Sources map[string]MetricsSource
+}
+
+func NewAttributor(log *logrus.Logger, location *time.Location, db Database, clusters []Cluster) (*Attributor, error) {
+	sources := make(map[string]MetricsSource)
+	for _, c := range clusters {
+		err := c.Validate()
+		if err != nil {
+			return nil, err
+		}
+		if c.MetricsFile != "" {
+			sources[c.Name] = FileMetrics{Path: c.MetricsFile, Cluster: c.Name}
+		} else {
+			sources[c.Name] = NewPrometheusMetrics(c.Prometheus, c.Queries)
+		}
+	}
+	return &Attributor{
+		log:      log,
+		location: location,
+		db:       db,
+		Clusters: clusters,
+		Sources:  sources,
+	}, nil
+}
+
+func (a *Attributor) Name() string {
+	return "kubernetes"
+}
+
+// Run attributes the cost of the nodes of each cluster for every day the run
+// saved reports of them. A cluster whose metrics cannot be read is skipped.
+func (a *Attributor) Run(_ datamodels.Run, reports datamodels.Reports) error {
+	a.log.Debug("Entering kubernetes.Run")
+	defer a.log.Debug("Returning kubernetes.Run")
+
+	costs := []datamodels.NamespaceCost{}
+	for _, cluster := range a.Clusters {
+		nodeCosts := make(map[time.Time]float64)
+		for _, r := range reports {
+			if cluster.Nodes.Matches(r) {
+				nodeCosts[time.Date(r.Year, r.Month, r.Day, 0, 0, 0, 0, a.location)] += r.Cost
+			}
+		}
+		days := days{}
+		for day := range nodeCosts {
+			days = append(days, day)
+		}
+		sort.Sort(days)
+
+		for _, day := range days {
+			metrics, err := a.Sources[cluster.Name].Metrics(day)
+			if err != nil {
+				a.log.Errorf("Failed to get the metrics of Kubernetes cluster %s for %s, not attributing its cost: %s", cluster.Name, day.Format("2006-01-02"), err.Error())
+				continue
+			}
+			costs = append(costs, Attribute(cluster.Name, day, nodeCosts[day], cluster.cpuWeight(), metrics)...)
+		}
+	}
+	if len(costs) == 0 {
+		return nil
+	}
+	a.log.Infof("Attributed the cost of Kubernetes nodes to %d namespaces and days", len(costs))
+	return a.db.SaveNamespaceCosts(costs)
+}
+
+type days []time.Time
+
+func (d days) Len() int           { return len(d) }
+func (d days) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d days) Less(i, j int) bool { return d[i].Before(d[j]) }
@@ -0,0 +1,94 @@
+package kubernetes_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/kubernetes"
+	"github.com/challiwill/meteorologica/kubernetes/kubernetesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Attributor", func() {
+	var (
+		log        *logrus.Logger
+		db         *kubernetesfakes.FakeDatabase
+		metrics    *kubernetesfakes.FakeMetricsSource
+		attributor *Attributor
+		reports    datamodels.Reports
+	)
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		db = new(kubernetesfakes.FakeDatabase)
+		var err error
+		attributor, err = NewAttributor(log, time.UTC, db, []Cluster{{
+			Name:        "prod",
+			Nodes:       Nodes{Resource: "GCP", Account: "k8s-prod"},
+			MetricsFile: "metrics.csv",
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		metrics = new(kubernetesfakes.FakeMetricsSource)
+		metrics.MetricsReturns(Metrics{Namespaces: map[string]Usage{"web": {CPURequest: 5, MemoryRequest: 5}}, CPUCapacity: 10, MemoryCapacity: 10}, nil)
+		attributor.Sources["prod"] = metrics
+
+		reports = datamodels.Reports{
+			{Resource: "GCP", AccountNumber: "k8s-prod", Year: 2016, Month: time.September, Day: 13, ServiceType: "Core", Cost: 30},
+			{Resource: "GCP", AccountNumber: "k8s-prod", Year: 2016, Month: time.September, Day: 12, ServiceType: "Core", Cost: 30},
+			{Resource: "GCP", AccountNumber: "k8s-prod", Year: 2016, Month: time.September, Day: 12, ServiceType: "RAM", Cost: 10},
+			{Resource: "GCP", AccountNumber: "other", Year: 2016, Month: time.September, Day: 12, ServiceType: "Core", Cost: 100},
+			{Resource: "AWS", AccountNumber: "k8s-prod", Year: 2016, Month: time.September, Day: 12, ServiceType: "Core", Cost: 100},
+		}
+	})
+
+	It("attributes the cost of the cluster's nodes each day to its namespaces", func() {
+		Expect(attributor.Name()).To(Equal("kubernetes"))
+		Expect(attributor.Run(datamodels.Run{}, reports)).To(Succeed())
+
+		Expect(metrics.MetricsCallCount()).To(Equal(2))
+		Expect(metrics.MetricsArgsForCall(0)).To(Equal(time.Date(2016, time.September, 12, 0, 0, 0, 0, time.UTC)))
+		Expect(metrics.MetricsArgsForCall(1)).To(Equal(time.Date(2016, time.September, 13, 0, 0, 0, 0, time.UTC)))
+
+		costs := db.SaveNamespaceCostsArgsForCall(0)
+		Expect(costs).To(HaveLen(4))
+		Expect(costs[0].Namespace).To(Equal("web"))
+		Expect(costs[0].Day).To(Equal(12))
+		Expect(costs[0].Cost).To(BeNumerically("~", 20))
+		Expect(costs[1].Namespace).To(Equal(datamodels.IdleNamespace))
+		Expect(costs[1].Cost).To(BeNumerically("~", 20))
+		Expect(costs[2].Day).To(Equal(13))
+		Expect(costs[2].Cost).To(BeNumerically("~", 15))
+	})
+
+	It("only counts the node reports of the service types given", func() {
+		attributor.Clusters[0].Nodes.ServiceTypes = []string{"RAM"}
+		Expect(attributor.Run(datamodels.Run{}, reports)).To(Succeed())
+		costs := db.SaveNamespaceCostsArgsForCall(0)
+		Expect(costs).To(HaveLen(2))
+		Expect(costs[0].Cost + costs[1].Cost).To(BeNumerically("~", 10))
+	})
+
+	It("skips the days whose metrics cannot be read", func() {
+		metrics.MetricsReturns(Metrics{}, errors.New("some-error"))
+		Expect(attributor.Run(datamodels.Run{}, reports)).To(Succeed())
+		Expect(db.SaveNamespaceCostsCallCount()).To(Equal(0))
+	})
+
+	It("returns the error when saving fails", func() {
+		db.SaveNamespaceCostsReturns(errors.New("some-error"))
+		Expect(attributor.Run(datamodels.Run{}, reports)).To(MatchError("some-error"))
+	})
+
+	It("validates the clusters", func() {
+		_, err := NewAttributor(log, time.UTC, db, []Cluster{{Name: "prod", Nodes: Nodes{Resource: "GCP"}}})
+		Expect(err).To(MatchError("Kubernetes cluster prod must have either a metrics-file or a prometheus URL"))
+		_, err = NewAttributor(log, time.UTC, db, []Cluster{{Name: "prod", MetricsFile: "metrics.csv"}})
+		Expect(err).To(MatchError("Kubernetes cluster prod must have the resource of its nodes"))
+	})
+})
@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// DefaultCPUWeight is the share of the cost of the nodes of a cluster that is
+// for their CPU, the rest being for their memory.
+const DefaultCPUWeight = 0.5
+
+// Cluster is a Kubernetes cluster, the reports of its nodes and where the
+// metrics of its namespaces are read from.
+type Cluster struct {
+	Name  string
+	Nodes Nodes
+	// MetricsFile is a CSV, JSON or NDJSON export of the metrics of the
+	// cluster (see FileMetrics).
+	MetricsFile string `yaml:"metrics-file"`
+	// Prometheus is the URL of a Prometheus-compatible query endpoint the
+	// metrics of the cluster are queried from.
+	Prometheus string
+	Queries    Queries
+	// CPUWeight is the share of the cost of the nodes that is for their CPU,
+	// DefaultCPUWeight when it is zero.
+	CPUWeight float64 `yaml:"cpu-weight"`
+}
+
+// Nodes matches the reports of the cost of the nodes of a cluster.
+type Nodes struct {
+	Resource     string
+	Account      string
+	Region       string
+	ServiceTypes []string `yaml:"service-types"`
+}
+
+func (c Cluster) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("Kubernetes clusters must have a name")
+	}
+	if c.Nodes.Resource == "" {
+		return fmt.Errorf("Kubernetes cluster %s must have the resource of its nodes", c.Name)
+	}
+	if (c.MetricsFile == "") == (c.Prometheus == "") {
+		return fmt.Errorf("Kubernetes cluster %s must have either a metrics-file or a prometheus URL", c.Name)
+	}
+	if c.CPUWeight < 0 || c.CPUWeight > 1 {
+		return fmt.Errorf("Kubernetes cluster %s must have a cpu-weight between 0 and 1", c.Name)
+	}
+	return nil
+}
+
+func (c Cluster) cpuWeight() float64 {
+	if c.CPUWeight == 0 {
+		return DefaultCPUWeight
+	}
+	return c.CPUWeight
+}
+
+func (n Nodes) Matches(report datamodels.Report) bool {
+	if report.Resource != n.Resource ||
+		(n.Account != "" && n.Account != report.AccountNumber) ||
+		(n.Region != "" && n.Region != report.Region) {
+		return false
+	}
+	if len(n.ServiceTypes) == 0 {
+		return true
+	}
+	for _, serviceType := range n.ServiceTypes {
+		if serviceType == report.ServiceType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// Attribute splits the cost of the nodes of a cluster for a day between its
+// namespaces. The CPU share of the cost is split by the greater of the CPU
+// each namespace requested and used, out of the capacity of the nodes, and
+// the memory share likewise. What is left is the cost of idle capacity. When
+// the namespaces requested or used more than the capacity, or the capacity is
+// not known, they are charged all of the cost by their share of what they
+// requested and used.
+func Attribute(cluster string, day time.Time, nodeCost, cpuWeight float64, metrics Metrics) []datamodels.NamespaceCost {
+	namespaces := []string{}
+	cpuAllocated, memoryAllocated := 0.0, 0.0
+	for namespace, usage := range metrics.Namespaces {
+		namespaces = append(namespaces, namespace)
+		cpuAllocated += math.Max(usage.CPURequest, usage.CPUUsage)
+		memoryAllocated += math.Max(usage.MemoryRequest, usage.MemoryUsage)
+	}
+	sort.Strings(namespaces)
+	cpuCapacity := math.Max(metrics.CPUCapacity, cpuAllocated)
+	memoryCapacity := math.Max(metrics.MemoryCapacity, memoryAllocated)
+
+	cpuCost := nodeCost * cpuWeight
+	memoryCost := nodeCost - cpuCost
+	idle := datamodels.NamespaceCost{
+		Cluster:    cluster,
+		Namespace:  datamodels.IdleNamespace,
+		Year:       day.Year(),
+		Month:      day.Month(),
+		Day:        day.Day(),
+		CPUCost:    cpuCost,
+		MemoryCost: memoryCost,
+	}
+
+	costs := []datamodels.NamespaceCost{}
+	for _, namespace := range namespaces {
+		usage := metrics.Namespaces[namespace]
+		cost := datamodels.NamespaceCost{
+			Cluster:               cluster,
+			Namespace:             namespace,
+			Year:                  day.Year(),
+			Month:                 day.Month(),
+			Day:                   day.Day(),
+			CPURequestCoreHours:   usage.CPURequest,
+			CPUUsageCoreHours:     usage.CPUUsage,
+			MemoryRequestGiBHours: usage.MemoryRequest,
+			MemoryUsageGiBHours:   usage.MemoryUsage,
+		}
+		if cpuCapacity > 0 {
+			cost.CPUCost = cpuCost * math.Max(usage.CPURequest, usage.CPUUsage) / cpuCapacity
+		}
+		if memoryCapacity > 0 {
+			cost.MemoryCost = memoryCost * math.Max(usage.MemoryRequest, usage.MemoryUsage) / memoryCapacity
+		}
+		cost.Cost = cost.CPUCost + cost.MemoryCost
+		idle.CPUCost -= cost.CPUCost
+		idle.MemoryCost -= cost.MemoryCost
+		costs = append(costs, cost)
+	}
+	idle.Cost = idle.CPUCost + idle.MemoryCost
+	return append(costs, idle)
+}
@@ -0,0 +1,74 @@
+package kubernetes_test
+
+import (
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/kubernetes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Attribute", func() {
+	var day time.Time
+
+	BeforeEach(func() {
+		day = time.Date(2016, time.September, 12, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("charges each namespace for the greater of what it requested and used, leaving the rest idle", func() {
+		costs := Attribute("prod", day, 100, 0.6, Metrics{
+			Namespaces: map[string]Usage{
+				"web":   {CPURequest: 20, CPUUsage: 10, MemoryRequest: 10, MemoryUsage: 40},
+				"batch": {CPURequest: 10, CPUUsage: 30, MemoryRequest: 20, MemoryUsage: 20},
+			},
+			CPUCapacity:    100,
+			MemoryCapacity: 100,
+		})
+
+		Expect(costs).To(HaveLen(3))
+		batch, web, idle := costs[0], costs[1], costs[2]
+		Expect(batch.Namespace).To(Equal("batch"))
+		Expect(batch.CPUCost).To(BeNumerically("~", 60*0.3))
+		Expect(batch.MemoryCost).To(BeNumerically("~", 40*0.2))
+		Expect(batch.Cost).To(BeNumerically("~", 18+8))
+		Expect(batch.CPUUsageCoreHours).To(Equal(30.0))
+
+		Expect(web.Namespace).To(Equal("web"))
+		Expect(web.Cost).To(BeNumerically("~", 60*0.2+40*0.4))
+
+		Expect(idle).To(Equal(datamodels.NamespaceCost{
+			Cluster:    "prod",
+			Namespace:  datamodels.IdleNamespace,
+			Year:       2016,
+			Month:      time.September,
+			Day:        12,
+			CPUCost:    idle.CPUCost,
+			MemoryCost: idle.MemoryCost,
+			Cost:       idle.Cost,
+		}))
+		Expect(idle.CPUCost).To(BeNumerically("~", 60*0.5))
+		Expect(idle.MemoryCost).To(BeNumerically("~", 40*0.4))
+		Expect(batch.Cost + web.Cost + idle.Cost).To(BeNumerically("~", 100))
+	})
+
+	It("charges the namespaces all of the cost when they requested more than the capacity", func() {
+		costs := Attribute("prod", day, 100, 0.5, Metrics{
+			Namespaces: map[string]Usage{
+				"web":   {CPURequest: 30, MemoryRequest: 10},
+				"batch": {CPURequest: 10, MemoryRequest: 30},
+			},
+		})
+		Expect(costs[0].Cost).To(BeNumerically("~", 50*0.25+50*0.75))
+		Expect(costs[1].Cost).To(BeNumerically("~", 50*0.75+50*0.25))
+		Expect(costs[2].Cost).To(BeNumerically("~", 0))
+	})
+
+	It("is all idle when no namespace requested or used anything", func() {
+		costs := Attribute("prod", day, 100, 0.5, Metrics{CPUCapacity: 10, MemoryCapacity: 10})
+		Expect(costs).To(HaveLen(1))
+		Expect(costs[0].Namespace).To(Equal(datamodels.IdleNamespace))
+		Expect(costs[0].Cost).To(Equal(100.0))
+	})
+})
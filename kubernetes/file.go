@@ -0,0 +1,90 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/generic"
+)
+
+// The columns of a metrics file.
+const (
+	DateColumn           = "date"
+	ClusterColumn        = "cluster"
+	NamespaceColumn      = "namespace"
+	CPURequestColumn     = "cpu_request"
+	CPUUsageColumn       = "cpu_usage"
+	MemoryRequestColumn  = "memory_request"
+	MemoryUsageColumn    = "memory_usage"
+	CPUCapacityColumn    = "cpu_capacity"
+	MemoryCapacityColumn = "memory_capacity"
+)
+
+// FileMetrics reads metrics from a CSV, JSON or NDJSON file with a row per
+// day and pod or node. The date is like 2016-09-12 and the cluster, when
+// given, must be the cluster's. Rows of pods have a namespace and what it
+// requested and used, in core-hours and GiB-hours, and rows of nodes have
+// their capacity. Columns that are missing or empty are zero.
+type FileMetrics struct {
+	Path    string
+	Cluster string
+}
+
+func (f FileMetrics) Metrics(day time.Time) (Metrics, error) {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return Metrics{}, err
+	}
+	defer file.Close()
+
+	var rows []generic.Row
+	if strings.HasSuffix(f.Path, ".json") || strings.HasSuffix(f.Path, ".ndjson") {
+		rows, err = generic.ReadJSON(file)
+	} else {
+		rows, err = generic.ReadCSV(file, "")
+	}
+	if err != nil {
+		return Metrics{}, fmt.Errorf("Failed to read %s: %s", f.Path, err.Error())
+	}
+
+	metrics := newMetrics()
+	date := day.Format("2006-01-02")
+	for i, row := range rows {
+		if row[DateColumn] != date || (row[ClusterColumn] != "" && row[ClusterColumn] != f.Cluster) {
+			continue
+		}
+		values, err := numbers(row, CPURequestColumn, CPUUsageColumn, MemoryRequestColumn, MemoryUsageColumn, CPUCapacityColumn, MemoryCapacityColumn)
+		if err != nil {
+			return Metrics{}, fmt.Errorf("Failed to read row %d of %s: %s", i+1, f.Path, err.Error())
+		}
+		if namespace := row[NamespaceColumn]; namespace != "" {
+			usage := metrics.Namespaces[namespace]
+			usage.CPURequest += values[0]
+			usage.CPUUsage += values[1]
+			usage.MemoryRequest += values[2]
+			usage.MemoryUsage += values[3]
+			metrics.Namespaces[namespace] = usage
+		}
+		metrics.CPUCapacity += values[4]
+		metrics.MemoryCapacity += values[5]
+	}
+	return metrics, nil
+}
+
+func numbers(row generic.Row, columns ...string) ([]float64, error) {
+	values := make([]float64, len(columns))
+	for i, column := range columns {
+		if row[column] == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(row[column], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s %q", column, row[column])
+		}
+		values[i] = value
+	}
+	return values, nil
+}
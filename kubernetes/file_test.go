@@ -0,0 +1,74 @@
+package kubernetes_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/challiwill/meteorologica/kubernetes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileMetrics", func() {
+	var (
+		dir string
+		day time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "kubernetes")
+		Expect(err).NotTo(HaveOccurred())
+		day = time.Date(2016, time.September, 12, 0, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("adds up the pods of each namespace and the capacity of the nodes of the cluster on the day", func() {
+		path := filepath.Join(dir, "metrics.csv")
+		Expect(ioutil.WriteFile(path, []byte(`date,cluster,namespace,pod,node,cpu_request,cpu_usage,memory_request,memory_usage,cpu_capacity,memory_capacity
+2016-09-12,prod,web,web-1,,12,6,24,12,,
+2016-09-12,prod,web,web-2,,12,8,24,30,,
+2016-09-12,,batch,batch-1,,2,20,4,8,,
+2016-09-12,prod,,,node-1,,,,,96,384
+2016-09-12,prod,,,node-2,,,,,96,384
+2016-09-12,staging,web,web-1,,100,100,100,100,,
+2016-09-13,prod,web,web-1,,100,100,100,100,,
+`), 0644)).To(Succeed())
+
+		metrics, err := FileMetrics{Path: path, Cluster: "prod"}.Metrics(day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metrics).To(Equal(Metrics{
+			Namespaces: map[string]Usage{
+				"web":   {CPURequest: 24, CPUUsage: 14, MemoryRequest: 48, MemoryUsage: 42},
+				"batch": {CPURequest: 2, CPUUsage: 20, MemoryRequest: 4, MemoryUsage: 8},
+			},
+			CPUCapacity:    192,
+			MemoryCapacity: 768,
+		}))
+	})
+
+	It("reads NDJSON", func() {
+		path := filepath.Join(dir, "metrics.ndjson")
+		Expect(ioutil.WriteFile(path, []byte(`{"date": "2016-09-12", "namespace": "web", "cpu_request": 1.5}
+{"date": "2016-09-12", "node": "node-1", "cpu_capacity": 24}
+`), 0644)).To(Succeed())
+
+		metrics, err := FileMetrics{Path: path, Cluster: "prod"}.Metrics(day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metrics.Namespaces["web"].CPURequest).To(Equal(1.5))
+		Expect(metrics.CPUCapacity).To(Equal(24.0))
+	})
+
+	It("returns an error naming the row that is not a number", func() {
+		path := filepath.Join(dir, "metrics.csv")
+		Expect(ioutil.WriteFile(path, []byte("date,namespace,cpu_request\n2016-09-12,web,lots\n"), 0644)).To(Succeed())
+		_, err := FileMetrics{Path: path, Cluster: "prod"}.Metrics(day)
+		Expect(err).To(MatchError(`Failed to read row 1 of ` + path + `: Invalid cpu_request "lots"`))
+	})
+})
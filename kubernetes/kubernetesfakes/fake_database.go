@@ -0,0 +1,77 @@
+// This file was generated by counterfeiter
+package kubernetesfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/kubernetes"
+)
+
+type FakeDatabase struct {
+	SaveNamespaceCostsStub        func([]datamodels.NamespaceCost) error
+	saveNamespaceCostsMutex       sync.RWMutex
+	saveNamespaceCostsArgsForCall []struct {
+		arg1 []datamodels.NamespaceCost
+	}
+	saveNamespaceCostsReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) SaveNamespaceCosts(arg1 []datamodels.NamespaceCost) error {
+	fake.saveNamespaceCostsMutex.Lock()
+	fake.saveNamespaceCostsArgsForCall = append(fake.saveNamespaceCostsArgsForCall, struct {
+		arg1 []datamodels.NamespaceCost
+	}{arg1})
+	fake.recordInvocation("SaveNamespaceCosts", []interface{}{arg1})
+	fake.saveNamespaceCostsMutex.Unlock()
+	if fake.SaveNamespaceCostsStub != nil {
+		return fake.SaveNamespaceCostsStub(arg1)
+	} else {
+		return fake.saveNamespaceCostsReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveNamespaceCostsCallCount() int {
+	fake.saveNamespaceCostsMutex.RLock()
+	defer fake.saveNamespaceCostsMutex.RUnlock()
+	return len(fake.saveNamespaceCostsArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveNamespaceCostsArgsForCall(i int) []datamodels.NamespaceCost {
+	fake.saveNamespaceCostsMutex.RLock()
+	defer fake.saveNamespaceCostsMutex.RUnlock()
+	return fake.saveNamespaceCostsArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveNamespaceCostsReturns(result1 error) {
+	fake.SaveNamespaceCostsStub = nil
+	fake.saveNamespaceCostsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.saveNamespaceCostsMutex.RLock()
+	defer fake.saveNamespaceCostsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kubernetes.Database = new(FakeDatabase)
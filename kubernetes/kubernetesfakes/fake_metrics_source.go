@@ -0,0 +1,79 @@
+// This file was generated by counterfeiter
+package kubernetesfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/kubernetes"
+)
+
+type FakeMetricsSource struct {
+	MetricsStub        func(time.Time) (kubernetes.Metrics, error)
+	metricsMutex       sync.RWMutex
+	metricsArgsForCall []struct {
+		arg1 time.Time
+	}
+	metricsReturns struct {
+		result1 kubernetes.Metrics
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeMetricsSource) Metrics(arg1 time.Time) (kubernetes.Metrics, error) {
+	fake.metricsMutex.Lock()
+	fake.metricsArgsForCall = append(fake.metricsArgsForCall, struct {
+		arg1 time.Time
+	}{arg1})
+	fake.recordInvocation("Metrics", []interface{}{arg1})
+	fake.metricsMutex.Unlock()
+	if fake.MetricsStub != nil {
+		return fake.MetricsStub(arg1)
+	} else {
+		return fake.metricsReturns.result1, fake.metricsReturns.result2
+	}
+}
+
+func (fake *FakeMetricsSource) MetricsCallCount() int {
+	fake.metricsMutex.RLock()
+	defer fake.metricsMutex.RUnlock()
+	return len(fake.metricsArgsForCall)
+}
+
+func (fake *FakeMetricsSource) MetricsArgsForCall(i int) time.Time {
+	fake.metricsMutex.RLock()
+	defer fake.metricsMutex.RUnlock()
+	return fake.metricsArgsForCall[i].arg1
+}
+
+func (fake *FakeMetricsSource) MetricsReturns(result1 kubernetes.Metrics, result2 error) {
+	fake.MetricsStub = nil
+	fake.metricsReturns = struct {
+		result1 kubernetes.Metrics
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMetricsSource) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.metricsMutex.RLock()
+	defer fake.metricsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeMetricsSource) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kubernetes.MetricsSource = new(FakeMetricsSource)
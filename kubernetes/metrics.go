@@ -0,0 +1,30 @@
+package kubernetes
+
+import "time"
+
+//go:generate counterfeiter . MetricsSource
+
+// MetricsSource returns the metrics of a cluster for a day.
+type MetricsSource interface {
+	Metrics(day time.Time) (Metrics, error)
+}
+
+// Metrics are how much CPU and memory each namespace of a cluster requested
+// and used over a day, and how much the nodes of the cluster could allocate,
+// in core-hours and GiB-hours.
+type Metrics struct {
+	Namespaces     map[string]Usage
+	CPUCapacity    float64
+	MemoryCapacity float64
+}
+
+type Usage struct {
+	CPURequest    float64
+	CPUUsage      float64
+	MemoryRequest float64
+	MemoryUsage   float64
+}
+
+func newMetrics() Metrics {
+	return Metrics{Namespaces: make(map[string]Usage)}
+}
@@ -0,0 +1,177 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/errare"
+)
+
+// Queries are the PromQL queries of the metrics of a cluster over the day
+// before they are evaluated. The queries of namespaces must return a series
+// per namespace, with a namespace label, and the queries of capacity are
+// summed.
+type Queries struct {
+	CPURequest     string `yaml:"cpu-request"`
+	CPUUsage       string `yaml:"cpu-usage"`
+	MemoryRequest  string `yaml:"memory-request"`
+	MemoryUsage    string `yaml:"memory-usage"`
+	CPUCapacity    string `yaml:"cpu-capacity"`
+	MemoryCapacity string `yaml:"memory-capacity"`
+}
+
+// DefaultQueries query the metrics of kube-state-metrics and cAdvisor, in
+// core-hours and GiB-hours. The sums over time of 5 minute samples are
+// divided by 12 to get hours.
+var DefaultQueries = Queries{
+	CPURequest:     `sum by (namespace) (sum_over_time(kube_pod_container_resource_requests{resource="cpu"}[1d:5m])) / 12`,
+	CPUUsage:       `sum by (namespace) (increase(container_cpu_usage_seconds_total{container!=""}[1d])) / 3600`,
+	MemoryRequest:  `sum by (namespace) (sum_over_time(kube_pod_container_resource_requests{resource="memory"}[1d:5m])) / 12 / 1073741824`,
+	MemoryUsage:    `sum by (namespace) (avg_over_time(container_memory_working_set_bytes{container!=""}[1d])) * 24 / 1073741824`,
+	CPUCapacity:    `sum(sum_over_time(kube_node_status_allocatable{resource="cpu"}[1d:5m])) / 12`,
+	MemoryCapacity: `sum(sum_over_time(kube_node_status_allocatable{resource="memory"}[1d:5m])) / 12 / 1073741824`,
+}
+
+// PrometheusMetrics queries metrics from the query API of Prometheus, at the
+// end of the day.
+type PrometheusMetrics struct {
+	URL     string
+	Queries Queries
+	client  *http.Client
+}
+
+// NewPrometheusMetrics returns the metrics at the URL, using the default of
+// each query that is not given.
+func NewPrometheusMetrics(serverURL string, queries Queries) *PrometheusMetrics {
+	defaults := []struct {
+		query        *string
+		defaultQuery string
+	}{
+		{&queries.CPURequest, DefaultQueries.CPURequest},
+		{&queries.CPUUsage, DefaultQueries.CPUUsage},
+		{&queries.MemoryRequest, DefaultQueries.MemoryRequest},
+		{&queries.MemoryUsage, DefaultQueries.MemoryUsage},
+		{&queries.CPUCapacity, DefaultQueries.CPUCapacity},
+		{&queries.MemoryCapacity, DefaultQueries.MemoryCapacity},
+	}
+	for _, d := range defaults {
+		if *d.query == "" {
+			*d.query = d.defaultQuery
+		}
+	}
+	return &PrometheusMetrics{
+		URL:     serverURL,
+		Queries: queries,
+		client:  new(http.Client),
+	}
+}
+
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func (p *PrometheusMetrics) Metrics(day time.Time) (Metrics, error) {
+	at := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+	metrics := newMetrics()
+
+	namespaceQueries := []struct {
+		query string
+		add   func(*Usage, float64)
+	}{
+		{p.Queries.CPURequest, func(u *Usage, v float64) { u.CPURequest += v }},
+		{p.Queries.CPUUsage, func(u *Usage, v float64) { u.CPUUsage += v }},
+		{p.Queries.MemoryRequest, func(u *Usage, v float64) { u.MemoryRequest += v }},
+		{p.Queries.MemoryUsage, func(u *Usage, v float64) { u.MemoryUsage += v }},
+	}
+	for _, q := range namespaceQueries {
+		values, err := p.query(q.query, at)
+		if err != nil {
+			return Metrics{}, err
+		}
+		for namespace, value := range values {
+			if namespace == "" {
+				continue
+			}
+			usage := metrics.Namespaces[namespace]
+			q.add(&usage, value)
+			metrics.Namespaces[namespace] = usage
+		}
+	}
+
+	capacityQueries := []struct {
+		query    string
+		capacity *float64
+	}{
+		{p.Queries.CPUCapacity, &metrics.CPUCapacity},
+		{p.Queries.MemoryCapacity, &metrics.MemoryCapacity},
+	}
+	for _, q := range capacityQueries {
+		values, err := p.query(q.query, at)
+		if err != nil {
+			return Metrics{}, err
+		}
+		for _, value := range values {
+			*q.capacity += value
+		}
+	}
+	return metrics, nil
+}
+
+// query returns the value of each series the query evaluates to at the time,
+// by namespace.
+func (p *PrometheusMetrics) query(query string, at time.Time) (map[string]float64, error) {
+	values := url.Values{}
+	values.Set("query", query)
+	values.Set("time", strconv.FormatInt(at.Unix(), 10))
+	resp, err := p.client.Get(strings.TrimRight(p.URL, "/") + "/api/v1/query?" + values.Encode())
+	if err != nil {
+		return nil, errare.NewRequestError(err, "Prometheus")
+	}
+	defer resp.Body.Close()
+
+	var response queryResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil && resp.StatusCode != http.StatusOK {
+		return nil, errare.NewResponseError(resp.Status, "Prometheus")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("Prometheus failed to evaluate %s: %s", query, response.Error)
+	}
+	if response.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("Prometheus evaluated %s to a %s, expected a vector", query, response.Data.ResultType)
+	}
+
+	byNamespace := make(map[string]float64)
+	for _, result := range response.Data.Result {
+		if len(result.Value) != 2 {
+			return nil, fmt.Errorf("Prometheus returned an invalid sample for %s", query)
+		}
+		sample, _ := result.Value[1].(string)
+		value, err := strconv.ParseFloat(sample, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Prometheus returned an invalid sample for %s: %q", query, sample)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		byNamespace[result.Metric["namespace"]] += value
+	}
+	return byNamespace, nil
+}
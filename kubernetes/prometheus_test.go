@@ -0,0 +1,91 @@
+package kubernetes_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	. "github.com/challiwill/meteorologica/kubernetes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrometheusMetrics", func() {
+	var (
+		server  *httptest.Server
+		results map[string]string
+		times   []string
+		day     time.Time
+	)
+
+	BeforeEach(func() {
+		day = time.Date(2016, time.September, 12, 0, 0, 0, 0, time.UTC)
+		times = []string{}
+		results = map[string]string{
+			"cpu-request":     `[{"metric": {"namespace": "web"}, "value": [1473724800, "24"]}, {"metric": {"namespace": "batch"}, "value": [1473724800, "2"]}]`,
+			"cpu-usage":       `[{"metric": {"namespace": "web"}, "value": [1473724800, "14"]}, {"metric": {}, "value": [1473724800, "5"]}]`,
+			"memory-request":  `[{"metric": {"namespace": "web"}, "value": [1473724800, "48"]}]`,
+			"memory-usage":    `[{"metric": {"namespace": "web"}, "value": [1473724800, "NaN"]}]`,
+			"cpu-capacity":    `[{"metric": {}, "value": [1473724800, "192"]}]`,
+			"memory-capacity": `[{"metric": {}, "value": [1473724800, "768"]}]`,
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/api/v1/query"))
+			times = append(times, r.URL.Query().Get("time"))
+			result, ok := results[r.URL.Query().Get("query")]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"status": "error", "errorType": "bad_data", "error": "parse error"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "vector", "result": %s}}`, result)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	queries := Queries{
+		CPURequest:     "cpu-request",
+		CPUUsage:       "cpu-usage",
+		MemoryRequest:  "memory-request",
+		MemoryUsage:    "memory-usage",
+		CPUCapacity:    "cpu-capacity",
+		MemoryCapacity: "memory-capacity",
+	}
+
+	It("queries the metrics of each namespace and the capacity at the end of the day", func() {
+		metrics, err := NewPrometheusMetrics(server.URL, queries).Metrics(day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metrics).To(Equal(Metrics{
+			Namespaces: map[string]Usage{
+				"web":   {CPURequest: 24, CPUUsage: 14, MemoryRequest: 48},
+				"batch": {CPURequest: 2},
+			},
+			CPUCapacity:    192,
+			MemoryCapacity: 768,
+		}))
+		Expect(times).To(HaveLen(6))
+		for _, t := range times {
+			Expect(t).To(Equal(strconv.FormatInt(day.AddDate(0, 0, 1).Unix(), 10)))
+		}
+	})
+
+	It("uses the default of each query that is not given", func() {
+		metrics := NewPrometheusMetrics(server.URL, Queries{CPURequest: "cpu-request"})
+		Expect(metrics.Queries.CPURequest).To(Equal("cpu-request"))
+		Expect(metrics.Queries.CPUUsage).To(Equal(DefaultQueries.CPUUsage))
+		Expect(metrics.Queries.MemoryCapacity).To(Equal(DefaultQueries.MemoryCapacity))
+	})
+
+	It("returns the error of a query Prometheus fails to evaluate", func() {
+		q := queries
+		q.MemoryUsage = "memory-usage{"
+		_, err := NewPrometheusMetrics(server.URL, q).Metrics(day)
+		Expect(err).To(MatchError("Prometheus failed to evaluate memory-usage{: parse error"))
+	})
+})
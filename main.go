@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -14,13 +15,29 @@ import (
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/challiwill/meteorologica/allocation"
+	"github.com/challiwill/meteorologica/anomaly"
+	"github.com/challiwill/meteorologica/api"
 	"github.com/challiwill/meteorologica/aws"
 	"github.com/challiwill/meteorologica/azure"
+	"github.com/challiwill/meteorologica/budget"
+	"github.com/challiwill/meteorologica/cloudfoundry"
+	"github.com/challiwill/meteorologica/compare"
 	"github.com/challiwill/meteorologica/datamodels"
 	"github.com/challiwill/meteorologica/db"
 	"github.com/challiwill/meteorologica/db/migrations"
+	"github.com/challiwill/meteorologica/digest"
+	"github.com/challiwill/meteorologica/focus"
+	"github.com/challiwill/meteorologica/forecast"
 	"github.com/challiwill/meteorologica/gcp"
+	"github.com/challiwill/meteorologica/generic"
+	"github.com/challiwill/meteorologica/kubernetes"
+	"github.com/challiwill/meteorologica/notify"
+	"github.com/challiwill/meteorologica/ownership"
+	"github.com/challiwill/meteorologica/reconcile"
+	"github.com/challiwill/meteorologica/sink"
 	"github.com/challiwill/meteorologica/usagedatajob"
+	"github.com/challiwill/meteorologica/validation"
 	"github.com/heroku/rollrus"
 	"github.com/jinzhu/configor"
 	"github.com/robfig/cron"
@@ -28,16 +45,105 @@ import (
 
 type DBClient interface {
 	SaveReports(datamodels.Reports) error
+	SaveResourceReports(datamodels.Reports) error
+	GetDailyCost(string, int, time.Month, int) (float64, error)
 	GetUsageMonthToDate(datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error)
+	GetLatestMonthToDateSnapshot(datamodels.ReportIdentifier) (datamodels.UsageMonthToDate, error)
+	SaveMonthToDateSnapshots([]datamodels.UsageMonthToDate) error
+	StartRun(datamodels.Run) error
+	FinishRun(datamodels.Run) error
+	SaveReportVersions(datamodels.Run, datamodels.Reports) error
+	SaveRunIssues([]datamodels.RunIssue) error
+	GetReports(time.Time, time.Time) (datamodels.Reports, error)
+	GetDailyCosts(time.Time, time.Time) ([]datamodels.DailyCost, error)
+	SaveAnomalies([]datamodels.Anomaly) error
+	SaveBudgetAlert(datamodels.BudgetAlert) (bool, error)
+	GetAnomalies(time.Time, time.Time) ([]datamodels.Anomaly, error)
+	ClearAllocations(string, int, time.Month, int) error
+	SaveAllocations([]datamodels.Allocation) error
+	GetAllocatedCosts(time.Time, time.Time) ([]datamodels.AllocatedCost, error)
+	SaveNamespaceCosts([]datamodels.NamespaceCost) error
+	SaveAzurePriceSheet([]datamodels.AzurePrice) error
+	SaveAzureBalances([]datamodels.AzureBalance) error
+	SaveAzureCommitmentProjection(datamodels.AzureCommitmentProjection) error
+	SaveReconciliations([]datamodels.Reconciliation) error
+	GetReconciliations(string) ([]datamodels.Reconciliation, error)
+	GetTeams() ([]datamodels.Team, error)
+	SaveTeam(datamodels.Team) error
+	DeleteTeam(string) error
+	GetOwnershipMappings() ([]datamodels.OwnershipMapping, error)
+	SaveOwnershipMapping(datamodels.OwnershipMapping) error
+	DeleteOwnershipMapping(int64) error
 	Close() error
 }
 
 var Config = struct {
-	Port int `default:"8080"`
+	Port     int    `default:"8080"`
+	APIToken string `yaml:"api-token" env:"M_API_TOKEN"`
+
+	RestatementWindow int `yaml:"restatement-window" env:"M_RESTATEMENT_WINDOW" default:"3"`
+
+	Anomalies struct {
+		Method       string  `default:"mad" env:"M_ANOMALIES_METHOD"`
+		BaselineDays int     `yaml:"baseline-days" env:"M_ANOMALIES_BASELINE_DAYS" default:"28"`
+		Threshold    float64 `env:"M_ANOMALIES_THRESHOLD" default:"3.5"`
+		MinimumCost  float64 `yaml:"minimum-cost" env:"M_ANOMALIES_MINIMUM_COST" default:"10"`
+	}
+
+	Forecast struct {
+		BacktestPeriods int `yaml:"backtest-periods" env:"M_FORECAST_BACKTEST_PERIODS" default:"3"`
+	}
+
+	Budgets []budget.Budget
+
+	Ownership struct {
+		File string `env:"M_OWNERSHIP_FILE"`
+	}
+
+	Validation []validation.RuleConfig
+
+	Allocations []allocation.Rule
+
+	Kubernetes []kubernetes.Cluster
+
+	Reconciliation struct {
+		Tolerance float64 `env:"M_RECONCILIATION_TOLERANCE" default:"1"`
+	}
+
+	Digest struct {
+		Schedule string `env:"M_DIGEST_SCHEDULE" default:"0 0 8 * * MON"`
+		From     string `env:"M_DIGEST_FROM"`
+		To       []string
+		Top      int `env:"M_DIGEST_TOP" default:"5"`
+		SMTP     struct {
+			Host     string `env:"M_DIGEST_SMTP_HOST"`
+			Port     int    `env:"M_DIGEST_SMTP_PORT" default:"25"`
+			Username string `env:"M_DIGEST_SMTP_USERNAME"`
+			Password string `env:"M_DIGEST_SMTP_PASSWORD"`
+		}
+	}
+
+	Outputs []sink.Output
+
+	Sources []generic.Source
+
+	CloudFoundry cloudfoundry.Foundation `yaml:"cloud-foundry"`
+
+	Notifications struct {
+		File             string `env:"M_NOTIFICATIONS_FILE"`
+		Channels         []notify.Channel
+		Templates        map[string]string
+		Retries          int `env:"M_NOTIFICATIONS_RETRIES" default:"3"`
+		RetryDelay       int `yaml:"retry-delay" env:"M_NOTIFICATIONS_RETRY_DELAY" default:"2"`
+		RateLimit        int `yaml:"rate-limit" env:"M_NOTIFICATIONS_RATE_LIMIT" default:"20"`
+		ProviderFailures int `yaml:"provider-failures" env:"M_NOTIFICATIONS_PROVIDER_FAILURES" default:"3"`
+	}
 
 	Azure struct {
-		AccessKey        string `yaml:"access-key" env:"M_AZURE_ACCESS_KEY"`
-		EnrollmentNumber int    `yaml:"enrollment-number" env:"M_AZURE_ENROLLMENT_NUMBER"`
+		AccessKey        string                 `yaml:"access-key" env:"M_AZURE_ACCESS_KEY"`
+		EnrollmentNumber int                    `yaml:"enrollment-number" env:"M_AZURE_ENROLLMENT_NUMBER"`
+		ServicePrincipal azure.ServicePrincipal `yaml:"service-principal"`
+		BurnDays         int                    `yaml:"burn-days" env:"M_AZURE_BURN_DAYS" default:"30"`
 	}
 
 	GCP struct {
@@ -66,22 +172,34 @@ var Config = struct {
 }{}
 
 var (
-	resourcesFlag string
-	cronFlag      bool
-	verboseFlag   *bool
-	fileFlag      bool
-	dbFlag        bool
+	resourcesFlag   string
+	cronFlag        bool
+	verboseFlag     *bool
+	fileFlag        bool
+	fileDirFlag     string
+	dbFlag          bool
+	resourceFlag    bool
+	reconcileFlag   string
+	compareFlag     string
+	formatFlag      string
+	fileFormatFlag  string
+	exportFocusFlag string
 )
 
 func main() {
-	flag.StringVar(&resourcesFlag, "resources", "aws,gcp,azure", "A comma seperated list of resource to retrieve billing information from. If none are specified the default is AWS, GCP, and Azure")
+	flag.StringVar(&resourcesFlag, "resources", "", "A comma seperated list of resource to retrieve billing information from. If none are specified the default is AWS, GCP, Azure, Cloud Foundry if configured and every configured source")
 	flag.BoolVar(&cronFlag, "cron", false, "Run job periodically every day at midnight")
 	verboseFlag = flag.Bool("v", false, "Log at Debug level")
-	flag.BoolVar(&fileFlag, "file", false, "Save a local copy of the data as a normalized CSV file")
+	flag.BoolVar(&fileFlag, "file", false, "Save a local copy of the data as normalized CSV files")
+	flag.StringVar(&fileDirFlag, "file-dir", "billing-data", "The directory the files saved with -file are partitioned in")
 	flag.BoolVar(&dbFlag, "db", true, "Save the data to the database")
+	flag.BoolVar(&resourceFlag, "resource-level", false, "Also collect and save usage per resource (instance, volume...) where the IAAS supports it")
+	flag.StringVar(&reconcileFlag, "reconcile", "", "Reconcile the saved costs of the given month (YYYY-MM) with the invoice of each IAAS and exit")
+	flag.StringVar(&compareFlag, "compare", "", "Compare the spend of two periods given as current,previous (each YYYY-MM or YYYY-Qn, previous defaults to the period before current), print the changes and exit")
+	flag.StringVar(&formatFlag, "format", "", "The format -compare prints in (text, the default, csv or json) or -export-focus prints in (csv, the default, or ndjson)")
+	flag.StringVar(&fileFormatFlag, "file-format", "csv", "The format of the file saved with -file: the normalized csv or ndjson, focus-csv or focus-ndjson")
+	flag.StringVar(&exportFocusFlag, "export-focus", "", "Print the saved usage of the given month (YYYY-MM) in the FOCUS format and exit")
 	flag.Parse()
-	resources := strings.Split(resourcesFlag, ",")
-
 	_ = os.Setenv("CONFIGOR_ENV_PREFIX", "M")
 	err := configor.Load(&Config, "configuration/meteorologica.yml")
 	if err != nil {
@@ -90,6 +208,17 @@ func main() {
 
 	log := configureLog()
 
+	resources := strings.Split(resourcesFlag, ",")
+	if resourcesFlag == "" {
+		resources = []string{"aws", "gcp", "azure"}
+		if Config.CloudFoundry.API != "" {
+			resources = append(resources, cloudfoundry.IAAS)
+		}
+		for _, source := range Config.Sources {
+			resources = append(resources, source.Name)
+		}
+	}
+
 	sfTime, err := time.LoadLocation("America/Los_Angeles")
 	if err != nil {
 		sfTime = time.Now().Location()
@@ -111,15 +240,77 @@ func main() {
 		dbClient = db.NewNullClient(log)
 	}
 
+	if compareFlag != "" {
+		periods := strings.Split(compareFlag, ",")
+		current, err := compare.ParsePeriod(periods[0])
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		previous := current.Previous()
+		if len(periods) > 1 {
+			previous, err = compare.ParsePeriod(periods[1])
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+		}
+		comparison, err := compare.NewComparer(log, dbClient).Compare(current, previous, compare.DefaultTop)
+		_ = dbClient.Close()
+		if err != nil {
+			log.Fatal("Comparison failed: ", err.Error())
+		}
+		format := formatFlag
+		if format == "" {
+			format = compare.Text
+		}
+		err = compare.Write(os.Stdout, comparison, format)
+		if err != nil {
+			log.Fatal("Failed to print comparison: ", err.Error())
+		}
+		os.Exit(0)
+	}
+
+	if exportFocusFlag != "" {
+		month, err := time.Parse("2006-01", exportFocusFlag)
+		if err != nil {
+			log.Fatalf("Invalid month %q to export, expected YYYY-MM", exportFocusFlag)
+		}
+		format := formatFlag
+		if format == "" {
+			format = focus.CSV
+		}
+		exporter, err := focus.NewExporter(format, billingAccounts())
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		reports, err := dbClient.GetReports(month, month.AddDate(0, 1, -1))
+		_ = dbClient.Close()
+		if err != nil {
+			log.Fatal("Failed to read saved usage: ", err.Error())
+		}
+		err = exporter.Write(os.Stdout, reports, true)
+		if err != nil {
+			log.Fatal("Failed to export usage: ", err.Error())
+		}
+		os.Exit(0)
+	}
+
 	var iaasClients []usagedatajob.IaasClient
 
 	// Azure Client
+	var azureClient *azure.Client
 	if caseInsensitiveContains(resources, "Azure") {
 		log.Debug("Creating Azure Client")
-		if Config.Azure.AccessKey == "" || Config.Azure.EnrollmentNumber == 0 {
-			log.Fatal("Azure requires access-key and enrollment-number to be configured")
+		principal := Config.Azure.ServicePrincipal
+		if Config.Azure.EnrollmentNumber == 0 || (Config.Azure.AccessKey == "" && principal.ClientID == "") {
+			log.Fatal("Azure requires enrollment-number and either access-key or service-principal to be configured")
+		}
+		azureClient = azure.NewClient(log, sfTime, "https://ea.azure.com/", Config.Azure.AccessKey, Config.Azure.EnrollmentNumber, Config.RestatementWindow)
+		if principal.ClientID != "" {
+			if principal.TenantID == "" || principal.ClientSecret == "" {
+				log.Fatal("Azure service-principal requires tenant-id, client-id and client-secret to be configured")
+			}
+			azureClient.API = azure.NewConsumptionAPI(log, azure.ManagementURL, azure.LoginURL, principal, Config.Azure.EnrollmentNumber)
 		}
-		azureClient := azure.NewClient(log, sfTime, "https://ea.azure.com/", Config.Azure.AccessKey, Config.Azure.EnrollmentNumber)
 		iaasClients = append(iaasClients, azureClient)
 	}
 
@@ -133,7 +324,7 @@ func main() {
 		if err != nil {
 			log.Fatal("Failed to create GCP credentials: ", err.Error())
 		}
-		gcpClient, err := gcp.NewClient(log, sfTime, gcpCredentials, Config.GCP.BucketName)
+		gcpClient, err := gcp.NewClient(log, sfTime, gcpCredentials, Config.GCP.BucketName, Config.RestatementWindow)
 		if err != nil {
 			log.Fatal("Failed to create GCP client: ", err.Error())
 		}
@@ -152,17 +343,126 @@ func main() {
 		if Config.AWS.MasterAccountNumber == int64(0) {
 			log.Fatal("AWS requires master_account_number to be configured")
 		}
-		_ = os.Setenv("AWS_ACCESS_KEY_ID", Config.AWS.AccessKeyID)
-		_ = os.Setenv("AWS_SECRET_ACCESS_KEY", Config.AWS.SecretAccessKey)
-		sess, err := session.NewSession(&awssdk.Config{Region: awssdk.String(Config.AWS.Region)})
+		sess, err := awsSession()
 		if err != nil {
 			log.Fatal("Failed to create AWS credentials: ", err.Error())
 		}
-		awsClient := aws.NewClient(log, sfTime, Config.AWS.Region, Config.AWS.BucketName, Config.AWS.MasterAccountNumber, s3.New(sess), dbClient)
+		awsClient := aws.NewClient(log, sfTime, Config.AWS.Region, Config.AWS.BucketName, Config.AWS.MasterAccountNumber, s3.New(sess), dbClient, Config.RestatementWindow)
 		iaasClients = append(iaasClients, awsClient)
 	}
 
-	usageDataJob := usagedatajob.NewJob(log, sfTime, iaasClients, dbClient, fileFlag)
+	// Cloud Foundry Client
+	if caseInsensitiveContains(resources, cloudfoundry.IAAS) {
+		log.Debug("Creating Cloud Foundry Client")
+		cloudFoundryClient, err := cloudfoundry.NewClient(log, sfTime, Config.CloudFoundry, Config.RestatementWindow)
+		if err != nil {
+			log.Fatal("Failed to create Cloud Foundry client: ", err.Error())
+		}
+		iaasClients = append(iaasClients, cloudFoundryClient)
+	}
+
+	// Generic Clients
+	for _, source := range Config.Sources {
+		if !caseInsensitiveContains(resources, source.Name) {
+			continue
+		}
+		log.Debugf("Creating %s Client", source.Name)
+		genericClient, err := generic.NewClient(log, sfTime, source, Config.RestatementWindow)
+		if err != nil {
+			log.Fatal("Failed to create generic client: ", err.Error())
+		}
+		iaasClients = append(iaasClients, genericClient)
+	}
+
+	var invoiceClients []reconcile.InvoiceClient
+	for _, iaasClient := range iaasClients {
+		if invoiceClient, ok := iaasClient.(reconcile.InvoiceClient); ok {
+			invoiceClients = append(invoiceClients, invoiceClient)
+		}
+	}
+	reconciler := reconcile.NewReconciler(log, sfTime, dbClient, invoiceClients, Config.RestatementWindow, Config.Reconciliation.Tolerance)
+	if reconcileFlag != "" {
+		month, err := time.Parse("2006-01", reconcileFlag)
+		if err != nil {
+			log.Fatalf("Invalid month %q to reconcile, expected YYYY-MM", reconcileFlag)
+		}
+		_, err = reconciler.Reconcile(month.Year(), month.Month())
+		_ = dbClient.Close()
+		if err != nil {
+			log.Fatal("Reconciliation failed: ", err.Error())
+		}
+		os.Exit(0)
+	}
+
+	notifier := configureNotifier(log)
+
+	sinks, err := configureSinks(dbClient)
+	if err != nil {
+		log.Fatal("Failed to configure outputs: ", err.Error())
+	}
+	usageDataJob := usagedatajob.NewJob(log, sfTime, iaasClients, dbClient, sinks, resourceFlag)
+	usageDataJob.Notifier = notifier
+	usageDataJob.FailureThreshold = Config.Notifications.ProviderFailures
+	if len(Config.Validation) > 0 {
+		validator, err := validation.NewValidator(log, dbClient, Config.Validation)
+		if err != nil {
+			log.Fatal("Failed to load validation rules: ", err.Error())
+		}
+		usageDataJob.Validator = validator
+	}
+	usageDataJob.Stages = append(usageDataJob.Stages, reconciler)
+
+	owners := ownership.NewService(log, dbClient)
+	if Config.Ownership.File != "" {
+		directory, err := ownership.LoadFile(Config.Ownership.File)
+		if err != nil {
+			log.Fatal("Failed to load ownership: ", err.Error())
+		}
+		err = owners.Import(directory)
+		if err != nil {
+			log.Fatal("Failed to import ownership: ", err.Error())
+		}
+	}
+
+	if len(Config.Allocations) > 0 {
+		allocator, err := allocation.NewAllocator(log, dbClient, owners, Config.Allocations)
+		if err != nil {
+			log.Fatal("Failed to load allocation rules: ", err.Error())
+		}
+		usageDataJob.Stages = append(usageDataJob.Stages, allocator)
+	}
+
+	if len(Config.Kubernetes) > 0 {
+		attributor, err := kubernetes.NewAttributor(log, sfTime, dbClient, Config.Kubernetes)
+		if err != nil {
+			log.Fatal("Failed to load Kubernetes clusters: ", err.Error())
+		}
+		usageDataJob.Stages = append(usageDataJob.Stages, attributor)
+	}
+
+	if azureClient != nil {
+		usageDataJob.Stages = append(usageDataJob.Stages, azure.NewImporter(log, azureClient, dbClient, Config.Azure.BurnDays))
+	}
+
+	anomalyDetector, err := anomaly.NewDetector(log, dbClient, anomaly.Method(Config.Anomalies.Method), Config.Anomalies.BaselineDays, Config.Anomalies.Threshold, Config.Anomalies.MinimumCost)
+	if err != nil {
+		log.Fatal("Failed to create anomaly detector: ", err.Error())
+	}
+	anomalyDetector.Notifier = notifier
+	usageDataJob.Stages = append(usageDataJob.Stages, anomalyDetector)
+
+	forecaster := forecast.NewForecaster(log, dbClient, Config.Forecast.BacktestPeriods)
+	if fileFlag {
+		usageDataJob.Stages = append(usageDataJob.Stages, forecast.NewCSVExporter(log, sfTime, forecaster, fileDirFlag))
+	}
+
+	if len(Config.Budgets) > 0 {
+		budgetEvaluator, err := budget.NewEvaluator(log, sfTime, dbClient, forecaster, notifier, Config.Budgets)
+		if err != nil {
+			log.Fatal("Failed to load budgets: ", err.Error())
+		}
+		usageDataJob.Stages = append(usageDataJob.Stages, budgetEvaluator)
+	}
 
 	if !cronFlag {
 		usageDataJob.Run()
@@ -175,23 +475,50 @@ func main() {
 	if err != nil {
 		log.Fatal("Could not create cron job: ", err.Error())
 	}
+	if len(Config.Digest.To) > 0 {
+		if Config.Digest.From == "" || Config.Digest.SMTP.Host == "" {
+			log.Fatal("The digest requires from and smtp host to be configured")
+		}
+		mailer := digest.NewSMTPMailer(Config.Digest.SMTP.Host, Config.Digest.SMTP.Port, Config.Digest.SMTP.Username, Config.Digest.SMTP.Password, Config.Digest.From, Config.Digest.To)
+		err = c.AddJob(Config.Digest.Schedule, digest.NewJob(log, sfTime, dbClient, mailer, Config.Digest.Top))
+		if err != nil {
+			log.Fatal("Could not create digest cron job: ", err.Error())
+		}
+	}
 	c.Start()
 
 	// HEALTHCHECK
 	http.HandleFunc("/healthcheck", func(w http.ResponseWriter, r *http.Request) {
+		entries := c.Entries()
+		entry := entries[0]
+		for _, e := range entries {
+			if e.Job == cron.Job(usageDataJob) {
+				entry = e
+			}
+		}
 		fmt.Fprintf(w, "Meteorologica is deployed\n\n Last job ran at %s\n\n Next job will run in roughly %s\n    at %s\n\nThere are %d jobs scheduled.",
-			c.Entries()[0].Prev.In(sfTime).String(),
-			c.Entries()[0].Next.In(sfTime).Sub(time.Now().In(sfTime)).String(),
-			c.Entries()[0].Next.In(sfTime).String(),
-			len(c.Entries()),
+			entry.Prev.In(sfTime).String(),
+			entry.Next.In(sfTime).Sub(time.Now().In(sfTime)).String(),
+			entry.Next.In(sfTime).String(),
+			len(entries),
 		)
 	})
+	http.Handle("/anomalies", api.NewAnomaliesHandler(log, dbClient))
+	http.Handle("/forecast", api.NewForecastHandler(log, sfTime, forecaster))
+	http.Handle("/teams", api.RequireToken(Config.APIToken, api.NewTeamsHandler(log, owners)))
+	http.Handle("/ownership-mappings", api.RequireToken(Config.APIToken, api.NewOwnershipMappingsHandler(log, owners)))
+	http.Handle("/compare", api.NewCompareHandler(log, sfTime, compare.NewComparer(log, dbClient)))
+	http.Handle("/chargeback", api.NewChargebackHandler(log, sfTime, owners))
 	log.Fatal(http.ListenAndServe(":"+strconv.Itoa(Config.Port), nil))
 }
 
 func configureLog() *logrus.Logger {
 	log := logrus.New()
 	log.Out = os.Stdout
+	if compareFlag != "" || exportFocusFlag != "" {
+		// Keep the printed output clean
+		log.Out = os.Stderr
+	}
 	log.Level = logrus.InfoLevel
 	env := configor.ENV()
 	if (*verboseFlag || env == "development") && *verboseFlag != false {
@@ -204,6 +531,124 @@ func configureLog() *logrus.Logger {
 	return log
 }
 
+// configureSinks returns where the usage collected by each run is written:
+// the database unless -db=false, a local file with -file and every configured
+// output.
+func configureSinks(dbClient DBClient) ([]usagedatajob.OutputSink, error) {
+	var sinks []usagedatajob.OutputSink
+	if dbFlag {
+		sinks = append(sinks, sink.NewDatabaseSink(dbClient))
+	}
+	if fileFlag {
+		format, err := sink.NewFormat(fileFormatFlag, billingAccounts())
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink.NewPartitionedFileSink(fileDirFlag, format, false))
+	}
+
+	for _, output := range Config.Outputs {
+		format, err := sink.NewFormat(output.Format, billingAccounts())
+		if err != nil {
+			return nil, err
+		}
+		switch output.Type {
+		case "file":
+			path := output.Path
+			if path == "" {
+				path = "billing-data"
+			}
+			sinks = append(sinks, sink.NewPartitionedFileSink(path, format, output.Gzip))
+		case "gcs":
+			if output.Bucket == "" || Config.GCP.ApplicationCredentialsPath == "" {
+				return nil, errors.New("gcs outputs require a bucket and the GCP application-credentials-path to be configured")
+			}
+			gcpCredentials, err := ioutil.ReadFile(Config.GCP.ApplicationCredentialsPath)
+			if err != nil {
+				return nil, err
+			}
+			bucket, err := gcp.NewBucket(gcpCredentials, output.Bucket)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink.NewObjectSink(bucket, output.Prefix, format, output.Gzip))
+		case "s3":
+			if output.Bucket == "" || Config.AWS.Region == "" {
+				return nil, errors.New("s3 outputs require a bucket and the AWS region to be configured")
+			}
+			sess, err := awsSession()
+			if err != nil {
+				return nil, err
+			}
+			bucket := aws.NewBucket(sess, output.Bucket, output.Endpoint)
+			sinks = append(sinks, sink.NewPartitionedObjectSink(bucket, output.Prefix, format, output.Gzip))
+		default:
+			return nil, fmt.Errorf("Unknown output type %q, must be file, gcs or s3", output.Type)
+		}
+	}
+	return sinks, nil
+}
+
+var sharedAWSSession *session.Session
+
+// awsSession returns the session the AWS client and s3 outputs share, created
+// the first time it is needed with the configured credentials.
+func awsSession() (*session.Session, error) {
+	if sharedAWSSession != nil {
+		return sharedAWSSession, nil
+	}
+	_ = os.Setenv("AWS_ACCESS_KEY_ID", Config.AWS.AccessKeyID)
+	_ = os.Setenv("AWS_SECRET_ACCESS_KEY", Config.AWS.SecretAccessKey)
+	sess, err := session.NewSession(&awssdk.Config{Region: awssdk.String(Config.AWS.Region)})
+	if err != nil {
+		return nil, err
+	}
+	sharedAWSSession = sess
+	return sess, nil
+}
+
+// billingAccounts are the billing account of each configured IAAS, the
+// account its invoices are issued to.
+func billingAccounts() map[string]string {
+	accounts := make(map[string]string)
+	if Config.AWS.MasterAccountNumber != 0 {
+		accounts["AWS"] = strconv.FormatInt(Config.AWS.MasterAccountNumber, 10)
+	}
+	if Config.Azure.EnrollmentNumber != 0 {
+		accounts["Azure"] = strconv.Itoa(Config.Azure.EnrollmentNumber)
+	}
+	return accounts
+}
+
+// Notifier sends alerts to the configured destination.
+type Notifier interface {
+	NotifyBudget(datamodels.BudgetAlert) error
+	NotifyAnomaly(datamodels.Anomaly) error
+	NotifyJobFailure(datamodels.JobFailure) error
+	NotifyProviderDown(datamodels.JobFailure) error
+	NotifyOutputFailure(datamodels.OutputFailure) error
+}
+
+func configureNotifier(log *logrus.Logger) Notifier {
+	if len(Config.Notifications.Channels) > 0 {
+		log.Infof("Posting notifications to %d webhooks", len(Config.Notifications.Channels))
+		var interval time.Duration
+		if Config.Notifications.RateLimit > 0 {
+			interval = time.Minute / time.Duration(Config.Notifications.RateLimit)
+		}
+		notifier, err := notify.NewWebhookNotifier(log, Config.Notifications.Channels, Config.Notifications.Templates, Config.Notifications.Retries, time.Duration(Config.Notifications.RetryDelay)*time.Second, interval)
+		if err != nil {
+			log.Fatal("Failed to configure notifications: ", err.Error())
+		}
+		return notifier
+	}
+	if Config.Notifications.File != "" {
+		log.Infof("Writing notifications to %s", Config.Notifications.File)
+		return notify.NewFileNotifier(Config.Notifications.File)
+	}
+	return notify.NewNullNotifier(log)
+}
+
 func caseInsensitiveContains(haystack []string, needle string) bool {
 	for _, hay := range haystack {
 		if strings.ToLower(hay) == strings.ToLower(needle) {
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// FileNotifier appends every notification to a file as a line of JSON. It is
+// meant for trying out alerts and for tests.
+type FileNotifier struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+type fileNotification struct {
+	Type  string      `json:"type"`
+	Event interface{} `json:"event"`
+}
+
+func (n *FileNotifier) NotifyBudget(alert datamodels.BudgetAlert) error {
+	return n.write(fileNotification{Type: BudgetEvent, Event: alert})
+}
+
+func (n *FileNotifier) NotifyAnomaly(anomaly datamodels.Anomaly) error {
+	return n.write(fileNotification{Type: AnomalyEvent, Event: anomaly})
+}
+
+func (n *FileNotifier) NotifyJobFailure(failure datamodels.JobFailure) error {
+	return n.write(fileNotification{Type: JobFailureEvent, Event: failure})
+}
+
+func (n *FileNotifier) NotifyProviderDown(failure datamodels.JobFailure) error {
+	return n.write(fileNotification{Type: ProviderDownEvent, Event: failure})
+}
+
+func (n *FileNotifier) NotifyOutputFailure(failure datamodels.OutputFailure) error {
+	return n.write(fileNotification{Type: OutputFailureEvent, Event: failure})
+}
+
+func (n *FileNotifier) write(notification fileNotification) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(notification)
+}
@@ -0,0 +1,63 @@
+package notify_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/notify"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileNotifier", func() {
+	var (
+		dir      string
+		path     string
+		notifier *FileNotifier
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "notify")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "notifications.json")
+		notifier = NewFileNotifier(path)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("appends each budget alert as a line of JSON", func() {
+		Expect(notifier.NotifyBudget(datamodels.BudgetAlert{Budget: "team-x", Threshold: 50})).To(Succeed())
+		Expect(notifier.NotifyBudget(datamodels.BudgetAlert{Budget: "team-x", Threshold: 80})).To(Succeed())
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(ContainSubstring(`"type":"budget"`))
+		Expect(lines[0]).To(ContainSubstring(`"threshold":50`))
+		Expect(lines[1]).To(ContainSubstring(`"threshold":80`))
+	})
+
+	It("appends anomalies and job failures with their type", func() {
+		Expect(notifier.NotifyAnomaly(datamodels.Anomaly{Resource: "AWS"})).To(Succeed())
+		Expect(notifier.NotifyJobFailure(datamodels.JobFailure{Resource: "GCP"})).To(Succeed())
+		Expect(notifier.NotifyProviderDown(datamodels.JobFailure{Resource: "GCP"})).To(Succeed())
+		Expect(notifier.NotifyOutputFailure(datamodels.OutputFailure{Resource: "GCP", Output: "file"})).To(Succeed())
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		Expect(lines).To(HaveLen(4))
+		Expect(lines[0]).To(ContainSubstring(`"type":"anomaly"`))
+		Expect(lines[1]).To(ContainSubstring(`"type":"job_failure"`))
+		Expect(lines[2]).To(ContainSubstring(`"type":"provider_down"`))
+		Expect(lines[3]).To(ContainSubstring(`"type":"output_failure"`))
+	})
+})
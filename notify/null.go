@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// NullNotifier only logs that a notification would have been sent, for when
+// no notifier is configured.
+type NullNotifier struct {
+	log *logrus.Logger
+}
+
+func NewNullNotifier(log *logrus.Logger) *NullNotifier {
+	return &NullNotifier{log: log}
+}
+
+func (n *NullNotifier) NotifyBudget(datamodels.BudgetAlert) error {
+	n.log.Debug("No-op: using notify.NullNotifier")
+	return nil
+}
+
+func (n *NullNotifier) NotifyAnomaly(datamodels.Anomaly) error {
+	n.log.Debug("No-op: using notify.NullNotifier")
+	return nil
+}
+
+func (n *NullNotifier) NotifyJobFailure(datamodels.JobFailure) error {
+	n.log.Debug("No-op: using notify.NullNotifier")
+	return nil
+}
+
+func (n *NullNotifier) NotifyProviderDown(datamodels.JobFailure) error {
+	n.log.Debug("No-op: using notify.NullNotifier")
+	return nil
+}
+
+func (n *NullNotifier) NotifyOutputFailure(datamodels.OutputFailure) error {
+	n.log.Debug("No-op: using notify.NullNotifier")
+	return nil
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// The events notifications are sent for.
+const (
+	BudgetEvent        = "budget"
+	AnomalyEvent       = "anomaly"
+	JobFailureEvent    = "job_failure"
+	ProviderDownEvent  = "provider_down"
+	OutputFailureEvent = "output_failure"
+)
+
+// DefaultTemplates are the messages sent for each event. Each is a
+// text/template executed with the event.
+var DefaultTemplates = map[string]string{
+	BudgetEvent:        `Budget {{.Budget}} {{if eq .Kind "forecast"}}is forecast to reach{{else}}has reached{{end}} {{.Threshold}}% of {{printf "%.2f" .Amount}} in {{.Period}}: {{printf "%.2f" .Actual}} spent, {{printf "%.2f" .Forecast}} forecast`,
+	AnomalyEvent:       `Cost of {{.Resource}} account {{.AccountNumber}} on {{.Year}}-{{printf "%02d" .Month}}-{{printf "%02d" .Day}} was {{printf "%.2f" .Cost}}, expected {{printf "%.2f" .Expected}} ({{printf "%+.2f" .Magnitude}})`,
+	JobFailureEvent:    `{{.Resource}} usage was not saved by run {{.RunID}}: {{.Error}}`,
+	ProviderDownEvent:  `{{.Resource}} usage has not been saved for {{.ConsecutiveFailures}} runs in a row: {{.Error}}`,
+	OutputFailureEvent: `{{.Resource}} usage was not written to {{.Output}} by run {{.RunID}}: {{.Error}}`,
+}
+
+// parseTemplates parses the default templates, replaced by any of the given
+// ones.
+func parseTemplates(overrides map[string]string) (map[string]*template.Template, error) {
+	for event := range overrides {
+		if _, ok := DefaultTemplates[event]; !ok {
+			return nil, fmt.Errorf("Unknown notification template %q, must be one of %s, %s, %s, %s or %s", event, BudgetEvent, AnomalyEvent, JobFailureEvent, ProviderDownEvent, OutputFailureEvent)
+		}
+	}
+
+	templates := make(map[string]*template.Template)
+	for event, text := range DefaultTemplates {
+		if override, ok := overrides[event]; ok {
+			text = override
+		}
+		t, err := template.New(event).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s notification template: %s", event, err.Error())
+		}
+		templates[event] = t
+	}
+	return templates, nil
+}
+
+func render(t *template.Template, event interface{}) (string, error) {
+	text := new(bytes.Buffer)
+	err := t.Execute(text, event)
+	return text.String(), err
+}
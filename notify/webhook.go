@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// The kinds of webhook a notification can be posted to.
+const (
+	// Slack posts the message as a Slack-compatible incoming webhook.
+	Slack = "slack"
+	// Webhook posts the message along with the event as JSON.
+	Webhook = "webhook"
+)
+
+// Channel is a webhook notifications are posted to.
+type Channel struct {
+	Type string
+	URL  string
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+type webhookMessage struct {
+	Type  string      `json:"type"`
+	Text  string      `json:"text"`
+	Event interface{} `json:"event"`
+}
+
+// WebhookNotifier posts every notification to each of its channels. Failed
+// posts are retried, waiting twice as long before each retry, and posts to a
+// channel are at least the given interval apart.
+type WebhookNotifier struct {
+	log        *logrus.Logger
+	client     *http.Client
+	channels   []Channel
+	templates  map[string]*template.Template
+	retries    int
+	retryDelay time.Duration
+	interval   time.Duration
+
+	mutex    sync.Mutex
+	lastPost map[string]time.Time
+}
+
+func NewWebhookNotifier(log *logrus.Logger, channels []Channel, templates map[string]string, retries int, retryDelay, interval time.Duration) (*WebhookNotifier, error) {
+	for _, channel := range channels {
+		if channel.Type != Slack && channel.Type != Webhook {
+			return nil, fmt.Errorf("Unknown notification channel type %q, must be %q or %q", channel.Type, Slack, Webhook)
+		}
+		if channel.URL == "" {
+			return nil, fmt.Errorf("Notification channel of type %s requires a url", channel.Type)
+		}
+	}
+	parsed, err := parseTemplates(templates)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookNotifier{
+		log:        log,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		channels:   channels,
+		templates:  parsed,
+		retries:    retries,
+		retryDelay: retryDelay,
+		interval:   interval,
+		lastPost:   make(map[string]time.Time),
+	}, nil
+}
+
+func (n *WebhookNotifier) NotifyBudget(alert datamodels.BudgetAlert) error {
+	return n.notify(BudgetEvent, alert)
+}
+
+func (n *WebhookNotifier) NotifyAnomaly(anomaly datamodels.Anomaly) error {
+	return n.notify(AnomalyEvent, anomaly)
+}
+
+func (n *WebhookNotifier) NotifyJobFailure(failure datamodels.JobFailure) error {
+	return n.notify(JobFailureEvent, failure)
+}
+
+func (n *WebhookNotifier) NotifyProviderDown(failure datamodels.JobFailure) error {
+	return n.notify(ProviderDownEvent, failure)
+}
+
+func (n *WebhookNotifier) NotifyOutputFailure(failure datamodels.OutputFailure) error {
+	return n.notify(OutputFailureEvent, failure)
+}
+
+func (n *WebhookNotifier) notify(eventType string, event interface{}) error {
+	n.log.Debug("Entering notify.notify")
+	defer n.log.Debug("Returning notify.notify")
+
+	text, err := render(n.templates[eventType], event)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, channel := range n.channels {
+		var message interface{} = webhookMessage{Type: eventType, Text: text, Event: event}
+		if channel.Type == Slack {
+			message = slackMessage{Text: text}
+		}
+		body, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		err = n.post(channel.URL, body)
+		if err != nil {
+			n.log.Errorf("Failed to post %s notification to %s webhook: %s", eventType, channel.Type, err.Error())
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// post posts the body to the url, retrying when the post fails or the
+// server is unavailable or rate limiting.
+func (n *WebhookNotifier) post(url string, body []byte) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	delay := n.retryDelay
+	var err error
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			n.log.Debugf("Retrying notification in %s: %s", delay.String(), err.Error())
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		if wait := n.interval - time.Since(n.lastPost[url]); wait > 0 {
+			time.Sleep(wait)
+		}
+		n.lastPost[url] = time.Now()
+
+		var resp *http.Response
+		resp, err = n.client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("Webhook responded with %s", resp.Status)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return err
+		}
+	}
+	return err
+}
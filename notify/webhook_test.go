@@ -0,0 +1,186 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/notify"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+// webhookServer records the bodies posted to it and responds with the next of
+// its statuses, then with 200.
+type webhookServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	statuses []int
+	bodies   []map[string]interface{}
+	times    []time.Time
+}
+
+func newWebhookServer(statuses ...int) *webhookServer {
+	s := &webhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer GinkgoRecover()
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		Expect(r.Method).To(Equal("POST"))
+		Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+		body, err := ioutil.ReadAll(r.Body)
+		Expect(err).NotTo(HaveOccurred())
+		var message map[string]interface{}
+		Expect(json.Unmarshal(body, &message)).To(Succeed())
+		s.bodies = append(s.bodies, message)
+		s.times = append(s.times, time.Now())
+		if len(s.statuses) > 0 {
+			w.WriteHeader(s.statuses[0])
+			s.statuses = s.statuses[1:]
+		}
+	}))
+	return s
+}
+
+var _ = Describe("WebhookNotifier", func() {
+	var (
+		log       *logrus.Logger
+		slack     *webhookServer
+		webhook   *webhookServer
+		templates map[string]string
+		retries   int
+		interval  time.Duration
+		notifier  *WebhookNotifier
+		failure   datamodels.JobFailure
+	)
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		slack = newWebhookServer()
+		webhook = newWebhookServer()
+		templates = nil
+		retries = 2
+		interval = 0
+		failure = datamodels.JobFailure{RunID: "run-1", Resource: "AWS", Error: "Failed to get usage data: timeout", ConsecutiveFailures: 3}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		notifier, err = NewWebhookNotifier(log, []Channel{
+			{Type: Slack, URL: slack.URL},
+			{Type: Webhook, URL: webhook.URL},
+		}, templates, retries, time.Millisecond, interval)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		slack.Close()
+		webhook.Close()
+	})
+
+	It("posts the message to Slack and the message and event to the webhook", func() {
+		Expect(notifier.NotifyJobFailure(failure)).To(Succeed())
+
+		Expect(slack.bodies).To(Equal([]map[string]interface{}{
+			{"text": "AWS usage was not saved by run run-1: Failed to get usage data: timeout"},
+		}))
+		Expect(webhook.bodies).To(HaveLen(1))
+		Expect(webhook.bodies[0]["type"]).To(Equal(JobFailureEvent))
+		Expect(webhook.bodies[0]["text"]).To(Equal("AWS usage was not saved by run run-1: Failed to get usage data: timeout"))
+		Expect(webhook.bodies[0]["event"]).To(HaveKeyWithValue("consecutive_failures", 3.0))
+	})
+
+	It("has a message for every event", func() {
+		Expect(notifier.NotifyProviderDown(failure)).To(Succeed())
+		Expect(notifier.NotifyAnomaly(datamodels.Anomaly{Resource: "GCP", AccountNumber: "123", Year: 2016, Month: time.September, Day: 9, Cost: 500, Expected: 100, Magnitude: 400})).To(Succeed())
+		Expect(notifier.NotifyBudget(datamodels.BudgetAlert{Budget: "team-x", Period: "2016-09", Kind: datamodels.ForecastSpend, Threshold: 80, Amount: 1000, Actual: 500, Forecast: 850})).To(Succeed())
+		Expect(notifier.NotifyOutputFailure(datamodels.OutputFailure{RunID: "run-1", Resource: "AWS", Output: "bucket", Error: "access denied"})).To(Succeed())
+
+		Expect(slack.bodies).To(Equal([]map[string]interface{}{
+			{"text": "AWS usage has not been saved for 3 runs in a row: Failed to get usage data: timeout"},
+			{"text": "Cost of GCP account 123 on 2016-09-09 was 500.00, expected 100.00 (+400.00)"},
+			{"text": "Budget team-x is forecast to reach 80% of 1000.00 in 2016-09: 500.00 spent, 850.00 forecast"},
+			{"text": "AWS usage was not written to bucket by run run-1: access denied"},
+		}))
+		Expect(webhook.bodies[2]["type"]).To(Equal(BudgetEvent))
+	})
+
+	Context("with a template of its own", func() {
+		BeforeEach(func() {
+			templates = map[string]string{JobFailureEvent: ":fire: {{.Resource}} failed"}
+		})
+
+		It("uses it for its event", func() {
+			Expect(notifier.NotifyJobFailure(failure)).To(Succeed())
+			Expect(slack.bodies[0]["text"]).To(Equal(":fire: AWS failed"))
+		})
+	})
+
+	Context("when the webhook is unavailable", func() {
+		BeforeEach(func() {
+			webhook.statuses = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+		})
+
+		It("retries", func() {
+			Expect(notifier.NotifyJobFailure(failure)).To(Succeed())
+			Expect(webhook.bodies).To(HaveLen(3))
+			Expect(slack.bodies).To(HaveLen(1))
+		})
+
+		Context("for longer than the retries", func() {
+			BeforeEach(func() {
+				retries = 1
+			})
+
+			It("returns the error after posting to the other channels", func() {
+				Expect(notifier.NotifyJobFailure(failure)).To(MatchError("Webhook responded with 429 Too Many Requests"))
+				Expect(webhook.bodies).To(HaveLen(2))
+				Expect(slack.bodies).To(HaveLen(1))
+			})
+		})
+	})
+
+	Context("when the webhook rejects the notification", func() {
+		BeforeEach(func() {
+			slack.statuses = []int{http.StatusBadRequest}
+		})
+
+		It("does not retry", func() {
+			Expect(notifier.NotifyJobFailure(failure)).To(MatchError("Webhook responded with 400 Bad Request"))
+			Expect(slack.bodies).To(HaveLen(1))
+			Expect(webhook.bodies).To(HaveLen(1))
+		})
+	})
+
+	Context("with a rate limit", func() {
+		BeforeEach(func() {
+			interval = 50 * time.Millisecond
+		})
+
+		It("posts to each channel at most once an interval", func() {
+			Expect(notifier.NotifyJobFailure(failure)).To(Succeed())
+			Expect(notifier.NotifyJobFailure(failure)).To(Succeed())
+			Expect(slack.times).To(HaveLen(2))
+			Expect(slack.times[1].Sub(slack.times[0])).To(BeNumerically(">=", interval))
+		})
+	})
+
+	It("rejects unknown channels and templates", func() {
+		_, err := NewWebhookNotifier(log, []Channel{{Type: "email", URL: "x"}}, nil, 0, 0, 0)
+		Expect(err).To(MatchError(ContainSubstring(`Unknown notification channel type "email"`)))
+		_, err = NewWebhookNotifier(log, []Channel{{Type: Slack}}, nil, 0, 0, 0)
+		Expect(err).To(MatchError("Notification channel of type slack requires a url"))
+		_, err = NewWebhookNotifier(log, nil, map[string]string{"deploy": "x"}, 0, 0, 0)
+		Expect(err).To(MatchError(ContainSubstring(`Unknown notification template "deploy"`)))
+		_, err = NewWebhookNotifier(log, nil, map[string]string{BudgetEvent: "{{.Budget"}, 0, 0, 0)
+		Expect(err).To(MatchError(ContainSubstring("Invalid budget notification template")))
+	})
+})
@@ -0,0 +1,186 @@
+package ownership
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/gocarina/gocsv"
+	"gopkg.in/yaml.v2"
+)
+
+const dateFormat = "2006-01-02"
+
+// Directory is the team hierarchy and the mappings that assign costs to it.
+type Directory struct {
+	Teams    []datamodels.Team             `json:"teams" yaml:"teams"`
+	Mappings []datamodels.OwnershipMapping `json:"mappings" yaml:"mappings"`
+}
+
+// LoadFile reads a directory from a YAML file with teams and mappings, or the
+// mappings alone from a CSV file.
+func LoadFile(path string) (Directory, error) {
+	directory := Directory{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return directory, err
+		}
+		err = yaml.Unmarshal(contents, &directory)
+		if err != nil {
+			return directory, err
+		}
+	case ".csv":
+		file, err := os.Open(path)
+		if err != nil {
+			return directory, err
+		}
+		defer file.Close()
+		err = gocsv.UnmarshalFile(file, &directory.Mappings)
+		if err != nil {
+			return directory, err
+		}
+	default:
+		return directory, fmt.Errorf("Ownership file %s must be .yml or .csv", path)
+	}
+	return directory, directory.Validate()
+}
+
+func (d Directory) Validate() error {
+	for _, team := range d.Teams {
+		err := ValidateTeam(team)
+		if err != nil {
+			return err
+		}
+	}
+	for _, m := range d.Mappings {
+		err := ValidateMapping(m)
+		if err != nil {
+			return err
+		}
+	}
+	parents := d.parents()
+	for _, team := range d.Teams {
+		if len(path(team.Name, parents)) == 0 {
+			return fmt.Errorf("Team %s is its own ancestor", team.Name)
+		}
+	}
+	return nil
+}
+
+func ValidateTeam(team datamodels.Team) error {
+	if team.Name == "" {
+		return fmt.Errorf("Teams must have a name")
+	}
+	if team.Name == team.Parent {
+		return fmt.Errorf("Team %s cannot be its own parent", team.Name)
+	}
+	return nil
+}
+
+func ValidateMapping(m datamodels.OwnershipMapping) error {
+	if m.Team == "" {
+		return fmt.Errorf("Ownership mappings must have a team")
+	}
+	if m.Resource == "" && m.AccountNumber == "" && m.AzureDepartment == "" && m.AzureCostCenter == "" && m.Tag == "" {
+		return fmt.Errorf("Ownership mapping to %s must match a resource, account, Azure department, Azure cost center or tag", m.Team)
+	}
+	if strings.HasPrefix(m.Tag, "=") {
+		return fmt.Errorf("Ownership mapping to %s matches tag %q without a key, expected key=value or key", m.Team, m.Tag)
+	}
+	for _, date := range []string{m.EffectiveFrom, m.EffectiveTo} {
+		if date == "" {
+			continue
+		}
+		_, err := time.Parse(dateFormat, date)
+		if err != nil {
+			return fmt.Errorf("Ownership mapping to %s has invalid date %q, expected YYYY-MM-DD", m.Team, date)
+		}
+	}
+	if m.EffectiveFrom != "" && m.EffectiveTo != "" && m.EffectiveTo < m.EffectiveFrom {
+		return fmt.Errorf("Ownership mapping to %s ends before it starts", m.Team)
+	}
+	return nil
+}
+
+// Owner returns the team a cost is charged to. When several mappings match,
+// the one matching the most fields wins, then the one that started last.
+func (d Directory) Owner(cost datamodels.DailyCost) string {
+	date := cost.Date().Format(dateFormat)
+	var (
+		owner    *datamodels.OwnershipMapping
+		ownerFit int
+	)
+	for i := range d.Mappings {
+		m := &d.Mappings[i]
+		fit, ok := matches(*m, cost, date)
+		if !ok {
+			continue
+		}
+		if owner == nil || fit > ownerFit || (fit == ownerFit && m.EffectiveFrom > owner.EffectiveFrom) {
+			owner, ownerFit = m, fit
+		}
+	}
+	if owner == nil {
+		return datamodels.Unallocated
+	}
+	return owner.Team
+}
+
+// matches returns whether the mapping applies to the cost on the date, and how
+// many fields it matched on.
+func matches(m datamodels.OwnershipMapping, cost datamodels.DailyCost, date string) (int, bool) {
+	if (m.EffectiveFrom != "" && date < m.EffectiveFrom) || (m.EffectiveTo != "" && date > m.EffectiveTo) {
+		return 0, false
+	}
+	fit := 0
+	for _, field := range [][2]string{
+		{m.Resource, cost.Resource},
+		{m.AccountNumber, cost.AccountNumber},
+		{m.AzureDepartment, cost.DepartmentName},
+		{m.AzureCostCenter, cost.CostCenter},
+	} {
+		if field[0] == "" {
+			continue
+		}
+		if field[0] != field[1] {
+			return 0, false
+		}
+		fit++
+	}
+	if m.Tag != "" {
+		if !datamodels.HasTag(cost.Tags, m.Tag) {
+			return 0, false
+		}
+		fit++
+	}
+	return fit, true
+}
+
+func (d Directory) parents() map[string]string {
+	parents := make(map[string]string)
+	for _, team := range d.Teams {
+		parents[team.Name] = team.Parent
+	}
+	return parents
+}
+
+// path returns the team and its ancestors, top first, or nothing if the team
+// is its own ancestor.
+func path(team string, parents map[string]string) []string {
+	seen := make(map[string]bool)
+	teams := []string{}
+	for name := team; name != ""; name = parents[name] {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		teams = append([]string{name}, teams...)
+	}
+	return teams
+}
@@ -0,0 +1,156 @@
+package ownership_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/ownership"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Directory", func() {
+	Describe("LoadFile", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "ownership")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		write := func(name, contents string) string {
+			path := filepath.Join(dir, name)
+			Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+			return path
+		}
+
+		It("loads teams and mappings from YAML", func() {
+			directory, err := LoadFile(write("ownership.yml", `
+teams:
+  - name: platform
+    cost-center: CC-1
+  - name: databases
+    parent: platform
+mappings:
+  - team: databases
+    resource: AWS
+    account: "123"
+    effective-from: "2016-09-01"
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(directory.Teams).To(Equal([]datamodels.Team{
+				{Name: "platform", CostCenter: "CC-1"},
+				{Name: "databases", Parent: "platform"},
+			}))
+			Expect(directory.Mappings).To(Equal([]datamodels.OwnershipMapping{
+				{Team: "databases", Resource: "AWS", AccountNumber: "123", EffectiveFrom: "2016-09-01"},
+			}))
+		})
+
+		It("loads mappings from CSV", func() {
+			directory, err := LoadFile(write("ownership.csv", `Team,Resource,Account Number,Azure Department,Azure Cost Center,Tag,Effective From,Effective To
+databases,Azure,,Engineering,,,,2016-12-31
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(directory.Mappings).To(Equal([]datamodels.OwnershipMapping{
+				{Team: "databases", Resource: "Azure", AzureDepartment: "Engineering", EffectiveTo: "2016-12-31"},
+			}))
+		})
+
+		It("rejects other files", func() {
+			_, err := LoadFile(write("ownership.json", "{}"))
+			Expect(err).To(MatchError(ContainSubstring("must be .yml or .csv")))
+		})
+
+		It("rejects invalid mappings", func() {
+			_, err := LoadFile(write("ownership.yml", `
+mappings:
+  - team: databases
+    tag: =databases
+`))
+			Expect(err).To(MatchError(ContainSubstring("without a key")))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("rejects mappings that match everything", func() {
+			Expect(Directory{Mappings: []datamodels.OwnershipMapping{{Team: "a"}}}.Validate()).To(HaveOccurred())
+		})
+
+		It("rejects mappings that end before they start", func() {
+			err := Directory{Mappings: []datamodels.OwnershipMapping{
+				{Team: "a", Resource: "AWS", EffectiveFrom: "2016-10-01", EffectiveTo: "2016-09-30"},
+			}}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("ends before it starts")))
+		})
+
+		It("rejects invalid dates", func() {
+			err := Directory{Mappings: []datamodels.OwnershipMapping{
+				{Team: "a", Resource: "AWS", EffectiveFrom: "10/01/2016"},
+			}}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("expected YYYY-MM-DD")))
+		})
+
+		It("rejects cycles in the hierarchy", func() {
+			err := Directory{Teams: []datamodels.Team{
+				{Name: "a", Parent: "b"},
+				{Name: "b", Parent: "a"},
+			}}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("its own ancestor")))
+		})
+	})
+
+	Describe("Owner", func() {
+		var (
+			directory Directory
+			cost      datamodels.DailyCost
+		)
+
+		BeforeEach(func() {
+			directory = Directory{Mappings: []datamodels.OwnershipMapping{
+				{Team: "cloud", Resource: "AWS"},
+				{Team: "databases", Resource: "AWS", AccountNumber: "123", EffectiveTo: "2016-09-30"},
+				{Team: "analytics", Resource: "AWS", AccountNumber: "123", EffectiveFrom: "2016-10-01"},
+				{Team: "engineering", AzureDepartment: "Engineering"},
+				{Team: "search", Resource: "Azure", Tag: "team=search"},
+			}}
+			cost = datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", Year: 2016, Month: time.September, Day: 30}
+		})
+
+		It("charges the most specific mapping in effect on the day", func() {
+			Expect(directory.Owner(cost)).To(Equal("databases"))
+			cost.Month, cost.Day = time.October, 1
+			Expect(directory.Owner(cost)).To(Equal("analytics"))
+			cost.AccountNumber = "456"
+			Expect(directory.Owner(cost)).To(Equal("cloud"))
+		})
+
+		It("matches tags", func() {
+			cost = datamodels.DailyCost{Resource: "Azure", AccountNumber: "789", DepartmentName: "Engineering", Tags: `{"team":"search"}`, Year: 2016, Month: time.October, Day: 1}
+			Expect(directory.Owner(cost)).To(Equal("search"))
+		})
+
+		It("matches the Azure department", func() {
+			cost = datamodels.DailyCost{Resource: "Azure", AccountNumber: "789", DepartmentName: "Engineering", Year: 2016, Month: time.October, Day: 1}
+			Expect(directory.Owner(cost)).To(Equal("engineering"))
+		})
+
+		It("prefers the mapping that started last when they are as specific", func() {
+			directory.Mappings = append(directory.Mappings, datamodels.OwnershipMapping{Team: "storage", Resource: "AWS", EffectiveFrom: "2016-09-15"})
+			Expect(directory.Owner(datamodels.DailyCost{Resource: "AWS", Year: 2016, Month: time.September, Day: 20})).To(Equal("storage"))
+		})
+
+		It("leaves costs without a mapping unallocated", func() {
+			Expect(directory.Owner(datamodels.DailyCost{Resource: "GCP", Year: 2016, Month: time.October, Day: 1})).To(Equal(datamodels.Unallocated))
+		})
+	})
+})
@@ -0,0 +1,371 @@
+// This file was generated by counterfeiter
+package ownershipfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/ownership"
+)
+
+type FakeDatabase struct {
+	GetDailyCostsStub        func(time.Time, time.Time) ([]datamodels.DailyCost, error)
+	getDailyCostsMutex       sync.RWMutex
+	getDailyCostsArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getDailyCostsReturns struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}
+	GetAllocatedCostsStub        func(time.Time, time.Time) ([]datamodels.AllocatedCost, error)
+	getAllocatedCostsMutex       sync.RWMutex
+	getAllocatedCostsArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getAllocatedCostsReturns struct {
+		result1 []datamodels.AllocatedCost
+		result2 error
+	}
+	GetTeamsStub        func() ([]datamodels.Team, error)
+	getTeamsMutex       sync.RWMutex
+	getTeamsArgsForCall []struct{}
+	getTeamsReturns     struct {
+		result1 []datamodels.Team
+		result2 error
+	}
+	SaveTeamStub        func(datamodels.Team) error
+	saveTeamMutex       sync.RWMutex
+	saveTeamArgsForCall []struct {
+		arg1 datamodels.Team
+	}
+	saveTeamReturns struct {
+		result1 error
+	}
+	DeleteTeamStub        func(string) error
+	deleteTeamMutex       sync.RWMutex
+	deleteTeamArgsForCall []struct {
+		arg1 string
+	}
+	deleteTeamReturns struct {
+		result1 error
+	}
+	GetOwnershipMappingsStub        func() ([]datamodels.OwnershipMapping, error)
+	getOwnershipMappingsMutex       sync.RWMutex
+	getOwnershipMappingsArgsForCall []struct{}
+	getOwnershipMappingsReturns     struct {
+		result1 []datamodels.OwnershipMapping
+		result2 error
+	}
+	SaveOwnershipMappingStub        func(datamodels.OwnershipMapping) error
+	saveOwnershipMappingMutex       sync.RWMutex
+	saveOwnershipMappingArgsForCall []struct {
+		arg1 datamodels.OwnershipMapping
+	}
+	saveOwnershipMappingReturns struct {
+		result1 error
+	}
+	DeleteOwnershipMappingStub        func(int64) error
+	deleteOwnershipMappingMutex       sync.RWMutex
+	deleteOwnershipMappingArgsForCall []struct {
+		arg1 int64
+	}
+	deleteOwnershipMappingReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) GetDailyCosts(arg1 time.Time, arg2 time.Time) ([]datamodels.DailyCost, error) {
+	fake.getDailyCostsMutex.Lock()
+	fake.getDailyCostsArgsForCall = append(fake.getDailyCostsArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetDailyCosts", []interface{}{arg1, arg2})
+	fake.getDailyCostsMutex.Unlock()
+	if fake.GetDailyCostsStub != nil {
+		return fake.GetDailyCostsStub(arg1, arg2)
+	} else {
+		return fake.getDailyCostsReturns.result1, fake.getDailyCostsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetDailyCostsCallCount() int {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return len(fake.getDailyCostsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetDailyCostsArgsForCall(i int) (time.Time, time.Time) {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return fake.getDailyCostsArgsForCall[i].arg1, fake.getDailyCostsArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) GetDailyCostsReturns(result1 []datamodels.DailyCost, result2 error) {
+	fake.GetDailyCostsStub = nil
+	fake.getDailyCostsReturns = struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) GetAllocatedCosts(arg1 time.Time, arg2 time.Time) ([]datamodels.AllocatedCost, error) {
+	fake.getAllocatedCostsMutex.Lock()
+	fake.getAllocatedCostsArgsForCall = append(fake.getAllocatedCostsArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetAllocatedCosts", []interface{}{arg1, arg2})
+	fake.getAllocatedCostsMutex.Unlock()
+	if fake.GetAllocatedCostsStub != nil {
+		return fake.GetAllocatedCostsStub(arg1, arg2)
+	} else {
+		return fake.getAllocatedCostsReturns.result1, fake.getAllocatedCostsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetAllocatedCostsCallCount() int {
+	fake.getAllocatedCostsMutex.RLock()
+	defer fake.getAllocatedCostsMutex.RUnlock()
+	return len(fake.getAllocatedCostsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetAllocatedCostsArgsForCall(i int) (time.Time, time.Time) {
+	fake.getAllocatedCostsMutex.RLock()
+	defer fake.getAllocatedCostsMutex.RUnlock()
+	return fake.getAllocatedCostsArgsForCall[i].arg1, fake.getAllocatedCostsArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) GetAllocatedCostsReturns(result1 []datamodels.AllocatedCost, result2 error) {
+	fake.GetAllocatedCostsStub = nil
+	fake.getAllocatedCostsReturns = struct {
+		result1 []datamodels.AllocatedCost
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) GetTeams() ([]datamodels.Team, error) {
+	fake.getTeamsMutex.Lock()
+	fake.getTeamsArgsForCall = append(fake.getTeamsArgsForCall, struct{}{})
+	fake.recordInvocation("GetTeams", []interface{}{})
+	fake.getTeamsMutex.Unlock()
+	if fake.GetTeamsStub != nil {
+		return fake.GetTeamsStub()
+	} else {
+		return fake.getTeamsReturns.result1, fake.getTeamsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetTeamsCallCount() int {
+	fake.getTeamsMutex.RLock()
+	defer fake.getTeamsMutex.RUnlock()
+	return len(fake.getTeamsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetTeamsReturns(result1 []datamodels.Team, result2 error) {
+	fake.GetTeamsStub = nil
+	fake.getTeamsReturns = struct {
+		result1 []datamodels.Team
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) SaveTeam(arg1 datamodels.Team) error {
+	fake.saveTeamMutex.Lock()
+	fake.saveTeamArgsForCall = append(fake.saveTeamArgsForCall, struct {
+		arg1 datamodels.Team
+	}{arg1})
+	fake.recordInvocation("SaveTeam", []interface{}{arg1})
+	fake.saveTeamMutex.Unlock()
+	if fake.SaveTeamStub != nil {
+		return fake.SaveTeamStub(arg1)
+	} else {
+		return fake.saveTeamReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveTeamCallCount() int {
+	fake.saveTeamMutex.RLock()
+	defer fake.saveTeamMutex.RUnlock()
+	return len(fake.saveTeamArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveTeamArgsForCall(i int) datamodels.Team {
+	fake.saveTeamMutex.RLock()
+	defer fake.saveTeamMutex.RUnlock()
+	return fake.saveTeamArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveTeamReturns(result1 error) {
+	fake.SaveTeamStub = nil
+	fake.saveTeamReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) DeleteTeam(arg1 string) error {
+	fake.deleteTeamMutex.Lock()
+	fake.deleteTeamArgsForCall = append(fake.deleteTeamArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("DeleteTeam", []interface{}{arg1})
+	fake.deleteTeamMutex.Unlock()
+	if fake.DeleteTeamStub != nil {
+		return fake.DeleteTeamStub(arg1)
+	} else {
+		return fake.deleteTeamReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) DeleteTeamCallCount() int {
+	fake.deleteTeamMutex.RLock()
+	defer fake.deleteTeamMutex.RUnlock()
+	return len(fake.deleteTeamArgsForCall)
+}
+
+func (fake *FakeDatabase) DeleteTeamArgsForCall(i int) string {
+	fake.deleteTeamMutex.RLock()
+	defer fake.deleteTeamMutex.RUnlock()
+	return fake.deleteTeamArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) DeleteTeamReturns(result1 error) {
+	fake.DeleteTeamStub = nil
+	fake.deleteTeamReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) GetOwnershipMappings() ([]datamodels.OwnershipMapping, error) {
+	fake.getOwnershipMappingsMutex.Lock()
+	fake.getOwnershipMappingsArgsForCall = append(fake.getOwnershipMappingsArgsForCall, struct{}{})
+	fake.recordInvocation("GetOwnershipMappings", []interface{}{})
+	fake.getOwnershipMappingsMutex.Unlock()
+	if fake.GetOwnershipMappingsStub != nil {
+		return fake.GetOwnershipMappingsStub()
+	} else {
+		return fake.getOwnershipMappingsReturns.result1, fake.getOwnershipMappingsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetOwnershipMappingsCallCount() int {
+	fake.getOwnershipMappingsMutex.RLock()
+	defer fake.getOwnershipMappingsMutex.RUnlock()
+	return len(fake.getOwnershipMappingsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetOwnershipMappingsReturns(result1 []datamodels.OwnershipMapping, result2 error) {
+	fake.GetOwnershipMappingsStub = nil
+	fake.getOwnershipMappingsReturns = struct {
+		result1 []datamodels.OwnershipMapping
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) SaveOwnershipMapping(arg1 datamodels.OwnershipMapping) error {
+	fake.saveOwnershipMappingMutex.Lock()
+	fake.saveOwnershipMappingArgsForCall = append(fake.saveOwnershipMappingArgsForCall, struct {
+		arg1 datamodels.OwnershipMapping
+	}{arg1})
+	fake.recordInvocation("SaveOwnershipMapping", []interface{}{arg1})
+	fake.saveOwnershipMappingMutex.Unlock()
+	if fake.SaveOwnershipMappingStub != nil {
+		return fake.SaveOwnershipMappingStub(arg1)
+	} else {
+		return fake.saveOwnershipMappingReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveOwnershipMappingCallCount() int {
+	fake.saveOwnershipMappingMutex.RLock()
+	defer fake.saveOwnershipMappingMutex.RUnlock()
+	return len(fake.saveOwnershipMappingArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveOwnershipMappingArgsForCall(i int) datamodels.OwnershipMapping {
+	fake.saveOwnershipMappingMutex.RLock()
+	defer fake.saveOwnershipMappingMutex.RUnlock()
+	return fake.saveOwnershipMappingArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveOwnershipMappingReturns(result1 error) {
+	fake.SaveOwnershipMappingStub = nil
+	fake.saveOwnershipMappingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) DeleteOwnershipMapping(arg1 int64) error {
+	fake.deleteOwnershipMappingMutex.Lock()
+	fake.deleteOwnershipMappingArgsForCall = append(fake.deleteOwnershipMappingArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	fake.recordInvocation("DeleteOwnershipMapping", []interface{}{arg1})
+	fake.deleteOwnershipMappingMutex.Unlock()
+	if fake.DeleteOwnershipMappingStub != nil {
+		return fake.DeleteOwnershipMappingStub(arg1)
+	} else {
+		return fake.deleteOwnershipMappingReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) DeleteOwnershipMappingCallCount() int {
+	fake.deleteOwnershipMappingMutex.RLock()
+	defer fake.deleteOwnershipMappingMutex.RUnlock()
+	return len(fake.deleteOwnershipMappingArgsForCall)
+}
+
+func (fake *FakeDatabase) DeleteOwnershipMappingArgsForCall(i int) int64 {
+	fake.deleteOwnershipMappingMutex.RLock()
+	defer fake.deleteOwnershipMappingMutex.RUnlock()
+	return fake.deleteOwnershipMappingArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) DeleteOwnershipMappingReturns(result1 error) {
+	fake.DeleteOwnershipMappingStub = nil
+	fake.deleteOwnershipMappingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	fake.getAllocatedCostsMutex.RLock()
+	defer fake.getAllocatedCostsMutex.RUnlock()
+	fake.getTeamsMutex.RLock()
+	defer fake.getTeamsMutex.RUnlock()
+	fake.saveTeamMutex.RLock()
+	defer fake.saveTeamMutex.RUnlock()
+	fake.deleteTeamMutex.RLock()
+	defer fake.deleteTeamMutex.RUnlock()
+	fake.getOwnershipMappingsMutex.RLock()
+	defer fake.getOwnershipMappingsMutex.RUnlock()
+	fake.saveOwnershipMappingMutex.RLock()
+	defer fake.saveOwnershipMappingMutex.RUnlock()
+	fake.deleteOwnershipMappingMutex.RLock()
+	defer fake.deleteOwnershipMappingMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ ownership.Database = new(FakeDatabase)
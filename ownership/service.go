@@ -0,0 +1,274 @@
+package ownership
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+	GetAllocatedCosts(from, to time.Time) ([]datamodels.AllocatedCost, error)
+	GetTeams() ([]datamodels.Team, error)
+	SaveTeam(datamodels.Team) error
+	DeleteTeam(string) error
+	GetOwnershipMappings() ([]datamodels.OwnershipMapping, error)
+	SaveOwnershipMapping(datamodels.OwnershipMapping) error
+	DeleteOwnershipMapping(int64) error
+}
+
+// InvalidError is returned when a team or mapping is rejected, as opposed to
+// failing to be read or saved.
+type InvalidError struct {
+	Reason string
+}
+
+func (e InvalidError) Error() string {
+	return e.Reason
+}
+
+// ErrMappingNotFound is returned when updating a mapping whose ID is not saved.
+var ErrMappingNotFound = errors.New("Ownership mapping not found")
+
+// Service keeps the directory in the database and charges costs to teams
+// with it.
+type Service struct {
+	log *logrus.Logger
+	db  Database
+}
+
+func NewService(log *logrus.Logger, db Database) *Service {
+	return &Service{log: log, db: db}
+}
+
+func (s *Service) Directory() (Directory, error) {
+	teams, err := s.db.GetTeams()
+	if err != nil {
+		return Directory{}, err
+	}
+	mappings, err := s.db.GetOwnershipMappings()
+	if err != nil {
+		return Directory{}, err
+	}
+	return Directory{Teams: teams, Mappings: mappings}, nil
+}
+
+// Import saves every team and mapping of the directory, keeping those already
+// saved.
+func (s *Service) Import(directory Directory) error {
+	s.log.Debug("Entering ownership.Import")
+	defer s.log.Debug("Returning ownership.Import")
+
+	err := directory.Validate()
+	if err != nil {
+		return err
+	}
+	for _, team := range directory.Teams {
+		err = s.db.SaveTeam(team)
+		if err != nil {
+			return err
+		}
+	}
+	for _, m := range directory.Mappings {
+		err = s.db.SaveOwnershipMapping(m)
+		if err != nil {
+			return err
+		}
+	}
+	s.log.Infof("Imported %d teams and %d ownership mappings", len(directory.Teams), len(directory.Mappings))
+	return nil
+}
+
+func (s *Service) SaveTeam(team datamodels.Team) error {
+	directory, err := s.Directory()
+	if err != nil {
+		return err
+	}
+	teams := []datamodels.Team{team}
+	for _, t := range directory.Teams {
+		if t.Name != team.Name {
+			teams = append(teams, t)
+		}
+	}
+	err = Directory{Teams: teams}.Validate()
+	if err != nil {
+		return InvalidError{err.Error()}
+	}
+	return s.db.SaveTeam(team)
+}
+
+func (s *Service) DeleteTeam(name string) error {
+	return s.db.DeleteTeam(name)
+}
+
+// SaveMapping creates the mapping, or updates the saved mapping with its ID
+// when it has one.
+func (s *Service) SaveMapping(m datamodels.OwnershipMapping) error {
+	err := ValidateMapping(m)
+	if err != nil {
+		return InvalidError{err.Error()}
+	}
+	if m.ID != 0 {
+		mappings, err := s.db.GetOwnershipMappings()
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, saved := range mappings {
+			if saved.ID == m.ID {
+				found = true
+			}
+		}
+		if !found {
+			return ErrMappingNotFound
+		}
+	}
+	return s.db.SaveOwnershipMapping(m)
+}
+
+func (s *Service) DeleteMapping(id int64) error {
+	return s.db.DeleteOwnershipMapping(id)
+}
+
+// Statements charges the costs of the month to the teams that owned them on
+// each day, except for the costs split by allocation rules, which are charged
+// to the teams they were allocated to instead. Every team of the hierarchy
+// gets a statement, plus Unallocated when some costs have no owner.
+func (s *Service) Statements(year int, month time.Month) ([]datamodels.ChargebackStatement, error) {
+	s.log.Debug("Entering ownership.Statements")
+	defer s.log.Debug("Returning ownership.Statements")
+
+	start, end, period := calendar.MonthContaining(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+	costs, err := s.db.GetDailyCosts(start, end)
+	if err != nil {
+		return nil, err
+	}
+	allocated, err := s.db.GetAllocatedCosts(start, end)
+	if err != nil {
+		return nil, err
+	}
+	directory, err := s.Directory()
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make(map[string]*datamodels.ChargebackStatement)
+	lines := make(map[string]map[lineKey]*datamodels.ChargebackLine)
+	statement := func(team string) *datamodels.ChargebackStatement {
+		if statements[team] == nil {
+			statements[team] = &datamodels.ChargebackStatement{Team: team, Period: period, Lines: []datamodels.ChargebackLine{}}
+			lines[team] = make(map[lineKey]*datamodels.ChargebackLine)
+		}
+		return statements[team]
+	}
+	for _, team := range directory.Teams {
+		statement(team.Name).CostCenter = team.CostCenter
+	}
+	for _, m := range directory.Mappings {
+		statement(m.Team)
+	}
+
+	charge := func(team string, c datamodels.DailyCost, cost float64) {
+		statement(team).Total += cost
+		key := lineKey{c.Resource, c.AccountNumber, c.ServiceType}
+		if lines[team][key] == nil {
+			lines[team][key] = &datamodels.ChargebackLine{
+				Team:          team,
+				Period:        period,
+				Resource:      c.Resource,
+				AccountNumber: c.AccountNumber,
+				AccountName:   c.AccountName,
+				ServiceType:   c.ServiceType,
+			}
+		}
+		lines[team][key].Cost += cost
+	}
+	for _, c := range costs {
+		charge(directory.Owner(c), c, c.Cost)
+	}
+	// Allocated costs move from the owner of the reports they were split
+	// from to the team they were allocated to.
+	for _, a := range allocated {
+		charge(directory.Owner(a.DailyCost), a.DailyCost, -a.Cost)
+		charge(a.Team, a.DailyCost, a.Cost)
+	}
+
+	// Every ancestor gets a statement before the totals are rolled up, so
+	// that parents that are not defined as teams get a path too.
+	parents := directory.parents()
+	teams := []string{}
+	for team := range statements {
+		teams = append(teams, team)
+	}
+	for _, team := range teams {
+		for _, ancestor := range path(team, parents) {
+			statement(ancestor)
+		}
+	}
+	teams = teams[:0]
+	for team := range statements {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	for _, team := range teams {
+		st := statements[team]
+		teamPath := path(team, parents)
+		st.Path = strings.Join(teamPath, "/")
+		for _, ancestor := range teamPath {
+			statements[ancestor].RolledUpTotal += st.Total
+		}
+	}
+
+	sorted := statementSlice{}
+	for team, st := range statements {
+		keys := lineKeys{}
+		for key := range lines[team] {
+			keys = append(keys, key)
+		}
+		sort.Sort(keys)
+		for _, key := range keys {
+			// Lines that were allocated away entirely are left out.
+			if math.Abs(lines[team][key].Cost) < 0.005 {
+				continue
+			}
+			st.Lines = append(st.Lines, *lines[team][key])
+		}
+		sorted = append(sorted, *st)
+	}
+	sort.Sort(sorted)
+	return sorted, nil
+}
+
+type lineKey struct {
+	resource      string
+	accountNumber string
+	serviceType   string
+}
+
+type lineKeys []lineKey
+
+func (k lineKeys) Len() int      { return len(k) }
+func (k lineKeys) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k lineKeys) Less(i, j int) bool {
+	if k[i].resource != k[j].resource {
+		return k[i].resource < k[j].resource
+	}
+	if k[i].accountNumber != k[j].accountNumber {
+		return k[i].accountNumber < k[j].accountNumber
+	}
+	return k[i].serviceType < k[j].serviceType
+}
+
+type statementSlice []datamodels.ChargebackStatement
+
+func (s statementSlice) Len() int           { return len(s) }
+func (s statementSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s statementSlice) Less(i, j int) bool { return s[i].Path < s[j].Path }
@@ -0,0 +1,226 @@
+package ownership_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/ownership"
+	"github.com/challiwill/meteorologica/ownership/ownershipfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Service", func() {
+	var (
+		db      *ownershipfakes.FakeDatabase
+		service *Service
+	)
+
+	BeforeEach(func() {
+		log := logrus.New()
+		log.Out = NewBuffer()
+		db = new(ownershipfakes.FakeDatabase)
+		db.GetTeamsReturns([]datamodels.Team{
+			{Name: "engineering", CostCenter: "CC-1"},
+			{Name: "platform", Parent: "engineering"},
+			{Name: "databases", Parent: "platform", CostCenter: "CC-2"},
+		}, nil)
+		db.GetOwnershipMappingsReturns([]datamodels.OwnershipMapping{
+			{Team: "platform", Resource: "AWS"},
+			{Team: "databases", Resource: "AWS", AccountNumber: "123"},
+		}, nil)
+		service = NewService(log, db)
+	})
+
+	Describe("Statements", func() {
+		var (
+			statements []datamodels.ChargebackStatement
+			err        error
+		)
+
+		BeforeEach(func() {
+			db.GetDailyCostsReturns([]datamodels.DailyCost{
+				{Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.September, Day: 1, Cost: 10},
+				{Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.September, Day: 2, Cost: 15},
+				{Resource: "AWS", AccountNumber: "456", ServiceType: "S3", Year: 2016, Month: time.September, Day: 1, Cost: 5},
+				{Resource: "GCP", AccountNumber: "789", ServiceType: "Compute", Year: 2016, Month: time.September, Day: 1, Cost: 2},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			statements, err = service.Statements(2016, time.September)
+		})
+
+		It("reads the costs of the month", func() {
+			Expect(err).NotTo(HaveOccurred())
+			from, to := db.GetDailyCostsArgsForCall(0)
+			Expect(from).To(Equal(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(to).To(Equal(time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("charges each team and rolls the totals up the hierarchy", func() {
+			Expect(statements).To(HaveLen(4))
+
+			Expect(statements[0].Team).To(Equal(datamodels.Unallocated))
+			Expect(statements[0].Total).To(Equal(2.0))
+
+			Expect(statements[1].Path).To(Equal("engineering"))
+			Expect(statements[1].CostCenter).To(Equal("CC-1"))
+			Expect(statements[1].Total).To(Equal(0.0))
+			Expect(statements[1].RolledUpTotal).To(Equal(30.0))
+
+			Expect(statements[2].Path).To(Equal("engineering/platform"))
+			Expect(statements[2].Total).To(Equal(5.0))
+			Expect(statements[2].RolledUpTotal).To(Equal(30.0))
+
+			Expect(statements[3].Path).To(Equal("engineering/platform/databases"))
+			Expect(statements[3].Period).To(Equal("2016-09"))
+			Expect(statements[3].Total).To(Equal(25.0))
+			Expect(statements[3].RolledUpTotal).To(Equal(25.0))
+			Expect(statements[3].Lines).To(Equal([]datamodels.ChargebackLine{
+				{Team: "databases", Period: "2016-09", Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Cost: 25},
+			}))
+		})
+
+		Context("when a parent is not defined as a team", func() {
+			BeforeEach(func() {
+				db.GetTeamsReturns([]datamodels.Team{
+					{Name: "platform", Parent: "engineering"},
+					{Name: "databases", Parent: "platform", CostCenter: "CC-2"},
+				}, nil)
+			})
+
+			It("gives it a statement with a path and the rolled up total", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(statements).To(HaveLen(4))
+				Expect(statements[1].Team).To(Equal("engineering"))
+				Expect(statements[1].Path).To(Equal("engineering"))
+				Expect(statements[1].Total).To(Equal(0.0))
+				Expect(statements[1].RolledUpTotal).To(Equal(30.0))
+				Expect(statements[2].Path).To(Equal("engineering/platform"))
+				Expect(statements[2].RolledUpTotal).To(Equal(30.0))
+			})
+		})
+
+		Context("with allocated costs", func() {
+			BeforeEach(func() {
+				db.GetAllocatedCostsReturns([]datamodels.AllocatedCost{
+					{Team: "platform", DailyCost: datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.September, Day: 1, Cost: 10}},
+					{Team: "platform", DailyCost: datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.September, Day: 2, Cost: 9}},
+					{Team: "databases", DailyCost: datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.September, Day: 2, Cost: 6}},
+				}, nil)
+			})
+
+			It("reads the allocations of the month", func() {
+				from, to := db.GetAllocatedCostsArgsForCall(0)
+				Expect(from).To(Equal(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC)))
+				Expect(to).To(Equal(time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC)))
+			})
+
+			It("charges them to the teams they were allocated to instead of the owner", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(statements[2].Path).To(Equal("engineering/platform"))
+				Expect(statements[2].Total).To(Equal(24.0))
+				Expect(statements[2].RolledUpTotal).To(Equal(30.0))
+				Expect(statements[2].Lines).To(Equal([]datamodels.ChargebackLine{
+					{Team: "platform", Period: "2016-09", Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Cost: 19},
+					{Team: "platform", Period: "2016-09", Resource: "AWS", AccountNumber: "456", ServiceType: "S3", Cost: 5},
+				}))
+				Expect(statements[3].Total).To(Equal(6.0))
+				Expect(statements[3].Lines).To(Equal([]datamodels.ChargebackLine{
+					{Team: "databases", Period: "2016-09", Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Cost: 6},
+				}))
+			})
+
+			Context("when they are allocated away from the owner entirely", func() {
+				BeforeEach(func() {
+					db.GetAllocatedCostsReturns([]datamodels.AllocatedCost{
+						{Team: "platform", DailyCost: datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.September, Day: 1, Cost: 10}},
+						{Team: "platform", DailyCost: datamodels.DailyCost{Resource: "AWS", AccountNumber: "123", ServiceType: "EC2", Year: 2016, Month: time.September, Day: 2, Cost: 15}},
+					}, nil)
+				})
+
+				It("leaves their lines out of the owner's statement", func() {
+					Expect(statements[3].Total).To(Equal(0.0))
+					Expect(statements[3].Lines).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("when the costs cannot be read", func() {
+			BeforeEach(func() {
+				db.GetDailyCostsReturns(nil, errors.New("some-error"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(MatchError("some-error"))
+			})
+		})
+
+		Context("when the allocated costs cannot be read", func() {
+			BeforeEach(func() {
+				db.GetAllocatedCostsReturns(nil, errors.New("some-error"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(MatchError("some-error"))
+			})
+		})
+	})
+
+	Describe("Import", func() {
+		It("saves every team and mapping", func() {
+			err := service.Import(Directory{
+				Teams:    []datamodels.Team{{Name: "a"}, {Name: "b", Parent: "a"}},
+				Mappings: []datamodels.OwnershipMapping{{Team: "b", Resource: "AWS"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.SaveTeamCallCount()).To(Equal(2))
+			Expect(db.SaveOwnershipMappingCallCount()).To(Equal(1))
+		})
+
+		It("saves nothing when the directory is invalid", func() {
+			err := service.Import(Directory{Mappings: []datamodels.OwnershipMapping{{Team: "b"}}})
+			Expect(err).To(HaveOccurred())
+			Expect(db.SaveOwnershipMappingCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("SaveTeam", func() {
+		It("saves the team", func() {
+			Expect(service.SaveTeam(datamodels.Team{Name: "storage", Parent: "platform"})).To(Succeed())
+			Expect(db.SaveTeamArgsForCall(0)).To(Equal(datamodels.Team{Name: "storage", Parent: "platform"}))
+		})
+
+		It("rejects parents that would make a cycle", func() {
+			err := service.SaveTeam(datamodels.Team{Name: "engineering", Parent: "databases"})
+			Expect(err).To(BeAssignableToTypeOf(InvalidError{}))
+			Expect(db.SaveTeamCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("SaveMapping", func() {
+		It("rejects invalid mappings", func() {
+			err := service.SaveMapping(datamodels.OwnershipMapping{Team: "a"})
+			Expect(err).To(BeAssignableToTypeOf(InvalidError{}))
+			Expect(db.SaveOwnershipMappingCallCount()).To(Equal(0))
+		})
+
+		It("updates a saved mapping by its ID", func() {
+			db.GetOwnershipMappingsReturns([]datamodels.OwnershipMapping{{ID: 3, Team: "a", Resource: "AWS"}}, nil)
+			mapping := datamodels.OwnershipMapping{ID: 3, Team: "b", Resource: "AWS"}
+			Expect(service.SaveMapping(mapping)).To(Succeed())
+			Expect(db.SaveOwnershipMappingArgsForCall(0)).To(Equal(mapping))
+		})
+
+		It("does not update mappings that are not saved", func() {
+			db.GetOwnershipMappingsReturns([]datamodels.OwnershipMapping{{ID: 3, Team: "a", Resource: "AWS"}}, nil)
+			Expect(service.SaveMapping(datamodels.OwnershipMapping{ID: 4, Team: "b", Resource: "AWS"})).To(MatchError(ErrMappingNotFound))
+			Expect(db.SaveOwnershipMappingCallCount()).To(Equal(0))
+		})
+	})
+})
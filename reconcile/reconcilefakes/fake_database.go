@@ -0,0 +1,170 @@
+// This file was generated by counterfeiter
+package reconcilefakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/reconcile"
+)
+
+type FakeDatabase struct {
+	GetDailyCostsStub        func(time.Time, time.Time) ([]datamodels.DailyCost, error)
+	getDailyCostsMutex       sync.RWMutex
+	getDailyCostsArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+	}
+	getDailyCostsReturns struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}
+	SaveReconciliationsStub        func([]datamodels.Reconciliation) error
+	saveReconciliationsMutex       sync.RWMutex
+	saveReconciliationsArgsForCall []struct {
+		arg1 []datamodels.Reconciliation
+	}
+	saveReconciliationsReturns struct {
+		result1 error
+	}
+	GetReconciliationsStub        func(string) ([]datamodels.Reconciliation, error)
+	getReconciliationsMutex       sync.RWMutex
+	getReconciliationsArgsForCall []struct {
+		arg1 string
+	}
+	getReconciliationsReturns struct {
+		result1 []datamodels.Reconciliation
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) GetDailyCosts(arg1 time.Time, arg2 time.Time) ([]datamodels.DailyCost, error) {
+	fake.getDailyCostsMutex.Lock()
+	fake.getDailyCostsArgsForCall = append(fake.getDailyCostsArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+	}{arg1, arg2})
+	fake.recordInvocation("GetDailyCosts", []interface{}{arg1, arg2})
+	fake.getDailyCostsMutex.Unlock()
+	if fake.GetDailyCostsStub != nil {
+		return fake.GetDailyCostsStub(arg1, arg2)
+	} else {
+		return fake.getDailyCostsReturns.result1, fake.getDailyCostsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetDailyCostsCallCount() int {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return len(fake.getDailyCostsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetDailyCostsArgsForCall(i int) (time.Time, time.Time) {
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	return fake.getDailyCostsArgsForCall[i].arg1, fake.getDailyCostsArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) GetDailyCostsReturns(result1 []datamodels.DailyCost, result2 error) {
+	fake.GetDailyCostsStub = nil
+	fake.getDailyCostsReturns = struct {
+		result1 []datamodels.DailyCost
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) SaveReconciliations(arg1 []datamodels.Reconciliation) error {
+	fake.saveReconciliationsMutex.Lock()
+	fake.saveReconciliationsArgsForCall = append(fake.saveReconciliationsArgsForCall, struct {
+		arg1 []datamodels.Reconciliation
+	}{arg1})
+	fake.recordInvocation("SaveReconciliations", []interface{}{arg1})
+	fake.saveReconciliationsMutex.Unlock()
+	if fake.SaveReconciliationsStub != nil {
+		return fake.SaveReconciliationsStub(arg1)
+	} else {
+		return fake.saveReconciliationsReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveReconciliationsCallCount() int {
+	fake.saveReconciliationsMutex.RLock()
+	defer fake.saveReconciliationsMutex.RUnlock()
+	return len(fake.saveReconciliationsArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveReconciliationsArgsForCall(i int) []datamodels.Reconciliation {
+	fake.saveReconciliationsMutex.RLock()
+	defer fake.saveReconciliationsMutex.RUnlock()
+	return fake.saveReconciliationsArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveReconciliationsReturns(result1 error) {
+	fake.SaveReconciliationsStub = nil
+	fake.saveReconciliationsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) GetReconciliations(arg1 string) ([]datamodels.Reconciliation, error) {
+	fake.getReconciliationsMutex.Lock()
+	fake.getReconciliationsArgsForCall = append(fake.getReconciliationsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("GetReconciliations", []interface{}{arg1})
+	fake.getReconciliationsMutex.Unlock()
+	if fake.GetReconciliationsStub != nil {
+		return fake.GetReconciliationsStub(arg1)
+	} else {
+		return fake.getReconciliationsReturns.result1, fake.getReconciliationsReturns.result2
+	}
+}
+
+func (fake *FakeDatabase) GetReconciliationsCallCount() int {
+	fake.getReconciliationsMutex.RLock()
+	defer fake.getReconciliationsMutex.RUnlock()
+	return len(fake.getReconciliationsArgsForCall)
+}
+
+func (fake *FakeDatabase) GetReconciliationsArgsForCall(i int) string {
+	fake.getReconciliationsMutex.RLock()
+	defer fake.getReconciliationsMutex.RUnlock()
+	return fake.getReconciliationsArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) GetReconciliationsReturns(result1 []datamodels.Reconciliation, result2 error) {
+	fake.GetReconciliationsStub = nil
+	fake.getReconciliationsReturns = struct {
+		result1 []datamodels.Reconciliation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getDailyCostsMutex.RLock()
+	defer fake.getDailyCostsMutex.RUnlock()
+	fake.saveReconciliationsMutex.RLock()
+	defer fake.saveReconciliationsMutex.RUnlock()
+	fake.getReconciliationsMutex.RLock()
+	defer fake.getReconciliationsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ reconcile.Database = new(FakeDatabase)
@@ -0,0 +1,115 @@
+// This file was generated by counterfeiter
+package reconcilefakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/reconcile"
+)
+
+type FakeInvoiceClient struct {
+	NameStub        func() string
+	nameMutex       sync.RWMutex
+	nameArgsForCall []struct{}
+	nameReturns     struct {
+		result1 string
+	}
+	GetInvoiceStub        func(int, time.Month) (datamodels.Invoice, error)
+	getInvoiceMutex       sync.RWMutex
+	getInvoiceArgsForCall []struct {
+		arg1 int
+		arg2 time.Month
+	}
+	getInvoiceReturns struct {
+		result1 datamodels.Invoice
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInvoiceClient) Name() string {
+	fake.nameMutex.Lock()
+	fake.nameArgsForCall = append(fake.nameArgsForCall, struct{}{})
+	fake.recordInvocation("Name", []interface{}{})
+	fake.nameMutex.Unlock()
+	if fake.NameStub != nil {
+		return fake.NameStub()
+	} else {
+		return fake.nameReturns.result1
+	}
+}
+
+func (fake *FakeInvoiceClient) NameCallCount() int {
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
+	return len(fake.nameArgsForCall)
+}
+
+func (fake *FakeInvoiceClient) NameReturns(result1 string) {
+	fake.NameStub = nil
+	fake.nameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeInvoiceClient) GetInvoice(arg1 int, arg2 time.Month) (datamodels.Invoice, error) {
+	fake.getInvoiceMutex.Lock()
+	fake.getInvoiceArgsForCall = append(fake.getInvoiceArgsForCall, struct {
+		arg1 int
+		arg2 time.Month
+	}{arg1, arg2})
+	fake.recordInvocation("GetInvoice", []interface{}{arg1, arg2})
+	fake.getInvoiceMutex.Unlock()
+	if fake.GetInvoiceStub != nil {
+		return fake.GetInvoiceStub(arg1, arg2)
+	} else {
+		return fake.getInvoiceReturns.result1, fake.getInvoiceReturns.result2
+	}
+}
+
+func (fake *FakeInvoiceClient) GetInvoiceCallCount() int {
+	fake.getInvoiceMutex.RLock()
+	defer fake.getInvoiceMutex.RUnlock()
+	return len(fake.getInvoiceArgsForCall)
+}
+
+func (fake *FakeInvoiceClient) GetInvoiceArgsForCall(i int) (int, time.Month) {
+	fake.getInvoiceMutex.RLock()
+	defer fake.getInvoiceMutex.RUnlock()
+	return fake.getInvoiceArgsForCall[i].arg1, fake.getInvoiceArgsForCall[i].arg2
+}
+
+func (fake *FakeInvoiceClient) GetInvoiceReturns(result1 datamodels.Invoice, result2 error) {
+	fake.GetInvoiceStub = nil
+	fake.getInvoiceReturns = struct {
+		result1 datamodels.Invoice
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInvoiceClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
+	fake.getInvoiceMutex.RLock()
+	defer fake.getInvoiceMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeInvoiceClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ reconcile.InvoiceClient = new(FakeInvoiceClient)
@@ -0,0 +1,206 @@
+package reconcile
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// centRounding is the largest unexplained gap that is put down to the
+// rounding of the many rows that add up to the totals.
+const centRounding = 0.01
+
+//go:generate counterfeiter . InvoiceClient
+
+// InvoiceClient is implemented by IaasClients that can tell what a month was
+// invoiced.
+type InvoiceClient interface {
+	Name() string
+	GetInvoice(int, time.Month) (datamodels.Invoice, error)
+}
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+	SaveReconciliations([]datamodels.Reconciliation) error
+	GetReconciliations(string) ([]datamodels.Reconciliation, error)
+}
+
+// GapError is returned when the causes of the gap between what a provider
+// invoiced and what was saved leave more than the tolerance unexplained.
+type GapError struct {
+	Reconciliations []datamodels.Reconciliation
+}
+
+func (e GapError) Error() string {
+	gaps := []string{}
+	for _, r := range e.Reconciliations {
+		gaps = append(gaps, fmt.Sprintf("%s %s is off by %.2f (invoiced %.2f, saved %.2f)", r.Resource, r.Period, r.Gap, r.InvoiceTotal, r.StoredTotal))
+	}
+	return "Saved costs do not match the invoice: " + strings.Join(gaps, "; ")
+}
+
+// Reconciler compares the invoice of each provider with the rows saved for
+// the month.
+type Reconciler struct {
+	log               *logrus.Logger
+	location          *time.Location
+	db                Database
+	clients           []InvoiceClient
+	restatementWindow int
+
+	Tolerance float64
+}
+
+func NewReconciler(log *logrus.Logger, location *time.Location, db Database, clients []InvoiceClient, restatementWindow int, tolerance float64) *Reconciler {
+	return &Reconciler{
+		log:               log,
+		location:          location,
+		db:                db,
+		clients:           clients,
+		restatementWindow: restatementWindow,
+
+		Tolerance: tolerance,
+	}
+}
+
+func (r *Reconciler) Name() string {
+	return "reconciliation"
+}
+
+// Run reconciles the previous month once the restatement window no longer
+// reaches into it, so its rows are final. Providers that were already
+// reconciled for the month are skipped.
+func (r *Reconciler) Run(run datamodels.Run, _ datamodels.Reports) error {
+	r.log.Debug("Entering reconcile.Run")
+	defer r.log.Debug("Returning reconcile.Run")
+
+	runDate := run.StartedAt.In(r.location)
+	yesterday := time.Date(runDate.Year(), runDate.Month(), runDate.Day()-1, 0, 0, 0, 0, time.UTC)
+	window := calendar.DaysEndingOn(yesterday, r.restatementWindow)
+	if window[0].Month() != yesterday.Month() {
+		return nil
+	}
+	start, _, period := calendar.MonthContaining(yesterday.AddDate(0, 0, -yesterday.Day()))
+
+	reconciled, err := r.db.GetReconciliations(period)
+	if err != nil {
+		return err
+	}
+	done := make(map[string]bool)
+	for _, rec := range reconciled {
+		done[rec.Resource] = true
+	}
+	clients := []InvoiceClient{}
+	for _, c := range r.clients {
+		if !done[c.Name()] {
+			clients = append(clients, c)
+		}
+	}
+	if len(clients) == 0 {
+		return nil
+	}
+	_, err = r.reconcile(clients, start.Year(), start.Month())
+	return err
+}
+
+// Reconcile compares the invoice of every provider for the month with the
+// saved rows, and saves the reconciliations. A GapError is returned along with
+// them when the unexplained part of any gap exceeds the tolerance.
+func (r *Reconciler) Reconcile(year int, month time.Month) ([]datamodels.Reconciliation, error) {
+	return r.reconcile(r.clients, year, month)
+}
+
+func (r *Reconciler) reconcile(clients []InvoiceClient, year int, month time.Month) ([]datamodels.Reconciliation, error) {
+	r.log.Debug("Entering reconcile.Reconcile")
+	defer r.log.Debug("Returning reconcile.Reconcile")
+
+	start, end, _ := calendar.MonthContaining(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+	costs, err := r.db.GetDailyCosts(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliations := []datamodels.Reconciliation{}
+	gaps := []datamodels.Reconciliation{}
+	for _, client := range clients {
+		invoice, err := client.GetInvoice(year, month)
+		if err != nil {
+			r.log.Errorf("Failed to get the %s invoice for %d-%02d: %s", client.Name(), year, month, err.Error())
+			continue
+		}
+		rec := r.Compare(invoice, costs)
+		reconciliations = append(reconciliations, rec)
+		if rec.WithinTolerance {
+			r.log.Infof("%s %s reconciles with the invoice (gap %.2f, unexplained %.2f)", rec.Resource, rec.Period, rec.Gap, rec.Unexplained)
+			if rec.MissingDates != "" {
+				r.log.Warnf("%s %s has no saved costs on %s", rec.Resource, rec.Period, rec.MissingDates)
+			}
+			continue
+		}
+		r.log.Errorf("%s %s does not reconcile with the invoice: invoiced %.2f, saved %.2f, gap %.2f of which rounding %.2f, credits %.2f, dropped rows %.2f, missing days %.2f (%s), unexplained %.2f",
+			rec.Resource, rec.Period, rec.InvoiceTotal, rec.StoredTotal, rec.Gap, rec.Rounding, rec.Credits, rec.DroppedRows, rec.MissingDays, rec.MissingDates, rec.Unexplained)
+		gaps = append(gaps, rec)
+	}
+
+	err = r.db.SaveReconciliations(reconciliations)
+	if err != nil {
+		return reconciliations, err
+	}
+	if len(gaps) > 0 {
+		return reconciliations, GapError{Reconciliations: gaps}
+	}
+	return reconciliations, nil
+}
+
+// Compare breaks the gap between the invoice and the saved costs of its
+// provider down into its causes.
+func (r *Reconciler) Compare(invoice datamodels.Invoice, costs []datamodels.DailyCost) datamodels.Reconciliation {
+	_, end, period := calendar.MonthContaining(time.Date(invoice.Year, invoice.Month, 1, 0, 0, 0, 0, time.UTC))
+	rec := datamodels.Reconciliation{
+		Resource:     invoice.Resource,
+		Period:       period,
+		InvoiceTotal: invoice.Total,
+		Rounding:     invoice.Rounding,
+		Credits:      invoice.Credits,
+		DroppedRows:  invoice.Dropped,
+		Tolerance:    r.Tolerance,
+	}
+
+	saved := make(map[int]bool)
+	for _, c := range costs {
+		if c.Resource != invoice.Resource {
+			continue
+		}
+		rec.StoredTotal += c.Cost
+		saved[c.Day] = true
+	}
+
+	missingDates := []string{}
+	for day := 1; day <= end.Day(); day++ {
+		if saved[day] {
+			continue
+		}
+		if invoice.DailyCosts != nil && invoice.DailyCosts[day] == 0 {
+			continue
+		}
+		rec.MissingDays += invoice.DailyCosts[day]
+		missingDates = append(missingDates, time.Date(invoice.Year, invoice.Month, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02"))
+	}
+	rec.MissingDates = strings.Join(missingDates, " ")
+
+	rec.Gap = rec.InvoiceTotal - rec.StoredTotal
+	rec.Unexplained = rec.Gap - rec.Rounding - rec.Credits - rec.DroppedRows - rec.MissingDays
+	if math.Abs(rec.Unexplained) < centRounding {
+		rec.Rounding += rec.Unexplained
+		rec.Unexplained = 0
+	}
+	rec.WithinTolerance = math.Abs(rec.Unexplained) <= r.Tolerance
+	return rec
+}
@@ -0,0 +1,219 @@
+package reconcile_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/azure"
+	"github.com/challiwill/meteorologica/azure/azurefakes"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/reconcile"
+	"github.com/challiwill/meteorologica/reconcile/reconcilefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Reconciler", func() {
+	var (
+		logOutput  *Buffer
+		db         *reconcilefakes.FakeDatabase
+		client     *reconcilefakes.FakeInvoiceClient
+		reconciler *Reconciler
+		costs      []datamodels.DailyCost
+	)
+
+	BeforeEach(func() {
+		log := logrus.New()
+		logOutput = NewBuffer()
+		log.Out = logOutput
+		db = new(reconcilefakes.FakeDatabase)
+		client = new(reconcilefakes.FakeInvoiceClient)
+		client.NameReturns("GCP")
+		reconciler = NewReconciler(log, time.UTC, db, []InvoiceClient{client}, 3, 1)
+
+		costs = []datamodels.DailyCost{}
+		for day := 1; day <= 30; day++ {
+			if day == 3 {
+				continue
+			}
+			costs = append(costs, datamodels.DailyCost{Resource: "GCP", Year: 2016, Month: time.September, Day: day, Cost: 2})
+		}
+		costs = append(costs, datamodels.DailyCost{Resource: "AWS", Year: 2016, Month: time.September, Day: 3, Cost: 100})
+	})
+
+	Describe("Compare", func() {
+		It("breaks the gap down by cause", func() {
+			dailyCosts := map[int]float64{}
+			for day := 1; day <= 30; day++ {
+				dailyCosts[day] = 2
+			}
+			rec := reconciler.Compare(datamodels.Invoice{
+				Resource:   "GCP",
+				Year:       2016,
+				Month:      time.September,
+				Total:      60 - 15 + 3 + 0.004,
+				Credits:    -15,
+				Dropped:    3,
+				DailyCosts: dailyCosts,
+			}, costs)
+
+			Expect(rec.Period).To(Equal("2016-09"))
+			Expect(rec.StoredTotal).To(Equal(58.0))
+			Expect(rec.Gap).To(BeNumerically("~", -9.996, 0.0001))
+			Expect(rec.Credits).To(Equal(-15.0))
+			Expect(rec.DroppedRows).To(Equal(3.0))
+			Expect(rec.MissingDays).To(Equal(2.0))
+			Expect(rec.MissingDates).To(Equal("2016-09-03"))
+			Expect(rec.Rounding).To(BeNumerically("~", 0.004, 0.0001))
+			Expect(rec.Unexplained).To(Equal(0.0))
+			Expect(rec.WithinTolerance).To(BeTrue())
+		})
+
+		It("lists the days without saved rows when the provider does not break its billing down by day", func() {
+			rec := reconciler.Compare(datamodels.Invoice{Resource: "GCP", Year: 2016, Month: time.September, Total: 60}, costs)
+			Expect(rec.MissingDates).To(Equal("2016-09-03"))
+			Expect(rec.MissingDays).To(Equal(0.0))
+			Expect(rec.Unexplained).To(Equal(2.0))
+		})
+
+		It("is within tolerance when the unexplained gap is", func() {
+			rec := reconciler.Compare(datamodels.Invoice{Resource: "GCP", Year: 2016, Month: time.September, Total: 58.5}, costs)
+			Expect(rec.WithinTolerance).To(BeTrue())
+		})
+
+		It("is not within tolerance when the causes do not account for the gap", func() {
+			rec := reconciler.Compare(datamodels.Invoice{Resource: "GCP", Year: 2016, Month: time.September, Total: 58.5, Credits: 10}, costs)
+			Expect(rec.Gap).To(BeNumerically("~", 0.5, 0.0001))
+			Expect(rec.Unexplained).To(BeNumerically("~", -9.5, 0.0001))
+			Expect(rec.WithinTolerance).To(BeFalse())
+		})
+	})
+
+	Describe("Reconcile", func() {
+		var (
+			reconciliations []datamodels.Reconciliation
+			err             error
+		)
+
+		BeforeEach(func() {
+			db.GetDailyCostsReturns(costs, nil)
+			client.GetInvoiceReturns(datamodels.Invoice{Resource: "GCP", Year: 2016, Month: time.September, Total: 58}, nil)
+		})
+
+		JustBeforeEach(func() {
+			reconciliations, err = reconciler.Reconcile(2016, time.September)
+		})
+
+		It("reconciles the saved costs of the month with the invoice", func() {
+			Expect(err).NotTo(HaveOccurred())
+			from, to := db.GetDailyCostsArgsForCall(0)
+			Expect(from).To(Equal(time.Date(2016, time.September, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(to).To(Equal(time.Date(2016, time.September, 30, 0, 0, 0, 0, time.UTC)))
+			year, month := client.GetInvoiceArgsForCall(0)
+			Expect(year).To(Equal(2016))
+			Expect(month).To(Equal(time.September))
+			Expect(reconciliations).To(HaveLen(1))
+			Expect(db.SaveReconciliationsArgsForCall(0)).To(Equal(reconciliations))
+		})
+
+		Context("with an Azure invoice with marketplace and separately billed charges", func() {
+			BeforeEach(func() {
+				api := new(azurefakes.FakeUsageAPI)
+				api.GetUsageReturns([]*azure.Usage{{SubscriptionGuid: "some-guid", Year: 2016, Month: 9, Day: 12, ExtendedCost: 45}}, nil)
+				api.GetMarketplaceChargesReturns([]*azure.MarketplaceCharge{{SubscriptionGuid: "some-guid", PublisherName: "some-publisher", Year: 2016, Month: 9, Day: 12, ExtendedCost: 7}}, nil)
+				api.GetBalanceReturns(&azure.Balance{TotalUsage: 45, ServiceOverage: 10, ChargesBilledSeparately: 5, TotalOverage: 15, MarketplaceCharges: 7}, nil)
+				azureClient := azure.NewClient(logrus.New(), time.UTC, "", "some-key", 1337, 3)
+				azureClient.API = api
+				reconciler = NewReconciler(logrus.New(), time.UTC, db, []InvoiceClient{azureClient}, 3, 1)
+
+				db.GetDailyCostsReturns([]datamodels.DailyCost{
+					{Resource: "Azure", ServiceType: "Microsoft.Compute", Year: 2016, Month: time.September, Day: 12, Cost: 45},
+					{Resource: "Azure", ServiceType: "Marketplace: some-publisher", Year: 2016, Month: time.September, Day: 12, Cost: 7},
+				}, nil)
+			})
+
+			It("counts each charge once", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reconciliations).To(HaveLen(1))
+				Expect(reconciliations[0].InvoiceTotal).To(Equal(52.0))
+				Expect(reconciliations[0].StoredTotal).To(Equal(52.0))
+				Expect(reconciliations[0].Unexplained).To(Equal(0.0))
+				Expect(reconciliations[0].WithinTolerance).To(BeTrue())
+			})
+		})
+
+		Context("when the gap exceeds the tolerance", func() {
+			BeforeEach(func() {
+				client.GetInvoiceReturns(datamodels.Invoice{Resource: "GCP", Year: 2016, Month: time.September, Total: 70}, nil)
+			})
+
+			It("fails loudly", func() {
+				Expect(err).To(BeAssignableToTypeOf(GapError{}))
+				Expect(err.Error()).To(ContainSubstring("GCP 2016-09 is off by 12.00 (invoiced 70.00, saved 58.00)"))
+				Expect(logOutput).To(Say("level=error"))
+				Expect(db.SaveReconciliationsCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the invoice cannot be read", func() {
+			BeforeEach(func() {
+				client.GetInvoiceReturns(datamodels.Invoice{}, errors.New("some-error"))
+			})
+
+			It("skips the provider", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reconciliations).To(BeEmpty())
+				Expect(logOutput).To(Say("Failed to get the GCP invoice for 2016-09: some-error"))
+			})
+		})
+	})
+
+	Describe("Run", func() {
+		var startedAt time.Time
+
+		BeforeEach(func() {
+			db.GetDailyCostsReturns(costs, nil)
+			client.GetInvoiceReturns(datamodels.Invoice{Resource: "GCP", Year: 2016, Month: time.September, Total: 58}, nil)
+		})
+
+		JustBeforeEach(func() {
+			Expect(reconciler.Run(datamodels.NewRun(startedAt), nil)).To(Succeed())
+		})
+
+		Context("while the restatement window reaches into the previous month", func() {
+			BeforeEach(func() {
+				startedAt = time.Date(2016, time.October, 3, 6, 0, 0, 0, time.UTC)
+			})
+
+			It("does not reconcile", func() {
+				Expect(client.GetInvoiceCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("once the previous month is out of the restatement window", func() {
+			BeforeEach(func() {
+				startedAt = time.Date(2016, time.October, 4, 6, 0, 0, 0, time.UTC)
+			})
+
+			It("reconciles the previous month", func() {
+				Expect(db.GetReconciliationsArgsForCall(0)).To(Equal("2016-09"))
+				year, month := client.GetInvoiceArgsForCall(0)
+				Expect(year).To(Equal(2016))
+				Expect(month).To(Equal(time.September))
+			})
+
+			Context("when the provider was already reconciled", func() {
+				BeforeEach(func() {
+					db.GetReconciliationsReturns([]datamodels.Reconciliation{{Resource: "GCP", Period: "2016-09"}}, nil)
+				})
+
+				It("does not reconcile it again", func() {
+					Expect(client.GetInvoiceCallCount()).To(Equal(0))
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,51 @@
+package sink
+
+import "github.com/challiwill/meteorologica/datamodels"
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	SaveReports(datamodels.Reports) error
+	SaveReportVersions(datamodels.Run, datamodels.Reports) error
+}
+
+// DatabaseSink saves reports, and the history of every report, to the
+// database.
+type DatabaseSink struct {
+	db  Database
+	run datamodels.Run
+}
+
+func NewDatabaseSink(db Database) *DatabaseSink {
+	return &DatabaseSink{db: db}
+}
+
+func (s *DatabaseSink) Name() string {
+	return "database"
+}
+
+// SavesToDatabase is always true: the usage is only saved once it has been
+// written here.
+func (s *DatabaseSink) SavesToDatabase() bool {
+	return true
+}
+
+func (s *DatabaseSink) Open(run datamodels.Run) error {
+	s.run = run
+	return nil
+}
+
+// Write saves the reports and then their versions, which are kept even when
+// saving the reports themselves fails.
+func (s *DatabaseSink) Write(reports datamodels.Reports) error {
+	err := s.db.SaveReports(reports)
+	versionErr := s.db.SaveReportVersions(s.run, reports)
+	if err != nil {
+		return err
+	}
+	return versionErr
+}
+
+func (s *DatabaseSink) Close() error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package sink_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/sink"
+	"github.com/challiwill/meteorologica/sink/sinkfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DatabaseSink", func() {
+	var (
+		db      *sinkfakes.FakeDatabase
+		dbSink  *DatabaseSink
+		run     datamodels.Run
+		reports datamodels.Reports
+	)
+
+	BeforeEach(func() {
+		db = new(sinkfakes.FakeDatabase)
+		dbSink = NewDatabaseSink(db)
+		run = datamodels.NewRun(time.Date(2016, time.October, 18, 6, 0, 0, 0, time.UTC))
+		reports = datamodels.Reports{{ID: "a", Cost: 1}}
+		Expect(dbSink.Open(run)).To(Succeed())
+	})
+
+	It("saves the reports and their versions for the run", func() {
+		Expect(dbSink.Write(reports)).To(Succeed())
+		Expect(db.SaveReportsArgsForCall(0)).To(Equal(reports))
+		versionRun, versions := db.SaveReportVersionsArgsForCall(0)
+		Expect(versionRun).To(Equal(run))
+		Expect(versions).To(Equal(reports))
+		Expect(dbSink.Close()).To(Succeed())
+	})
+
+	It("is the sink that saves to the database", func() {
+		Expect(dbSink.SavesToDatabase()).To(BeTrue())
+	})
+
+	Context("when saving the reports fails", func() {
+		BeforeEach(func() {
+			db.SaveReportsReturns(errors.New("some-error"))
+		})
+
+		It("still saves their versions and returns the error", func() {
+			Expect(dbSink.Write(reports)).To(MatchError("some-error"))
+			Expect(db.SaveReportVersionsCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when saving the versions fails", func() {
+		BeforeEach(func() {
+			db.SaveReportVersionsReturns(errors.New("some-error"))
+		})
+
+		It("returns the error", func() {
+			Expect(dbSink.Write(reports)).To(MatchError("some-error"))
+		})
+	})
+})
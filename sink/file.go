@@ -0,0 +1,87 @@
+package sink
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// FileSink writes the reports of a run to a file in a directory, named after
+// the month of the run and the format, and gzipped if asked to. The file is
+// only created once there are reports to write, with the header first.
+type FileSink struct {
+	dir    string
+	format Format
+	gzip   bool
+
+	run  datamodels.Run
+	file *fileWriter
+	path string
+}
+
+func NewFileSink(dir string, format Format, gzip bool) *FileSink {
+	return &FileSink{dir: dir, format: format, gzip: gzip}
+}
+
+func (s *FileSink) Name() string {
+	return "file " + filepath.Join(s.dir, s.fileName())
+}
+
+func (s *FileSink) Open(run datamodels.Run) error {
+	s.run = run
+	s.path = ""
+	return nil
+}
+
+// Path is the file written by the run, empty if nothing was written.
+func (s *FileSink) Path() string {
+	return s.path
+}
+
+func (s *FileSink) Write(reports datamodels.Reports) error {
+	header := false
+	if s.file == nil {
+		err := s.create()
+		if err != nil {
+			return err
+		}
+		header = true
+	}
+	return s.format.Write(s.file, reports, header)
+}
+
+func (s *FileSink) create() error {
+	path := filepath.Join(s.dir, s.fileName())
+	file, err := createFile(path, s.gzip)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.path = path
+	return nil
+}
+
+// fileName is like 2016-September-normalized-billing-data.csv.
+func (s *FileSink) fileName() string {
+	name := strings.Join([]string{
+		strconv.Itoa(s.run.StartedAt.Year()),
+		s.run.StartedAt.Month().String(),
+		s.format.Name(),
+		"billing-data",
+	}, "-") + s.format.Extension()
+	if s.gzip {
+		name += ".gz"
+	}
+	return name
+}
+
+func (s *FileSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	file := s.file
+	s.file = nil
+	return file.Close()
+}
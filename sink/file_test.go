@@ -0,0 +1,93 @@
+package sink_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/sink"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileSink", func() {
+	var (
+		dir string
+		run datamodels.Run
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "sink")
+		Expect(err).NotTo(HaveOccurred())
+		run = datamodels.NewRun(time.Date(2016, time.September, 18, 6, 0, 0, 0, time.UTC))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("writes the reports of every write to a file named after the month and format, with one header", func() {
+		fileSink := NewFileSink(dir, CSV{}, false)
+		Expect(fileSink.Open(run)).To(Succeed())
+		Expect(fileSink.Write(datamodels.Reports{{ID: "a", Resource: "AWS"}})).To(Succeed())
+		Expect(fileSink.Write(datamodels.Reports{{ID: "b", Resource: "GCP"}})).To(Succeed())
+		Expect(fileSink.Close()).To(Succeed())
+
+		Expect(fileSink.Path()).To(Equal(filepath.Join(dir, "2016-September-normalized-billing-data.csv")))
+		Expect(fileSink.Name()).To(Equal("file " + fileSink.Path()))
+		contents, err := ioutil.ReadFile(fileSink.Path())
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(HavePrefix("ID,Account Number,"))
+		Expect(lines[1]).To(HavePrefix("a,"))
+		Expect(lines[2]).To(HavePrefix("b,"))
+	})
+
+	It("does not create a file when nothing is written", func() {
+		fileSink := NewFileSink(dir, CSV{}, false)
+		Expect(fileSink.Open(run)).To(Succeed())
+		Expect(fileSink.Close()).To(Succeed())
+		Expect(fileSink.Path()).To(BeEmpty())
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+
+	It("gzips the file when asked to", func() {
+		fileSink := NewFileSink(dir, NDJSON{}, true)
+		Expect(fileSink.Open(run)).To(Succeed())
+		Expect(fileSink.Write(datamodels.Reports{{ID: "a"}, {ID: "b"}})).To(Succeed())
+		Expect(fileSink.Close()).To(Succeed())
+
+		Expect(fileSink.Path()).To(HaveSuffix("2016-September-normalized-billing-data.ndjson.gz"))
+		file, err := os.Open(fileSink.Path())
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+		gz, err := gzip.NewReader(file)
+		Expect(err).NotTo(HaveOccurred())
+		contents, err := ioutil.ReadAll(gz)
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(HavePrefix(`{"ID":"a",`))
+	})
+
+	It("starts a new file with a header on the next run", func() {
+		fileSink := NewFileSink(dir, CSV{}, false)
+		for i := 0; i < 2; i++ {
+			Expect(fileSink.Open(run)).To(Succeed())
+			Expect(fileSink.Write(datamodels.Reports{{ID: "a"}})).To(Succeed())
+			Expect(fileSink.Close()).To(Succeed())
+		}
+		contents, err := ioutil.ReadFile(fileSink.Path())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Split(strings.TrimSpace(string(contents)), "\n")).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,74 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/focus"
+	"github.com/gocarina/gocsv"
+)
+
+// Format encodes reports for a file or object. Name and Extension make up the
+// name of what is written.
+type Format interface {
+	Name() string
+	Extension() string
+	Write(w io.Writer, reports datamodels.Reports, header bool) error
+}
+
+// NewFormat returns the named format: csv or ndjson for the normalized
+// reports, or focus-csv or focus-ndjson for FOCUS rows.
+func NewFormat(name string, billingAccounts map[string]string) (Format, error) {
+	switch name {
+	case "", "csv":
+		return CSV{}, nil
+	case "ndjson":
+		return NDJSON{}, nil
+	case "focus-csv", "focus-ndjson":
+		return focus.NewExporter(strings.TrimPrefix(name, "focus-"), billingAccounts)
+	}
+	return nil, fmt.Errorf("Unknown output format %q, must be csv, ndjson, focus-csv or focus-ndjson", name)
+}
+
+// CSV writes the normalized reports as CSV.
+type CSV struct{}
+
+func (CSV) Name() string {
+	return "normalized"
+}
+
+func (CSV) Extension() string {
+	return ".csv"
+}
+
+func (CSV) Write(w io.Writer, reports datamodels.Reports, header bool) error {
+	if header {
+		return gocsv.Marshal(&reports, w)
+	}
+	return gocsv.MarshalWithoutHeaders(&reports, w)
+}
+
+// NDJSON writes the normalized reports as a line of JSON each.
+type NDJSON struct{}
+
+func (NDJSON) Name() string {
+	return "normalized"
+}
+
+func (NDJSON) Extension() string {
+	return ".ndjson"
+}
+
+func (NDJSON) Write(w io.Writer, reports datamodels.Reports, _ bool) error {
+	encoder := json.NewEncoder(w)
+	for _, r := range reports {
+		err := encoder.Encode(r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
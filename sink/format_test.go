@@ -0,0 +1,27 @@
+package sink_test
+
+import (
+	"github.com/challiwill/meteorologica/focus"
+	. "github.com/challiwill/meteorologica/sink"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewFormat", func() {
+	It("returns the normalized and FOCUS formats", func() {
+		for name, extension := range map[string]string{"": ".csv", "csv": ".csv", "ndjson": ".ndjson", "focus-csv": ".csv", "focus-ndjson": ".ndjson"} {
+			format, err := NewFormat(name, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(format.Extension()).To(Equal(extension))
+		}
+		format, err := NewFormat("focus-csv", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(format).To(BeAssignableToTypeOf(&focus.Exporter{}))
+	})
+
+	It("rejects unknown formats", func() {
+		_, err := NewFormat("parquet", nil)
+		Expect(err).To(MatchError(ContainSubstring(`Unknown output format "parquet"`)))
+	})
+})
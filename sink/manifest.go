@@ -0,0 +1,115 @@
+package sink
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Manifest lists the files a run wrote to a partitioned directory. Current
+// lists the file of every day in the directory as of the run: the file of the
+// latest run that wrote the day.
+type Manifest struct {
+	RunID      string              `json:"run_id"`
+	StartedAt  time.Time           `json:"started_at"`
+	Format     string              `json:"format"`
+	Partitions []ManifestPartition `json:"partitions"`
+	Current    []ManifestPartition `json:"current"`
+}
+
+type ManifestPartition struct {
+	Resource string `json:"resource"`
+	Year     int    `json:"year"`
+	Month    int    `json:"month"`
+	Day      int    `json:"day"`
+	Path     string `json:"path"`
+	Rows     int    `json:"rows"`
+}
+
+// currentPartitions returns the current file of every day: the partitions the
+// run wrote, and those of the latest earlier manifest in dir for the days the
+// run did not write.
+func currentPartitions(dir, runID string, written []ManifestPartition) ([]ManifestPartition, error) {
+	previous, err := latestManifest(dir, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]ManifestPartition)
+	for _, p := range append(previous.Current, written...) {
+		byPath[filepath.ToSlash(filepath.Dir(filepath.FromSlash(p.Path)))] = p
+	}
+	days := []string{}
+	for day := range byPath {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	current := []ManifestPartition{}
+	for _, day := range days {
+		current = append(current, byPath[day])
+	}
+	return current, nil
+}
+
+// latestManifest reads the manifest in dir of the latest run before the given
+// one. Run IDs sort in the order the runs started.
+func latestManifest(dir, runID string) (Manifest, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return Manifest{}, nil
+	}
+	if err != nil {
+		return Manifest{}, err
+	}
+	latest := ""
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		if entry.IsDir() || id == entry.Name() || id >= runID || id <= latest {
+			continue
+		}
+		latest = id
+	}
+	if latest == "" {
+		return Manifest{}, nil
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, latest+".json"))
+	if err != nil {
+		return Manifest{}, err
+	}
+	var manifest Manifest
+	err = json.Unmarshal(contents, &manifest)
+	return manifest, err
+}
+
+// writeManifest writes the manifest to a temporary file next to the path and
+// renames it into place, so it is never seen half written.
+func writeManifest(path string, manifest Manifest) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(path), ".manifest")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(manifest)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,69 @@
+package sink
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+//go:generate counterfeiter . ObjectStore
+
+// ObjectStore keeps files as objects in a bucket.
+type ObjectStore interface {
+	Put(key string, file *os.File) error
+}
+
+// ObjectSink writes the reports of a run like a FileSink, to a temporary
+// file, and uploads it to the store under the prefix when the run is done.
+type ObjectSink struct {
+	store  ObjectStore
+	prefix string
+	format Format
+	gzip   bool
+
+	dir  string
+	file *FileSink
+}
+
+func NewObjectSink(store ObjectStore, prefix string, format Format, gzip bool) *ObjectSink {
+	return &ObjectSink{store: store, prefix: prefix, format: format, gzip: gzip}
+}
+
+func (s *ObjectSink) Name() string {
+	return "object storage " + s.prefix
+}
+
+func (s *ObjectSink) Open(run datamodels.Run) error {
+	dir, err := ioutil.TempDir("", "meteorologica")
+	if err != nil {
+		return err
+	}
+	s.dir = dir
+	s.file = NewFileSink(dir, s.format, s.gzip)
+	return s.file.Open(run)
+}
+
+func (s *ObjectSink) Write(reports datamodels.Reports) error {
+	return s.file.Write(reports)
+}
+
+// Close uploads what the run wrote, if anything.
+func (s *ObjectSink) Close() error {
+	defer os.RemoveAll(s.dir)
+
+	err := s.file.Close()
+	if err != nil {
+		return err
+	}
+	if s.file.Path() == "" {
+		return nil
+	}
+	file, err := os.Open(s.file.Path())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return s.store.Put(path.Join(s.prefix, path.Base(s.file.Path())), file)
+}
@@ -0,0 +1,61 @@
+package sink_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/sink"
+	"github.com/challiwill/meteorologica/sink/sinkfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ObjectSink", func() {
+	var (
+		store      *sinkfakes.FakeObjectStore
+		objectSink *ObjectSink
+		uploaded   string
+		uploadedAt string
+	)
+
+	BeforeEach(func() {
+		store = new(sinkfakes.FakeObjectStore)
+		store.PutStub = func(key string, file *os.File) error {
+			contents, err := ioutil.ReadAll(file)
+			uploaded = string(contents)
+			uploadedAt = file.Name()
+			return err
+		}
+		objectSink = NewObjectSink(store, "billing", CSV{}, false)
+		Expect(objectSink.Open(datamodels.NewRun(time.Date(2016, time.September, 18, 6, 0, 0, 0, time.UTC)))).To(Succeed())
+	})
+
+	It("uploads what the run wrote under the prefix and removes the temporary file", func() {
+		Expect(objectSink.Write(datamodels.Reports{{ID: "a"}})).To(Succeed())
+		Expect(store.PutCallCount()).To(Equal(0))
+		Expect(objectSink.Close()).To(Succeed())
+
+		Expect(store.PutCallCount()).To(Equal(1))
+		key, _ := store.PutArgsForCall(0)
+		Expect(key).To(Equal("billing/2016-September-normalized-billing-data.csv"))
+		Expect(uploaded).To(HavePrefix("ID,Account Number,"))
+		_, err := os.Stat(uploadedAt)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("uploads nothing when nothing was written", func() {
+		Expect(objectSink.Close()).To(Succeed())
+		Expect(store.PutCallCount()).To(Equal(0))
+	})
+
+	It("returns the error when the upload fails", func() {
+		store.PutStub = nil
+		store.PutReturns(errors.New("some-error"))
+		Expect(objectSink.Write(datamodels.Reports{{ID: "a"}})).To(Succeed())
+		Expect(objectSink.Close()).To(MatchError("some-error"))
+	})
+})
@@ -0,0 +1,19 @@
+package sink
+
+// Output configures where the usage collected by each run is written to, as
+// well as the database.
+type Output struct {
+	// Type is file, for a file per IAAS and day partitioned under Path, gcs,
+	// for an object under Prefix in the GCS Bucket, or s3, for an object per
+	// IAAS and day under Prefix in the S3 Bucket.
+	Type   string
+	Path   string
+	Bucket string
+	Prefix string
+	// Endpoint is the URL of an S3-compatible service, such as MinIO, to use
+	// instead of S3.
+	Endpoint string
+	// Format is csv (the default), ndjson, focus-csv or focus-ndjson.
+	Format string
+	Gzip   bool
+}
@@ -0,0 +1,141 @@
+package sink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// Partition is the IAAS and day a report is for.
+type Partition struct {
+	Resource string
+	Year     int
+	Month    time.Month
+	Day      int
+}
+
+func PartitionOf(report datamodels.Report) Partition {
+	return Partition{
+		Resource: report.Resource,
+		Year:     report.Year,
+		Month:    report.Month,
+		Day:      report.Day,
+	}
+}
+
+// Path is the Hive-style path of the partition, like
+// resource=AWS/year=2016/month=09/day=12.
+func (p Partition) Path() string {
+	return fmt.Sprintf("resource=%s/year=%04d/month=%02d/day=%02d", p.Resource, p.Year, int(p.Month), p.Day)
+}
+
+type Partitions []Partition
+
+func (p Partitions) Len() int           { return len(p) }
+func (p Partitions) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p Partitions) Less(i, j int) bool { return p[i].Path() < p[j].Path() }
+
+// partitionedFiles writes reports to a file per partition under a directory,
+// each with the header first.
+type partitionedFiles struct {
+	dir    string
+	name   string
+	format Format
+	gzip   bool
+
+	files map[Partition]*fileWriter
+	rows  map[Partition]int
+}
+
+func newPartitionedFiles(dir, name string, format Format, gzip bool) *partitionedFiles {
+	return &partitionedFiles{
+		dir:    dir,
+		name:   name,
+		format: format,
+		gzip:   gzip,
+		files:  make(map[Partition]*fileWriter),
+		rows:   make(map[Partition]int),
+	}
+}
+
+// fileName is like part.csv.gz.
+func (f *partitionedFiles) fileName() string {
+	name := f.name + f.format.Extension()
+	if f.gzip {
+		name += ".gz"
+	}
+	return name
+}
+
+// Path is where the file of the partition is written, relative to the
+// directory.
+func (f *partitionedFiles) Path(partition Partition) string {
+	return filepath.Join(filepath.FromSlash(partition.Path()), f.fileName())
+}
+
+func (f *partitionedFiles) Write(reports datamodels.Reports) error {
+	partitions := Partitions{}
+	byPartition := make(map[Partition]datamodels.Reports)
+	for _, r := range reports {
+		partition := PartitionOf(r)
+		if _, ok := byPartition[partition]; !ok {
+			partitions = append(partitions, partition)
+		}
+		byPartition[partition] = append(byPartition[partition], r)
+	}
+
+	for _, partition := range partitions {
+		file, header := f.files[partition], false
+		if file == nil {
+			path := filepath.Join(f.dir, f.Path(partition))
+			err := os.MkdirAll(filepath.Dir(path), 0755)
+			if err != nil {
+				return err
+			}
+			file, err = createFile(path, f.gzip)
+			if err != nil {
+				return err
+			}
+			f.files[partition] = file
+			header = true
+		}
+		err := f.format.Write(file, byPartition[partition], header)
+		if err != nil {
+			return err
+		}
+		f.rows[partition] += len(byPartition[partition])
+	}
+	return nil
+}
+
+// Partitions are the partitions written to, in order.
+func (f *partitionedFiles) Partitions() Partitions {
+	partitions := Partitions{}
+	for partition := range f.rows {
+		partitions = append(partitions, partition)
+	}
+	sort.Sort(partitions)
+	return partitions
+}
+
+// Rows is the number of reports written to the partition.
+func (f *partitionedFiles) Rows(partition Partition) int {
+	return f.rows[partition]
+}
+
+// Close closes every file, returning the first error.
+func (f *partitionedFiles) Close() error {
+	var firstErr error
+	for _, file := range f.files {
+		err := file.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	f.files = make(map[Partition]*fileWriter)
+	return firstErr
+}
@@ -0,0 +1,105 @@
+package sink
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// ManifestDir is the directory of a PartitionedFileSink the manifests are
+// written to. Tools reading the partitions skip it as it starts with _.
+const ManifestDir = "_manifests"
+
+// PartitionedFileSink writes the reports of a run to a file per IAAS and day
+// in a directory tree like resource=AWS/year=2016/month=09/day=12, named
+// after the run like part-20160912T060000.000000.csv.gz, so earlier runs are
+// never overwritten. The files are written to a temporary directory and only
+// renamed into the tree when the run is done, followed by a manifest in
+// _manifests/<run ID>.json listing the partitions and row counts of the run
+// and, as restated days have the files of several runs, the current file of
+// every day in the tree.
+type PartitionedFileSink struct {
+	dir    string
+	format Format
+	gzip   bool
+
+	run   datamodels.Run
+	tmp   string
+	files *partitionedFiles
+}
+
+func NewPartitionedFileSink(dir string, format Format, gzip bool) *PartitionedFileSink {
+	return &PartitionedFileSink{dir: dir, format: format, gzip: gzip}
+}
+
+func (s *PartitionedFileSink) Name() string {
+	return "partitioned files " + s.dir
+}
+
+func (s *PartitionedFileSink) Open(run datamodels.Run) error {
+	err := os.MkdirAll(s.dir, 0755)
+	if err != nil {
+		return err
+	}
+	// The temporary directory is in the tree so the files can be renamed
+	// into place rather than copied across file systems.
+	tmp, err := ioutil.TempDir(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	s.run = run
+	s.tmp = tmp
+	s.files = newPartitionedFiles(tmp, "part-"+run.ID, s.format, s.gzip)
+	return nil
+}
+
+func (s *PartitionedFileSink) Write(reports datamodels.Reports) error {
+	return s.files.Write(reports)
+}
+
+// Close moves the files of the run into the tree and writes its manifest.
+func (s *PartitionedFileSink) Close() error {
+	defer os.RemoveAll(s.tmp)
+
+	err := s.files.Close()
+	if err != nil {
+		return err
+	}
+	partitions := s.files.Partitions()
+	if len(partitions) == 0 {
+		return nil
+	}
+
+	manifest := Manifest{
+		RunID:     s.run.ID,
+		StartedAt: s.run.StartedAt,
+		Format:    s.format.Name(),
+	}
+	for _, partition := range partitions {
+		name := s.files.Path(partition)
+		path := filepath.Join(s.dir, name)
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.Rename(filepath.Join(s.tmp, name), path)
+		if err != nil {
+			return err
+		}
+		manifest.Partitions = append(manifest.Partitions, ManifestPartition{
+			Resource: partition.Resource,
+			Year:     partition.Year,
+			Month:    int(partition.Month),
+			Day:      partition.Day,
+			Path:     filepath.ToSlash(name),
+			Rows:     s.files.Rows(partition),
+		})
+	}
+	manifest.Current, err = currentPartitions(filepath.Join(s.dir, ManifestDir), s.run.ID, manifest.Partitions)
+	if err != nil {
+		return err
+	}
+	return writeManifest(filepath.Join(s.dir, ManifestDir, s.run.ID+".json"), manifest)
+}
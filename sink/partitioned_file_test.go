@@ -0,0 +1,146 @@
+package sink_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/sink"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PartitionedFileSink", func() {
+	var (
+		dir       string
+		run       datamodels.Run
+		fileSink  *PartitionedFileSink
+		readLines func(string) []string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "sink")
+		Expect(err).NotTo(HaveOccurred())
+		run = datamodels.NewRun(time.Date(2016, time.September, 18, 6, 0, 0, 0, time.UTC))
+		fileSink = NewPartitionedFileSink(filepath.Join(dir, "billing-data"), CSV{}, false)
+		readLines = func(path string) []string {
+			contents, err := ioutil.ReadFile(filepath.Join(dir, "billing-data", path))
+			Expect(err).NotTo(HaveOccurred())
+			return strings.Split(strings.TrimSpace(string(contents)), "\n")
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	writeRun := func(run datamodels.Run) {
+		Expect(fileSink.Open(run)).To(Succeed())
+		Expect(fileSink.Write(datamodels.Reports{
+			{ID: "a", Resource: "AWS", Year: 2016, Month: time.September, Day: 12},
+			{ID: "b", Resource: "AWS", Year: 2016, Month: time.September, Day: 12},
+		})).To(Succeed())
+		Expect(fileSink.Write(datamodels.Reports{
+			{ID: "c", Resource: "GCP", Year: 2016, Month: time.September, Day: 13},
+		})).To(Succeed())
+	}
+
+	It("writes a file per IAAS and day named after the run", func() {
+		writeRun(run)
+		Expect(fileSink.Close()).To(Succeed())
+
+		aws := readLines("resource=AWS/year=2016/month=09/day=12/part-20160918T060000.000000.csv")
+		Expect(aws).To(HaveLen(3))
+		Expect(aws[0]).To(HavePrefix("ID,Account Number,"))
+		Expect(readLines("resource=GCP/year=2016/month=09/day=13/part-20160918T060000.000000.csv")).To(HaveLen(2))
+	})
+
+	It("writes a manifest of the partitions and row counts of the run", func() {
+		writeRun(run)
+		Expect(fileSink.Close()).To(Succeed())
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, "billing-data", "_manifests", "20160918T060000.000000.json"))
+		Expect(err).NotTo(HaveOccurred())
+		var manifest Manifest
+		Expect(json.Unmarshal(contents, &manifest)).To(Succeed())
+		Expect(manifest.RunID).To(Equal(run.ID))
+		Expect(manifest.Format).To(Equal("normalized"))
+		Expect(manifest.Partitions).To(Equal([]ManifestPartition{
+			{Resource: "AWS", Year: 2016, Month: 9, Day: 12, Path: "resource=AWS/year=2016/month=09/day=12/part-20160918T060000.000000.csv", Rows: 2},
+			{Resource: "GCP", Year: 2016, Month: 9, Day: 13, Path: "resource=GCP/year=2016/month=09/day=13/part-20160918T060000.000000.csv", Rows: 1},
+		}))
+		Expect(manifest.Current).To(Equal(manifest.Partitions))
+	})
+
+	It("only moves the files into the tree when the run is done", func() {
+		writeRun(run)
+		_, err := os.Stat(filepath.Join(dir, "billing-data", "resource=AWS"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		Expect(fileSink.Close()).To(Succeed())
+		entries, err := ioutil.ReadDir(filepath.Join(dir, "billing-data"))
+		Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		Expect(names).To(ConsistOf("_manifests", "resource=AWS", "resource=GCP"))
+	})
+
+	Describe("when a later run restates a day", func() {
+		readManifest := func(runID string) Manifest {
+			contents, err := ioutil.ReadFile(filepath.Join(dir, "billing-data", "_manifests", runID+".json"))
+			Expect(err).NotTo(HaveOccurred())
+			var manifest Manifest
+			Expect(json.Unmarshal(contents, &manifest)).To(Succeed())
+			return manifest
+		}
+
+		BeforeEach(func() {
+			writeRun(run)
+			Expect(fileSink.Close()).To(Succeed())
+			Expect(fileSink.Open(datamodels.NewRun(run.StartedAt.AddDate(0, 0, 1)))).To(Succeed())
+			Expect(fileSink.Write(datamodels.Reports{
+				{ID: "a", Resource: "AWS", Year: 2016, Month: time.September, Day: 12},
+			})).To(Succeed())
+			Expect(fileSink.Close()).To(Succeed())
+		})
+
+		It("keeps the files of earlier runs", func() {
+			files, err := ioutil.ReadDir(filepath.Join(dir, "billing-data", "resource=AWS/year=2016/month=09/day=12"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(2))
+		})
+
+		It("can still read the files of an earlier run's manifest", func() {
+			manifest := readManifest("20160918T060000.000000")
+			Expect(manifest.Partitions).To(HaveLen(2))
+			for _, partition := range manifest.Partitions {
+				Expect(readLines(partition.Path)).To(HaveLen(partition.Rows + 1))
+			}
+		})
+
+		It("lists the current file of every day in the latest manifest", func() {
+			manifest := readManifest("20160919T060000.000000")
+			Expect(manifest.Partitions).To(HaveLen(1))
+			Expect(manifest.Current).To(Equal([]ManifestPartition{
+				{Resource: "AWS", Year: 2016, Month: 9, Day: 12, Path: "resource=AWS/year=2016/month=09/day=12/part-20160919T060000.000000.csv", Rows: 1},
+				{Resource: "GCP", Year: 2016, Month: 9, Day: 13, Path: "resource=GCP/year=2016/month=09/day=13/part-20160918T060000.000000.csv", Rows: 1},
+			}))
+		})
+	})
+
+	It("writes nothing when nothing was written", func() {
+		Expect(fileSink.Open(run)).To(Succeed())
+		Expect(fileSink.Close()).To(Succeed())
+		entries, err := ioutil.ReadDir(filepath.Join(dir, "billing-data"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+})
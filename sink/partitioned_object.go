@@ -0,0 +1,73 @@
+package sink
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+// PartitionedObjectSink writes the reports of a run to a temporary file per
+// IAAS and day, and uploads them to the store when the run is done, under the
+// prefix with a Hive-style key like
+// resource=AWS/year=2016/month=09/day=12/part.csv.gz so they can be queried in
+// place. Each run replaces the objects of the days it wrote.
+type PartitionedObjectSink struct {
+	store  ObjectStore
+	prefix string
+	format Format
+	gzip   bool
+
+	dir   string
+	files *partitionedFiles
+}
+
+func NewPartitionedObjectSink(store ObjectStore, prefix string, format Format, gzip bool) *PartitionedObjectSink {
+	return &PartitionedObjectSink{store: store, prefix: prefix, format: format, gzip: gzip}
+}
+
+func (s *PartitionedObjectSink) Name() string {
+	return "partitioned object storage " + s.prefix
+}
+
+func (s *PartitionedObjectSink) Open(run datamodels.Run) error {
+	dir, err := ioutil.TempDir("", "meteorologica")
+	if err != nil {
+		return err
+	}
+	s.dir = dir
+	s.files = newPartitionedFiles(dir, "part", s.format, s.gzip)
+	return nil
+}
+
+func (s *PartitionedObjectSink) Write(reports datamodels.Reports) error {
+	return s.files.Write(reports)
+}
+
+// Close uploads the file of every partition the run wrote.
+func (s *PartitionedObjectSink) Close() error {
+	defer os.RemoveAll(s.dir)
+
+	err := s.files.Close()
+	if err != nil {
+		return err
+	}
+	for _, partition := range s.files.Partitions() {
+		err = s.put(s.files.Path(partition))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PartitionedObjectSink) put(name string) error {
+	file, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return s.store.Put(path.Join(s.prefix, filepath.ToSlash(name)), file)
+}
@@ -0,0 +1,67 @@
+package sink_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/sink"
+	"github.com/challiwill/meteorologica/sink/sinkfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PartitionedObjectSink", func() {
+	var (
+		store      *sinkfakes.FakeObjectStore
+		objectSink *PartitionedObjectSink
+		uploaded   map[string][]string
+	)
+
+	BeforeEach(func() {
+		uploaded = make(map[string][]string)
+		store = new(sinkfakes.FakeObjectStore)
+		store.PutStub = func(key string, file *os.File) error {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				return err
+			}
+			contents, err := ioutil.ReadAll(gz)
+			uploaded[key] = strings.Split(strings.TrimSpace(string(contents)), "\n")
+			return err
+		}
+		objectSink = NewPartitionedObjectSink(store, "billing", CSV{}, true)
+		Expect(objectSink.Open(datamodels.NewRun(time.Date(2016, time.September, 18, 6, 0, 0, 0, time.UTC)))).To(Succeed())
+	})
+
+	It("uploads a file per IAAS and day with a Hive-style key", func() {
+		Expect(objectSink.Write(datamodels.Reports{
+			{ID: "a", Resource: "AWS", Year: 2016, Month: time.September, Day: 12},
+			{ID: "b", Resource: "AWS", Year: 2016, Month: time.September, Day: 13},
+		})).To(Succeed())
+		Expect(objectSink.Write(datamodels.Reports{
+			{ID: "c", Resource: "GCP", Year: 2016, Month: time.September, Day: 12},
+			{ID: "d", Resource: "AWS", Year: 2016, Month: time.September, Day: 12},
+		})).To(Succeed())
+		Expect(objectSink.Close()).To(Succeed())
+
+		Expect(store.PutCallCount()).To(Equal(3))
+		Expect(uploaded).To(HaveLen(3))
+		aws12 := uploaded["billing/resource=AWS/year=2016/month=09/day=12/part.csv.gz"]
+		Expect(aws12).To(HaveLen(3))
+		Expect(aws12[0]).To(HavePrefix("ID,Account Number,"))
+		Expect(aws12[1]).To(HavePrefix("a,"))
+		Expect(aws12[2]).To(HavePrefix("d,"))
+		Expect(uploaded["billing/resource=AWS/year=2016/month=09/day=13/part.csv.gz"]).To(HaveLen(2))
+		Expect(uploaded["billing/resource=GCP/year=2016/month=09/day=12/part.csv.gz"]).To(HaveLen(2))
+	})
+
+	It("uploads nothing when nothing was written", func() {
+		Expect(objectSink.Close()).To(Succeed())
+		Expect(store.PutCallCount()).To(Equal(0))
+	})
+})
@@ -0,0 +1,122 @@
+// This file was generated by counterfeiter
+package sinkfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/sink"
+)
+
+type FakeDatabase struct {
+	SaveReportsStub        func(datamodels.Reports) error
+	saveReportsMutex       sync.RWMutex
+	saveReportsArgsForCall []struct {
+		arg1 datamodels.Reports
+	}
+	saveReportsReturns struct {
+		result1 error
+	}
+	SaveReportVersionsStub        func(datamodels.Run, datamodels.Reports) error
+	saveReportVersionsMutex       sync.RWMutex
+	saveReportVersionsArgsForCall []struct {
+		arg1 datamodels.Run
+		arg2 datamodels.Reports
+	}
+	saveReportVersionsReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) SaveReports(arg1 datamodels.Reports) error {
+	fake.saveReportsMutex.Lock()
+	fake.saveReportsArgsForCall = append(fake.saveReportsArgsForCall, struct {
+		arg1 datamodels.Reports
+	}{arg1})
+	fake.recordInvocation("SaveReports", []interface{}{arg1})
+	fake.saveReportsMutex.Unlock()
+	if fake.SaveReportsStub != nil {
+		return fake.SaveReportsStub(arg1)
+	} else {
+		return fake.saveReportsReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveReportsCallCount() int {
+	fake.saveReportsMutex.RLock()
+	defer fake.saveReportsMutex.RUnlock()
+	return len(fake.saveReportsArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveReportsArgsForCall(i int) datamodels.Reports {
+	fake.saveReportsMutex.RLock()
+	defer fake.saveReportsMutex.RUnlock()
+	return fake.saveReportsArgsForCall[i].arg1
+}
+
+func (fake *FakeDatabase) SaveReportsReturns(result1 error) {
+	fake.SaveReportsStub = nil
+	fake.saveReportsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) SaveReportVersions(arg1 datamodels.Run, arg2 datamodels.Reports) error {
+	fake.saveReportVersionsMutex.Lock()
+	fake.saveReportVersionsArgsForCall = append(fake.saveReportVersionsArgsForCall, struct {
+		arg1 datamodels.Run
+		arg2 datamodels.Reports
+	}{arg1, arg2})
+	fake.recordInvocation("SaveReportVersions", []interface{}{arg1, arg2})
+	fake.saveReportVersionsMutex.Unlock()
+	if fake.SaveReportVersionsStub != nil {
+		return fake.SaveReportVersionsStub(arg1, arg2)
+	} else {
+		return fake.saveReportVersionsReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) SaveReportVersionsCallCount() int {
+	fake.saveReportVersionsMutex.RLock()
+	defer fake.saveReportVersionsMutex.RUnlock()
+	return len(fake.saveReportVersionsArgsForCall)
+}
+
+func (fake *FakeDatabase) SaveReportVersionsArgsForCall(i int) (datamodels.Run, datamodels.Reports) {
+	fake.saveReportVersionsMutex.RLock()
+	defer fake.saveReportVersionsMutex.RUnlock()
+	return fake.saveReportVersionsArgsForCall[i].arg1, fake.saveReportVersionsArgsForCall[i].arg2
+}
+
+func (fake *FakeDatabase) SaveReportVersionsReturns(result1 error) {
+	fake.SaveReportVersionsStub = nil
+	fake.saveReportVersionsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.saveReportsMutex.RLock()
+	defer fake.saveReportsMutex.RUnlock()
+	fake.saveReportVersionsMutex.RLock()
+	defer fake.saveReportVersionsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ sink.Database = new(FakeDatabase)
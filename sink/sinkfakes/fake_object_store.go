@@ -0,0 +1,79 @@
+// This file was generated by counterfeiter
+package sinkfakes
+
+import (
+	"os"
+	"sync"
+
+	"github.com/challiwill/meteorologica/sink"
+)
+
+type FakeObjectStore struct {
+	PutStub        func(string, *os.File) error
+	putMutex       sync.RWMutex
+	putArgsForCall []struct {
+		arg1 string
+		arg2 *os.File
+	}
+	putReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeObjectStore) Put(arg1 string, arg2 *os.File) error {
+	fake.putMutex.Lock()
+	fake.putArgsForCall = append(fake.putArgsForCall, struct {
+		arg1 string
+		arg2 *os.File
+	}{arg1, arg2})
+	fake.recordInvocation("Put", []interface{}{arg1, arg2})
+	fake.putMutex.Unlock()
+	if fake.PutStub != nil {
+		return fake.PutStub(arg1, arg2)
+	} else {
+		return fake.putReturns.result1
+	}
+}
+
+func (fake *FakeObjectStore) PutCallCount() int {
+	fake.putMutex.RLock()
+	defer fake.putMutex.RUnlock()
+	return len(fake.putArgsForCall)
+}
+
+func (fake *FakeObjectStore) PutArgsForCall(i int) (string, *os.File) {
+	fake.putMutex.RLock()
+	defer fake.putMutex.RUnlock()
+	return fake.putArgsForCall[i].arg1, fake.putArgsForCall[i].arg2
+}
+
+func (fake *FakeObjectStore) PutReturns(result1 error) {
+	fake.PutStub = nil
+	fake.putReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.putMutex.RLock()
+	defer fake.putMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeObjectStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ sink.ObjectStore = new(FakeObjectStore)
@@ -0,0 +1,38 @@
+package sink
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// fileWriter writes to a file, through gzip if asked to.
+type fileWriter struct {
+	io.Writer
+	file *os.File
+	gz   *gzip.Writer
+}
+
+func createFile(path string, gzipped bool) (*fileWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &fileWriter{Writer: file, file: file}
+	if gzipped {
+		w.gz = gzip.NewWriter(file)
+		w.Writer = w.gz
+	}
+	return w, nil
+}
+
+func (w *fileWriter) Close() error {
+	if w.gz != nil {
+		err := w.gz.Close()
+		if err != nil {
+			w.file.Close()
+			return err
+		}
+	}
+	return w.file.Close()
+}
@@ -0,0 +1,128 @@
+package units
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	GiB       = "GiB"
+	GiBMonths = "GiB-Months"
+	Hours     = "Hours"
+	Requests  = "Requests"
+)
+
+const (
+	bytesPerGiB   = 1024 * 1024 * 1024
+	secondsInHour = 60 * 60
+	hoursInMonth  = 730 // the convention AWS and Azure price monthly storage against
+)
+
+// Conversion turns a quantity measured in a provider unit into a quantity of
+// a canonical unit by multiplying it by Factor.
+type Conversion struct {
+	Unit   string
+	Factor float64
+}
+
+type Registry struct {
+	mutex       sync.RWMutex
+	conversions map[string]Conversion
+}
+
+// NewRegistry returns a Registry that knows about the units reported by AWS,
+// GCP, Azure and Cloud Foundry.
+func NewRegistry() *Registry {
+	r := &Registry{conversions: make(map[string]Conversion)}
+
+	// GCP
+	r.Register("byte-seconds", GiBMonths, 1.0/(bytesPerGiB*secondsInHour*hoursInMonth))
+	r.Register("bytes", GiB, 1.0/bytesPerGiB)
+	r.Register("seconds", Hours, 1.0/secondsInHour)
+	r.Register("requests", Requests, 1)
+
+	// AWS
+	r.Register("Hrs", Hours, 1)
+	r.Register("GB-Mo", GiBMonths, 1)
+	r.Register("GB", GiB, 1)
+
+	// Azure
+	r.Register("Hours", Hours, 1)
+	r.Register("Hour", Hours, 1)
+	r.Register("Minutes", Hours, 1.0/60)
+	r.Register("GB/Month", GiBMonths, 1)
+	r.Register("Transactions", Requests, 1)
+
+	// Cloud Foundry
+	r.Register("GiB-Hours", GiBMonths, 1.0/hoursInMonth)
+
+	return r
+}
+
+// Register adds or replaces the conversion for a provider unit. Units are
+// matched case insensitively.
+func (r *Registry) Register(providerUnit, canonicalUnit string, factor float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.conversions[key(providerUnit)] = Conversion{Unit: canonicalUnit, Factor: factor}
+}
+
+// Lookup returns the conversion for a provider unit. Units with a numeric
+// multiplier, like Azure's "10,000s" or "100 Hours", are scaled accordingly.
+func (r *Registry) Lookup(providerUnit string) (Conversion, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if c, ok := r.conversions[key(providerUnit)]; ok {
+		return c, true
+	}
+
+	multiplier, rest, ok := splitMultiplier(providerUnit)
+	if !ok {
+		return Conversion{}, false
+	}
+	if rest == "s" || rest == "" { // "10,000s" is a count of whatever the meter measures
+		return Conversion{Unit: Requests, Factor: multiplier}, true
+	}
+	if c, ok := r.conversions[key(rest)]; ok {
+		return Conversion{Unit: c.Unit, Factor: c.Factor * multiplier}, true
+	}
+	return Conversion{}, false
+}
+
+// Normalize converts a quantity in a provider unit into its canonical unit.
+// Unknown units are passed through unchanged.
+func (r *Registry) Normalize(quantity float64, providerUnit string) (float64, string) {
+	c, ok := r.Lookup(providerUnit)
+	if !ok {
+		return quantity, providerUnit
+	}
+	return quantity * c.Factor, c.Unit
+}
+
+var DefaultRegistry = NewRegistry()
+
+func Normalize(quantity float64, providerUnit string) (float64, string) {
+	return DefaultRegistry.Normalize(quantity, providerUnit)
+}
+
+func key(unit string) string {
+	return strings.ToLower(strings.TrimSpace(unit))
+}
+
+func splitMultiplier(unit string) (float64, string, bool) {
+	unit = strings.TrimSpace(unit)
+	i := 0
+	for i < len(unit) && (unit[i] >= '0' && unit[i] <= '9' || unit[i] == ',' || unit[i] == '.') {
+		i++
+	}
+	if i == 0 {
+		return 0, "", false
+	}
+	multiplier, err := strconv.ParseFloat(strings.Replace(unit[:i], ",", "", -1), 64)
+	if err != nil || multiplier == 0 {
+		return 0, "", false
+	}
+	return multiplier, strings.TrimSpace(unit[i:]), true
+}
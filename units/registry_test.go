@@ -0,0 +1,74 @@
+package units_test
+
+import (
+	. "github.com/challiwill/meteorologica/units"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var registry *Registry
+
+	BeforeEach(func() {
+		registry = NewRegistry()
+	})
+
+	Describe("Normalize", func() {
+		It("converts byte-seconds into GiB-months", func() {
+			quantity, unit := registry.Normalize(1024*1024*1024*60*60*730, "byte-seconds")
+			Expect(unit).To(Equal("GiB-Months"))
+			Expect(quantity).To(BeNumerically("~", 1, 0.000001))
+		})
+
+		It("converts seconds into hours", func() {
+			quantity, unit := registry.Normalize(7200, "seconds")
+			Expect(unit).To(Equal("Hours"))
+			Expect(quantity).To(BeNumerically("~", 2, 0.000001))
+		})
+
+		It("matches provider units case insensitively", func() {
+			quantity, unit := registry.Normalize(3, "hrs")
+			Expect(unit).To(Equal("Hours"))
+			Expect(quantity).To(Equal(3.0))
+		})
+
+		It("converts Azure monthly storage", func() {
+			quantity, unit := registry.Normalize(5, "GB/Month")
+			Expect(unit).To(Equal("GiB-Months"))
+			Expect(quantity).To(Equal(5.0))
+		})
+
+		It("converts Cloud Foundry memory hours", func() {
+			quantity, unit := registry.Normalize(1460, "GiB-Hours")
+			Expect(unit).To(Equal("GiB-Months"))
+			Expect(quantity).To(BeNumerically("~", 2.0))
+		})
+
+		It("scales units that carry a multiplier", func() {
+			quantity, unit := registry.Normalize(2, "100 Hours")
+			Expect(unit).To(Equal("Hours"))
+			Expect(quantity).To(Equal(200.0))
+
+			quantity, unit = registry.Normalize(3, "10,000s")
+			Expect(unit).To(Equal("Requests"))
+			Expect(quantity).To(Equal(30000.0))
+		})
+
+		It("passes unknown units through unchanged", func() {
+			quantity, unit := registry.Normalize(4.5, "Operation Units")
+			Expect(unit).To(Equal("Operation Units"))
+			Expect(quantity).To(Equal(4.5))
+		})
+	})
+
+	Describe("Register", func() {
+		It("adds new conversions", func() {
+			registry.Register("Minutes", "Seconds", 60)
+
+			quantity, unit := registry.Normalize(2, "minutes")
+			Expect(unit).To(Equal("Seconds"))
+			Expect(quantity).To(Equal(120.0))
+		})
+	})
+})
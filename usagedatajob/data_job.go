@@ -1,23 +1,85 @@
 package usagedatajob
 
 import (
-	"os"
-	"strconv"
-	"strings"
+	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/challiwill/meteorologica/datamodels"
-	"github.com/gocarina/gocsv"
 )
 
+//go:generate counterfeiter . IaasClient
+
 type IaasClient interface {
 	Name() string
 	GetNormalizedUsage() (datamodels.Reports, error)
 }
 
+//go:generate counterfeiter . ResourceLevelClient
+
+// ResourceLevelClient is implemented by IaasClients that can report usage per
+// resource (instance, volume, bucket...) rather than per service.
+type ResourceLevelClient interface {
+	GetResourceLevelUsage() (datamodels.Reports, error)
+}
+
+//go:generate counterfeiter . Stage
+
+// Stage is run after the usage of every IaasClient has been saved, with the
+// reports that were saved by the run.
+type Stage interface {
+	Name() string
+	Run(datamodels.Run, datamodels.Reports) error
+}
+
+//go:generate counterfeiter . Validator
+
+// Validator checks the usage of an IaasClient before it is saved and returns
+// the issues it finds. Usage with blocking issues is not saved.
+type Validator interface {
+	Validate(datamodels.Run, string, datamodels.Reports) ([]datamodels.RunIssue, error)
+}
+
+//go:generate counterfeiter . OutputSink
+
+// OutputSink is somewhere the usage of every IaasClient is written. It is
+// opened at the start of each run, written the usage of each IaasClient that
+// is to be saved, and closed at the end of the run.
+type OutputSink interface {
+	Name() string
+	Open(datamodels.Run) error
+	Write(datamodels.Reports) error
+	Close() error
+}
+
+// DatabaseSink is implemented by the OutputSink that saves usage to the
+// database. Only its writes decide whether the usage of an IaasClient was
+// saved and is passed on to the Stages; the other sinks are only outputs.
+type DatabaseSink interface {
+	SavesToDatabase() bool
+}
+
+//go:generate counterfeiter . Notifier
+
+// Notifier is told when the usage of an IaasClient is not saved, when an
+// IaasClient has not had its usage saved for FailureThreshold runs in a row,
+// and when the usage could not be written to one of the other sinks.
+type Notifier interface {
+	NotifyJobFailure(datamodels.JobFailure) error
+	NotifyProviderDown(datamodels.JobFailure) error
+	NotifyOutputFailure(datamodels.OutputFailure) error
+}
+
+//go:generate counterfeiter . DBClient
+
 type DBClient interface {
-	SaveReports(datamodels.Reports) error
+	SaveResourceReports(datamodels.Reports) error
+	GetDailyCost(string, int, time.Month, int) (float64, error)
+	StartRun(datamodels.Run) error
+	FinishRun(datamodels.Run) error
+	SaveRunIssues([]datamodels.RunIssue) error
 }
 
 type UsageDataJob struct {
@@ -25,9 +87,16 @@ type UsageDataJob struct {
 	location *time.Location
 
 	IAASClients []IaasClient
+	Validator   Validator
+	Sinks       []OutputSink
+	Stages      []Stage
+
+	Notifier         Notifier
+	FailureThreshold int
+	failures         map[string]int
 
-	saveFile bool
-	DBClient DBClient
+	resourceLevel bool
+	DBClient      DBClient
 }
 
 func NewJob(
@@ -35,7 +104,8 @@ func NewJob(
 	location *time.Location,
 	iaasClients []IaasClient,
 	dbClient DBClient,
-	saveFile bool,
+	sinks []OutputSink,
+	resourceLevel bool,
 ) *UsageDataJob {
 	return &UsageDataJob{
 		log:      log,
@@ -43,8 +113,10 @@ func NewJob(
 
 		IAASClients: iaasClients,
 		DBClient:    dbClient,
+		Sinks:       sinks,
+		failures:    make(map[string]int),
 
-		saveFile: saveFile,
+		resourceLevel: resourceLevel,
 	}
 }
 
@@ -55,53 +127,245 @@ func (j *UsageDataJob) Run() {
 	runTime := time.Now().In(j.location)
 	j.log.Infof("Running periodic job at %s ...", runTime.String())
 
-	normalizedFileName := strings.Join([]string{
-		strconv.Itoa(runTime.Year()),
-		runTime.Month().String(),
-		"normalized-billing-data.csv",
-	}, "-")
-	normalizedFile, err := os.Create(normalizedFileName)
+	run := datamodels.NewRun(runTime)
+	err := j.DBClient.StartRun(run)
 	if err != nil {
-		j.log.Fatal("Failed to create normalized file: ", err.Error())
+		j.log.Errorf("Failed to record run %s: %s", run.ID, err.Error())
 	}
 
-	for i, iaasClient := range j.IAASClients {
-		normalizedData, err := iaasClient.GetNormalizedUsage()
+	sinks := []OutputSink{}
+	for _, sink := range j.Sinks {
+		err = sink.Open(run)
 		if err != nil {
-			j.log.Errorf("Failed to get %s usage data: %s", iaasClient.Name(), err.Error())
+			j.log.Errorf("Failed to open %s, not writing to it: %s", sink.Name(), err.Error())
 			continue
 		}
+		sinks = append(sinks, sink)
+	}
 
-		j.log.Debugf("Saving %s data to database...", iaasClient.Name())
-		err = j.DBClient.SaveReports(normalizedData)
+	savedData := datamodels.Reports{}
+	for _, iaasClient := range j.IAASClients {
+		normalizedData, resourceData, err := j.getUsage(iaasClient)
 		if err != nil {
-			j.log.Errorf("Failed to save %s usage data to the database: %s", iaasClient.Name(), err.Error())
-		} else {
-			j.log.Debugf("Saved %s data to database", iaasClient.Name())
+			j.log.Errorf("Failed to get %s usage data: %s", iaasClient.Name(), err.Error())
+			j.failed(run, iaasClient.Name(), "Failed to get usage data: "+err.Error())
+			continue
 		}
 
-		if j.saveFile { // Append to file
-			j.log.Debugf("Writing %s data to file...", iaasClient.Name())
-			if i == 0 {
-				err = gocsv.MarshalFile(&normalizedData, normalizedFile)
+		if !j.validate(run, iaasClient.Name(), normalizedData) {
+			j.failed(run, iaasClient.Name(), "Usage data failed blocking validation rules")
+			continue
+		}
+
+		if len(resourceData) > 0 {
+			j.log.Debugf("Saving %s resource-level data to database...", iaasClient.Name())
+			err = j.DBClient.SaveResourceReports(resourceData)
+			if err != nil {
+				j.log.Errorf("Failed to save %s resource-level usage data to the database: %s", iaasClient.Name(), err.Error())
 			} else {
-				err = gocsv.MarshalWithoutHeaders(&normalizedData, normalizedFile)
+				j.log.Debugf("Saved %s resource-level data to database", iaasClient.Name())
 			}
+		}
+
+		j.logRestatements(normalizedData)
+
+		saved := true
+		for _, sink := range sinks {
+			j.log.Debugf("Writing %s data to %s...", iaasClient.Name(), sink.Name())
+			err = sink.Write(normalizedData)
 			if err != nil {
-				j.log.Errorf("Failed to write normalized %s data to file: %s", iaasClient.Name(), err.Error())
+				j.log.Errorf("Failed to write %s usage data to %s: %s", iaasClient.Name(), sink.Name(), err.Error())
+				if db, ok := sink.(DatabaseSink); ok && db.SavesToDatabase() {
+					saved = false
+					j.failed(run, iaasClient.Name(), "Failed to save usage data to database: "+err.Error())
+				} else {
+					j.outputFailed(run, iaasClient.Name(), sink.Name(), err.Error())
+				}
 			} else {
-				j.log.Debugf("Wrote normalized %s data to %s", iaasClient.Name(), normalizedFile.Name())
+				j.log.Debugf("Wrote %s data to %s", iaasClient.Name(), sink.Name())
 			}
 		}
+		if !saved {
+			continue
+		}
+		savedData = append(savedData, normalizedData...)
+		j.failures[iaasClient.Name()] = 0
 	}
 
-	if !j.saveFile {
-		err = os.Remove(normalizedFileName)
+	for _, sink := range sinks {
+		err = sink.Close()
 		if err != nil {
-			j.log.Warn("Failed to remove file:", normalizedFile)
+			j.log.Errorf("Failed to finish writing to %s: %s", sink.Name(), err.Error())
+		}
+	}
+
+	for _, stage := range j.Stages {
+		j.log.Debugf("Running %s stage...", stage.Name())
+		err = stage.Run(run, savedData)
+		if err != nil {
+			j.log.Errorf("Failed to run %s stage: %s", stage.Name(), err.Error())
 		}
 	}
 
 	finishedTime := time.Now().In(j.location)
+	run.FinishedAt = finishedTime
+	err = j.DBClient.FinishRun(run)
+	if err != nil {
+		j.log.Errorf("Failed to record the end of run %s: %s", run.ID, err.Error())
+	}
 	j.log.Infof("Finished periodic job at %s. It took %s.", finishedTime.String(), finishedTime.Sub(runTime).String())
 }
+
+// validate records the issues the Validator finds in the usage of an
+// IaasClient, and returns whether the usage may be saved.
+func (j *UsageDataJob) validate(run datamodels.Run, name string, reports datamodels.Reports) bool {
+	if j.Validator == nil {
+		return true
+	}
+	issues, err := j.Validator.Validate(run, name, reports)
+	if err != nil {
+		j.log.Errorf("Failed to validate %s usage data, saving it anyway: %s", name, err.Error())
+		return true
+	}
+	if len(issues) == 0 {
+		return true
+	}
+
+	blocked := false
+	for _, issue := range issues {
+		if issue.Severity == datamodels.Block {
+			blocked = true
+			j.log.Errorf("%s usage data failed %s: %s", name, issue.Rule, issue.Message)
+		} else {
+			j.log.Warnf("%s usage data failed %s: %s", name, issue.Rule, issue.Message)
+		}
+	}
+	err = j.DBClient.SaveRunIssues(issues)
+	if err != nil {
+		j.log.Errorf("Failed to record the issues with %s usage data: %s", name, err.Error())
+	}
+	if blocked {
+		j.log.Errorf("Not saving %s usage data", name)
+	}
+	return !blocked
+}
+
+// failed notifies that the usage of an IaasClient was not saved, and that the
+// IaasClient is down when it has now failed FailureThreshold runs in a row.
+func (j *UsageDataJob) failed(run datamodels.Run, name, reason string) {
+	j.failures[name]++
+	if j.Notifier == nil {
+		return
+	}
+
+	failure := datamodels.JobFailure{
+		RunID:               run.ID,
+		Resource:            name,
+		Error:               reason,
+		ConsecutiveFailures: j.failures[name],
+	}
+	err := j.Notifier.NotifyJobFailure(failure)
+	if err != nil {
+		j.log.Errorf("Failed to notify that %s usage data was not saved: %s", name, err.Error())
+	}
+	if j.FailureThreshold > 0 && failure.ConsecutiveFailures == j.FailureThreshold {
+		err = j.Notifier.NotifyProviderDown(failure)
+		if err != nil {
+			j.log.Errorf("Failed to notify that %s is down: %s", name, err.Error())
+		}
+	}
+}
+
+// outputFailed notifies that the usage of an IaasClient was not written to a
+// sink other than the database. The usage was still saved, so it does not
+// count towards the IaasClient being down.
+func (j *UsageDataJob) outputFailed(run datamodels.Run, name, output, reason string) {
+	if j.Notifier == nil {
+		return
+	}
+
+	err := j.Notifier.NotifyOutputFailure(datamodels.OutputFailure{
+		RunID:    run.ID,
+		Resource: name,
+		Output:   output,
+		Error:    reason,
+	})
+	if err != nil {
+		j.log.Errorf("Failed to notify that %s usage data was not written to %s: %s", name, output, err.Error())
+	}
+}
+
+// getUsage returns the consolidated usage of an IaasClient and, in resource
+// level mode, its resource-level usage. The consolidated usage is always the
+// IaasClient's own, so that the daily reports are the same in both modes; the
+// resource-level usage is only saved to its own table, and is left out when
+// it cannot be read.
+func (j *UsageDataJob) getUsage(iaasClient IaasClient) (datamodels.Reports, datamodels.Reports, error) {
+	normalizedData, err := iaasClient.GetNormalizedUsage()
+	if err != nil || !j.resourceLevel {
+		return normalizedData, nil, err
+	}
+
+	resourceLevelClient, ok := iaasClient.(ResourceLevelClient)
+	if !ok {
+		j.log.Warnf("%s does not report usage by resource, collecting consolidated usage only", iaasClient.Name())
+		return normalizedData, nil, nil
+	}
+
+	resourceData, err := resourceLevelClient.GetResourceLevelUsage()
+	if err != nil {
+		j.log.Errorf("Failed to get %s resource-level usage data, saving consolidated usage only: %s", iaasClient.Name(), err.Error())
+		return normalizedData, nil, nil
+	}
+	return normalizedData, resourceData, nil
+}
+
+type reportDay struct {
+	resource string
+	year     int
+	month    time.Month
+	day      int
+}
+
+func (d reportDay) String() string {
+	return fmt.Sprintf("%s %04d-%02d-%02d", d.resource, d.year, d.month, d.day)
+}
+
+type reportDays []reportDay
+
+func (d reportDays) Len() int           { return len(d) }
+func (d reportDays) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d reportDays) Less(i, j int) bool { return d[i].String() < d[j].String() }
+
+// logRestatements logs how much the total cost of each day changed compared to
+// what is already saved, as providers keep restating the last few days.
+func (j *UsageDataJob) logRestatements(reports datamodels.Reports) {
+	totals := make(map[reportDay]float64)
+	for _, r := range reports {
+		totals[reportDay{r.Resource, r.Year, r.Month, r.Day}] += r.Cost
+	}
+
+	days := reportDays{}
+	for d := range totals {
+		days = append(days, d)
+	}
+	sort.Sort(days)
+
+	for _, d := range days {
+		saved, err := j.DBClient.GetDailyCost(d.resource, d.year, d.month, d.day)
+		if err != nil {
+			j.log.Warnf("Failed to get saved cost of %s: %s", d, err.Error())
+			continue
+		}
+		if saved == 0 {
+			j.log.Debugf("Cost of %s is new: %.2f", d, totals[d])
+			continue
+		}
+		moved := totals[d] - saved
+		if math.Abs(moved) < 0.005 {
+			j.log.Debugf("Cost of %s is unchanged: %.2f", d, saved)
+			continue
+		}
+		j.log.Infof("Cost of %s was restated by %+.2f (%.2f -> %.2f)", d, moved, saved, totals[d])
+	}
+}
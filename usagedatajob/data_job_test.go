@@ -1,14 +1,345 @@
 package usagedatajob_test
 
 import (
-	// . "github.com/challiwill/meteorologica/usagedatajob"
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/usagedatajob"
+	"github.com/challiwill/meteorologica/usagedatajob/usagedatajobfakes"
 
 	. "github.com/onsi/ginkgo"
-	// . "github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
 )
 
+type fakeResourceLevelIaasClient struct {
+	*usagedatajobfakes.FakeIaasClient
+	*usagedatajobfakes.FakeResourceLevelClient
+}
+
+type fakeDatabaseSink struct {
+	*usagedatajobfakes.FakeOutputSink
+}
+
+func (fakeDatabaseSink) SavesToDatabase() bool {
+	return true
+}
+
 var _ = Describe("DataJob", func() {
+	var (
+		log           *logrus.Logger
+		dbClient      *usagedatajobfakes.FakeDBClient
+		iaasClient    *usagedatajobfakes.FakeIaasClient
+		resourceLevel bool
+		job           *UsageDataJob
+	)
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		dbClient = new(usagedatajobfakes.FakeDBClient)
+		iaasClient = new(usagedatajobfakes.FakeIaasClient)
+		iaasClient.NameReturns("some-iaas")
+		iaasClient.GetNormalizedUsageReturns(datamodels.Reports{datamodels.Report{ID: "a", Cost: 1}}, nil)
+		resourceLevel = false
+	})
+
 	Describe("Run", func() {
-		XIt("works", func() {})
+		var (
+			iaasClients []IaasClient
+			stage       *usagedatajobfakes.FakeStage
+			stages      []Stage
+			validator   Validator
+			notifier    *usagedatajobfakes.FakeNotifier
+			sink        *usagedatajobfakes.FakeOutputSink
+			sinks       []OutputSink
+		)
+
+		BeforeEach(func() {
+			iaasClients = []IaasClient{iaasClient}
+			validator = nil
+			notifier = new(usagedatajobfakes.FakeNotifier)
+			sink = new(usagedatajobfakes.FakeOutputSink)
+			sink.NameReturns("some-sink")
+			sinks = []OutputSink{sink}
+			stage = new(usagedatajobfakes.FakeStage)
+			stage.NameReturns("some-stage")
+			stages = []Stage{stage}
+		})
+
+		JustBeforeEach(func() {
+			job = NewJob(log, time.Now().Location(), iaasClients, dbClient, sinks, resourceLevel)
+			job.Stages = stages
+			job.Validator = validator
+			job.Notifier = notifier
+			job.FailureThreshold = 2
+			job.Run()
+		})
+
+		It("writes the normalized usage of each client to each sink", func() {
+			Expect(sink.WriteCallCount()).To(Equal(1))
+			Expect(sink.WriteArgsForCall(0)).To(Equal(datamodels.Reports{datamodels.Report{ID: "a", Cost: 1}}))
+			Expect(dbClient.SaveResourceReportsCallCount()).To(Equal(0))
+		})
+
+		It("opens the sinks for the run and closes them at the end", func() {
+			Expect(sink.OpenCallCount()).To(Equal(1))
+			Expect(sink.OpenArgsForCall(0)).To(Equal(dbClient.StartRunArgsForCall(0)))
+			Expect(sink.CloseCallCount()).To(Equal(1))
+		})
+
+		It("records the run", func() {
+			Expect(dbClient.StartRunCallCount()).To(Equal(1))
+			run := dbClient.StartRunArgsForCall(0)
+			Expect(run.ID).NotTo(BeEmpty())
+
+			Expect(dbClient.FinishRunCallCount()).To(Equal(1))
+			finishedRun := dbClient.FinishRunArgsForCall(0)
+			Expect(finishedRun.ID).To(Equal(run.ID))
+			Expect(finishedRun.FinishedAt).NotTo(BeZero())
+		})
+
+		It("runs each stage with the saved reports", func() {
+			Expect(stage.RunCallCount()).To(Equal(1))
+			run, reports := stage.RunArgsForCall(0)
+			Expect(run).To(Equal(dbClient.StartRunArgsForCall(0)))
+			Expect(reports).To(Equal(datamodels.Reports{datamodels.Report{ID: "a", Cost: 1}}))
+		})
+
+		Context("when a sink fails to write", func() {
+			var otherSink *usagedatajobfakes.FakeOutputSink
+
+			BeforeEach(func() {
+				sink.WriteReturns(errors.New("some-error"))
+				otherSink = new(usagedatajobfakes.FakeOutputSink)
+				sinks = append(sinks, otherSink)
+			})
+
+			It("still writes to the other sinks", func() {
+				Expect(otherSink.WriteCallCount()).To(Equal(1))
+			})
+
+			It("still passes the reports to the stages", func() {
+				_, reports := stage.RunArgsForCall(0)
+				Expect(reports).To(Equal(datamodels.Reports{datamodels.Report{ID: "a", Cost: 1}}))
+			})
+
+			It("notifies the output failure without failing the job", func() {
+				Expect(notifier.NotifyJobFailureCallCount()).To(Equal(0))
+				Expect(notifier.NotifyOutputFailureCallCount()).To(Equal(1))
+				Expect(notifier.NotifyOutputFailureArgsForCall(0)).To(Equal(datamodels.OutputFailure{
+					RunID:    dbClient.StartRunArgsForCall(0).ID,
+					Resource: "some-iaas",
+					Output:   "some-sink",
+					Error:    "some-error",
+				}))
+			})
+		})
+
+		Context("when the database sink fails to write", func() {
+			var databaseSink fakeDatabaseSink
+
+			BeforeEach(func() {
+				databaseSink = fakeDatabaseSink{new(usagedatajobfakes.FakeOutputSink)}
+				databaseSink.NameReturns("database")
+				databaseSink.WriteReturns(errors.New("some-error"))
+				sinks = append(sinks, databaseSink)
+			})
+
+			It("still writes to the other sinks", func() {
+				Expect(sink.WriteCallCount()).To(Equal(1))
+			})
+
+			It("does not pass the reports to the stages", func() {
+				_, reports := stage.RunArgsForCall(0)
+				Expect(reports).To(BeEmpty())
+			})
+
+			It("notifies the job failure", func() {
+				Expect(notifier.NotifyJobFailureCallCount()).To(Equal(1))
+				Expect(notifier.NotifyJobFailureArgsForCall(0).Error).To(Equal("Failed to save usage data to database: some-error"))
+				Expect(notifier.NotifyOutputFailureCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a sink fails to open", func() {
+			BeforeEach(func() {
+				sink.OpenReturns(errors.New("some-error"))
+			})
+
+			It("does not write to it", func() {
+				Expect(sink.WriteCallCount()).To(Equal(0))
+				Expect(sink.CloseCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("with a validator", func() {
+			var fakeValidator *usagedatajobfakes.FakeValidator
+
+			BeforeEach(func() {
+				fakeValidator = new(usagedatajobfakes.FakeValidator)
+				validator = fakeValidator
+			})
+
+			It("validates the usage of each client", func() {
+				Expect(fakeValidator.ValidateCallCount()).To(Equal(1))
+				run, name, reports := fakeValidator.ValidateArgsForCall(0)
+				Expect(run).To(Equal(dbClient.StartRunArgsForCall(0)))
+				Expect(name).To(Equal("some-iaas"))
+				Expect(reports).To(Equal(datamodels.Reports{datamodels.Report{ID: "a", Cost: 1}}))
+			})
+
+			Context("when the issues only annotate the run", func() {
+				BeforeEach(func() {
+					fakeValidator.ValidateReturns([]datamodels.RunIssue{{Rule: "some-rule", Severity: datamodels.Annotate}}, nil)
+				})
+
+				It("records the issues and saves the usage", func() {
+					Expect(dbClient.SaveRunIssuesCallCount()).To(Equal(1))
+					Expect(sink.WriteCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when an issue blocks the save", func() {
+				BeforeEach(func() {
+					fakeValidator.ValidateReturns([]datamodels.RunIssue{
+						{Rule: "some-rule", Severity: datamodels.Annotate},
+						{Rule: "other-rule", Severity: datamodels.Block},
+					}, nil)
+				})
+
+				It("records the issues and does not save the usage", func() {
+					Expect(dbClient.SaveRunIssuesArgsForCall(0)).To(HaveLen(2))
+					Expect(sink.WriteCallCount()).To(Equal(0))
+				})
+
+				It("notifies the failure", func() {
+					Expect(notifier.NotifyJobFailureArgsForCall(0).Error).To(Equal("Usage data failed blocking validation rules"))
+				})
+			})
+
+			Context("when validating fails", func() {
+				BeforeEach(func() {
+					fakeValidator.ValidateReturns(nil, errors.New("some-error"))
+				})
+
+				It("saves the usage anyway", func() {
+					Expect(sink.WriteCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when a day's cost was already saved", func() {
+			var logOutput *Buffer
+
+			BeforeEach(func() {
+				logOutput = NewBuffer()
+				log.Out = logOutput
+				iaasClient.GetNormalizedUsageReturns(datamodels.Reports{
+					datamodels.Report{ID: "a", Resource: "some-iaas", Year: 2016, Month: time.September, Day: 12, Cost: 1},
+					datamodels.Report{ID: "b", Resource: "some-iaas", Year: 2016, Month: time.September, Day: 12, Cost: 2.5},
+				}, nil)
+				dbClient.GetDailyCostReturns(3, nil)
+			})
+
+			It("logs how much the day's cost moved", func() {
+				resource, year, month, day := dbClient.GetDailyCostArgsForCall(0)
+				Expect(resource).To(Equal("some-iaas"))
+				Expect(year).To(Equal(2016))
+				Expect(month).To(Equal(time.September))
+				Expect(day).To(Equal(12))
+				Expect(logOutput).To(Say(`Cost of some-iaas 2016-09-12 was restated by \+0.50 \(3.00 -> 3.50\)`))
+			})
+		})
+
+		Context("when a client fails", func() {
+			BeforeEach(func() {
+				iaasClient.GetNormalizedUsageReturns(nil, errors.New("some-error"))
+			})
+
+			It("does not save anything", func() {
+				Expect(sink.WriteCallCount()).To(Equal(0))
+			})
+
+			It("notifies the failure", func() {
+				Expect(notifier.NotifyJobFailureCallCount()).To(Equal(1))
+				Expect(notifier.NotifyJobFailureArgsForCall(0)).To(Equal(datamodels.JobFailure{
+					RunID:               dbClient.StartRunArgsForCall(0).ID,
+					Resource:            "some-iaas",
+					Error:               "Failed to get usage data: some-error",
+					ConsecutiveFailures: 1,
+				}))
+				Expect(notifier.NotifyProviderDownCallCount()).To(Equal(0))
+			})
+
+			It("notifies the client is down once it has failed enough runs in a row", func() {
+				job.Run()
+				Expect(notifier.NotifyJobFailureCallCount()).To(Equal(2))
+				Expect(notifier.NotifyProviderDownCallCount()).To(Equal(1))
+				Expect(notifier.NotifyProviderDownArgsForCall(0).ConsecutiveFailures).To(Equal(2))
+
+				job.Run()
+				Expect(notifier.NotifyProviderDownCallCount()).To(Equal(1))
+			})
+
+			It("counts the failures again once the client succeeds", func() {
+				iaasClient.GetNormalizedUsageReturns(datamodels.Reports{datamodels.Report{ID: "a", Cost: 1}}, nil)
+				job.Run()
+				iaasClient.GetNormalizedUsageReturns(nil, errors.New("some-error"))
+				job.Run()
+				Expect(notifier.NotifyJobFailureCallCount()).To(Equal(2))
+				Expect(notifier.NotifyJobFailureArgsForCall(1).ConsecutiveFailures).To(Equal(1))
+				Expect(notifier.NotifyProviderDownCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("in resource-level mode", func() {
+			var resourceLevelClient fakeResourceLevelIaasClient
+
+			BeforeEach(func() {
+				resourceLevel = true
+				resourceLevelClient = fakeResourceLevelIaasClient{
+					FakeIaasClient:          new(usagedatajobfakes.FakeIaasClient),
+					FakeResourceLevelClient: new(usagedatajobfakes.FakeResourceLevelClient),
+				}
+				resourceLevelClient.NameReturns("some-resource-level-iaas")
+				resourceLevelClient.GetNormalizedUsageReturns(datamodels.Reports{datamodels.Report{ID: "b", Cost: 4, AccountName: "some-account"}}, nil)
+				resourceLevelClient.GetResourceLevelUsageReturns(datamodels.Reports{
+					datamodels.Report{ID: "b", Cost: 1, ResourceID: "vm-1", ResourceGroup: "group"},
+					datamodels.Report{ID: "b", Cost: 2, ResourceID: "vm-2", ResourceGroup: "group"},
+				}, nil)
+				iaasClients = []IaasClient{resourceLevelClient, iaasClient}
+			})
+
+			It("saves the resource-level usage of clients that support it", func() {
+				Expect(dbClient.SaveResourceReportsCallCount()).To(Equal(1))
+				Expect(dbClient.SaveResourceReportsArgsForCall(0)).To(HaveLen(2))
+			})
+
+			It("saves the same consolidated usage as without resource-level mode", func() {
+				Expect(resourceLevelClient.GetNormalizedUsageCallCount()).To(Equal(1))
+				Expect(sink.WriteCallCount()).To(Equal(2))
+				Expect(sink.WriteArgsForCall(0)).To(Equal(datamodels.Reports{datamodels.Report{ID: "b", Cost: 4, AccountName: "some-account"}}))
+			})
+
+			Context("when the resource-level usage cannot be read", func() {
+				BeforeEach(func() {
+					resourceLevelClient.GetResourceLevelUsageReturns(nil, errors.New("some-error"))
+				})
+
+				It("still saves the consolidated usage", func() {
+					Expect(dbClient.SaveResourceReportsCallCount()).To(Equal(0))
+					Expect(sink.WriteArgsForCall(0)).To(Equal(datamodels.Reports{datamodels.Report{ID: "b", Cost: 4, AccountName: "some-account"}}))
+					Expect(notifier.NotifyJobFailureCallCount()).To(Equal(0))
+				})
+			})
+
+			It("collects consolidated usage from clients that do not support it", func() {
+				Expect(iaasClient.GetNormalizedUsageCallCount()).To(Equal(1))
+				Expect(sink.WriteArgsForCall(1)).To(Equal(datamodels.Reports{datamodels.Report{ID: "a", Cost: 1}}))
+			})
+		})
 	})
 })
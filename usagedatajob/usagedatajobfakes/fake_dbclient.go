@@ -0,0 +1,258 @@
+// This file was generated by counterfeiter
+package usagedatajobfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/usagedatajob"
+)
+
+type FakeDBClient struct {
+	SaveResourceReportsStub        func(datamodels.Reports) error
+	saveResourceReportsMutex       sync.RWMutex
+	saveResourceReportsArgsForCall []struct {
+		arg1 datamodels.Reports
+	}
+	saveResourceReportsReturns struct {
+		result1 error
+	}
+	GetDailyCostStub        func(string, int, time.Month, int) (float64, error)
+	getDailyCostMutex       sync.RWMutex
+	getDailyCostArgsForCall []struct {
+		arg1 string
+		arg2 int
+		arg3 time.Month
+		arg4 int
+	}
+	getDailyCostReturns struct {
+		result1 float64
+		result2 error
+	}
+	StartRunStub        func(datamodels.Run) error
+	startRunMutex       sync.RWMutex
+	startRunArgsForCall []struct {
+		arg1 datamodels.Run
+	}
+	startRunReturns struct {
+		result1 error
+	}
+	FinishRunStub        func(datamodels.Run) error
+	finishRunMutex       sync.RWMutex
+	finishRunArgsForCall []struct {
+		arg1 datamodels.Run
+	}
+	finishRunReturns struct {
+		result1 error
+	}
+	SaveRunIssuesStub        func([]datamodels.RunIssue) error
+	saveRunIssuesMutex       sync.RWMutex
+	saveRunIssuesArgsForCall []struct {
+		arg1 []datamodels.RunIssue
+	}
+	saveRunIssuesReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDBClient) SaveResourceReports(arg1 datamodels.Reports) error {
+	fake.saveResourceReportsMutex.Lock()
+	fake.saveResourceReportsArgsForCall = append(fake.saveResourceReportsArgsForCall, struct {
+		arg1 datamodels.Reports
+	}{arg1})
+	fake.recordInvocation("SaveResourceReports", []interface{}{arg1})
+	fake.saveResourceReportsMutex.Unlock()
+	if fake.SaveResourceReportsStub != nil {
+		return fake.SaveResourceReportsStub(arg1)
+	} else {
+		return fake.saveResourceReportsReturns.result1
+	}
+}
+
+func (fake *FakeDBClient) SaveResourceReportsCallCount() int {
+	fake.saveResourceReportsMutex.RLock()
+	defer fake.saveResourceReportsMutex.RUnlock()
+	return len(fake.saveResourceReportsArgsForCall)
+}
+
+func (fake *FakeDBClient) SaveResourceReportsArgsForCall(i int) datamodels.Reports {
+	fake.saveResourceReportsMutex.RLock()
+	defer fake.saveResourceReportsMutex.RUnlock()
+	return fake.saveResourceReportsArgsForCall[i].arg1
+}
+
+func (fake *FakeDBClient) SaveResourceReportsReturns(result1 error) {
+	fake.SaveResourceReportsStub = nil
+	fake.saveResourceReportsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBClient) GetDailyCost(arg1 string, arg2 int, arg3 time.Month, arg4 int) (float64, error) {
+	fake.getDailyCostMutex.Lock()
+	fake.getDailyCostArgsForCall = append(fake.getDailyCostArgsForCall, struct {
+		arg1 string
+		arg2 int
+		arg3 time.Month
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("GetDailyCost", []interface{}{arg1, arg2, arg3, arg4})
+	fake.getDailyCostMutex.Unlock()
+	if fake.GetDailyCostStub != nil {
+		return fake.GetDailyCostStub(arg1, arg2, arg3, arg4)
+	} else {
+		return fake.getDailyCostReturns.result1, fake.getDailyCostReturns.result2
+	}
+}
+
+func (fake *FakeDBClient) GetDailyCostCallCount() int {
+	fake.getDailyCostMutex.RLock()
+	defer fake.getDailyCostMutex.RUnlock()
+	return len(fake.getDailyCostArgsForCall)
+}
+
+func (fake *FakeDBClient) GetDailyCostArgsForCall(i int) (string, int, time.Month, int) {
+	fake.getDailyCostMutex.RLock()
+	defer fake.getDailyCostMutex.RUnlock()
+	return fake.getDailyCostArgsForCall[i].arg1, fake.getDailyCostArgsForCall[i].arg2, fake.getDailyCostArgsForCall[i].arg3, fake.getDailyCostArgsForCall[i].arg4
+}
+
+func (fake *FakeDBClient) GetDailyCostReturns(result1 float64, result2 error) {
+	fake.GetDailyCostStub = nil
+	fake.getDailyCostReturns = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDBClient) StartRun(arg1 datamodels.Run) error {
+	fake.startRunMutex.Lock()
+	fake.startRunArgsForCall = append(fake.startRunArgsForCall, struct {
+		arg1 datamodels.Run
+	}{arg1})
+	fake.recordInvocation("StartRun", []interface{}{arg1})
+	fake.startRunMutex.Unlock()
+	if fake.StartRunStub != nil {
+		return fake.StartRunStub(arg1)
+	} else {
+		return fake.startRunReturns.result1
+	}
+}
+
+func (fake *FakeDBClient) StartRunCallCount() int {
+	fake.startRunMutex.RLock()
+	defer fake.startRunMutex.RUnlock()
+	return len(fake.startRunArgsForCall)
+}
+
+func (fake *FakeDBClient) StartRunArgsForCall(i int) datamodels.Run {
+	fake.startRunMutex.RLock()
+	defer fake.startRunMutex.RUnlock()
+	return fake.startRunArgsForCall[i].arg1
+}
+
+func (fake *FakeDBClient) StartRunReturns(result1 error) {
+	fake.StartRunStub = nil
+	fake.startRunReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBClient) FinishRun(arg1 datamodels.Run) error {
+	fake.finishRunMutex.Lock()
+	fake.finishRunArgsForCall = append(fake.finishRunArgsForCall, struct {
+		arg1 datamodels.Run
+	}{arg1})
+	fake.recordInvocation("FinishRun", []interface{}{arg1})
+	fake.finishRunMutex.Unlock()
+	if fake.FinishRunStub != nil {
+		return fake.FinishRunStub(arg1)
+	} else {
+		return fake.finishRunReturns.result1
+	}
+}
+
+func (fake *FakeDBClient) FinishRunCallCount() int {
+	fake.finishRunMutex.RLock()
+	defer fake.finishRunMutex.RUnlock()
+	return len(fake.finishRunArgsForCall)
+}
+
+func (fake *FakeDBClient) FinishRunArgsForCall(i int) datamodels.Run {
+	fake.finishRunMutex.RLock()
+	defer fake.finishRunMutex.RUnlock()
+	return fake.finishRunArgsForCall[i].arg1
+}
+
+func (fake *FakeDBClient) FinishRunReturns(result1 error) {
+	fake.FinishRunStub = nil
+	fake.finishRunReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBClient) SaveRunIssues(arg1 []datamodels.RunIssue) error {
+	fake.saveRunIssuesMutex.Lock()
+	fake.saveRunIssuesArgsForCall = append(fake.saveRunIssuesArgsForCall, struct {
+		arg1 []datamodels.RunIssue
+	}{arg1})
+	fake.recordInvocation("SaveRunIssues", []interface{}{arg1})
+	fake.saveRunIssuesMutex.Unlock()
+	if fake.SaveRunIssuesStub != nil {
+		return fake.SaveRunIssuesStub(arg1)
+	} else {
+		return fake.saveRunIssuesReturns.result1
+	}
+}
+
+func (fake *FakeDBClient) SaveRunIssuesCallCount() int {
+	fake.saveRunIssuesMutex.RLock()
+	defer fake.saveRunIssuesMutex.RUnlock()
+	return len(fake.saveRunIssuesArgsForCall)
+}
+
+func (fake *FakeDBClient) SaveRunIssuesArgsForCall(i int) []datamodels.RunIssue {
+	fake.saveRunIssuesMutex.RLock()
+	defer fake.saveRunIssuesMutex.RUnlock()
+	return fake.saveRunIssuesArgsForCall[i].arg1
+}
+
+func (fake *FakeDBClient) SaveRunIssuesReturns(result1 error) {
+	fake.SaveRunIssuesStub = nil
+	fake.saveRunIssuesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDBClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.saveResourceReportsMutex.RLock()
+	defer fake.saveResourceReportsMutex.RUnlock()
+	fake.getDailyCostMutex.RLock()
+	defer fake.getDailyCostMutex.RUnlock()
+	fake.startRunMutex.RLock()
+	defer fake.startRunMutex.RUnlock()
+	fake.finishRunMutex.RLock()
+	defer fake.finishRunMutex.RUnlock()
+	fake.saveRunIssuesMutex.RLock()
+	defer fake.saveRunIssuesMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDBClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ usagedatajob.DBClient = new(FakeDBClient)
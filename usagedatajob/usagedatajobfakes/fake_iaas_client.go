@@ -0,0 +1,102 @@
+// This file was generated by counterfeiter
+package usagedatajobfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/usagedatajob"
+)
+
+type FakeIaasClient struct {
+	NameStub        func() string
+	nameMutex       sync.RWMutex
+	nameArgsForCall []struct{}
+	nameReturns     struct {
+		result1 string
+	}
+	GetNormalizedUsageStub        func() (datamodels.Reports, error)
+	getNormalizedUsageMutex       sync.RWMutex
+	getNormalizedUsageArgsForCall []struct{}
+	getNormalizedUsageReturns     struct {
+		result1 datamodels.Reports
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeIaasClient) Name() string {
+	fake.nameMutex.Lock()
+	fake.nameArgsForCall = append(fake.nameArgsForCall, struct{}{})
+	fake.recordInvocation("Name", []interface{}{})
+	fake.nameMutex.Unlock()
+	if fake.NameStub != nil {
+		return fake.NameStub()
+	} else {
+		return fake.nameReturns.result1
+	}
+}
+
+func (fake *FakeIaasClient) NameCallCount() int {
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
+	return len(fake.nameArgsForCall)
+}
+
+func (fake *FakeIaasClient) NameReturns(result1 string) {
+	fake.NameStub = nil
+	fake.nameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIaasClient) GetNormalizedUsage() (datamodels.Reports, error) {
+	fake.getNormalizedUsageMutex.Lock()
+	fake.getNormalizedUsageArgsForCall = append(fake.getNormalizedUsageArgsForCall, struct{}{})
+	fake.recordInvocation("GetNormalizedUsage", []interface{}{})
+	fake.getNormalizedUsageMutex.Unlock()
+	if fake.GetNormalizedUsageStub != nil {
+		return fake.GetNormalizedUsageStub()
+	} else {
+		return fake.getNormalizedUsageReturns.result1, fake.getNormalizedUsageReturns.result2
+	}
+}
+
+func (fake *FakeIaasClient) GetNormalizedUsageCallCount() int {
+	fake.getNormalizedUsageMutex.RLock()
+	defer fake.getNormalizedUsageMutex.RUnlock()
+	return len(fake.getNormalizedUsageArgsForCall)
+}
+
+func (fake *FakeIaasClient) GetNormalizedUsageReturns(result1 datamodels.Reports, result2 error) {
+	fake.GetNormalizedUsageStub = nil
+	fake.getNormalizedUsageReturns = struct {
+		result1 datamodels.Reports
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIaasClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
+	fake.getNormalizedUsageMutex.RLock()
+	defer fake.getNormalizedUsageMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeIaasClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ usagedatajob.IaasClient = new(FakeIaasClient)
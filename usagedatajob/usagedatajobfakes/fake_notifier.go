@@ -0,0 +1,163 @@
+// This file was generated by counterfeiter
+package usagedatajobfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/usagedatajob"
+)
+
+type FakeNotifier struct {
+	NotifyJobFailureStub        func(datamodels.JobFailure) error
+	notifyJobFailureMutex       sync.RWMutex
+	notifyJobFailureArgsForCall []struct {
+		arg1 datamodels.JobFailure
+	}
+	notifyJobFailureReturns struct {
+		result1 error
+	}
+	NotifyProviderDownStub        func(datamodels.JobFailure) error
+	notifyProviderDownMutex       sync.RWMutex
+	notifyProviderDownArgsForCall []struct {
+		arg1 datamodels.JobFailure
+	}
+	notifyProviderDownReturns struct {
+		result1 error
+	}
+	NotifyOutputFailureStub        func(datamodels.OutputFailure) error
+	notifyOutputFailureMutex       sync.RWMutex
+	notifyOutputFailureArgsForCall []struct {
+		arg1 datamodels.OutputFailure
+	}
+	notifyOutputFailureReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeNotifier) NotifyJobFailure(arg1 datamodels.JobFailure) error {
+	fake.notifyJobFailureMutex.Lock()
+	fake.notifyJobFailureArgsForCall = append(fake.notifyJobFailureArgsForCall, struct {
+		arg1 datamodels.JobFailure
+	}{arg1})
+	fake.recordInvocation("NotifyJobFailure", []interface{}{arg1})
+	fake.notifyJobFailureMutex.Unlock()
+	if fake.NotifyJobFailureStub != nil {
+		return fake.NotifyJobFailureStub(arg1)
+	} else {
+		return fake.notifyJobFailureReturns.result1
+	}
+}
+
+func (fake *FakeNotifier) NotifyJobFailureCallCount() int {
+	fake.notifyJobFailureMutex.RLock()
+	defer fake.notifyJobFailureMutex.RUnlock()
+	return len(fake.notifyJobFailureArgsForCall)
+}
+
+func (fake *FakeNotifier) NotifyJobFailureArgsForCall(i int) datamodels.JobFailure {
+	fake.notifyJobFailureMutex.RLock()
+	defer fake.notifyJobFailureMutex.RUnlock()
+	return fake.notifyJobFailureArgsForCall[i].arg1
+}
+
+func (fake *FakeNotifier) NotifyJobFailureReturns(result1 error) {
+	fake.NotifyJobFailureStub = nil
+	fake.notifyJobFailureReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotifier) NotifyProviderDown(arg1 datamodels.JobFailure) error {
+	fake.notifyProviderDownMutex.Lock()
+	fake.notifyProviderDownArgsForCall = append(fake.notifyProviderDownArgsForCall, struct {
+		arg1 datamodels.JobFailure
+	}{arg1})
+	fake.recordInvocation("NotifyProviderDown", []interface{}{arg1})
+	fake.notifyProviderDownMutex.Unlock()
+	if fake.NotifyProviderDownStub != nil {
+		return fake.NotifyProviderDownStub(arg1)
+	} else {
+		return fake.notifyProviderDownReturns.result1
+	}
+}
+
+func (fake *FakeNotifier) NotifyProviderDownCallCount() int {
+	fake.notifyProviderDownMutex.RLock()
+	defer fake.notifyProviderDownMutex.RUnlock()
+	return len(fake.notifyProviderDownArgsForCall)
+}
+
+func (fake *FakeNotifier) NotifyProviderDownArgsForCall(i int) datamodels.JobFailure {
+	fake.notifyProviderDownMutex.RLock()
+	defer fake.notifyProviderDownMutex.RUnlock()
+	return fake.notifyProviderDownArgsForCall[i].arg1
+}
+
+func (fake *FakeNotifier) NotifyProviderDownReturns(result1 error) {
+	fake.NotifyProviderDownStub = nil
+	fake.notifyProviderDownReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotifier) NotifyOutputFailure(arg1 datamodels.OutputFailure) error {
+	fake.notifyOutputFailureMutex.Lock()
+	fake.notifyOutputFailureArgsForCall = append(fake.notifyOutputFailureArgsForCall, struct {
+		arg1 datamodels.OutputFailure
+	}{arg1})
+	fake.recordInvocation("NotifyOutputFailure", []interface{}{arg1})
+	fake.notifyOutputFailureMutex.Unlock()
+	if fake.NotifyOutputFailureStub != nil {
+		return fake.NotifyOutputFailureStub(arg1)
+	} else {
+		return fake.notifyOutputFailureReturns.result1
+	}
+}
+
+func (fake *FakeNotifier) NotifyOutputFailureCallCount() int {
+	fake.notifyOutputFailureMutex.RLock()
+	defer fake.notifyOutputFailureMutex.RUnlock()
+	return len(fake.notifyOutputFailureArgsForCall)
+}
+
+func (fake *FakeNotifier) NotifyOutputFailureArgsForCall(i int) datamodels.OutputFailure {
+	fake.notifyOutputFailureMutex.RLock()
+	defer fake.notifyOutputFailureMutex.RUnlock()
+	return fake.notifyOutputFailureArgsForCall[i].arg1
+}
+
+func (fake *FakeNotifier) NotifyOutputFailureReturns(result1 error) {
+	fake.NotifyOutputFailureStub = nil
+	fake.notifyOutputFailureReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotifier) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.notifyJobFailureMutex.RLock()
+	defer fake.notifyJobFailureMutex.RUnlock()
+	fake.notifyProviderDownMutex.RLock()
+	defer fake.notifyProviderDownMutex.RUnlock()
+	fake.notifyOutputFailureMutex.RLock()
+	defer fake.notifyOutputFailureMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeNotifier) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ usagedatajob.Notifier = new(FakeNotifier)
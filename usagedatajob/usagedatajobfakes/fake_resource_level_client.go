@@ -0,0 +1,69 @@
+// This file was generated by counterfeiter
+package usagedatajobfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/usagedatajob"
+)
+
+type FakeResourceLevelClient struct {
+	GetResourceLevelUsageStub        func() (datamodels.Reports, error)
+	getResourceLevelUsageMutex       sync.RWMutex
+	getResourceLevelUsageArgsForCall []struct{}
+	getResourceLevelUsageReturns     struct {
+		result1 datamodels.Reports
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeResourceLevelClient) GetResourceLevelUsage() (datamodels.Reports, error) {
+	fake.getResourceLevelUsageMutex.Lock()
+	fake.getResourceLevelUsageArgsForCall = append(fake.getResourceLevelUsageArgsForCall, struct{}{})
+	fake.recordInvocation("GetResourceLevelUsage", []interface{}{})
+	fake.getResourceLevelUsageMutex.Unlock()
+	if fake.GetResourceLevelUsageStub != nil {
+		return fake.GetResourceLevelUsageStub()
+	} else {
+		return fake.getResourceLevelUsageReturns.result1, fake.getResourceLevelUsageReturns.result2
+	}
+}
+
+func (fake *FakeResourceLevelClient) GetResourceLevelUsageCallCount() int {
+	fake.getResourceLevelUsageMutex.RLock()
+	defer fake.getResourceLevelUsageMutex.RUnlock()
+	return len(fake.getResourceLevelUsageArgsForCall)
+}
+
+func (fake *FakeResourceLevelClient) GetResourceLevelUsageReturns(result1 datamodels.Reports, result2 error) {
+	fake.GetResourceLevelUsageStub = nil
+	fake.getResourceLevelUsageReturns = struct {
+		result1 datamodels.Reports
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeResourceLevelClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getResourceLevelUsageMutex.RLock()
+	defer fake.getResourceLevelUsageMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeResourceLevelClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ usagedatajob.ResourceLevelClient = new(FakeResourceLevelClient)
@@ -0,0 +1,83 @@
+// This file was generated by counterfeiter
+package usagedatajobfakes
+
+import (
+	"sync"
+
+	"github.com/challiwill/meteorologica/datamodels"
+	"github.com/challiwill/meteorologica/usagedatajob"
+)
+
+type FakeValidator struct {
+	ValidateStub        func(datamodels.Run, string, datamodels.Reports) ([]datamodels.RunIssue, error)
+	validateMutex       sync.RWMutex
+	validateArgsForCall []struct {
+		arg1 datamodels.Run
+		arg2 string
+		arg3 datamodels.Reports
+	}
+	validateReturns struct {
+		result1 []datamodels.RunIssue
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeValidator) Validate(arg1 datamodels.Run, arg2 string, arg3 datamodels.Reports) ([]datamodels.RunIssue, error) {
+	fake.validateMutex.Lock()
+	fake.validateArgsForCall = append(fake.validateArgsForCall, struct {
+		arg1 datamodels.Run
+		arg2 string
+		arg3 datamodels.Reports
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("Validate", []interface{}{arg1, arg2, arg3})
+	fake.validateMutex.Unlock()
+	if fake.ValidateStub != nil {
+		return fake.ValidateStub(arg1, arg2, arg3)
+	} else {
+		return fake.validateReturns.result1, fake.validateReturns.result2
+	}
+}
+
+func (fake *FakeValidator) ValidateCallCount() int {
+	fake.validateMutex.RLock()
+	defer fake.validateMutex.RUnlock()
+	return len(fake.validateArgsForCall)
+}
+
+func (fake *FakeValidator) ValidateArgsForCall(i int) (datamodels.Run, string, datamodels.Reports) {
+	fake.validateMutex.RLock()
+	defer fake.validateMutex.RUnlock()
+	return fake.validateArgsForCall[i].arg1, fake.validateArgsForCall[i].arg2, fake.validateArgsForCall[i].arg3
+}
+
+func (fake *FakeValidator) ValidateReturns(result1 []datamodels.RunIssue, result2 error) {
+	fake.ValidateStub = nil
+	fake.validateReturns = struct {
+		result1 []datamodels.RunIssue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeValidator) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.validateMutex.RLock()
+	defer fake.validateMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeValidator) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ usagedatajob.Validator = new(FakeValidator)
@@ -0,0 +1,201 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+const (
+	NonNegativeCost    = "non-negative-cost"
+	CompleteDays       = "complete-days"
+	ExpectedAccounts   = "expected-accounts"
+	MaxDayOverDaySwing = "max-day-over-day-swing"
+)
+
+// maxExamples is how many offending rows or days an issue lists.
+const maxExamples = 5
+
+// RuleConfig configures a rule. Resource limits it to the usage of one IAAS,
+// Accounts are the accounts the expected-accounts rule expects, and Percent
+// is the largest change in a day's cost the max-day-over-day-swing rule
+// accepts.
+type RuleConfig struct {
+	Rule     string
+	Severity string
+	Resource string
+	Accounts []string
+	Percent  float64
+}
+
+func (c RuleConfig) validate() error {
+	if c.Severity != datamodels.Block && c.Severity != datamodels.Annotate {
+		return fmt.Errorf("Validation rule %s must have a %s or %s severity", c.Rule, datamodels.Block, datamodels.Annotate)
+	}
+	switch c.Rule {
+	case NonNegativeCost, CompleteDays:
+	case ExpectedAccounts:
+		if len(c.Accounts) == 0 {
+			return fmt.Errorf("Validation rule %s must list the accounts", c.Rule)
+		}
+	case MaxDayOverDaySwing:
+		if c.Percent <= 0 {
+			return fmt.Errorf("Validation rule %s must have a positive percent", c.Rule)
+		}
+	default:
+		return fmt.Errorf("Unknown validation rule %q", c.Rule)
+	}
+	return nil
+}
+
+// check returns what is wrong with the usage of a resource. saved is the cost
+// of each day that is already saved for the resource.
+func (c RuleConfig) check(reports datamodels.Reports, saved map[time.Time]float64) []string {
+	switch c.Rule {
+	case NonNegativeCost:
+		return checkNonNegativeCost(reports)
+	case CompleteDays:
+		return checkCompleteDays(reports, saved)
+	case ExpectedAccounts:
+		return checkExpectedAccounts(reports, c.Accounts)
+	case MaxDayOverDaySwing:
+		return checkDayOverDaySwing(reports, saved, c.Percent)
+	}
+	return nil
+}
+
+func checkNonNegativeCost(reports datamodels.Reports) []string {
+	negative := []string{}
+	for _, r := range reports {
+		if r.Cost < 0 {
+			negative = append(negative, fmt.Sprintf("%s %s on %s costs %.2f", r.AccountNumber, r.ServiceType, dateOf(r).Format("2006-01-02"), r.Cost))
+		}
+	}
+	if len(negative) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("%d rows have a negative cost: %s", len(negative), examples(negative))}
+}
+
+// checkCompleteDays expects every day of each month up to the latest day of
+// the usage to have been saved before or be part of the usage.
+func checkCompleteDays(reports datamodels.Reports, saved map[time.Time]float64) []string {
+	days := dailyTotals(reports)
+	if len(days) == 0 {
+		return nil
+	}
+	earliest, latest := dayRange(days)
+
+	missing := []string{}
+	start, _, _ := calendar.MonthContaining(earliest)
+	for day := start; !day.After(latest); day = day.AddDate(0, 0, 1) {
+		_, inUsage := days[day]
+		_, inSaved := saved[day]
+		if !inUsage && !inSaved {
+			missing = append(missing, day.Format("2006-01-02"))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("%d days have no usage: %s", len(missing), examples(missing))}
+}
+
+// checkExpectedAccounts expects every account to be part of the usage, and
+// every row of the usage to have an account.
+func checkExpectedAccounts(reports datamodels.Reports, accounts []string) []string {
+	present := make(map[string]bool)
+	withoutAccount := 0
+	for _, r := range reports {
+		if r.AccountNumber == "" {
+			withoutAccount++
+		}
+		present[r.AccountNumber] = true
+	}
+
+	issues := []string{}
+	missing := []string{}
+	for _, account := range accounts {
+		if !present[account] {
+			missing = append(missing, account)
+		}
+	}
+	if len(missing) > 0 {
+		issues = append(issues, fmt.Sprintf("%d expected accounts have no usage: %s", len(missing), examples(missing)))
+	}
+	if withoutAccount > 0 {
+		issues = append(issues, fmt.Sprintf("%d rows have no account", withoutAccount))
+	}
+	return issues
+}
+
+// checkDayOverDaySwing compares the cost of each day of the usage with the day
+// before, from the usage or from what is saved. Days after a day without any
+// cost are not compared.
+func checkDayOverDaySwing(reports datamodels.Reports, saved map[time.Time]float64, percent float64) []string {
+	days := dailyTotals(reports)
+	swings := []string{}
+	for _, day := range sortedDays(days) {
+		yesterday := day.AddDate(0, 0, -1)
+		previous, ok := days[yesterday]
+		if !ok {
+			previous = saved[yesterday]
+		}
+		if previous <= 0 {
+			continue
+		}
+		change := (days[day] - previous) / previous * 100
+		if math.Abs(change) > percent {
+			swings = append(swings, fmt.Sprintf("%s %+.0f%% (%.2f -> %.2f)", day.Format("2006-01-02"), change, previous, days[day]))
+		}
+	}
+	if len(swings) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("%d days changed by more than %g%%: %s", len(swings), percent, examples(swings))}
+}
+
+func dateOf(r datamodels.Report) time.Time {
+	return time.Date(r.Year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)
+}
+
+func dailyTotals(reports datamodels.Reports) map[time.Time]float64 {
+	totals := make(map[time.Time]float64)
+	for _, r := range reports {
+		totals[dateOf(r)] += r.Cost
+	}
+	return totals
+}
+
+// dayRange returns the earliest and latest of the days.
+func dayRange(days map[time.Time]float64) (time.Time, time.Time) {
+	sorted := sortedDays(days)
+	return sorted[0], sorted[len(sorted)-1]
+}
+
+type dayList []time.Time
+
+func (d dayList) Len() int           { return len(d) }
+func (d dayList) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d dayList) Less(i, j int) bool { return d[i].Before(d[j]) }
+
+func sortedDays(days map[time.Time]float64) []time.Time {
+	sorted := dayList{}
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Sort(sorted)
+	return sorted
+}
+
+func examples(items []string) string {
+	if len(items) > maxExamples {
+		return strings.Join(items[:maxExamples], ", ") + fmt.Sprintf(" and %d more", len(items)-maxExamples)
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/calendar"
+	"github.com/challiwill/meteorologica/datamodels"
+)
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	GetDailyCosts(from, to time.Time) ([]datamodels.DailyCost, error)
+}
+
+// Validator checks the usage of each IAAS against the configured rules before
+// it is saved.
+type Validator struct {
+	log   *logrus.Logger
+	db    Database
+	rules []RuleConfig
+}
+
+func NewValidator(log *logrus.Logger, db Database, rules []RuleConfig) (*Validator, error) {
+	for _, r := range rules {
+		err := r.validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Validator{log: log, db: db, rules: rules}, nil
+}
+
+// Validate returns the issues the rules find in the usage of the resource.
+func (v *Validator) Validate(run datamodels.Run, resource string, reports datamodels.Reports) ([]datamodels.RunIssue, error) {
+	v.log.Debug("Entering validation.Validate")
+	defer v.log.Debug("Returning validation.Validate")
+
+	issues := []datamodels.RunIssue{}
+	if len(reports) == 0 {
+		return issues, nil
+	}
+	saved, err := v.savedCosts(resource, reports)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range v.rules {
+		if rule.Resource != "" && rule.Resource != resource {
+			continue
+		}
+		for _, message := range rule.check(reports, saved) {
+			issues = append(issues, datamodels.RunIssue{
+				RunID:    run.ID,
+				Resource: resource,
+				Rule:     rule.Rule,
+				Severity: rule.Severity,
+				Message:  message,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// savedCosts returns the cost already saved for each day of the resource from
+// the start of the earliest month of the usage, and the day before it.
+func (v *Validator) savedCosts(resource string, reports datamodels.Reports) (map[time.Time]float64, error) {
+	days := dailyTotals(reports)
+	earliest, latest := dayRange(days)
+	from := earliest.AddDate(0, 0, -1)
+	if start, _, _ := calendar.MonthContaining(earliest); start.Before(from) {
+		from = start
+	}
+
+	costs, err := v.db.GetDailyCosts(from, latest)
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[time.Time]float64)
+	for _, c := range costs {
+		if c.Resource == resource {
+			saved[c.Date()] += c.Cost
+		}
+	}
+	return saved, nil
+}
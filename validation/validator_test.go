@@ -0,0 +1,157 @@
+package validation_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/challiwill/meteorologica/datamodels"
+	. "github.com/challiwill/meteorologica/validation"
+	"github.com/challiwill/meteorologica/validation/validationfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Validator", func() {
+	var (
+		log     *logrus.Logger
+		db      *validationfakes.FakeDatabase
+		rules   []RuleConfig
+		reports datamodels.Reports
+		run     datamodels.Run
+		issues  []datamodels.RunIssue
+		err     error
+	)
+
+	report := func(account string, day int, cost float64) datamodels.Report {
+		return datamodels.Report{Resource: "AWS", AccountNumber: account, ServiceType: "EC2", Year: 2016, Month: time.October, Day: day, Cost: cost}
+	}
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.Out = NewBuffer()
+		db = new(validationfakes.FakeDatabase)
+		db.GetDailyCostsReturns([]datamodels.DailyCost{
+			{Resource: "AWS", Year: 2016, Month: time.October, Day: 1, Cost: 100},
+			{Resource: "AWS", Year: 2016, Month: time.October, Day: 2, Cost: 100},
+			{Resource: "GCP", Year: 2016, Month: time.October, Day: 3, Cost: 100},
+		}, nil)
+		reports = datamodels.Reports{report("123", 3, 60), report("456", 3, 50), report("123", 4, 110)}
+		run = datamodels.NewRun(time.Date(2016, time.October, 5, 6, 0, 0, 0, time.UTC))
+	})
+
+	JustBeforeEach(func() {
+		validator, newErr := NewValidator(log, db, rules)
+		Expect(newErr).NotTo(HaveOccurred())
+		issues, err = validator.Validate(run, "AWS", reports)
+	})
+
+	Context("with valid usage", func() {
+		BeforeEach(func() {
+			rules = []RuleConfig{
+				{Rule: NonNegativeCost, Severity: datamodels.Block},
+				{Rule: CompleteDays, Severity: datamodels.Annotate},
+				{Rule: ExpectedAccounts, Severity: datamodels.Annotate, Accounts: []string{"123", "456"}},
+				{Rule: MaxDayOverDaySwing, Severity: datamodels.Annotate, Percent: 50},
+			}
+		})
+
+		It("finds no issues", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issues).To(BeEmpty())
+		})
+
+		It("reads what is saved from the start of the month", func() {
+			from, to := db.GetDailyCostsArgsForCall(0)
+			Expect(from).To(Equal(time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(to).To(Equal(time.Date(2016, time.October, 4, 0, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Context("with a negative cost", func() {
+		BeforeEach(func() {
+			rules = []RuleConfig{{Rule: NonNegativeCost, Severity: datamodels.Block}}
+			reports = append(reports, report("789", 4, -3.2))
+		})
+
+		It("finds an issue with the rule's severity", func() {
+			Expect(issues).To(Equal([]datamodels.RunIssue{{
+				RunID:    run.ID,
+				Resource: "AWS",
+				Rule:     NonNegativeCost,
+				Severity: datamodels.Block,
+				Message:  "1 rows have a negative cost: 789 EC2 on 2016-10-04 costs -3.20",
+			}}))
+		})
+	})
+
+	Context("with missing days", func() {
+		BeforeEach(func() {
+			rules = []RuleConfig{{Rule: CompleteDays, Severity: datamodels.Annotate}}
+			reports = datamodels.Reports{report("123", 5, 100)}
+		})
+
+		It("lists the days of the month that have no usage", func() {
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].Message).To(Equal("2 days have no usage: 2016-10-03, 2016-10-04"))
+		})
+	})
+
+	Context("with missing accounts", func() {
+		BeforeEach(func() {
+			rules = []RuleConfig{{Rule: ExpectedAccounts, Severity: datamodels.Annotate, Accounts: []string{"123", "999"}}}
+			reports = append(reports, report("", 4, 1))
+		})
+
+		It("lists the accounts without usage and counts the rows without an account", func() {
+			Expect(issues).To(HaveLen(2))
+			Expect(issues[0].Message).To(Equal("1 expected accounts have no usage: 999"))
+			Expect(issues[1].Message).To(Equal("1 rows have no account"))
+		})
+	})
+
+	Context("with a large swing", func() {
+		BeforeEach(func() {
+			rules = []RuleConfig{{Rule: MaxDayOverDaySwing, Severity: datamodels.Annotate, Percent: 5}}
+		})
+
+		It("compares each day with the day before, saved or not", func() {
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].Message).To(Equal("1 days changed by more than 5%: 2016-10-03 +10% (100.00 -> 110.00)"))
+		})
+	})
+
+	Context("with a rule for another resource", func() {
+		BeforeEach(func() {
+			rules = []RuleConfig{{Rule: MaxDayOverDaySwing, Severity: datamodels.Annotate, Percent: 5, Resource: "GCP"}}
+		})
+
+		It("does not apply it", func() {
+			Expect(issues).To(BeEmpty())
+		})
+	})
+
+	Context("when what is saved cannot be read", func() {
+		BeforeEach(func() {
+			rules = []RuleConfig{{Rule: CompleteDays, Severity: datamodels.Annotate}}
+			db.GetDailyCostsReturns(nil, errors.New("some-error"))
+		})
+
+		It("errors", func() {
+			Expect(err).To(MatchError("some-error"))
+		})
+	})
+
+	Describe("NewValidator", func() {
+		It("rejects unknown rules and severities", func() {
+			_, err := NewValidator(log, db, []RuleConfig{{Rule: "no-cats", Severity: datamodels.Block}})
+			Expect(err).To(MatchError(ContainSubstring("Unknown validation rule")))
+			_, err = NewValidator(log, db, []RuleConfig{{Rule: NonNegativeCost, Severity: "panic"}})
+			Expect(err).To(HaveOccurred())
+			_, err = NewValidator(log, db, []RuleConfig{{Rule: MaxDayOverDaySwing, Severity: datamodels.Block}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})